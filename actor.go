@@ -0,0 +1,104 @@
+package eloquent
+
+import (
+	"context"
+	"sync"
+)
+
+// ActorResolver returns who is performing a write, such as the id of the authenticated
+// user stored in ctx by middleware, or nil when it is unknown
+type ActorResolver func(ctx context.Context) interface{}
+
+var (
+	actorResolver   ActorResolver
+	actorResolverMu sync.RWMutex
+)
+
+// SetActorResolver sets the resolver filling the blame columns of Blameable models and
+// the deleted_by column of soft-deleted ones. Query writes pass the query's context and
+// model writes the context set with WithContext. Passing nil records no actor.
+func SetActorResolver(resolver ActorResolver) {
+	actorResolverMu.Lock()
+	defer actorResolverMu.Unlock()
+	actorResolver = resolver
+}
+
+// resolveActor returns the actor of a write made with ctx, or nil
+func resolveActor(ctx context.Context) interface{} {
+	actorResolverMu.RLock()
+	resolver := actorResolver
+	actorResolverMu.RUnlock()
+
+	if resolver == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return resolver(ctx)
+}
+
+// WithContext sets the context the model's writes resolve their actor from, typically
+// the request context
+func (m *BaseModel) WithContext(ctx context.Context) *BaseModel {
+	m.ctx = ctx
+	return m
+}
+
+// Context returns the context set with WithContext, or context.Background
+func (m *BaseModel) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// Blameable records who created and last updated each record, as returned by the actor
+// resolver, in created_by and updated_by. Other column names can be passed in that order.
+// Writes whose actor is unknown leave the columns untouched.
+func (m *BaseModel) Blameable(columns ...string) *BaseModel {
+	m.createdBy, m.updatedBy = "created_by", "updated_by"
+	if len(columns) > 0 {
+		m.createdBy = columns[0]
+	}
+	if len(columns) > 1 {
+		m.updatedBy = columns[1]
+	}
+	return m
+}
+
+// GetCreatedByColumn returns the column recording who created a record, or ""
+func (m *BaseModel) GetCreatedByColumn() string {
+	return m.createdBy
+}
+
+// GetUpdatedByColumn returns the column recording who last updated a record, or ""
+func (m *BaseModel) GetUpdatedByColumn() string {
+	return m.updatedBy
+}
+
+// blame sets the blame attributes of a model about to be inserted or updated
+func (m *BaseModel) blame(inserting bool) {
+	values := make(map[string]interface{}, 2)
+	m.blameValues(m.Context(), values, inserting)
+	for column, value := range values {
+		m.SetAttribute(column, value)
+	}
+}
+
+// blameValues adds the blame columns of a write made with ctx to values
+func (m *BaseModel) blameValues(ctx context.Context, values map[string]interface{}, inserting bool) {
+	if m.createdBy == "" && m.updatedBy == "" {
+		return
+	}
+	actor := resolveActor(ctx)
+	if actor == nil {
+		return
+	}
+	if inserting && m.createdBy != "" {
+		values[m.createdBy] = actor
+	}
+	if m.updatedBy != "" {
+		values[m.updatedBy] = actor
+	}
+}
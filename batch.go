@@ -0,0 +1,465 @@
+package eloquent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxBatchParams bounds the placeholders in a single batched statement.
+// It matches SQLite's historical default limit of 999 host parameters.
+const maxBatchParams = 999
+
+// Collection is a list of models that can be persisted together
+type Collection []Model
+
+// Save persists every new or dirty model in the collection, see SaveAll
+func (c Collection) Save() error {
+	return SaveAll(c)
+}
+
+// SaveAll persists many models with as few statements as possible, for import and sync jobs.
+// New models are inserted with multi-row INSERTs, and dirty models that share the same
+// changes are written with a single UPDATE ... WHERE key IN (...). Clean models are skipped,
+// and Updating and Updated hooks run for every updated model. An UPDATE that misses some of
+// its rows fails with an error naming their keys.
+// Models on the same connection are saved in one transaction; models on different
+// connections are saved in one transaction per connection.
+func SaveAll(models []Model) error {
+	batches, err := prepareBatches(models)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		if err := batch.exec(); err != nil {
+			return err
+		}
+		batch.finish()
+	}
+	return nil
+}
+
+// batchSave collects the statements for the models saved on one connection
+type batchSave struct {
+	conn       *Connection
+	inserted   []*BaseModel
+	updated    []*BaseModel
+	deleted    []*BaseModel
+	history    []batchStatement
+	statements []batchStatement
+
+	publisher EventPublisher
+	events    []ModelEvent
+
+	insertGroups map[string]*insertGroup
+	insertOrder  []string
+	updateGroups map[string]*updateGroup
+	updateOrder  []string
+
+	// slugs generated in the batch, keyed by table and slug
+	slugs map[string]bool
+	// last positions assigned in the batch, keyed by table and list
+	positions map[string]int64
+}
+
+// batchStatement is a single query of a batch with its arguments
+type batchStatement struct {
+	query string
+	args  []interface{}
+
+	// keys lists the primary keys of the rows an UPDATE or DELETE must reach, in the
+	// table and column named by table and primaryKey
+	keys       []interface{}
+	table      string
+	primaryKey string
+	delete     bool
+}
+
+// insertGroup holds new models of one table that set the same columns
+type insertGroup struct {
+	table   string
+	columns []string
+	// rows holds the attributes inserted for each model
+	rows []map[string]interface{}
+}
+
+// updateGroup holds existing models of one table that received the same changes
+type updateGroup struct {
+	table      string
+	primaryKey string
+	columns    []string
+	values     []interface{}
+	keys       []interface{}
+}
+
+// prepareBatches validates the models and groups their writes by connection
+func prepareBatches(models []Model) ([]*batchSave, error) {
+	var batches []*batchSave
+	byConn := make(map[*Connection]*batchSave)
+	seen := make(map[*BaseModel]bool)
+	now := time.Now()
+
+	for _, model := range models {
+		m := baseModelOf(model)
+		if m == nil {
+			return nil, fmt.Errorf("model %T does not support batch save", model)
+		}
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+
+		if m.IsReadOnly() {
+			return nil, ErrReadOnly
+		}
+
+		db, err := m.resolveConnection()
+		if err != nil {
+			return nil, err
+		}
+
+		batch, ok := byConn[db]
+		if !ok {
+			batch = &batchSave{
+				conn:         db,
+				insertGroups: make(map[string]*insertGroup),
+				updateGroups: make(map[string]*updateGroup),
+				slugs:        make(map[string]bool),
+				positions:    make(map[string]int64),
+			}
+			byConn[db] = batch
+			batches = append(batches, batch)
+		}
+
+		if m.exists {
+			if err := batch.addUpdate(m, now); err != nil {
+				return nil, err
+			}
+		} else if err := batch.addInsert(m, now); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, batch := range batches {
+		batch.build()
+	}
+	return batches, nil
+}
+
+// addInsert prepares a new model the same way Save does and queues it for insertion
+func (b *batchSave) addInsert(m *BaseModel, now time.Time) error {
+	if m.timestamps {
+		m.SetAttribute(m.createdAt, now)
+		m.SetAttribute(m.updatedAt, now)
+	}
+	m.blame(true)
+	if err := m.fillSlug(b.conn, true, b.slugs); err != nil {
+		return err
+	}
+	if err := m.fillPosition(b.conn, b.positions); err != nil {
+		return err
+	}
+	if m.GetAttribute(m.primaryKey) == nil {
+		m.SetAttribute(m.primaryKey, generateID())
+	}
+
+	attributes, err := m.insertAttributes()
+	if err != nil {
+		return err
+	}
+	columns := make([]string, 0, len(attributes))
+	for key := range attributes {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+
+	key := m.qualifiedTable() + "\x00" + strings.Join(columns, ",")
+	group, ok := b.insertGroups[key]
+	if !ok {
+		group = &insertGroup{table: m.qualifiedTable(), columns: columns}
+		b.insertGroups[key] = group
+		b.insertOrder = append(b.insertOrder, key)
+	}
+	group.rows = append(group.rows, attributes)
+	b.inserted = append(b.inserted, m)
+	return nil
+}
+
+// addUpdate queues the dirty attributes of an existing model, grouping it with
+// models of the same table that received identical changes
+func (b *batchSave) addUpdate(m *BaseModel, now time.Time) error {
+	m.syncFieldsToAttributes()
+	m.syncPrimaryKeyToAttributes()
+
+	if len(m.dirtyColumns()) == 0 {
+		return nil
+	}
+	if err := m.fireUpdating(); err != nil {
+		return err
+	}
+	b.addHistory(m, now)
+	if m.timestamps {
+		m.SetAttribute(m.updatedAt, now)
+	}
+	m.blame(false)
+	if err := m.fillSlug(b.conn, false, b.slugs); err != nil {
+		return err
+	}
+
+	dirty := m.GetDirty()
+	columns := m.dirtyColumns()
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = dirty[column]
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%#v", m.qualifiedTable(), strings.Join(columns, ","), values)
+	group, ok := b.updateGroups[key]
+	if !ok {
+		group = &updateGroup{
+			table:      m.qualifiedTable(),
+			primaryKey: m.primaryKey,
+			columns:    columns,
+			values:     values,
+		}
+		b.updateGroups[key] = group
+		b.updateOrder = append(b.updateOrder, key)
+	}
+	group.keys = append(group.keys, m.GetAttribute(m.primaryKey))
+	b.updated = append(b.updated, m)
+	return nil
+}
+
+// dirtyColumns returns the sorted names of the changed attributes, excluding the primary key
+func (m *BaseModel) dirtyColumns() []string {
+	var columns []string
+	for column := range m.GetDirty() {
+		if column != m.primaryKey {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// build turns the queued groups into statements, splitting them to respect maxBatchParams
+func (b *batchSave) build() {
+	for _, key := range b.insertOrder {
+		group := b.insertGroups[key]
+
+		perStatement := maxBatchParams / len(group.columns)
+		if perStatement < 1 {
+			perStatement = 1
+		}
+
+		row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(group.columns)), ", ") + ")"
+		for start := 0; start < len(group.rows); start += perStatement {
+			end := start + perStatement
+			if end > len(group.rows) {
+				end = len(group.rows)
+			}
+
+			rows := make([]string, 0, end-start)
+			args := make([]interface{}, 0, (end-start)*len(group.columns))
+			for _, attributes := range group.rows[start:end] {
+				rows = append(rows, row)
+				for _, column := range group.columns {
+					args = append(args, attributes[column])
+				}
+			}
+
+			b.statements = append(b.statements, batchStatement{
+				query: fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+					b.conn.prefixTable(group.table),
+					strings.Join(group.columns, ", "),
+					strings.Join(rows, ", ")),
+				args: args,
+			})
+		}
+	}
+
+	b.statements = append(b.statements, b.history...)
+	for _, key := range b.updateOrder {
+		group := b.updateGroups[key]
+
+		setParts := make([]string, len(group.columns))
+		for i, column := range group.columns {
+			setParts[i] = fmt.Sprintf("%s = ?", column)
+		}
+
+		perStatement := maxBatchParams - len(group.columns)
+		if perStatement < 1 {
+			perStatement = 1
+		}
+
+		for start := 0; start < len(group.keys); start += perStatement {
+			end := start + perStatement
+			if end > len(group.keys) {
+				end = len(group.keys)
+			}
+			keys := group.keys[start:end]
+
+			args := make([]interface{}, 0, len(group.values)+len(keys))
+			args = append(args, group.values...)
+			args = append(args, keys...)
+
+			b.statements = append(b.statements, batchStatement{
+				query: fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (%s)",
+					b.conn.prefixTable(group.table),
+					strings.Join(setParts, ", "),
+					group.primaryKey,
+					strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")),
+				args:       args,
+				keys:       keys,
+				table:      b.conn.prefixTable(group.table),
+				primaryKey: group.primaryKey,
+			})
+		}
+	}
+}
+
+// checkKeys returns an error naming the keys of a statement that affected fewer rows than
+// it has keys. Updates are checked against the table, since MySQL does not count rows an
+// update left unchanged; deleted rows cannot be looked up again, so deletes fail outright.
+func (b *batchSave) checkKeys(tx *sqlx.Tx, stmt batchStatement, rowsAffected int64) error {
+	if stmt.delete {
+		return fmt.Errorf("deleted %d of %d rows from %s, records may not exist", rowsAffected, len(stmt.keys), stmt.table)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%s)",
+		stmt.primaryKey, stmt.table, stmt.primaryKey,
+		strings.TrimSuffix(strings.Repeat("?, ", len(stmt.keys)), ", "))
+	start := time.Now()
+	var found []interface{}
+	err := tx.Select(&found, tx.Rebind(query), stmt.keys...)
+	b.conn.observe(query, stmt.keys, start, err)
+	if err != nil {
+		return fmt.Errorf("failed to check updated rows: %w", err)
+	}
+
+	existing := make(map[string]bool, len(found))
+	for _, key := range found {
+		if raw, ok := key.([]byte); ok {
+			key = string(raw)
+		}
+		existing[fmt.Sprint(key)] = true
+	}
+	var missing []interface{}
+	for _, key := range stmt.keys {
+		if !existing[fmt.Sprint(key)] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no rows were updated in %s for %s %v, records may not exist", stmt.table, stmt.primaryKey, missing)
+	}
+	return nil
+}
+
+// exec runs the batch's statements in a single transaction
+func (b *batchSave) exec() error {
+	if len(b.statements) == 0 {
+		return nil
+	}
+
+	return b.conn.Transaction(b.execIn)
+}
+
+// execIn runs the batch's statements in an open transaction. Events for its models are
+// collected here and stored in the same transaction when the publisher supports it.
+func (b *batchSave) execIn(tx *sqlx.Tx) error {
+	b.publisher = GetEventPublisher()
+	if b.publisher != nil {
+		b.events = b.modelEvents()
+	}
+
+	for _, stmt := range b.statements {
+		start := time.Now()
+		result, err := tx.Exec(tx.Rebind(stmt.query), stmt.args...)
+		b.conn.observe(stmt.query, stmt.args, start, err)
+		if err != nil {
+			return fmt.Errorf("failed to save batch: %w", err)
+		}
+
+		if len(stmt.keys) == 0 {
+			continue
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rowsAffected < int64(len(stmt.keys)) {
+			if err := b.checkKeys(tx, stmt, rowsAffected); err != nil {
+				return err
+			}
+		}
+	}
+
+	if txPublisher, ok := b.publisher.(TxEventPublisher); ok && len(b.events) > 0 {
+		if err := txPublisher.PublishTx(tx, b.events); err != nil {
+			return fmt.Errorf("failed to store model events: %w", err)
+		}
+	}
+	return nil
+}
+
+// modelEvents describes the batch's writes before they are marked saved
+func (b *batchSave) modelEvents() []ModelEvent {
+	events := make([]ModelEvent, 0, len(b.inserted)+len(b.updated)+len(b.deleted))
+	for _, m := range b.inserted {
+		events = append(events, m.newModelEvent(EventCreated, nil))
+	}
+	for _, m := range b.updated {
+		dirty := m.GetDirty()
+		if m.usesSoftDeletes() && dirty[m.deletedAt] != nil {
+			events = append(events, m.newModelEvent(EventDeleted, nil))
+			continue
+		}
+		events = append(events, m.newModelEvent(EventUpdated, dirty))
+	}
+	for _, m := range b.deleted {
+		events = append(events, m.newModelEvent(EventDeleted, nil))
+	}
+	return events
+}
+
+// finish marks the batch's models as saved once the transaction has committed and
+// publishes their events
+func (b *batchSave) finish() {
+	b.markSaved()
+	publishEvents(b.publisher, b.unpublished())
+}
+
+// markSaved marks the batch's models as saved, after its statements have run
+func (b *batchSave) markSaved() {
+	for _, m := range b.inserted {
+		m.exists = true
+		m.wasRecentlyCreated = true
+	}
+	for _, m := range b.updated {
+		m.SyncChanges()
+	}
+	for _, m := range append(b.inserted, b.updated...) {
+		m.syncOriginal()
+		m.syncAttributesToFields()
+	}
+	for _, m := range b.updated {
+		m.fireUpdated()
+	}
+	for _, m := range b.deleted {
+		m.exists = false
+	}
+}
+
+// unpublished returns the batch's events that were not stored in its transaction by a
+// TxEventPublisher and are left to publish once it has committed
+func (b *batchSave) unpublished() []ModelEvent {
+	if _, stored := b.publisher.(TxEventPublisher); b.publisher == nil || stored {
+		return nil
+	}
+	return b.events
+}
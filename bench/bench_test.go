@@ -0,0 +1,251 @@
+package bench
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/crashana/go-eloquent"
+)
+
+func BenchmarkQueryBuild(b *testing.B) {
+	conn := Open(b, Drivers()[0])
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		eloquent.NewQueryBuilder(conn).
+			Table("bench_users").
+			Select("id", "name", "email").
+			Where("active", true).
+			Where("age", ">", 30).
+			WhereIn("id", []interface{}{"u1", "u2", "u3"}).
+			OrderBy("name", "asc").
+			Limit(20).
+			ToSQL()
+	}
+}
+
+func BenchmarkHydrate(b *testing.B) {
+	for _, driver := range Drivers() {
+		conn := Open(b, driver)
+		if err := Seed(conn, 10000, 0); err != nil {
+			b.Fatalf("failed to seed %s: %v", driver.Name, err)
+		}
+		users := Users(conn)
+
+		for _, rows := range []int{1000, 10000} {
+			prefix := fmt.Sprintf("%s/%d", driver.Name, rows)
+			b.Run(prefix+"/eloquent", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := users.Query().Select("id", "name", "email", "age", "active").Limit(rows).Get(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(prefix+"/eloquent-maps", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := conn.Table("bench_users").Select("id", "name", "email", "age", "active").Limit(rows).Get(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(prefix+"/database-sql", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := ScanSQL(conn.DB.DB, rows); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(prefix+"/sqlx", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := ScanSQLX(conn.DB, rows); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	for _, driver := range Drivers() {
+		conn := Open(b, driver)
+		if err := Seed(conn, 0, 0); err != nil {
+			b.Fatalf("failed to seed %s: %v", driver.Name, err)
+		}
+		next := 0
+		newID := func() string {
+			next++
+			return fmt.Sprintf("i%08d", next)
+		}
+
+		b.Run(driver.Name+"/eloquent-save", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				user := NewUser(conn)
+				user.Fill(map[string]interface{}{"name": "Ada", "email": "ada@example.com", "age": int64(36), "active": true})
+				user.SetAttribute("id", newID())
+				if err := user.Save(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(driver.Name+"/eloquent-save-all", func(b *testing.B) {
+			b.ReportAllocs()
+			batch := make([]eloquent.Model, 0, 100)
+			for i := 0; i < b.N; i++ {
+				user := NewUser(conn)
+				user.Fill(map[string]interface{}{"name": "Ada", "email": "ada@example.com", "age": int64(36), "active": true})
+				user.SetAttribute("id", newID())
+				batch = append(batch, user)
+				if len(batch) == cap(batch) || i == b.N-1 {
+					if err := eloquent.SaveAll(batch); err != nil {
+						b.Fatal(err)
+					}
+					batch = batch[:0]
+				}
+			}
+		})
+		b.Run(driver.Name+"/database-sql", func(b *testing.B) {
+			b.ReportAllocs()
+			query := conn.DB.Rebind("INSERT INTO bench_users (id, name, email, age, active) VALUES (?, ?, ?, ?, ?)")
+			for i := 0; i < b.N; i++ {
+				if _, err := conn.DB.DB.Exec(query, newID(), "Ada", "ada@example.com", int64(36), true); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEagerLoad(b *testing.B) {
+	for _, driver := range Drivers() {
+		conn := Open(b, driver)
+		if err := Seed(conn, 1000, 5); err != nil {
+			b.Fatalf("failed to seed %s: %v", driver.Name, err)
+		}
+		users := Users(conn)
+
+		b.Run(driver.Name+"/eloquent", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := users.Query().With("posts").Limit(100).Get(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(driver.Name+"/database-sql", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rows, err := ScanSQL(conn.DB.DB, 100)
+				if err != nil {
+					b.Fatal(err)
+				}
+				ids := make([]string, len(rows))
+				args := make([]interface{}, len(rows))
+				for j, row := range rows {
+					ids[j] = "?"
+					args[j] = row.ID
+				}
+				query := conn.DB.Rebind("SELECT id, user_id, title FROM bench_posts WHERE user_id IN (" + strings.Join(ids, ", ") + ")")
+				posts, err := conn.DB.DB.Query(query, args...)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for posts.Next() {
+					var id, userID, title string
+					if err := posts.Scan(&id, &userID, &title); err != nil {
+						b.Fatal(err)
+					}
+				}
+				posts.Close()
+			}
+		})
+	}
+}
+
+func TestSeedAndModels(t *testing.T) {
+	conn := Open(t, Drivers()[0])
+	if err := Seed(conn, 20, 2); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	users, err := Users(conn).Query().With("posts").OrderBy("id", "asc").Get()
+	if err != nil {
+		t.Fatalf("Failed to query users: %v", err)
+	}
+	if len(users) != 20 || users[1].Name != "User 1" || users[1].Age != 21 {
+		t.Fatalf("Expected 20 hydrated users, got %d", len(users))
+	}
+	if posts, ok := users[0].GetRelation("posts").([]map[string]interface{}); !ok || len(posts) != 2 {
+		t.Errorf("Expected 2 eager loaded posts, got %v", users[0].GetRelation("posts"))
+	}
+
+	rows, err := ScanSQLX(conn.DB, 5)
+	if err != nil || len(rows) != 5 {
+		t.Errorf("Unexpected sqlx baseline rows %v, %v", rows, err)
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	baseline, err := ParseResults(strings.NewReader(`goos: linux
+BenchmarkQueryBuild-8           	  500000	      2000 ns/op	     900 B/op	      20 allocs/op
+BenchmarkQueryBuild-8           	  500000	      1900 ns/op	     900 B/op	      20 allocs/op
+BenchmarkHydrate/sqlite/1000/eloquent-8 	    500	   2000000 ns/op	  800000 B/op	   12000 allocs/op
+PASS`))
+	if err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if baseline["BenchmarkQueryBuild"].NsPerOp != 1900 {
+		t.Errorf("Expected the fastest run to be kept, got %+v", baseline["BenchmarkQueryBuild"])
+	}
+
+	current := map[string]Result{
+		"BenchmarkQueryBuild":                    {NsPerOp: 2000, AllocsPerOp: 30},
+		"BenchmarkHydrate/sqlite/1000/eloquent":  {NsPerOp: 2500000, AllocsPerOp: 12000},
+		"BenchmarkHydrate/sqlite/10000/eloquent": {NsPerOp: 1},
+	}
+	regressions := Regressions(baseline, current, 0.1)
+	if len(regressions) != 2 || regressions[0].Name != "BenchmarkHydrate/sqlite/1000/eloquent" || regressions[1].Metric != "allocs/op" {
+		t.Errorf("Unexpected regressions %v", regressions)
+	}
+}
+
+// TestRegressionGate fails when the results in BENCH_CURRENT regressed against those in
+// BENCH_BASELINE by more than BENCH_TOLERANCE, 0.2 by default; make bench-gate runs it
+func TestRegressionGate(t *testing.T) {
+	baselinePath, currentPath := os.Getenv("BENCH_BASELINE"), os.Getenv("BENCH_CURRENT")
+	if baselinePath == "" || currentPath == "" {
+		t.Skip("BENCH_BASELINE and BENCH_CURRENT are not set")
+	}
+
+	tolerance := 0.2
+	if value := os.Getenv("BENCH_TOLERANCE"); value != "" {
+		var err error
+		if tolerance, err = strconv.ParseFloat(value, 64); err != nil {
+			t.Fatalf("Invalid BENCH_TOLERANCE: %v", err)
+		}
+	}
+
+	read := func(path string) map[string]Result {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open results: %v", err)
+		}
+		defer f.Close()
+		results, err := ParseResults(f)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		return results
+	}
+
+	for _, regression := range Regressions(read(baselinePath), read(currentPath), tolerance) {
+		t.Error(regression)
+	}
+}
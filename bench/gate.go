@@ -0,0 +1,99 @@
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result holds a benchmark's measurements from go test -bench output
+type Result struct {
+	NsPerOp     float64
+	BytesPerOp  float64
+	AllocsPerOp float64
+}
+
+// Regression is a measurement that grew beyond the tolerance
+type Regression struct {
+	Name     string
+	Metric   string
+	Baseline float64
+	Current  float64
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s went from %.0f to %.0f (%+.1f%%)", r.Name, r.Metric, r.Baseline, r.Current, 100*(r.Current/r.Baseline-1))
+}
+
+// procsSuffix is the -GOMAXPROCS suffix go test adds to benchmark names
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// ParseResults reads go test -bench output. Benchmarks run several times, with -count,
+// keep their fastest run.
+func ParseResults(r io.Reader) (map[string]Result, error) {
+	results := make(map[string]Result)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		var result Result
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed benchmark line %q", scanner.Text())
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				result.NsPerOp = value
+			case "B/op":
+				result.BytesPerOp = value
+			case "allocs/op":
+				result.AllocsPerOp = value
+			}
+		}
+
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if previous, ok := results[name]; !ok || result.NsPerOp < previous.NsPerOp {
+			results[name] = result
+		}
+	}
+	return results, scanner.Err()
+}
+
+// Regressions compares the benchmarks in both result sets and returns those whose time or
+// allocations per operation grew by more than tolerance, 0.1 for 10%, sorted by name
+func Regressions(baseline, current map[string]Result, tolerance float64) []Regression {
+	var regressions []Regression
+	for name, before := range baseline {
+		after, ok := current[name]
+		if !ok {
+			continue
+		}
+		metrics := []struct {
+			name          string
+			before, after float64
+		}{
+			{"ns/op", before.NsPerOp, after.NsPerOp},
+			{"allocs/op", before.AllocsPerOp, after.AllocsPerOp},
+		}
+		for _, metric := range metrics {
+			if metric.before > 0 && metric.after > metric.before*(1+tolerance) {
+				regressions = append(regressions, Regression{Name: name, Metric: metric.name, Baseline: metric.before, Current: metric.after})
+			}
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		if regressions[i].Name != regressions[j].Name {
+			return regressions[i].Name < regressions[j].Name
+		}
+		return regressions[i].Metric < regressions[j].Metric
+	})
+	return regressions
+}
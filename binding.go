@@ -0,0 +1,59 @@
+package eloquent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ParamFunc reads a route parameter from a request. chi.URLParam has this signature.
+type ParamFunc func(r *http.Request, name string) string
+
+// bindingKey is the context key holding the model bound to a route parameter
+type bindingKey[T Model] struct {
+	param string
+}
+
+// Bind returns net/http middleware that resolves a route parameter to a model by primary
+// key and stores it in the request context, like Laravel's implicit route model binding.
+// Missing models get a 404 response and lookup failures a 500. Handlers read the model
+// with Bound:
+//
+//	r.With(eloquent.Bind(models.User, "user", chi.URLParam)).Get("/users/{user}", showUser)
+func Bind[T Model](static *ModelStatic[T], param string, paramFunc ParamFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, err := BindContext(r.Context(), static, param, paramFunc(r, param))
+			if errors.Is(err, ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BindContext finds the model with the given primary key and returns a context holding it
+// under the route parameter's name. It backs Bind and can be used to write the same
+// middleware for routers with their own handler types, such as gin or echo.
+func BindContext[T Model](ctx context.Context, static *ModelStatic[T], param string, id string) (context.Context, error) {
+	if id == "" {
+		return ctx, ErrNotFound
+	}
+
+	model, err := static.WithContext(ctx).Find(id)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, bindingKey[T]{param: param}, model), nil
+}
+
+// Bound returns the model bound to a route parameter by Bind or BindContext
+func Bound[T Model](ctx context.Context, param string) (T, bool) {
+	model, ok := ctx.Value(bindingKey[T]{param: param}).(T)
+	return model, ok
+}
@@ -0,0 +1,48 @@
+package eloquent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	fake := Fake(t)
+
+	lastSegment := func(r *http.Request, _ string) string {
+		return path.Base(r.URL.Path)
+	}
+
+	var bound *CustomerModel
+	handler := Bind(loaderCustomers, "customer", lastSegment)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bound, _ = Bound[*CustomerModel](r.Context(), "customer")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	fake.QueueRows(map[string]interface{}{"id": int64(7), "name": "Ada"})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/customers/7", nil))
+
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("Expected the handler to run, got status %d", recorder.Code)
+	}
+	if bound == nil || bound.GetAttribute("name") != "Ada" {
+		t.Fatalf("Expected the customer to be bound, got %v", bound)
+	}
+	if queries := fake.QueriesFor("customers"); len(queries) != 1 || queries[0].Args[0] != "7" {
+		t.Errorf("Expected one lookup by the path parameter, got %v", queries)
+	}
+
+	// Unknown keys are answered with a 404 without reaching the handler
+	bound = nil
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/customers/8", nil))
+	if recorder.Code != http.StatusNotFound || bound != nil {
+		t.Errorf("Expected 404 for a missing customer, got %d", recorder.Code)
+	}
+
+	if _, ok := Bound[*CustomerModel](httptest.NewRequest(http.MethodGet, "/", nil).Context(), "customer"); ok {
+		t.Error("Expected no model bound to an unrelated request")
+	}
+}
@@ -0,0 +1,851 @@
+package eloquent
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Blueprint describes the columns, indexes and constraints of a table for
+// SchemaBuilder.Create and SchemaBuilder.Table:
+//
+//	err := eloquent.Schema().Create("users", func(t *eloquent.Blueprint) {
+//		t.Increments("id")
+//		t.String("email").Unique()
+//		t.Integer("age").Default(0)
+//		t.Timestamps()
+//		t.Index("last_name", "first_name")
+//		t.Check("age >= 0")
+//	})
+type Blueprint struct {
+	table    string
+	creating bool
+	err      error
+
+	columns         []*ColumnDefinition
+	indexes         []*IndexDefinition
+	checks          []*CheckDefinition
+	foreignKeys     []*ForeignKeyDefinition
+	dropIndexes     []string
+	dropConstraints []constraintDrop
+
+	algorithm string
+	lock      string
+	runner    AlterRunner
+}
+
+// ColumnDefinition is a column added by a Blueprint, configured with chained modifiers
+type ColumnDefinition struct {
+	blueprint *Blueprint
+	name      string
+	kind      string
+	sqlType   string
+	length    int
+	precision int
+	scale     int
+
+	nullable     bool
+	hasDefault   bool
+	defaultValue interface{}
+	primary      bool
+}
+
+// IndexDefinition is an index or primary key added by a Blueprint
+type IndexDefinition struct {
+	kind    string
+	name    string
+	columns []string
+}
+
+// CheckDefinition is a check constraint added by a Blueprint
+type CheckDefinition struct {
+	name       string
+	expression string
+}
+
+// ForeignKeyDefinition is a foreign key constraint added by a Blueprint, completed with
+// References and On
+type ForeignKeyDefinition struct {
+	name       string
+	columns    []string
+	references []string
+	on         string
+	onDelete   string
+	onUpdate   string
+}
+
+// constraintDrop is a named constraint removed by a Blueprint
+type constraintDrop struct {
+	kind string
+	name string
+}
+
+// sqliteRebuild lists the table constraints SQLite can only add or drop by rebuilding the table
+type sqliteRebuild struct {
+	add  []string
+	drop []string
+}
+
+// Index kinds
+const (
+	indexPrimary  = "primary"
+	indexUnique   = "unique"
+	indexPlain    = "index"
+	indexFullText = "fulltext"
+	indexSpatial  = "spatial"
+)
+
+// Create creates a table described by define
+func (sb *SchemaBuilder) Create(table string, define func(*Blueprint)) error {
+	return sb.build(&Blueprint{table: table, creating: true}, define)
+}
+
+// Table changes an existing table: it adds and drops the columns, indexes and constraints
+// described by define. SQLite cannot add or drop table constraints such as foreign keys,
+// so the table is rebuilt with the changes, keeping its rows and indexes.
+func (sb *SchemaBuilder) Table(table string, define func(*Blueprint)) error {
+	return sb.build(&Blueprint{table: table}, define)
+}
+
+// DropIfExists drops a table if it exists
+func (sb *SchemaBuilder) DropIfExists(table string) error {
+	if err := validateColumn(table); err != nil {
+		return err
+	}
+	return sb.exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", sb.connection.prefixTable(table)))
+}
+
+// build runs the statements of a blueprint
+func (sb *SchemaBuilder) build(blueprint *Blueprint, define func(*Blueprint)) error {
+	if sb.connection == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+	define(blueprint)
+	if blueprint.runner != nil && blueprint.online(sb.connection.Driver) {
+		return sb.runAlter(blueprint)
+	}
+
+	statements, rebuild, err := blueprint.compile(sb.connection)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := sb.exec(statement); err != nil {
+			return err
+		}
+	}
+	if rebuild != nil {
+		return sb.rebuildSQLiteTable(sb.connection.prefixTable(blueprint.table), rebuild)
+	}
+	return nil
+}
+
+// Columns
+
+// Increments adds an auto-incrementing integer primary key
+func (b *Blueprint) Increments(name string) *ColumnDefinition {
+	return b.addColumn(name, "increments")
+}
+
+// String adds a VARCHAR column, 255 characters long unless a length is given
+func (b *Blueprint) String(name string, length ...int) *ColumnDefinition {
+	column := b.addColumn(name, "string")
+	column.length = 255
+	if len(length) > 0 {
+		column.length = length[0]
+	}
+	return column
+}
+
+// Text adds a TEXT column
+func (b *Blueprint) Text(name string) *ColumnDefinition {
+	return b.addColumn(name, "text")
+}
+
+// Integer adds an INTEGER column
+func (b *Blueprint) Integer(name string) *ColumnDefinition {
+	return b.addColumn(name, "integer")
+}
+
+// BigInteger adds a BIGINT column
+func (b *Blueprint) BigInteger(name string) *ColumnDefinition {
+	return b.addColumn(name, "bigInteger")
+}
+
+// Boolean adds a BOOLEAN column
+func (b *Blueprint) Boolean(name string) *ColumnDefinition {
+	return b.addColumn(name, "boolean")
+}
+
+// Float adds a double precision floating point column
+func (b *Blueprint) Float(name string) *ColumnDefinition {
+	return b.addColumn(name, "float")
+}
+
+// Decimal adds a DECIMAL column with the given precision and scale
+func (b *Blueprint) Decimal(name string, precision, scale int) *ColumnDefinition {
+	column := b.addColumn(name, "decimal")
+	column.precision, column.scale = precision, scale
+	return column
+}
+
+// Timestamp adds a date and time column
+func (b *Blueprint) Timestamp(name string) *ColumnDefinition {
+	return b.addColumn(name, "timestamp")
+}
+
+// JSON adds a JSON column, stored as text where the database has no JSON type
+func (b *Blueprint) JSON(name string) *ColumnDefinition {
+	return b.addColumn(name, "json")
+}
+
+// Column adds a column of a database-specific type, such as GEOMETRY or INET
+func (b *Blueprint) Column(name, sqlType string) *ColumnDefinition {
+	column := b.addColumn(name, "")
+	column.sqlType = sqlType
+	return column
+}
+
+// Timestamps adds nullable created_at and updated_at columns
+func (b *Blueprint) Timestamps() {
+	b.Timestamp("created_at").Nullable()
+	b.Timestamp("updated_at").Nullable()
+}
+
+// SoftDeletes adds the nullable deleted_at column used by WithSoftDeletes
+func (b *Blueprint) SoftDeletes() {
+	b.Timestamp("deleted_at").Nullable()
+}
+
+// addColumn appends a column of the given kind
+func (b *Blueprint) addColumn(name, kind string) *ColumnDefinition {
+	if err := validateColumn(name); err != nil && b.err == nil {
+		b.err = err
+	}
+	column := &ColumnDefinition{blueprint: b, name: name, kind: kind}
+	b.columns = append(b.columns, column)
+	return column
+}
+
+// Nullable allows the column to hold NULL
+func (c *ColumnDefinition) Nullable() *ColumnDefinition {
+	c.nullable = true
+	return c
+}
+
+// Default sets the column's default value. Strings are quoted, booleans and numbers are
+// written as literals.
+func (c *ColumnDefinition) Default(value interface{}) *ColumnDefinition {
+	c.hasDefault = true
+	c.defaultValue = value
+	return c
+}
+
+// Primary makes the column the table's primary key
+func (c *ColumnDefinition) Primary() *ColumnDefinition {
+	c.primary = true
+	return c
+}
+
+// Unique adds a unique index on the column
+func (c *ColumnDefinition) Unique() *ColumnDefinition {
+	c.blueprint.Unique(c.name)
+	return c
+}
+
+// Index adds an index on the column
+func (c *ColumnDefinition) Index() *ColumnDefinition {
+	c.blueprint.Index(c.name)
+	return c
+}
+
+// Indexes and constraints
+
+// Primary sets a primary key over one or more columns
+func (b *Blueprint) Primary(columns ...string) *IndexDefinition {
+	return b.addIndex(indexPrimary, columns)
+}
+
+// Unique adds a unique index over one or more columns
+func (b *Blueprint) Unique(columns ...string) *IndexDefinition {
+	return b.addIndex(indexUnique, columns)
+}
+
+// Index adds an index over one or more columns
+func (b *Blueprint) Index(columns ...string) *IndexDefinition {
+	return b.addIndex(indexPlain, columns)
+}
+
+// FullTextIndex adds a full-text index: FULLTEXT on MySQL and a GIN index over the columns'
+// text search vector on PostgreSQL. SQLite falls back to a plain index.
+func (b *Blueprint) FullTextIndex(columns ...string) *IndexDefinition {
+	return b.addIndex(indexFullText, columns)
+}
+
+// SpatialIndex adds a spatial index: SPATIAL on MySQL and GiST on PostgreSQL. SQLite falls
+// back to a plain index.
+func (b *Blueprint) SpatialIndex(columns ...string) *IndexDefinition {
+	return b.addIndex(indexSpatial, columns)
+}
+
+// DropIndex drops the named index
+func (b *Blueprint) DropIndex(name string) {
+	if err := validateColumn(name); err != nil && b.err == nil {
+		b.err = err
+	}
+	b.dropIndexes = append(b.dropIndexes, name)
+}
+
+// Foreign adds a foreign key constraint on one or more columns:
+//
+//	t.Foreign("user_id").References("id").On("users").OnDelete("cascade")
+func (b *Blueprint) Foreign(columns ...string) *ForeignKeyDefinition {
+	for _, column := range columns {
+		if err := validateColumn(column); err != nil && b.err == nil {
+			b.err = err
+		}
+	}
+	foreignKey := &ForeignKeyDefinition{
+		name:    b.table + "_" + strings.Join(columns, "_") + "_foreign",
+		columns: columns,
+	}
+	b.foreignKeys = append(b.foreignKeys, foreignKey)
+	return foreignKey
+}
+
+// DropForeign drops the named foreign key constraint
+func (b *Blueprint) DropForeign(name string) {
+	b.dropConstraint("foreign", name)
+}
+
+// DropCheck drops the named check constraint
+func (b *Blueprint) DropCheck(name string) {
+	b.dropConstraint("check", name)
+}
+
+// DropPrimary drops the table's primary key, named <table>_pkey unless a name is given
+func (b *Blueprint) DropPrimary(name ...string) {
+	constraint := b.table + "_pkey"
+	if len(name) > 0 {
+		constraint = name[0]
+	}
+	b.dropConstraint(indexPrimary, constraint)
+}
+
+// dropConstraint queues a named constraint to be dropped
+func (b *Blueprint) dropConstraint(kind, name string) {
+	if err := validateColumn(name); err != nil && b.err == nil {
+		b.err = err
+	}
+	b.dropConstraints = append(b.dropConstraints, constraintDrop{kind: kind, name: name})
+}
+
+// Check adds a check constraint, such as Check("age >= 0"). The expression is written
+// as is and must come from the application, never from user input.
+func (b *Blueprint) Check(expression string) *CheckDefinition {
+	words := strings.Trim(checkNameRegexp.ReplaceAllString(strings.ToLower(expression), "_"), "_")
+	check := &CheckDefinition{name: b.table + "_" + words + "_check", expression: expression}
+	b.checks = append(b.checks, check)
+	return check
+}
+
+// checkNameRegexp matches the characters left out of generated check constraint names
+var checkNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// addIndex appends an index named <table>_<columns>_<kind>
+func (b *Blueprint) addIndex(kind string, columns []string) *IndexDefinition {
+	for _, column := range columns {
+		if err := validateColumn(column); err != nil && b.err == nil {
+			b.err = err
+		}
+	}
+	if len(columns) == 0 && b.err == nil {
+		b.err = fmt.Errorf("%w: %s index without columns", ErrInvalidQuery, kind)
+	}
+
+	index := &IndexDefinition{
+		kind:    kind,
+		name:    b.table + "_" + strings.Join(columns, "_") + "_" + kind,
+		columns: columns,
+	}
+	if kind == indexPrimary {
+		index.name = b.table + "_pkey"
+	}
+	b.indexes = append(b.indexes, index)
+	return index
+}
+
+// Name replaces the generated index name
+func (i *IndexDefinition) Name(name string) *IndexDefinition {
+	i.name = name
+	return i
+}
+
+// Name replaces the generated constraint name
+func (c *CheckDefinition) Name(name string) *CheckDefinition {
+	c.name = name
+	return c
+}
+
+// References sets the referenced columns
+func (f *ForeignKeyDefinition) References(columns ...string) *ForeignKeyDefinition {
+	f.references = columns
+	return f
+}
+
+// On sets the referenced table
+func (f *ForeignKeyDefinition) On(table string) *ForeignKeyDefinition {
+	f.on = table
+	return f
+}
+
+// OnDelete sets what deleting the referenced row does: "cascade", "restrict", "set null",
+// "set default" or "no action"
+func (f *ForeignKeyDefinition) OnDelete(action string) *ForeignKeyDefinition {
+	f.onDelete = action
+	return f
+}
+
+// OnUpdate sets what changing the referenced key does, with the same actions as OnDelete
+func (f *ForeignKeyDefinition) OnUpdate(action string) *ForeignKeyDefinition {
+	f.onUpdate = action
+	return f
+}
+
+// Name replaces the generated constraint name
+func (f *ForeignKeyDefinition) Name(name string) *ForeignKeyDefinition {
+	f.name = name
+	return f
+}
+
+// referentialActions are the actions accepted by OnDelete and OnUpdate
+var referentialActions = map[string]bool{
+	"CASCADE": true, "RESTRICT": true, "SET NULL": true, "SET DEFAULT": true, "NO ACTION": true,
+}
+
+// compile returns the constraint's definition
+func (f *ForeignKeyDefinition) compile(conn *Connection) (string, error) {
+	if err := validateColumn(f.on); err != nil {
+		return "", err
+	}
+	if len(f.references) == 0 || len(f.references) != len(f.columns) {
+		return "", fmt.Errorf("%w: foreign key %s needs one referenced column per column", ErrInvalidQuery, f.name)
+	}
+	for _, column := range f.references {
+		if err := validateColumn(column); err != nil {
+			return "", err
+		}
+	}
+
+	sql := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		f.name, strings.Join(f.columns, ", "), conn.prefixTable(f.on), strings.Join(f.references, ", "))
+	for _, clause := range []struct{ event, action string }{{"DELETE", f.onDelete}, {"UPDATE", f.onUpdate}} {
+		if clause.action == "" {
+			continue
+		}
+		action := strings.ToUpper(strings.Join(strings.Fields(clause.action), " "))
+		if !referentialActions[action] {
+			return "", fmt.Errorf("%w: unknown foreign key action %q", ErrInvalidQuery, clause.action)
+		}
+		sql += " ON " + clause.event + " " + action
+	}
+	return sql, nil
+}
+
+// Compilation
+
+// compile returns the statements for the blueprint, and for SQLite the table constraints
+// that need a table rebuild
+func (b *Blueprint) compile(conn *Connection) ([]string, *sqliteRebuild, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+	if err := validateColumn(b.table); err != nil {
+		return nil, nil, err
+	}
+	driver := conn.Driver
+	table := conn.prefixTable(b.table)
+
+	constraints, err := b.tableConstraints(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.online(driver) {
+		clauses := b.alterClauses(constraints)
+		if len(clauses) == 0 {
+			return nil, nil, nil
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s %s", table, strings.Join(append(clauses, b.hints()...), ", "))}, nil, nil
+	}
+
+	var statements []string
+	var rebuild *sqliteRebuild
+	if b.creating {
+		definitions := make([]string, 0, len(b.columns)+len(constraints))
+		for _, column := range b.columns {
+			definitions = append(definitions, column.compile(driver))
+		}
+		definitions = append(definitions, constraints...)
+		statements = append(statements, fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(definitions, ", ")))
+	} else {
+		if driver == "sqlite3" && len(constraints)+len(b.dropConstraints) > 0 {
+			rebuild = &sqliteRebuild{add: constraints}
+			constraints = nil
+			for _, drop := range b.dropConstraints {
+				rebuild.drop = append(rebuild.drop, drop.name)
+			}
+		}
+		if rebuild == nil {
+			for _, drop := range b.dropConstraints {
+				statements = append(statements, drop.compile(driver, table))
+			}
+		}
+		for _, name := range b.dropIndexes {
+			if driver == "mysql" {
+				statements = append(statements, fmt.Sprintf("DROP INDEX %s ON %s", name, table))
+			} else {
+				statements = append(statements, fmt.Sprintf("DROP INDEX %s", name))
+			}
+		}
+		for _, column := range b.columns {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, column.compile(driver)))
+		}
+		for _, constraint := range constraints {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s", table, constraint))
+		}
+	}
+
+	for _, index := range b.indexes {
+		if index.kind != indexPrimary {
+			statements = append(statements, index.compile(driver, table))
+		}
+	}
+	return statements, rebuild, nil
+}
+
+// tableConstraints returns the definitions of the primary key, check and foreign key
+// constraints the blueprint adds
+func (b *Blueprint) tableConstraints(conn *Connection) ([]string, error) {
+	var constraints []string
+	for _, index := range b.indexes {
+		if index.kind == indexPrimary {
+			constraints = append(constraints, fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)", index.name, strings.Join(index.columns, ", ")))
+		}
+	}
+	for _, check := range b.checks {
+		constraints = append(constraints, fmt.Sprintf("CONSTRAINT %s CHECK (%s)", check.name, check.expression))
+	}
+	for _, foreignKey := range b.foreignKeys {
+		constraint, err := foreignKey.compile(conn)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, nil
+}
+
+// compile returns the statement dropping the constraint
+func (d constraintDrop) compile(driver, table string) string {
+	return fmt.Sprintf("ALTER TABLE %s %s", table, d.clause(driver))
+}
+
+// clause returns the ALTER TABLE clause dropping the constraint
+func (d constraintDrop) clause(driver string) string {
+	if driver != "mysql" {
+		return "DROP CONSTRAINT " + d.name
+	}
+	switch d.kind {
+	case "foreign":
+		return "DROP FOREIGN KEY " + d.name
+	case "check":
+		return "DROP CHECK " + d.name
+	}
+	return "DROP PRIMARY KEY"
+}
+
+// compile returns the column's definition
+func (c *ColumnDefinition) compile(driver string) string {
+	var sql strings.Builder
+	sql.WriteString(c.name)
+	sql.WriteString(" ")
+	sql.WriteString(c.typeFor(driver))
+	if c.kind == "increments" {
+		return sql.String()
+	}
+
+	if !c.nullable {
+		sql.WriteString(" NOT NULL")
+	}
+	if c.hasDefault {
+		sql.WriteString(" DEFAULT ")
+		sql.WriteString(sqlLiteral(c.defaultValue))
+	}
+	if c.primary {
+		sql.WriteString(" PRIMARY KEY")
+	}
+	return sql.String()
+}
+
+// typeFor returns the column's type in the driver's dialect
+func (c *ColumnDefinition) typeFor(driver string) string {
+	switch c.kind {
+	case "increments":
+		switch driver {
+		case "postgres":
+			return "BIGSERIAL PRIMARY KEY"
+		case "mysql":
+			return "BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY"
+		}
+		return "INTEGER PRIMARY KEY AUTOINCREMENT"
+	case "string":
+		return fmt.Sprintf("VARCHAR(%d)", c.length)
+	case "text":
+		return "TEXT"
+	case "integer":
+		return "INTEGER"
+	case "bigInteger":
+		return "BIGINT"
+	case "boolean":
+		return "BOOLEAN"
+	case "float":
+		switch driver {
+		case "postgres":
+			return "DOUBLE PRECISION"
+		case "mysql":
+			return "DOUBLE"
+		}
+		return "REAL"
+	case "decimal":
+		return fmt.Sprintf("DECIMAL(%d, %d)", c.precision, c.scale)
+	case "timestamp":
+		switch driver {
+		case "postgres":
+			return "TIMESTAMPTZ"
+		case "mysql":
+			return "DATETIME(6)"
+		}
+		return "DATETIME"
+	case "json":
+		switch driver {
+		case "postgres":
+			return "JSONB"
+		case "mysql":
+			return "JSON"
+		}
+		return "TEXT"
+	}
+	return c.sqlType
+}
+
+// compile returns the CREATE INDEX statement in the driver's dialect
+func (i *IndexDefinition) compile(driver, table string) string {
+	columns := strings.Join(i.columns, ", ")
+	switch {
+	case i.kind == indexUnique:
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", i.name, table, columns)
+	case i.kind == indexFullText && driver == "mysql":
+		return fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s)", i.name, table, columns)
+	case i.kind == indexFullText && driver == "postgres":
+		parts := make([]string, len(i.columns))
+		for n, column := range i.columns {
+			parts[n] = fmt.Sprintf("coalesce(%s, '')", column)
+		}
+		return fmt.Sprintf("CREATE INDEX %s ON %s USING GIN (to_tsvector('simple', %s))", i.name, table, strings.Join(parts, " || ' ' || "))
+	case i.kind == indexSpatial && driver == "mysql":
+		return fmt.Sprintf("CREATE SPATIAL INDEX %s ON %s (%s)", i.name, table, columns)
+	case i.kind == indexSpatial && driver == "postgres":
+		return fmt.Sprintf("CREATE INDEX %s ON %s USING GIST (%s)", i.name, table, columns)
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s)", i.name, table, columns)
+}
+
+// sqlLiteral writes a default value as an SQL literal
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return fmt.Sprint(value)
+}
+
+// createTableRegexp matches the start of a CREATE TABLE statement up to the table name
+var createTableRegexp = regexp.MustCompile(`(?is)^\s*CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?("[^"]+"|` + "`[^`]+`" + `|\[[^\]]+\]|[^\s(]+)`)
+
+// rebuildSQLiteTable recreates a SQLite table with changed table constraints, following
+// SQLite's procedure for schema changes ALTER TABLE cannot make: the rows are copied into
+// a new table, which replaces the old one, and the indexes are created again. Foreign key
+// enforcement is off meanwhile, so dropping the old table does not cascade.
+func (sb *SchemaBuilder) rebuildSQLiteTable(table string, changes *sqliteRebuild) (err error) {
+	if sb.connection.ReadOnly {
+		return ErrReadOnly
+	}
+
+	ctx := context.Background()
+	conn, err := sb.connection.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var createSQL string
+	if err := conn.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: table %s does not exist", ErrInvalidQuery, table)
+		}
+		return err
+	}
+	indexes, err := sqliteIndexes(ctx, conn, table)
+	if err != nil {
+		return err
+	}
+
+	temporary := table + "__rebuild"
+	createSQL, err = rebuiltTableSQL(createSQL, temporary, changes)
+	if err != nil {
+		return fmt.Errorf("cannot rebuild table %s: %w", table, err)
+	}
+
+	var foreignKeys int
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return err
+	}
+	if foreignKeys == 1 {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer func() {
+			if _, restoreErr := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	statements := []string{
+		createSQL,
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", temporary, table),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", temporary, table),
+	}
+	statements = append(statements, indexes...)
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to rebuild table %s: %w", table, err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	violated := rows.Next()
+	rows.Close()
+	if violated {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to rebuild table %s: foreign key violations", table)
+	}
+	return tx.Commit()
+}
+
+// sqliteIndexes returns the statements creating a SQLite table's explicit indexes
+func sqliteIndexes(ctx context.Context, conn *sql.Conn, table string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []string
+	for rows.Next() {
+		var index string
+		if err := rows.Scan(&index); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, rows.Err()
+}
+
+// constraintNameRegexp matches the name of a named table constraint
+var constraintNameRegexp = regexp.MustCompile(`(?is)^\s*CONSTRAINT\s+("[^"]+"|` + "`[^`]+`" + `|\[[^\]]+\]|[^\s(]+)`)
+
+// rebuiltTableSQL rewrites a CREATE TABLE statement to create the table under another
+// name, with the changed table constraints
+func rebuiltTableSQL(createSQL, name string, changes *sqliteRebuild) (string, error) {
+	location := createTableRegexp.FindStringSubmatchIndex(createSQL)
+	end := strings.LastIndex(createSQL, ")")
+	if location == nil || end < 0 {
+		return "", fmt.Errorf("unrecognized definition")
+	}
+	open := strings.Index(createSQL[location[1]:], "(")
+	if open < 0 || location[1]+open >= end {
+		return "", fmt.Errorf("unrecognized definition")
+	}
+	open += location[1]
+
+	drop := make(map[string]bool, len(changes.drop))
+	for _, constraint := range changes.drop {
+		drop[strings.ToLower(constraint)] = true
+	}
+	definitions := splitDefinitions(createSQL[open+1 : end])
+	kept := make([]string, 0, len(definitions)+len(changes.add))
+	for _, definition := range definitions {
+		if match := constraintNameRegexp.FindStringSubmatch(definition); match != nil {
+			constraint := strings.ToLower(strings.Trim(match[1], "\"`[]"))
+			if drop[constraint] {
+				delete(drop, constraint)
+				continue
+			}
+		}
+		kept = append(kept, definition)
+	}
+	for constraint := range drop {
+		return "", fmt.Errorf("%w: constraint %s does not exist", ErrInvalidQuery, constraint)
+	}
+	kept = append(kept, changes.add...)
+
+	return createSQL[:location[2]] + name + createSQL[location[3]:open+1] + strings.Join(kept, ", ") + createSQL[end:], nil
+}
+
+// splitDefinitions splits the body of a CREATE TABLE statement at the commas between its
+// column and constraint definitions
+func splitDefinitions(body string) []string {
+	var definitions []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '[':
+			quote = ']'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			definitions = append(definitions, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		}
+	}
+	return append(definitions, strings.TrimSpace(body[start:]))
+}
@@ -0,0 +1,166 @@
+package eloquent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BootOption configures Boot
+type BootOption func(*bootConfig)
+
+// bootConfig holds the options passed to Boot
+type bootConfig struct {
+	envFile         string
+	lazy            bool
+	skipAutoConnect bool
+	mustBeHealthy   bool
+}
+
+// WithEnvFile loads configuration from the given .env file instead of ./.env
+func WithEnvFile(path string) BootOption {
+	return func(c *bootConfig) {
+		c.envFile = path
+	}
+}
+
+// WithLazyConnect defers connecting until the first call to DB()
+func WithLazyConnect() BootOption {
+	return func(c *bootConfig) {
+		c.lazy = true
+	}
+}
+
+// WithoutAutoConnect loads the environment but leaves connecting to the caller
+func WithoutAutoConnect() BootOption {
+	return func(c *bootConfig) {
+		c.skipAutoConnect = true
+	}
+}
+
+// MustBeHealthyAtBoot makes Boot fail unless every managed connection, including those
+// registered before Boot, answers a HealthCheck within bootHealthTimeout after
+// connecting. It has no effect with WithLazyConnect or WithoutAutoConnect.
+func MustBeHealthyAtBoot() BootOption {
+	return func(c *bootConfig) {
+		c.mustBeHealthy = true
+	}
+}
+
+// bootHealthTimeout bounds the health check run by MustBeHealthyAtBoot
+const bootHealthTimeout = 5 * time.Second
+
+// pendingBoot holds a deferred connect registered by Boot with WithLazyConnect
+var (
+	pendingBoot   func() error
+	pendingBootMu sync.Mutex
+)
+
+// importConnections holds the connections opened by the import-time auto-connect, which
+// Boot closes once it has replaced them
+var (
+	importConnections   map[string]*Connection
+	importConnectionsMu sync.Mutex
+)
+
+// Boot explicitly initializes the package from the environment.
+// It is the preferred alternative to the automatic connect performed at import time,
+// which can be disabled by setting ELOQUENT_AUTO_CONNECT=false.
+func Boot(options ...BootOption) error {
+	config := &bootConfig{}
+	for _, option := range options {
+		option(config)
+	}
+
+	if config.envFile != "" {
+		if err := LoadEnv(config.envFile); err != nil {
+			return err
+		}
+	} else if err := LoadEnv(); err != nil {
+		return err
+	}
+
+	if config.skipAutoConnect {
+		return nil
+	}
+
+	if config.lazy {
+		pendingBootMu.Lock()
+		pendingBoot = bootConnect
+		pendingBootMu.Unlock()
+		return nil
+	}
+
+	if err := bootConnect(); err != nil {
+		return err
+	}
+
+	if config.mustBeHealthy {
+		ctx, cancel := context.WithTimeout(context.Background(), bootHealthTimeout)
+		defer cancel()
+		return HealthCheck(ctx).Err()
+	}
+	return nil
+}
+
+// bootConnect connects from the environment and closes the pools of the import-time
+// connections it replaced, so booting after the automatic connect does not leak them
+func bootConnect() error {
+	if err := AutoConnect(); err != nil {
+		return err
+	}
+
+	importConnectionsMu.Lock()
+	defer importConnectionsMu.Unlock()
+	cm := GetManager()
+	for name, conn := range importConnections {
+		if current, ok := cm.lookup(name); ok && current != conn {
+			_ = conn.DB.Close()
+			delete(importConnections, name)
+		}
+	}
+	return nil
+}
+
+// runPendingBoot performs a connect deferred by WithLazyConnect. The lock is released
+// before connecting, so ConnectionPlugins can call DB() while the connection is created.
+func runPendingBoot() {
+	pendingBootMu.Lock()
+	connect := pendingBoot
+	pendingBoot = nil
+	pendingBootMu.Unlock()
+
+	if connect == nil {
+		return
+	}
+	if err := connect(); err != nil {
+		GetLogger().Error("eloquent: lazy connect failed", "error", err)
+	}
+}
+
+// autoConnectEnabled reports whether the import-time auto-connect should run
+func autoConnectEnabled() bool {
+	switch strings.ToLower(os.Getenv("ELOQUENT_AUTO_CONNECT")) {
+	case "false", "0", "no", "off":
+		return false
+	}
+	return true
+}
+
+// init automatically initializes database connection from .env file
+func init() {
+	if !autoConnectEnabled() {
+		return
+	}
+
+	// Try to auto-connect from .env file
+	// This is optional - if it fails, user can still manually connect.
+	// The error is kept so it can be reported once a logger is set.
+	autoConnectErr = AutoConnect()
+
+	importConnectionsMu.Lock()
+	importConnections = GetManager().connectionsSnapshot()
+	importConnectionsMu.Unlock()
+}
@@ -0,0 +1,129 @@
+package eloquent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeBootEnvFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "boot.env")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create env file: %v", err)
+	}
+	return path
+}
+
+func TestBootWithoutAutoConnect(t *testing.T) {
+	path := writeBootEnvFile(t, "BOOT_TEST_VALUE=loaded\n")
+
+	if err := Boot(WithEnvFile(path), WithoutAutoConnect()); err != nil {
+		t.Fatalf("Boot failed: %v", err)
+	}
+
+	if Env("BOOT_TEST_VALUE") != "loaded" {
+		t.Errorf("Expected env file to be loaded, got %q", Env("BOOT_TEST_VALUE"))
+	}
+}
+
+func TestBootReturnsConnectError(t *testing.T) {
+	path := writeBootEnvFile(t, "DB_CONNECTION=unsupported\nDB_DATABASE=app\nDB_USERNAME=app\n")
+
+	if err := Boot(WithEnvFile(path)); err == nil {
+		t.Error("Expected Boot to return the connect error")
+	}
+}
+
+func TestBootWithLazyConnect(t *testing.T) {
+	path := writeBootEnvFile(t, "DB_CONNECTION=unsupported\nDB_DATABASE=app\nDB_USERNAME=app\n")
+
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+
+	if err := Boot(WithEnvFile(path), WithLazyConnect()); err != nil {
+		t.Fatalf("Expected lazy Boot to defer connecting, got %v", err)
+	}
+	if len(rec.error) != 0 {
+		t.Fatalf("Expected no connect attempt before DB(), got %d errors", len(rec.error))
+	}
+
+	DB()
+	if len(rec.error) != 1 {
+		t.Errorf("Expected lazy connect failure to be logged on first DB() call, got %d errors", len(rec.error))
+	}
+
+	DB()
+	if len(rec.error) != 1 {
+		t.Errorf("Expected lazy connect to run only once, got %d errors", len(rec.error))
+	}
+}
+
+// dbCallingPlugin calls DB() whenever a connection is created
+type dbCallingPlugin struct{}
+
+func (dbCallingPlugin) Name() string { return "db-calling" }
+
+func (dbCallingPlugin) ConnectionCreated(*Connection) { DB() }
+
+func TestLazyConnectWithPluginCallingDB(t *testing.T) {
+	RegisterPlugin(dbCallingPlugin{})
+	defer UnregisterPlugin("db-calling")
+	defer func() { _ = GetManager().CloseAll() }()
+
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	path := writeBootEnvFile(t, "")
+	if err := Boot(WithEnvFile(path), WithLazyConnect()); err != nil {
+		t.Fatalf("Boot failed: %v", err)
+	}
+
+	done := make(chan *Connection, 1)
+	go func() { done <- DB() }()
+	select {
+	case conn := <-done:
+		if conn == nil {
+			t.Error("Expected the lazy connect to open the default connection")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the lazy connect not to deadlock when a plugin calls DB()")
+	}
+}
+
+func TestAutoConnectEnabled(t *testing.T) {
+	t.Setenv("ELOQUENT_AUTO_CONNECT", "")
+	if !autoConnectEnabled() {
+		t.Error("Expected auto-connect to be enabled by default")
+	}
+
+	t.Setenv("ELOQUENT_AUTO_CONNECT", "false")
+	if autoConnectEnabled() {
+		t.Error("Expected ELOQUENT_AUTO_CONNECT=false to disable auto-connect")
+	}
+}
+
+func TestBootClosesReplacedImportConnections(t *testing.T) {
+	imported := NewTestSQLite(t)
+	WithTestConnection(t, imported)
+	importConnectionsMu.Lock()
+	importConnections = map[string]*Connection{"default": imported}
+	importConnectionsMu.Unlock()
+	t.Cleanup(func() {
+		importConnectionsMu.Lock()
+		importConnections = nil
+		importConnectionsMu.Unlock()
+	})
+
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	path := writeBootEnvFile(t, "")
+	if err := Boot(WithEnvFile(path)); err != nil {
+		t.Fatalf("Boot failed: %v", err)
+	}
+
+	if DB() == imported {
+		t.Fatal("Expected Boot to replace the import-time default connection")
+	}
+	if err := imported.DB.Ping(); err == nil {
+		t.Error("Expected the replaced import-time pool to be closed")
+	}
+}
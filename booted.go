@@ -0,0 +1,49 @@
+package eloquent
+
+import (
+	"reflect"
+	"sync"
+)
+
+// bootedDefinitions holds the definition each model type's Booted callback produced
+var bootedDefinitions sync.Map
+
+// Booted runs model-level setup once per model type: the first call for a type runs fn
+// on the model and keeps the configuration it leaves as the type's ModelDefinition, later
+// calls use that definition instead of running fn again. Call it from the model's
+// constructor after SetParentModel, so the model's type is known:
+//
+//	func NewUser() *User {
+//		user := &User{BaseModel: eloquent.NewBaseModel()}
+//		user.SetParentModel(user)
+//		user.Booted(func(m *eloquent.BaseModel) {
+//			m.Table("users").Fillable("name", "email").Hidden("password")
+//			m.SetAttribute("status", "active")
+//		})
+//		return user
+//	}
+//
+// Attributes fn sets become default values of every new instance.
+func (m *BaseModel) Booted(fn func(m *BaseModel)) *BaseModel {
+	key := modelTypeKey(m)
+	if definition, ok := bootedDefinitions.Load(key); ok {
+		m.useDefinition(definition.(*ModelDefinition))
+		return m
+	}
+
+	fn(m)
+	definition, _ := bootedDefinitions.LoadOrStore(key, newModelDefinition(m))
+	m.definition = definition.(*ModelDefinition)
+	return m
+}
+
+// modelTypeKey identifies a model's type: its Go type, or its table for a bare BaseModel
+func modelTypeKey(model Model) interface{} {
+	if bm, ok := model.(*BaseModel); ok {
+		if bm.parentModel == nil {
+			return bm.GetTable()
+		}
+		model = bm.parentModel
+	}
+	return reflect.TypeOf(model)
+}
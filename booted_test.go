@@ -0,0 +1,41 @@
+package eloquent
+
+import "testing"
+
+type bootedInvoice struct {
+	*BaseModel
+}
+
+func TestBooted(t *testing.T) {
+	boots := 0
+	newInvoice := func() *bootedInvoice {
+		invoice := &bootedInvoice{BaseModel: NewBaseModel()}
+		invoice.SetParentModel(invoice)
+		invoice.Booted(func(m *BaseModel) {
+			boots++
+			m.Table("invoices").Fillable("number", "total").Hidden("notes").StrictInserts()
+			m.SetAttribute("status", "draft")
+		})
+		return invoice
+	}
+
+	first := newInvoice()
+	second := newInvoice()
+	if boots != 1 || first.Definition() == nil || second.Definition() != first.Definition() {
+		t.Errorf("Expected the boot callback to run once and its definition to be shared, ran %d times", boots)
+	}
+
+	if second.GetTable() != "invoices" || len(second.GetFillable()) != 2 || len(second.GetHidden()) != 1 || !second.strictInserts {
+		t.Errorf("Expected the booted configuration to be copied, got table %q, fillable %v, hidden %v",
+			second.GetTable(), second.GetFillable(), second.GetHidden())
+	}
+	if second.GetAttribute("status") != "draft" {
+		t.Errorf("Expected the default status, got %v", second.GetAttribute("status"))
+	}
+
+	// Instances don't share attributes
+	second.SetAttribute("status", "sent")
+	if first.GetAttribute("status") != "draft" || newInvoice().GetAttribute("status") != "draft" {
+		t.Error("Expected changing one instance's attributes to leave the others alone")
+	}
+}
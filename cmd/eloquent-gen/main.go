@@ -0,0 +1,412 @@
+// Command eloquent-gen generates reflection-free field mapping for go-eloquent models.
+// For every struct embedding *eloquent.BaseModel it writes FillFromRow, which hydration
+// uses to fill the struct's fields from a row, EachField, which saving uses to find the
+// fields that changed, and FieldMap, which lists the fields by column. Models without
+// generated code keep working through reflection.
+//
+//	//go:generate go run github.com/crashana/go-eloquent/cmd/eloquent-gen
+//
+// Run in a package directory, it writes eloquent_gen.go for all models in the package;
+// -type limits it to a comma-separated list of models and -output names the file.
+// Columns come from db tags; fields without one are looked up under the name the
+// naming strategy gives them at runtime.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const eloquentPath = "github.com/crashana/go-eloquent"
+
+func main() {
+	types := flag.String("type", "", "comma-separated models to generate code for; all models by default")
+	output := flag.String("output", "eloquent_gen.go", "file to write, relative to the package directory")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var names []string
+	if *types != "" {
+		names = strings.Split(*types, ",")
+	}
+
+	source, err := generate(dir, names, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "eloquent-gen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(dir, *output), source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "eloquent-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// fieldKind is how a field is filled from a row value and compared with its zero value
+type fieldKind int
+
+const (
+	kindOther fieldKind = iota
+	kindString
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindTime
+	kindNillable
+)
+
+// basicKinds are the kinds of the predeclared types setFieldValue converts into
+var basicKinds = map[string]fieldKind{
+	"string": kindString, "bool": kindBool,
+	"int": kindInt, "int8": kindInt, "int16": kindInt, "int32": kindInt, "int64": kindInt, "rune": kindInt,
+	"uint": kindUint, "uint8": kindUint, "uint16": kindUint, "uint32": kindUint, "uint64": kindUint, "byte": kindUint,
+	"float32": kindFloat, "float64": kindFloat,
+}
+
+// field is a struct field the generated code fills
+type field struct {
+	name   string
+	column string // "" when the naming strategy names the column at runtime
+	typ    string
+	kind   fieldKind
+}
+
+// model is a struct embedding *eloquent.BaseModel
+type model struct {
+	name   string
+	fields []field
+}
+
+// file holds what the generated code needs from a parsed source file
+type file struct {
+	ast     *ast.File
+	imports map[string]string // local name → import path
+}
+
+// generate returns the formatted source of the generated file for the package in dir
+func generate(dir string, names []string, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+	filter := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}
+	packages, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(packages) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(packages))
+	}
+
+	var pkg *ast.Package
+	for _, p := range packages {
+		pkg = p
+	}
+
+	var files []file
+	underlying := make(map[string]string) // local named types with a predeclared underlying type
+	for _, f := range pkg.Files {
+		imports := make(map[string]string)
+		for _, spec := range f.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if path == eloquentPath {
+				name = "eloquent"
+			}
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = path
+		}
+		files = append(files, file{ast: f, imports: imports})
+
+		for _, decl := range f.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+				for _, spec := range gen.Specs {
+					if ts := spec.(*ast.TypeSpec); ts.Assign == 0 {
+						if ident, ok := ts.Type.(*ast.Ident); ok && basicKinds[ident.Name] != kindOther {
+							underlying[ts.Name.Name] = ident.Name
+						}
+					}
+				}
+			}
+		}
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	imports := map[string]string{}
+	var models []model
+	for _, f := range files {
+		for _, decl := range f.ast.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.TypeParams != nil || !embedsBaseModel(st, f.imports) {
+					continue
+				}
+				if len(wanted) > 0 && !wanted[ts.Name.Name] {
+					continue
+				}
+				delete(wanted, ts.Name.Name)
+				models = append(models, newModel(fset, ts.Name.Name, st, f, underlying, imports))
+			}
+		}
+	}
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for name := range wanted {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no models named %s embedding *eloquent.BaseModel", strings.Join(missing, ", "))
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no structs embedding *eloquent.BaseModel in %s", dir)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].name < models[j].name })
+
+	return render(pkg.Name, models, imports)
+}
+
+// embedsBaseModel reports whether a struct embeds *eloquent.BaseModel
+func embedsBaseModel(st *ast.StructType, imports map[string]string) bool {
+	for _, f := range st.Fields.List {
+		if len(f.Names) > 0 {
+			continue
+		}
+		star, ok := f.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "BaseModel" {
+			if pkg, ok := sel.X.(*ast.Ident); ok && imports[pkg.Name] == eloquentPath {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newModel collects the exported fields of a model struct, recording the imports their
+// types need in imports
+func newModel(fset *token.FileSet, name string, st *ast.StructType, f file, underlying, imports map[string]string) model {
+	m := model{name: name}
+	for _, astField := range st.Fields.List {
+		names := make([]string, 0, len(astField.Names))
+		for _, ident := range astField.Names {
+			names = append(names, ident.Name)
+		}
+		if len(names) == 0 {
+			embedded := astField.Type
+			if star, ok := embedded.(*ast.StarExpr); ok {
+				embedded = star.X
+			}
+			switch t := embedded.(type) {
+			case *ast.Ident:
+				names = append(names, t.Name)
+			case *ast.SelectorExpr:
+				if t.Sel.Name == "BaseModel" {
+					continue
+				}
+				names = append(names, t.Sel.Name)
+			}
+		}
+
+		column := ""
+		if astField.Tag != nil {
+			tag, _ := strconv.Unquote(astField.Tag.Value)
+			column = reflect.StructTag(tag).Get("db")
+		}
+
+		var typ bytes.Buffer
+		_ = printer.Fprint(&typ, fset, astField.Type)
+		kind := kindOf(astField.Type, f.imports, underlying)
+		ast.Inspect(astField.Type, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok {
+					if path, ok := f.imports[pkg.Name]; ok {
+						imports[pkg.Name] = path
+					}
+				}
+			}
+			return true
+		})
+
+		for _, fieldName := range names {
+			if !ast.IsExported(fieldName) {
+				continue
+			}
+			m.fields = append(m.fields, field{name: fieldName, column: column, typ: typ.String(), kind: kind})
+		}
+	}
+	return m
+}
+
+// kindOf classifies a field type
+func kindOf(expr ast.Expr, imports, underlying map[string]string) fieldKind {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if kind, ok := basicKinds[t.Name]; ok {
+			return kind
+		}
+		if basic, ok := underlying[t.Name]; ok {
+			return basicKinds[basic]
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && imports[pkg.Name] == "time" && t.Sel.Name == "Time" {
+			return kindTime
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return kindNillable
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return kindNillable
+		}
+	}
+	return kindOther
+}
+
+// render writes the generated file
+func render(pkgName string, models []model, imports map[string]string) ([]byte, error) {
+	var body bytes.Buffer
+	for _, m := range models {
+		for _, f := range m.fields {
+			if f.column == "" {
+				imports["eloquent"] = eloquentPath
+			}
+			if f.kind == kindOther {
+				imports["reflect"] = "reflect"
+			}
+		}
+		writeModel(&body, m)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by eloquent-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkgName)
+	if len(imports) > 0 {
+		out.WriteString("import (\n")
+		localNames := make([]string, 0, len(imports))
+		for name := range imports {
+			localNames = append(localNames, name)
+		}
+		sort.Slice(localNames, func(i, j int) bool { return imports[localNames[i]] < imports[localNames[j]] })
+		for _, name := range localNames {
+			path := imports[name]
+			if name == path[strings.LastIndex(path, "/")+1:] || path == eloquentPath && name == "eloquent" {
+				fmt.Fprintf(&out, "\t%q\n", path)
+			} else {
+				fmt.Fprintf(&out, "\t%s %q\n", name, path)
+			}
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// columnExpr is the Go expression naming a field's column
+func columnExpr(f field) string {
+	if f.column != "" {
+		return strconv.Quote(f.column)
+	}
+	return fmt.Sprintf("eloquent.GetNamingStrategy().ColumnName(%q)", f.name)
+}
+
+// writeModel writes the generated methods of a model
+func writeModel(out *bytes.Buffer, m model) {
+	fmt.Fprintf(out, "// FillFromRow fills the %s fields from a row without reflection\n", m.name)
+	fmt.Fprintf(out, "func (m *%s) FillFromRow(row map[string]interface{}) {\n", m.name)
+	for _, f := range m.fields {
+		writeFill(out, f)
+	}
+	out.WriteString("}\n\n")
+
+	fmt.Fprintf(out, "// EachField passes each %s field to fn with its column and whether it is zero\n", m.name)
+	fmt.Fprintf(out, "func (m *%s) EachField(fn func(column string, value interface{}, zero bool)) {\n", m.name)
+	for _, f := range m.fields {
+		fmt.Fprintf(out, "\tfn(%s, m.%s, %s)\n", columnExpr(f), f.name, zeroExpr(f))
+	}
+	out.WriteString("}\n\n")
+
+	fmt.Fprintf(out, "// FieldMap returns the %s fields by column\n", m.name)
+	fmt.Fprintf(out, "func (m *%s) FieldMap() map[string]interface{} {\n", m.name)
+	fmt.Fprintf(out, "\treturn map[string]interface{}{\n")
+	for _, f := range m.fields {
+		fmt.Fprintf(out, "\t\t%s: m.%s,\n", columnExpr(f), f.name)
+	}
+	out.WriteString("\t}\n}\n\n")
+}
+
+// writeFill writes the statement filling a field, converting values like the
+// reflection-based hydration does
+func writeFill(out *bytes.Buffer, f field) {
+	column := columnExpr(f)
+	switch f.kind {
+	case kindString, kindBool:
+		goType := "string"
+		if f.kind == kindBool {
+			goType = "bool"
+		}
+		value := "v"
+		if f.typ != goType {
+			value = f.typ + "(v)"
+		}
+		fmt.Fprintf(out, "\tif v, ok := row[%s].(%s); ok {\n\t\tm.%s = %s\n\t}\n", column, goType, f.name, value)
+	case kindInt, kindUint, kindFloat:
+		types := map[fieldKind][2]string{
+			kindInt:   {"int64", "int"},
+			kindUint:  {"uint64", "uint"},
+			kindFloat: {"float64", "float32"},
+		}[f.kind]
+		fmt.Fprintf(out, "\tswitch v := row[%s].(type) {\n", column)
+		for _, goType := range types {
+			fmt.Fprintf(out, "\tcase %s:\n\t\tm.%s = %s(v)\n", goType, f.name, f.typ)
+		}
+		out.WriteString("\t}\n")
+	default:
+		fmt.Fprintf(out, "\tif v, ok := row[%s].(%s); ok {\n\t\tm.%s = v\n\t}\n", column, f.typ, f.name)
+	}
+}
+
+// zeroExpr is the Go expression reporting whether a field holds its zero value
+func zeroExpr(f field) string {
+	switch f.kind {
+	case kindString:
+		return fmt.Sprintf("m.%s == \"\"", f.name)
+	case kindBool:
+		return fmt.Sprintf("!m.%s", f.name)
+	case kindInt, kindUint, kindFloat:
+		return fmt.Sprintf("m.%s == 0", f.name)
+	case kindTime:
+		return fmt.Sprintf("m.%s.IsZero()", f.name)
+	case kindNillable:
+		return fmt.Sprintf("m.%s == nil", f.name)
+	default:
+		return fmt.Sprintf("reflect.ValueOf(m.%s).IsZero()", f.name)
+	}
+}
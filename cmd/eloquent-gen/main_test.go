@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const modelSource = `package shop
+
+import (
+	"database/sql"
+	stdtime "time"
+
+	orm "github.com/crashana/go-eloquent"
+)
+
+type Status string
+
+type Order struct {
+	*orm.BaseModel
+
+	ID       int64          ` + "`db:\"id\"`" + `
+	Status   Status         ` + "`db:\"status\"`" + `
+	Total    float64
+	Note     *string        ` + "`db:\"note\"`" + `
+	Coupon   sql.NullString ` + "`db:\"coupon\"`" + `
+	PlacedAt stdtime.Time   ` + "`db:\"placed_at\"`" + `
+	internal string
+}
+
+type Address struct {
+	Street string
+}
+`
+
+func writeOrderModel(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "order.go"), []byte(modelSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	source, err := generate(writeOrderModel(t), nil, "eloquent_gen.go")
+	if err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	generated := string(source)
+
+	expected := []string{
+		"// Code generated by eloquent-gen. DO NOT EDIT.",
+		"package shop",
+		"\"database/sql\"",
+		"\"reflect\"",
+		"stdtime \"time\"",
+		"\"github.com/crashana/go-eloquent\"",
+		"func (m *Order) FillFromRow(row map[string]interface{}) {",
+		"case int64:\n\t\tm.ID = int64(v)",
+		"if v, ok := row[\"status\"].(string); ok {\n\t\tm.Status = Status(v)",
+		"switch v := row[eloquent.GetNamingStrategy().ColumnName(\"Total\")].(type) {",
+		"if v, ok := row[\"note\"].(*string); ok {",
+		"if v, ok := row[\"placed_at\"].(stdtime.Time); ok {",
+		"fn(\"status\", m.Status, m.Status == \"\")",
+		"fn(\"note\", m.Note, m.Note == nil)",
+		"fn(\"coupon\", m.Coupon, reflect.ValueOf(m.Coupon).IsZero())",
+		"fn(\"placed_at\", m.PlacedAt, m.PlacedAt.IsZero())",
+		"func (m *Order) FieldMap() map[string]interface{} {",
+	}
+	for _, snippet := range expected {
+		if !strings.Contains(generated, snippet) {
+			t.Errorf("Expected the generated code to contain %q, got:\n%s", snippet, generated)
+		}
+	}
+	if strings.Contains(generated, "internal") || strings.Contains(generated, "Address") {
+		t.Errorf("Expected unexported fields and plain structs to be skipped, got:\n%s", generated)
+	}
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	_, err := generate(writeOrderModel(t), []string{"Order", "Invoice"}, "eloquent_gen.go")
+	if err == nil || !strings.Contains(err.Error(), "Invoice") {
+		t.Errorf("Expected an error naming the missing model, got %v", err)
+	}
+}
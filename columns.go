@@ -0,0 +1,227 @@
+package eloquent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownColumn is returned when a strict model is saved with attributes its table lacks
+var ErrUnknownColumn = errors.New("unknown column")
+
+var (
+	columnValidation   bool
+	columnValidationMu sync.RWMutex
+)
+
+// SetColumnValidation checks model attributes against the columns of the model's table,
+// read once per table and cached: saving a model with attributes the table lacks logs a
+// warning naming them before the statement fails. Fill keeps unknown attributes, so the
+// warning also covers keys mass assigned to models that rely on Guarded.
+func SetColumnValidation(enabled bool) {
+	columnValidationMu.Lock()
+	defer columnValidationMu.Unlock()
+	columnValidation = enabled
+}
+
+// GetColumnValidation reports whether model attributes are checked against table columns
+func GetColumnValidation() bool {
+	columnValidationMu.RLock()
+	defer columnValidationMu.RUnlock()
+	return columnValidation
+}
+
+// GetColumnListing returns the columns of a table or view, in table order
+func (sb *SchemaBuilder) GetColumnListing(table string) ([]string, error) {
+	if sb.connection == nil {
+		return nil, ErrNoConnection
+	}
+	return sb.connection.tableColumns(context.Background(), table)
+}
+
+// GetColumns returns the columns of the model's table, cached per connection and table
+func (m *BaseModel) GetColumns() ([]string, error) {
+	db, err := m.resolveConnection()
+	if err != nil {
+		return nil, err
+	}
+	return db.tableColumns(m.Context(), m.qualifiedTable())
+}
+
+// hasColumns returns a lookup of the model's table columns when column validation is
+// enabled, or nil when it is disabled or the columns cannot be read
+func (m *BaseModel) hasColumns() map[string]bool {
+	if !GetColumnValidation() {
+		return nil
+	}
+	columns, err := m.GetColumns()
+	if err != nil {
+		return nil
+	}
+	lookup := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		lookup[column] = true
+	}
+	return lookup
+}
+
+// warnUnknownColumns logs the attributes about to be saved that the table lacks
+func (m *BaseModel) warnUnknownColumns() {
+	columns := m.hasColumns()
+	if columns == nil {
+		return
+	}
+
+	var unknown []string
+	for key := range m.attributes {
+		if !columns[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		GetLogger().Warn("eloquent: saving attributes the table does not have", "table", m.GetTable(), "columns", unknown)
+	}
+}
+
+// StrictInserts restricts the columns written when the model is created to its fillable
+// attributes, the primary key and the columns the model maintains itself, such as
+// timestamps, so attributes set directly with SetAttribute, hidden or guarded ones
+// included, stay out of the INSERT. Attributes the table does not have fail the insert
+// with ErrUnknownColumn naming them, instead of an error from the driver.
+func (m *BaseModel) StrictInserts() *BaseModel {
+	m.strictInserts = true
+	return m
+}
+
+// insertAttributes returns the attributes to write when the model is created
+func (m *BaseModel) insertAttributes() (map[string]interface{}, error) {
+	if !m.strictInserts {
+		m.warnUnknownColumns()
+		return m.attributes, nil
+	}
+
+	attributes := make(map[string]interface{}, len(m.attributes))
+	for key, value := range m.attributes {
+		if m.isFillable(key) || m.isMaintained(key) {
+			attributes[key] = value
+		}
+	}
+
+	columns, err := m.GetColumns()
+	if err != nil {
+		return attributes, nil
+	}
+	lookup := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		lookup[column] = true
+	}
+	var unknown []string
+	for key := range attributes {
+		if !lookup[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: %s has no column %s", ErrUnknownColumn, m.GetTable(), strings.Join(unknown, ", "))
+	}
+	return attributes, nil
+}
+
+// isMaintained reports whether the model fills the column itself when saving
+func (m *BaseModel) isMaintained(key string) bool {
+	if key == m.primaryKey || (m.timestamps && (key == m.createdAt || key == m.updatedAt)) {
+		return true
+	}
+	for _, column := range []string{m.deletedAt, m.createdBy, m.updatedBy, m.slugColumn, m.sortColumn} {
+		if column != "" && key == column {
+			return true
+		}
+	}
+	return m.contains(m.sortGroup, key)
+}
+
+// tableColumns returns the columns of a table, read from an empty result set so it works
+// the same on every driver. Lists are cached until the schema builder changes the schema.
+func (c *Connection) tableColumns(ctx context.Context, table string) ([]string, error) {
+	if err := validateColumn(table); err != nil {
+		return nil, err
+	}
+	if columns, ok := c.columnCache.Load(table); ok {
+		return columns.([]string), nil
+	}
+
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", c.prefixTable(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	c.columnCache.Store(table, columns)
+	return columns, nil
+}
+
+// forgetColumns clears the cached column lists after a schema change
+func (c *Connection) forgetColumns() {
+	c.columnCache.Range(func(table, _ interface{}) bool {
+		c.columnCache.Delete(table)
+		return true
+	})
+}
+
+// SelectExcept selects every column of the query's table except the given ones, such as
+// SelectExcept("password", "remember_token"). The column list is read from the database
+// when SelectExcept is called and cached per table, so set the table first.
+func (qb *QueryBuilder) SelectExcept(columns ...string) *QueryBuilder {
+	qb = qb.mutable()
+	if qb.connection == nil {
+		return qb.fail(ErrNoConnection)
+	}
+
+	all, err := qb.connection.tableColumns(qb.Context(), qb.table)
+	if err != nil {
+		return qb.fail(fmt.Errorf("failed to list the columns of %s: %w", qb.table, err))
+	}
+
+	excluded := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		excluded[column] = true
+	}
+	selected := make([]string, 0, len(all))
+	for _, column := range all {
+		if !excluded[column] {
+			selected = append(selected, column)
+		}
+	}
+	if len(qb.joins) > 0 {
+		table := qb.tableReference()
+		for i, column := range selected {
+			selected[i] = table + "." + column
+		}
+	}
+	qb.columns = selected
+	return qb
+}
+
+// SelectExcept selects every column except the given ones, see QueryBuilder.SelectExcept
+func (mqb *ModelQueryBuilder) SelectExcept(columns ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.SelectExcept(columns...))
+}
+
+// SelectExcept selects every column except the given ones, see QueryBuilder.SelectExcept
+func (tmqb *TypedModelQueryBuilder[T]) SelectExcept(columns ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.SelectExcept(columns...))
+}
+
+// SelectExcept starts a query selecting every column except the given ones (static-like)
+func (ms *ModelStatic[T]) SelectExcept(columns ...string) *TypedModelQueryBuilder[T] {
+	return ms.Query().SelectExcept(columns...)
+}
@@ -0,0 +1,124 @@
+package eloquent
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestColumnValidation(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	newCustomer := func() *CustomerModel {
+		customer := &CustomerModel{BaseModel: NewBaseModel()}
+		customer.Guarded("id").Connection(conn.Name)
+		customer.SetParentModel(customer)
+		return customer
+	}
+
+	customer := newCustomer()
+	columns, err := customer.GetColumns()
+	if err != nil || !reflect.DeepEqual(columns, []string{"id", "name", "created_at", "updated_at"}) {
+		t.Fatalf("Unexpected columns %v, %v", columns, err)
+	}
+
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+	rec.warn = nil // SetLogger reports a failed auto-connect from package init
+
+	// Disabled by default: unknown attributes are filled and only the database complains
+	customer.Fill(map[string]interface{}{"name": "Ada", "is_admin": true})
+	if customer.GetAttribute("is_admin") != true || len(rec.warn) != 0 {
+		t.Errorf("Expected no validation by default, got %v and warnings %v", customer.GetAttribute("is_admin"), rec.warn)
+	}
+
+	SetColumnValidation(true)
+	defer SetColumnValidation(false)
+
+	// Fill keeps unknown attributes and leaves the warning to the save
+	customer = newCustomer()
+	customer.Fill(map[string]interface{}{"name": "Ada", "is_admin": true})
+	if customer.GetAttribute("is_admin") != true || customer.GetAttribute("name") != "Ada" {
+		t.Errorf("Expected the unknown attribute to be kept, got %v", customer.ToMap())
+	}
+	if len(rec.warn) != 0 {
+		t.Errorf("Expected no warning while filling, got %v", rec.warn)
+	}
+
+	customer.SetAttribute("nickname", "Countess")
+	if err := customer.Save(); err == nil {
+		t.Error("Expected saving an unknown column to fail")
+	}
+	if len(rec.warn) != 1 || rec.warn[0] != "eloquent: saving attributes the table does not have" {
+		t.Errorf("Expected a warning before the failing insert, got %v", rec.warn)
+	}
+}
+
+func TestStrictInserts(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, password TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	newCustomer := func() *CustomerModel {
+		customer := &CustomerModel{BaseModel: NewBaseModel()}
+		customer.Fillable("name").StrictInserts().Connection(conn.Name)
+		customer.SetParentModel(customer)
+		return customer
+	}
+
+	customer := newCustomer()
+	customer.SetAttribute("name", "Ada")
+	customer.SetAttribute("password", "secret")
+	if err := customer.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	row, err := NewQueryBuilder(conn).Table("customers").First()
+	if err != nil {
+		t.Fatalf("Failed to read the row: %v", err)
+	}
+	if row["name"] != "Ada" || row["password"] != nil || row["id"] == nil || row["created_at"] == nil {
+		t.Errorf("Expected only fillable and maintained columns to be inserted, got %v", row)
+	}
+
+	customer = newCustomer()
+	customer.SetAttribute("name", "Grace")
+	customer.SetAttribute("nickname", "Amazing")
+	if err := customer.Save(); err != nil {
+		t.Errorf("Expected attributes outside the whitelist to be left out, got %v", err)
+	}
+
+	customer = newCustomer()
+	customer.Fillable("name", "nickname")
+	customer.SetAttribute("nickname", "Amazing")
+	err = customer.Save()
+	if !errors.Is(err, ErrUnknownColumn) || err.Error() != "unknown column: customers has no column nickname" {
+		t.Errorf("Expected ErrUnknownColumn naming the column, got %v", err)
+	}
+
+	// Batch saves insert the same attributes as Save
+	linus := newCustomer()
+	linus.SetAttribute("name", "Linus")
+	linus.SetAttribute("password", "secret")
+	if err := SaveAll([]Model{linus}); err != nil {
+		t.Fatalf("Failed to save in a batch: %v", err)
+	}
+	row, err = NewQueryBuilder(conn).Table("customers").Where("name", "Linus").First()
+	if err != nil || row["password"] != nil {
+		t.Errorf("Expected the batch to leave out attributes outside the whitelist, got %v, %v", row, err)
+	}
+	if err := SaveAll([]Model{customer}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn from SaveAll, got %v", err)
+	}
+	if err := NewUnitOfWork().Register(customer).Commit(); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn from a unit of work, got %v", err)
+	}
+	if count, _ := NewQueryBuilder(conn).Table("customers").Count(); count != 3 {
+		t.Errorf("Expected no row from the failed batches, got %d rows", count)
+	}
+}
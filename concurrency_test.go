@@ -0,0 +1,137 @@
+package eloquent
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// These tests exercise shared state from many goroutines; run them with -race.
+
+func TestConcurrentConnectionManager(t *testing.T) {
+	cm := NewConnectionManager()
+	defer func() { _ = cm.CloseAll() }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("conn_%d", i)
+			err := cm.AddConnection(name, ConnectionConfig{Driver: "sqlite3", Database: ":memory:"})
+			if err != nil {
+				t.Errorf("Failed to add connection %s: %v", name, err)
+				return
+			}
+			if conn := cm.GetConnection(name); conn == nil {
+				t.Errorf("Expected connection %s to be registered", name)
+				return
+			}
+			if _, err := cm.GetConnection(name).Select("SELECT 1 AS one"); err != nil {
+				t.Errorf("Query on %s failed: %v", name, err)
+			}
+			_ = cm.Metrics()
+		}(i)
+	}
+	wg.Wait()
+
+	if len(cm.Metrics()) != 8 {
+		t.Errorf("Expected 8 connections, got %d", len(cm.Metrics()))
+	}
+}
+
+func TestConcurrentSharedQueries(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each goroutine builds its own QueryBuilder; builders must not be shared
+			if _, err := conn.Insert("INSERT INTO items (name) VALUES (?)", fmt.Sprintf("item %d", i)); err != nil {
+				t.Errorf("Insert failed: %v", err)
+			}
+			if _, err := conn.Table("items").Where("id", ">", 0).Get(); err != nil {
+				t.Errorf("Select failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := conn.Table("items").Count()
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 8 {
+		t.Errorf("Expected 8 items, got %d", count)
+	}
+}
+
+func TestConcurrentGlobalSettings(t *testing.T) {
+	registry := NewScopeRegistry()
+	defer SetLogger(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("scope_%d", i)
+			registry.Register(name, LimitScope(i))
+			registry.RegisterGlobal(ActiveScope{})
+			_ = registry.ScopeExists(name)
+			_ = registry.ListScopes()
+			registry.ApplyGlobal(NewQueryBuilder(nil), NewBaseModel())
+
+			SetLogger(nopLogger{})
+			_ = GetLogger()
+			_ = Env("CONCURRENT_TEST_KEY")
+			_ = LoadEnv("nonexistent.env")
+		}(i)
+	}
+	wg.Wait()
+
+	if len(registry.ListScopes()) != 8 {
+		t.Errorf("Expected 8 scopes, got %d", len(registry.ListScopes()))
+	}
+}
+
+func TestConcurrentImmutableBuilder(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		if _, err := conn.Insert("INSERT INTO items (name) VALUES (?)", fmt.Sprintf("item %d", i)); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+
+	// An immutable base query may be shared and extended from many goroutines
+	base := conn.Table("items").Where("id", ">", 0).Immutable()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results, err := base.Where("id", "<=", i+1).OrderByDesc("id").Get()
+			if err != nil {
+				t.Errorf("Select failed: %v", err)
+				return
+			}
+			if len(results) != i+1 {
+				t.Errorf("Expected %d items, got %d", i+1, len(results))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if sql, _ := base.ToSQL(); sql != "SELECT * FROM items WHERE id > ?" {
+		t.Errorf("Expected shared base query to be unchanged, got %s", sql)
+	}
+}
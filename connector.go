@@ -0,0 +1,172 @@
+package eloquent
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// configConnector opens driver connections from a ConnectionConfig, fetching fresh
+// credentials and failing over between hosts for every new physical connection
+type configConnector struct {
+	driver driver.Driver
+	config ConnectionConfig
+	hosts  []string
+
+	// active is the index in hosts of the host new connections go to
+	active atomic.Int32
+}
+
+// newConfigConnector creates a connector for the config
+func newConfigConnector(config ConnectionConfig) (*configConnector, error) {
+	// Validate the driver before resolving it through database/sql
+	if _, err := buildDSN(config); err != nil {
+		return nil, err
+	}
+
+	// sql.Open does not connect, it only looks up the registered driver
+	probe, err := sql.Open(config.Driver, "")
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	hosts := []string{config.Host}
+	hosts = append(hosts, config.Hosts...)
+
+	return &configConnector{
+		driver: drv,
+		config: config,
+		hosts:  hosts,
+	}, nil
+}
+
+// Connect opens a connection to the active host, failing over to the next healthy one
+func (c *configConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	config := c.config
+	if config.Credentials != nil {
+		password, err := config.Credentials.Password(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain database credentials: %w", err)
+		}
+		config.Password = password
+	}
+
+	start := int(c.active.Load())
+	var lastErr error
+	for i := 0; i < len(c.hosts); i++ {
+		index := (start + i) % len(c.hosts)
+
+		conn, err := c.open(config, c.hosts[index])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if index != start {
+			c.active.Store(int32(index))
+			GetLogger().Warn("eloquent: failed over to standby host", "from", c.hosts[start], "to", c.hosts[index], "error", lastErr)
+		}
+		return conn, nil
+	}
+
+	return nil, lastErr
+}
+
+// Driver returns the underlying database driver
+func (c *configConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// open opens a driver connection to a single host
+func (c *configConnector) open(config ConnectionConfig, host string) (driver.Conn, error) {
+	if err := applyHost(&config, host); err != nil {
+		return nil, err
+	}
+
+	dsn, err := buildDSN(config)
+	if err != nil {
+		return nil, err
+	}
+	return c.driver.Open(dsn)
+}
+
+// promotePrimary moves new connections back to the primary host once it is reachable again
+func (c *configConnector) promotePrimary(ctx context.Context) error {
+	if c.active.Load() == 0 {
+		return nil
+	}
+
+	config := c.config
+	if config.Credentials != nil {
+		password, err := config.Credentials.Password(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to obtain database credentials: %w", err)
+		}
+		config.Password = password
+	}
+
+	conn, err := c.open(config, c.hosts[0])
+	if err != nil {
+		return err
+	}
+	_ = conn.Close()
+
+	c.active.Store(0)
+	GetLogger().Warn("eloquent: promoted primary host", "host", c.hosts[0])
+	return nil
+}
+
+// applyHost sets the host, and the port if given as host:port, on the config
+func applyHost(config *ConnectionConfig, host string) error {
+	h, p, err := net.SplitHostPort(host)
+	if err != nil {
+		// No port in the address
+		config.Host = host
+		return nil
+	}
+
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return fmt.Errorf("invalid port in host %s", host)
+	}
+	config.Host = h
+	config.Port = port
+	return nil
+}
+
+// openDB opens and pings a database handle for the config. A connector is returned
+// when the config needs per-connection credentials or host failover.
+func openDB(config ConnectionConfig) (*sqlx.DB, *configConnector, error) {
+	if config.Credentials == nil && len(config.Hosts) == 0 {
+		dsn, err := buildDSN(config)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		db, err := sqlx.Connect(config.Driver, dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return db, nil, nil
+	}
+
+	connector, err := newConfigConnector(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(connector), config.Driver)
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, connector, nil
+}
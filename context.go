@@ -0,0 +1,70 @@
+package eloquent
+
+import "context"
+
+// queryDefaultsKey is the context key holding query defaults
+type queryDefaultsKey struct{}
+
+// WithQueryDefaults returns a context whose queries apply the given callback, for example to
+// add tenant or locale constraints from middleware. Defaults accumulate: callbacks attached to
+// parent contexts run first. Queries pick them up through WithContext.
+func WithQueryDefaults(ctx context.Context, callback func(*QueryBuilder)) context.Context {
+	parent := queryDefaults(ctx)
+	defaults := make([]func(*QueryBuilder), len(parent), len(parent)+1)
+	copy(defaults, parent)
+	defaults = append(defaults, callback)
+	return context.WithValue(ctx, queryDefaultsKey{}, defaults)
+}
+
+// queryDefaults returns the query defaults attached to a context
+func queryDefaults(ctx context.Context) []func(*QueryBuilder) {
+	if ctx == nil {
+		return nil
+	}
+	defaults, _ := ctx.Value(queryDefaultsKey{}).([]func(*QueryBuilder))
+	return defaults
+}
+
+// WithContext associates the query with a context, applying any defaults attached to it
+// with WithQueryDefaults when the query is compiled
+func (qb *QueryBuilder) WithContext(ctx context.Context) *QueryBuilder {
+	qb = qb.mutable()
+	qb.ctx = ctx
+	return qb
+}
+
+// Context returns the context associated with the query, or context.Background
+func (qb *QueryBuilder) Context() context.Context {
+	if qb.ctx == nil {
+		return context.Background()
+	}
+	return qb.ctx
+}
+
+// withDefaults returns a copy of the builder with the registered QueryPlugins and the
+// context's query defaults applied, or the builder itself when there are none
+func (qb *QueryBuilder) withDefaults() *QueryBuilder {
+	var hooks []QueryPlugin
+	if !qb.hooked {
+		hooks = queryPlugins()
+	}
+	defaults := queryDefaults(qb.ctx)
+	if len(hooks) == 0 && len(defaults) == 0 {
+		return qb
+	}
+
+	target := qb.clone().groupWheres(0)
+	target.hooked = true
+	for _, hook := range hooks {
+		target = target.apply(func(q *QueryBuilder) {
+			if err := hook.BeforeQuery(q); err != nil {
+				q.fail(err)
+			}
+		})
+	}
+	target.ctx = nil
+	for _, callback := range defaults {
+		target = target.apply(callback)
+	}
+	return target
+}
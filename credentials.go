@@ -0,0 +1,18 @@
+package eloquent
+
+import (
+	"context"
+)
+
+// CredentialProvider supplies passwords for connections whose credentials rotate
+type CredentialProvider interface {
+	Password(ctx context.Context) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to the CredentialProvider interface
+type CredentialProviderFunc func(ctx context.Context) (string, error)
+
+// Password calls the underlying function
+func (f CredentialProviderFunc) Password(ctx context.Context) (string, error) {
+	return f(ctx)
+}
@@ -0,0 +1,48 @@
+package eloquent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestCredentialProvider(t *testing.T) {
+	cm := NewConnectionManager()
+
+	calls := 0
+	err := cm.AddConnection("iam", ConnectionConfig{
+		Driver:   "sqlite3",
+		Database: ":memory:",
+		Credentials: CredentialProviderFunc(func(ctx context.Context) (string, error) {
+			calls++
+			return fmt.Sprintf("token-%d", calls), nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Failed to add connection with credential provider: %v", err)
+	}
+	defer func() { _ = cm.CloseAll() }()
+
+	if calls == 0 {
+		t.Error("Expected credential provider to be called when connecting")
+	}
+
+	if _, err := cm.GetConnection("iam").Select("SELECT 1 AS one"); err != nil {
+		t.Errorf("Expected query over provided credentials to succeed, got %v", err)
+	}
+}
+
+func TestCredentialProviderError(t *testing.T) {
+	cm := NewConnectionManager()
+
+	err := cm.AddConnection("iam", ConnectionConfig{
+		Driver:   "sqlite3",
+		Database: ":memory:",
+		Credentials: CredentialProviderFunc(func(ctx context.Context) (string, error) {
+			return "", fmt.Errorf("token service unavailable")
+		}),
+	})
+	if err == nil {
+		t.Error("Expected error when credential provider fails")
+	}
+}
@@ -0,0 +1,96 @@
+package eloquent
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// cursorValue is one encoded cursor value, tagged with its type so it decodes to the
+// same Go type it was encoded from
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+// EncodeCursor encodes the values identifying a row into an opaque, URL-safe cursor.
+// Integers, floats, strings, booleans, byte slices, times and nil are supported.
+func EncodeCursor(values ...interface{}) (string, error) {
+	encoded := make([]cursorValue, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			encoded[i] = cursorValue{Type: "nil"}
+		case int:
+			encoded[i] = cursorValue{Type: "int", Value: fmt.Sprint(v)}
+		case int32:
+			encoded[i] = cursorValue{Type: "int", Value: fmt.Sprint(v)}
+		case int64:
+			encoded[i] = cursorValue{Type: "int", Value: fmt.Sprint(v)}
+		case float32:
+			encoded[i] = cursorValue{Type: "float", Value: fmt.Sprint(v)}
+		case float64:
+			encoded[i] = cursorValue{Type: "float", Value: fmt.Sprint(v)}
+		case string:
+			encoded[i] = cursorValue{Type: "string", Value: v}
+		case []byte:
+			encoded[i] = cursorValue{Type: "string", Value: string(v)}
+		case bool:
+			encoded[i] = cursorValue{Type: "bool", Value: fmt.Sprint(v)}
+		case time.Time:
+			encoded[i] = cursorValue{Type: "time", Value: v.Format(time.RFC3339Nano)}
+		default:
+			return "", fmt.Errorf("cannot encode %T in a cursor", value)
+		}
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a cursor created by EncodeCursor. Malformed cursors, which usually
+// come from clients, fail with ErrInvalidQuery.
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	var encoded []cursorValue
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	values := make([]interface{}, len(encoded))
+	for i, value := range encoded {
+		var err error
+		switch value.Type {
+		case "nil":
+			values[i] = nil
+		case "int":
+			var n int64
+			_, err = fmt.Sscan(value.Value, &n)
+			values[i] = n
+		case "float":
+			var f float64
+			_, err = fmt.Sscan(value.Value, &f)
+			values[i] = f
+		case "string":
+			values[i] = value.Value
+		case "bool":
+			values[i] = value.Value == "true"
+		case "time":
+			values[i], err = time.Parse(time.RFC3339Nano, value.Value)
+		default:
+			err = fmt.Errorf("unknown type %q", value.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+		}
+	}
+	return values, nil
+}
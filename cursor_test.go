@@ -0,0 +1,34 @@
+package eloquent
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	values := []interface{}{int64(42), 1.5, "o'brien", true, created, nil}
+
+	cursor, err := EncodeCursor(values...)
+	if err != nil {
+		t.Fatalf("Failed to encode cursor: %v", err)
+	}
+	decoded, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("Expected %v, got %v", values, decoded)
+	}
+
+	if _, err := EncodeCursor(struct{}{}); err == nil {
+		t.Error("Expected an error for an unsupported value")
+	}
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "W3sidCI6InVmbyJ9XQ"} {
+		if _, err := DecodeCursor(cursor); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery for %q, got %v", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package eloquent
+
+// ModelDefinition is a model type's configuration, built once and shared by the instances
+// created from it, so constructors don't allocate fillable, hidden or cast lists for
+// every instance, which adds up when hydrating many rows:
+//
+//	var userDefinition = eloquent.DefineModel(func(m *eloquent.BaseModel) {
+//		m.Table("users").Fillable("name", "email").Hidden("password")
+//	})
+//
+//	func NewUser() *UserModel {
+//		user := &UserModel{BaseModel: userDefinition.NewBaseModel()}
+//		user.SetParentModel(user)
+//		return user
+//	}
+//
+// A definition cannot be changed once built. Configuring an instance replaces its own
+// settings and leaves the definition and the other instances alone.
+type ModelDefinition struct {
+	template *BaseModel
+}
+
+// DefineModel builds a model definition from the configuration fn applies. Attributes fn
+// sets become default values of every instance.
+func DefineModel(fn func(m *BaseModel)) *ModelDefinition {
+	m := NewBaseModel()
+	fn(m)
+	return newModelDefinition(m)
+}
+
+// newModelDefinition snapshots the configuration and attributes of m
+func newModelDefinition(m *BaseModel) *ModelDefinition {
+	template := &BaseModel{attributes: make(map[string]interface{}, len(m.attributes))}
+	template.copyConfig(m)
+	for column, value := range m.attributes {
+		template.attributes[column] = value
+	}
+
+	// Instances appending to a shared list must not write into the definition's array
+	template.alwaysWith = template.alwaysWith[:len(template.alwaysWith):len(template.alwaysWith)]
+	template.defaultOrders = template.defaultOrders[:len(template.defaultOrders):len(template.defaultOrders)]
+	template.stateColumns = template.stateColumns[:len(template.stateColumns):len(template.stateColumns)]
+
+	definition := &ModelDefinition{template: template}
+	template.definition = definition
+	return definition
+}
+
+// NewBaseModel creates a BaseModel using the definition's configuration
+func (d *ModelDefinition) NewBaseModel() *BaseModel {
+	m := &BaseModel{
+		attributes: make(map[string]interface{}, len(d.template.attributes)),
+		original:   make(map[string]interface{}),
+		relations:  make(map[string]interface{}),
+	}
+	m.useDefinition(d)
+	return m
+}
+
+// Definition returns the definition the model was created from, by NewBaseModel or
+// Booted, or nil. Instances created from the same definition share its configuration.
+func (m *BaseModel) Definition() *ModelDefinition {
+	return m.definition
+}
+
+// useDefinition gives the model the definition's configuration and default attributes
+func (m *BaseModel) useDefinition(d *ModelDefinition) {
+	m.copyConfig(d.template)
+	for column, value := range d.template.attributes {
+		m.SetAttribute(column, value)
+	}
+}
+
+// detachDefinition is called before a setter modifies one of the configuration maps in
+// place, so it modifies a private copy instead of the maps shared with the definition
+func (m *BaseModel) detachDefinition() {
+	if m.definition == nil {
+		return
+	}
+	m.definition = nil
+	m.accessors = cloneMap(m.accessors)
+	m.contextCasts = cloneMap(m.contextCasts)
+	m.stateMachines = cloneMap(m.stateMachines)
+}
+
+// cloneMap returns a shallow copy of a map, or nil for a nil map
+func cloneMap[K comparable, V any](source map[K]V) map[K]V {
+	if source == nil {
+		return nil
+	}
+	clone := make(map[K]V, len(source))
+	for key, value := range source {
+		clone[key] = value
+	}
+	return clone
+}
@@ -0,0 +1,53 @@
+package eloquent
+
+import "testing"
+
+func TestModelDefinition(t *testing.T) {
+	definition := DefineModel(func(m *BaseModel) {
+		m.Fillable("name").Hidden("password").Casts(map[string]string{"active": "bool"}).AlwaysWith("orders")
+		m.Accessor("label", func(Model) interface{} { return "customer" })
+		m.SetAttribute("active", true)
+	})
+
+	newCustomer := func() *CustomerModel {
+		customer := &CustomerModel{BaseModel: definition.NewBaseModel()}
+		customer.SetParentModel(customer)
+		return customer
+	}
+
+	first, second := newCustomer(), newCustomer()
+	if first.Definition() != definition || second.Definition() != first.Definition() {
+		t.Error("Expected instances to share their definition")
+	}
+	if first.GetTable() != "customers" || len(first.GetFillable()) != 1 || first.GetCasts()["active"] != "bool" {
+		t.Errorf("Expected the definition's configuration, got table %q, fillable %v, casts %v",
+			first.GetTable(), first.GetFillable(), first.GetCasts())
+	}
+	if first.GetAttribute("active") != true {
+		t.Errorf("Expected the default attribute, got %v", first.GetAttribute("active"))
+	}
+
+	// Configuring an instance leaves the definition and other instances alone
+	first.SetAttribute("active", false)
+	first.AlwaysWith("invoices")
+	first.Accessor("label", func(Model) interface{} { return "vip" })
+	if first.Definition() != nil {
+		t.Error("Expected modifying a shared map to detach the instance from its definition")
+	}
+	if second.GetAttribute("active") != true || len(second.GetAlwaysWith()) != 1 || second.accessors["label"](second) != "customer" {
+		t.Errorf("Expected the other instance to keep the definition, got %v and %v", second.ToMap(), second.GetAlwaysWith())
+	}
+	if third := newCustomer(); len(third.GetAlwaysWith()) != 1 || third.accessors["label"](third) != "customer" {
+		t.Errorf("Expected new instances to keep the definition, got %v", third.GetAlwaysWith())
+	}
+}
+
+func BenchmarkModelDefinitionNewBaseModel(b *testing.B) {
+	definition := DefineModel(func(m *BaseModel) {
+		m.Fillable("name", "email").Hidden("password").Casts(map[string]string{"active": "bool"})
+	})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = definition.NewBaseModel()
+	}
+}
@@ -0,0 +1,78 @@
+package eloquent
+
+import "sort"
+
+// AlwaysWith eager loads relationships on every query for the model, like With on each
+// query; Without leaves them out of a single query. Relationships are named as their
+// builder names them, or after their method.
+func (m *BaseModel) AlwaysWith(relations ...string) *BaseModel {
+	m.alwaysWith = append(m.alwaysWith, relations...)
+	return m
+}
+
+// GetAlwaysWith returns the relationships eager loaded on every query for the model
+func (m *BaseModel) GetAlwaysWith() []string {
+	return m.alwaysWith
+}
+
+// Without stops eager loading relationships added with With or AlwaysWith
+func (qb *QueryBuilder) Without(relations ...string) *QueryBuilder {
+	qb = qb.mutable()
+	for _, relation := range relations {
+		delete(qb.eagerLoad, relation)
+	}
+	return qb
+}
+
+// With eager loads relationships on the models the query returns
+func (mqb *ModelQueryBuilder) With(relations ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.With(relations...))
+}
+
+// Without leaves relationships out of the eager loads
+func (mqb *ModelQueryBuilder) Without(relations ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.Without(relations...))
+}
+
+// With eager loads relationships on the models the query returns
+func (tmqb *TypedModelQueryBuilder[T]) With(relations ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.With(relations...))
+}
+
+// Without leaves relationships out of the eager loads
+func (tmqb *TypedModelQueryBuilder[T]) Without(relations ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.Without(relations...))
+}
+
+// With starts a query eager loading relationships (static-like)
+func (ms *ModelStatic[T]) With(relations ...string) *TypedModelQueryBuilder[T] {
+	return ms.Query().With(relations...)
+}
+
+// Without starts a query leaving relationships out of the eager loads (static-like)
+func (ms *ModelStatic[T]) Without(relations ...string) *TypedModelQueryBuilder[T] {
+	return ms.Query().Without(relations...)
+}
+
+// loadEager loads the query's eager loaded relationships on models, in name order,
+// applying the callbacks given to WithCallback
+func (qb *QueryBuilder) loadEager(models []Model) error {
+	if len(qb.eagerLoad) == 0 || len(models) == 0 {
+		return nil
+	}
+
+	relations := make([]string, 0, len(qb.eagerLoad))
+	for relation := range qb.eagerLoad {
+		relations = append(relations, relation)
+	}
+	sort.Strings(relations)
+
+	for _, model := range models {
+		for _, relation := range relations {
+			if err := loadRelation(model, relation, qb.eagerLoad[relation]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+// Package eloquenthttp provides generic REST handlers over go-eloquent models, so small
+// services get index, show, store, update and destroy endpoints without writing them.
+//
+//	eloquenthttp.Routes(mux, "/users", models.User, eloquenthttp.Options{
+//		Filters:  eloquent.AllowedFilters{Filters: map[string][]string{"status": nil}},
+//		Includes: []string{"posts"},
+//	})
+//
+// Responses wrap models in a data key. Index adds a meta key with pagination details,
+// and every handler honours ?fields=name,email (or fields[table]=...) to return a sparse
+// fieldset and ?include=posts to load relationships the model declares.
+package eloquenthttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/crashana/go-eloquent"
+)
+
+// Options configures the generated handlers
+type Options struct {
+	// Filters whitelists the filters, sorts and page sizes accepted by Index
+	Filters eloquent.AllowedFilters
+	// Includes lists the relationships clients may load with ?include=
+	Includes []string
+	// ID extracts the primary key from a request; by default it is the last path segment
+	ID func(*http.Request) string
+}
+
+// Routes registers the handlers for a model on a mux: GET and POST on prefix, and GET,
+// PUT, PATCH and DELETE on prefix/{id}
+func Routes[T eloquent.Model](mux *http.ServeMux, prefix string, static *eloquent.ModelStatic[T], options ...Options) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	index, store := Index(static, options...), Store(static, options...)
+	mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			index(w, r)
+		case http.MethodPost:
+			store(w, r)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	})
+
+	show, update, destroy := Show(static, options...), Update(static, options...), Destroy(static, options...)
+	mux.HandleFunc(prefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			show(w, r)
+		case http.MethodPut, http.MethodPatch:
+			update(w, r)
+		case http.MethodDelete:
+			destroy(w, r)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+		}
+	})
+}
+
+// Index lists models, applying the allowed filters and sorts and paginating with
+// ?page= and ?per_page=
+func Index[T eloquent.Model](static *eloquent.ModelStatic[T], options ...Options) http.HandlerFunc {
+	opts := optionsOf(options)
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := eloquent.FilterFromQuery(r.URL.Query(), opts.Filters)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		includes, err := opts.includes(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		query := static.WithContext(r.Context())
+		filter.Apply(query.QueryBuilder)
+
+		total, err := query.Count()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		models, err := query.Offset((filter.Page - 1) * filter.PerPage).Limit(filter.PerPage).Get()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		data := make([]map[string]interface{}, len(models))
+		for i, model := range models {
+			if data[i], err = present(model, r, includes); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": data,
+			"meta": map[string]interface{}{
+				"total":        total,
+				"per_page":     filter.PerPage,
+				"current_page": filter.Page,
+				"last_page":    (total + int64(filter.PerPage) - 1) / int64(filter.PerPage),
+			},
+		})
+	}
+}
+
+// Show returns one model by primary key
+func Show[T eloquent.Model](static *eloquent.ModelStatic[T], options ...Options) http.HandlerFunc {
+	opts := optionsOf(options)
+	return func(w http.ResponseWriter, r *http.Request) {
+		includes, err := opts.includes(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		model, err := static.WithContext(r.Context()).Find(opts.ID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeModel(w, http.StatusOK, model, r, includes)
+	}
+}
+
+// Store creates a model from a JSON object body. Only fillable attributes are assigned.
+func Store[T eloquent.Model](static *eloquent.ModelStatic[T], options ...Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		attributes, err := decodeAttributes(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		model, err := static.Create(attributes)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeModel(w, http.StatusCreated, model, r, nil)
+	}
+}
+
+// Update updates a model by primary key from a JSON object body. Only fillable
+// attributes are assigned.
+func Update[T eloquent.Model](static *eloquent.ModelStatic[T], options ...Options) http.HandlerFunc {
+	opts := optionsOf(options)
+	return func(w http.ResponseWriter, r *http.Request) {
+		attributes, err := decodeAttributes(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		model, err := static.WithContext(r.Context()).Find(opts.ID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := model.Update(attributes); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeModel(w, http.StatusOK, model, r, nil)
+	}
+}
+
+// Destroy deletes a model by primary key, softly if the model uses soft deletes
+func Destroy[T eloquent.Model](static *eloquent.ModelStatic[T], options ...Options) http.HandlerFunc {
+	opts := optionsOf(options)
+	return func(w http.ResponseWriter, r *http.Request) {
+		model, err := static.WithContext(r.Context()).Find(opts.ID(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := model.Delete(); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// requestError is a client error in a request's parameters or body
+type requestError struct {
+	message string
+}
+
+func (e requestError) Error() string {
+	return e.message
+}
+
+// optionsOf returns the given options with defaults applied
+func optionsOf(options []Options) Options {
+	var opts Options
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.ID == nil {
+		opts.ID = func(r *http.Request) string {
+			return path.Base(strings.TrimSuffix(r.URL.Path, "/"))
+		}
+	}
+	return opts
+}
+
+// includes returns the relationships requested with ?include=, which must all be allowed
+func (o Options) includes(r *http.Request) ([]string, error) {
+	value := r.URL.Query().Get("include")
+	if value == "" {
+		return nil, nil
+	}
+
+	includes := strings.Split(value, ",")
+	for _, include := range includes {
+		allowed := false
+		for _, name := range o.Includes {
+			if name == include {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, requestError{"include '" + include + "' is not allowed"}
+		}
+	}
+	return includes, nil
+}
+
+// present loads the requested relationships and serializes a model, keeping only the
+// requested fields plus the primary key and the included relationships
+func present(model eloquent.Model, r *http.Request, includes []string) (map[string]interface{}, error) {
+	for _, include := range includes {
+		if err := eloquent.LoadRelation(model, include); err != nil {
+			return nil, err
+		}
+	}
+
+	data := model.ToMap()
+	fields := requestedFields(r, model.GetTable())
+	if fields == nil {
+		return data, nil
+	}
+
+	keep := map[string]bool{model.GetPrimaryKey(): true}
+	for _, field := range append(fields, includes...) {
+		keep[field] = true
+	}
+	for key := range data {
+		if !keep[key] {
+			delete(data, key)
+		}
+	}
+	return data, nil
+}
+
+// requestedFields returns the sparse fieldset from ?fields= or ?fields[table]=, or nil
+func requestedFields(r *http.Request, table string) []string {
+	query := r.URL.Query()
+	value := query.Get("fields[" + table + "]")
+	if value == "" {
+		value = query.Get("fields")
+	}
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// decodeAttributes reads a JSON object from the request body
+func decodeAttributes(r *http.Request) (map[string]interface{}, error) {
+	var attributes map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&attributes); err != nil {
+		return nil, requestError{"request body must be a JSON object"}
+	}
+	return attributes, nil
+}
+
+// writeModel serializes one model in a data key
+func writeModel(w http.ResponseWriter, status int, model eloquent.Model, r *http.Request, includes []string) {
+	data, err := present(model, r, includes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, map[string]interface{}{"data": data})
+}
+
+// writeError maps an error to a status code. Details of server errors are not exposed.
+func writeError(w http.ResponseWriter, err error) {
+	var reqErr requestError
+	status, message := http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+
+	switch {
+	case errors.As(err, &reqErr), errors.Is(err, eloquent.ErrInvalidQuery):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, eloquent.ErrNotFound):
+		status, message = http.StatusNotFound, http.StatusText(http.StatusNotFound)
+	case errors.Is(err, eloquent.ErrReadOnly):
+		status, message = http.StatusForbidden, http.StatusText(http.StatusForbidden)
+	}
+
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+// methodNotAllowed reports an unsupported method
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": http.StatusText(http.StatusMethodNotAllowed)})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,156 @@
+package eloquenthttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crashana/go-eloquent"
+)
+
+type authorModel struct {
+	*eloquent.BaseModel
+}
+
+func (a *authorModel) Books() *eloquent.Relationship {
+	return eloquent.NewRelationshipBuilder(a).HasMany("books", "books")
+}
+
+func setupServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	conn := eloquent.NewTestSQLite(t)
+	schema := []string{
+		"CREATE TABLE authors (id TEXT PRIMARY KEY, name TEXT, status TEXT, created_at DATETIME, updated_at DATETIME)",
+		"CREATE TABLE books (id INTEGER PRIMARY KEY, author_id TEXT, title TEXT)",
+		"INSERT INTO authors (id, name, status) VALUES ('1', 'Ursula', 'active'), ('2', 'Octavia', 'active'), ('3', 'Isaac', 'retired')",
+		"INSERT INTO books (author_id, title) VALUES ('1', 'The Dispossessed'), ('1', 'The Lathe of Heaven')",
+	}
+	for _, statement := range schema {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up schema: %v", err)
+		}
+	}
+
+	authors := eloquent.NewModelStatic(func() *authorModel {
+		author := &authorModel{BaseModel: eloquent.NewBaseModel()}
+		author.Fillable("name", "status").Connection(conn.Name)
+		author.SetParentModel(author)
+		return author
+	})
+
+	mux := http.NewServeMux()
+	Routes(mux, "/authors", authors, Options{
+		Filters: eloquent.AllowedFilters{
+			Filters: map[string][]string{"status": nil},
+			Sorts:   []string{"name"},
+		},
+		Includes: []string{"books"},
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func request(t *testing.T, method, url, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func TestIndex(t *testing.T) {
+	server := setupServer(t)
+
+	status, body := request(t, http.MethodGet, server.URL+"/authors?filter[status]=active&sort=name&per_page=1&fields=name", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", status, body)
+	}
+
+	data := body["data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("Expected one author on the page, got %d", len(data))
+	}
+	author := data[0].(map[string]interface{})
+	if author["name"] != "Octavia" || author["status"] != nil || author["id"] == nil {
+		t.Errorf("Expected Octavia with only id and name, got %v", author)
+	}
+
+	meta := body["meta"].(map[string]interface{})
+	if meta["total"] != float64(2) || meta["last_page"] != float64(2) {
+		t.Errorf("Expected 2 active authors over 2 pages, got %v", meta)
+	}
+
+	if status, _ := request(t, http.MethodGet, server.URL+"/authors?filter[name]=Ursula", ""); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a filter that is not allowed, got %d", status)
+	}
+}
+
+func TestShowWithInclude(t *testing.T) {
+	server := setupServer(t)
+
+	status, body := request(t, http.MethodGet, server.URL+"/authors/1?include=books", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", status, body)
+	}
+	author := body["data"].(map[string]interface{})
+	if books, _ := author["books"].([]interface{}); len(books) != 2 {
+		t.Errorf("Expected 2 included books, got %v", author["books"])
+	}
+
+	if status, _ := request(t, http.MethodGet, server.URL+"/authors/99", ""); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing author, got %d", status)
+	}
+	if status, _ := request(t, http.MethodGet, server.URL+"/authors/1?include=password", ""); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an include that is not allowed, got %d", status)
+	}
+}
+
+func TestStoreUpdateDestroy(t *testing.T) {
+	server := setupServer(t)
+
+	status, body := request(t, http.MethodPost, server.URL+"/authors", `{"name": "Ted", "status": "active", "id": 500}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %v", status, body)
+	}
+	created := body["data"].(map[string]interface{})
+	if created["name"] != "Ted" || created["id"] == float64(500) || created["id"] == nil {
+		t.Errorf("Expected Ted with a generated id, got %v", created)
+	}
+
+	status, body = request(t, http.MethodPatch, server.URL+"/authors/3", `{"status": "active"}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", status, body)
+	}
+	if updated := body["data"].(map[string]interface{}); updated["status"] != "active" {
+		t.Errorf("Expected updated status, got %v", updated)
+	}
+
+	if status, _ := request(t, http.MethodDelete, server.URL+"/authors/3", ""); status != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", status)
+	}
+	if status, _ := request(t, http.MethodGet, server.URL+"/authors/3", ""); status != http.StatusNotFound {
+		t.Errorf("Expected deleted author to be gone, got %d", status)
+	}
+
+	if status, _ := request(t, http.MethodPost, server.URL+"/authors", `not json`); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid body, got %d", status)
+	}
+	if status, _ := request(t, http.MethodPut, server.URL+"/authors", `{}`); status != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", status)
+	}
+}
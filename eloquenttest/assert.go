@@ -0,0 +1,167 @@
+// Package eloquenttest provides assertions on database state for tests, modelled on
+// Laravel's assertDatabaseHas and friends:
+//
+//	eloquenttest.AssertDatabaseHas(t, "users", map[string]interface{}{"email": "ann@example.com"})
+//	eloquenttest.AssertDatabaseCount(t, "posts", 2)
+//
+// The assertions query the default connection, or the connection passed as their last
+// argument. A nil value matches NULL. Failures are reported with t.Errorf, so a test
+// keeps running; each assertion returns whether it passed.
+package eloquenttest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crashana/go-eloquent"
+)
+
+// TestingT is the subset of testing.TB the assertions use
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// sampleSize is the number of table rows shown when AssertDatabaseHas fails
+const sampleSize = 3
+
+// AssertDatabaseHas asserts that the table has a row with the given values
+func AssertDatabaseHas(t TestingT, table string, values map[string]interface{}, conn ...*eloquent.Connection) bool {
+	t.Helper()
+
+	count, err := matching(table, values, conn).Count()
+	if err != nil {
+		t.Errorf("Failed to query %s: %v", table, err)
+		return false
+	}
+	if count > 0 {
+		return true
+	}
+
+	sample, err := connection(conn).Table(table).Limit(sampleSize).Get()
+	if err != nil {
+		t.Errorf("Failed asserting that %s has a row matching %s", table, describe(values))
+		return false
+	}
+	found := "the table is empty"
+	if len(sample) > 0 {
+		rows := make([]string, len(sample))
+		for i, row := range sample {
+			rows[i] = describe(row)
+		}
+		found = "found " + strings.Join(rows, ", ")
+	}
+	t.Errorf("Failed asserting that %s has a row matching %s; %s", table, describe(values), found)
+	return false
+}
+
+// AssertDatabaseMissing asserts that the table has no row with the given values
+func AssertDatabaseMissing(t TestingT, table string, values map[string]interface{}, conn ...*eloquent.Connection) bool {
+	t.Helper()
+
+	count, err := matching(table, values, conn).Count()
+	if err != nil {
+		t.Errorf("Failed to query %s: %v", table, err)
+		return false
+	}
+	if count > 0 {
+		t.Errorf("Failed asserting that %s has no row matching %s; found %d", table, describe(values), count)
+		return false
+	}
+	return true
+}
+
+// AssertDatabaseCount asserts that the table has exactly count rows
+func AssertDatabaseCount(t TestingT, table string, count int64, conn ...*eloquent.Connection) bool {
+	t.Helper()
+
+	actual, err := connection(conn).Table(table).Count()
+	if err != nil {
+		t.Errorf("Failed to query %s: %v", table, err)
+		return false
+	}
+	if actual != count {
+		t.Errorf("Failed asserting that %s has %d rows; found %d", table, count, actual)
+		return false
+	}
+	return true
+}
+
+// AssertSoftDeleted asserts that the table has a row with the given values whose
+// deleted_at column is set
+func AssertSoftDeleted(t TestingT, table string, values map[string]interface{}, conn ...*eloquent.Connection) bool {
+	t.Helper()
+
+	count, err := matching(table, values, conn).WhereNotNull("deleted_at").Count()
+	if err != nil {
+		t.Errorf("Failed to query %s: %v", table, err)
+		return false
+	}
+	if count == 0 {
+		t.Errorf("Failed asserting that %s has a soft deleted row matching %s", table, describe(values))
+		return false
+	}
+	return true
+}
+
+// AssertNotSoftDeleted asserts that the table has a row with the given values whose
+// deleted_at column is NULL
+func AssertNotSoftDeleted(t TestingT, table string, values map[string]interface{}, conn ...*eloquent.Connection) bool {
+	t.Helper()
+
+	count, err := matching(table, values, conn).WhereNull("deleted_at").Count()
+	if err != nil {
+		t.Errorf("Failed to query %s: %v", table, err)
+		return false
+	}
+	if count == 0 {
+		t.Errorf("Failed asserting that %s has a row matching %s that is not soft deleted", table, describe(values))
+		return false
+	}
+	return true
+}
+
+// connection returns the connection passed to an assertion, or the default connection
+func connection(conn []*eloquent.Connection) *eloquent.Connection {
+	if len(conn) > 0 && conn[0] != nil {
+		return conn[0]
+	}
+	return eloquent.DB()
+}
+
+// matching builds a query for the rows of table with the given values
+func matching(table string, values map[string]interface{}, conn []*eloquent.Connection) *eloquent.QueryBuilder {
+	query := connection(conn).Table(table)
+	for _, column := range sortedKeys(values) {
+		if values[column] == nil {
+			query = query.WhereNull(column)
+		} else {
+			query = query.Where(column, values[column])
+		}
+	}
+	return query
+}
+
+// describe formats values as {column: value, ...} in column order
+func describe(values map[string]interface{}) string {
+	parts := make([]string, 0, len(values))
+	for _, column := range sortedKeys(values) {
+		value := values[column]
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %v", column, value))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// sortedKeys returns the columns of values in alphabetical order
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package eloquenttest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/crashana/go-eloquent"
+)
+
+// recorder collects the failures reported by an assertion
+type recorder struct {
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func setupUsers(t *testing.T) *eloquent.Connection {
+	t.Helper()
+
+	conn := eloquent.NewTestSQLite(t)
+	schema := []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT, deleted_at DATETIME)",
+		"INSERT INTO users (name, email) VALUES ('Ann', 'ann@example.com'), ('Ben', NULL)",
+		"INSERT INTO users (name, email, deleted_at) VALUES ('Cat', 'cat@example.com', CURRENT_TIMESTAMP)",
+	}
+	for _, statement := range schema {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up schema: %v", err)
+		}
+	}
+	return conn
+}
+
+func TestAssertionsPass(t *testing.T) {
+	conn := setupUsers(t)
+
+	r := &recorder{}
+	AssertDatabaseHas(r, "users", map[string]interface{}{"name": "Ann", "email": "ann@example.com"}, conn)
+	AssertDatabaseHas(r, "users", map[string]interface{}{"name": "Ben", "email": nil}, conn)
+	AssertDatabaseMissing(r, "users", map[string]interface{}{"name": "Dan"}, conn)
+	AssertDatabaseCount(r, "users", 3, conn)
+	AssertSoftDeleted(r, "users", map[string]interface{}{"name": "Cat"}, conn)
+	AssertNotSoftDeleted(r, "users", map[string]interface{}{"name": "Ann"}, conn)
+	if len(r.failures) > 0 {
+		t.Errorf("Expected every assertion to pass, got:\n%s", strings.Join(r.failures, "\n"))
+	}
+}
+
+func TestAssertionsFail(t *testing.T) {
+	conn := setupUsers(t)
+
+	tests := []struct {
+		assert func(TestingT) bool
+		want   string
+	}{
+		{
+			func(r TestingT) bool {
+				return AssertDatabaseHas(r, "users", map[string]interface{}{"name": "Dan"}, conn)
+			},
+			"Failed asserting that users has a row matching {name: Dan}; found {deleted_at: <nil>, email: ann@example.com, id: 1, name: Ann}",
+		},
+		{
+			func(r TestingT) bool {
+				return AssertDatabaseMissing(r, "users", map[string]interface{}{"name": "Ann"}, conn)
+			},
+			"Failed asserting that users has no row matching {name: Ann}; found 1",
+		},
+		{
+			func(r TestingT) bool { return AssertDatabaseCount(r, "users", 2, conn) },
+			"Failed asserting that users has 2 rows; found 3",
+		},
+		{
+			func(r TestingT) bool {
+				return AssertSoftDeleted(r, "users", map[string]interface{}{"name": "Ann"}, conn)
+			},
+			"Failed asserting that users has a soft deleted row matching {name: Ann}",
+		},
+		{
+			func(r TestingT) bool {
+				return AssertNotSoftDeleted(r, "users", map[string]interface{}{"name": "Cat"}, conn)
+			},
+			"Failed asserting that users has a row matching {name: Cat} that is not soft deleted",
+		},
+	}
+	for _, test := range tests {
+		r := &recorder{}
+		if test.assert(r) {
+			t.Errorf("Expected the assertion to fail: %s", test.want)
+		}
+		if len(r.failures) != 1 || !strings.HasPrefix(r.failures[0], test.want) {
+			t.Errorf("Expected failure %q, got %q", test.want, r.failures)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // EnvConfig holds environment configuration
@@ -13,7 +14,24 @@ type EnvConfig struct {
 	values map[string]string
 }
 
-var envConfig *EnvConfig
+var (
+	envConfig *EnvConfig
+	envMu     sync.RWMutex
+)
+
+// setEnvConfig replaces the loaded .env configuration
+func setEnvConfig(config *EnvConfig) {
+	envMu.Lock()
+	defer envMu.Unlock()
+	envConfig = config
+}
+
+// envLoaded reports whether a .env configuration has been loaded
+func envLoaded() bool {
+	envMu.RLock()
+	defer envMu.RUnlock()
+	return envConfig != nil
+}
 
 // LoadEnv loads environment variables from .env file
 func LoadEnv(filepath ...string) error {
@@ -29,7 +47,7 @@ func LoadEnv(filepath ...string) error {
 	// Check if .env file exists
 	if _, err := os.Stat(envFile); os.IsNotExist(err) {
 		// .env file doesn't exist, that's okay - we'll use system environment variables
-		envConfig = config
+		setEnvConfig(config)
 		return nil
 	}
 
@@ -79,18 +97,21 @@ func LoadEnv(filepath ...string) error {
 		return fmt.Errorf("error reading .env file: %w", err)
 	}
 
-	envConfig = config
+	setEnvConfig(config)
 	return nil
 }
 
 // Env gets an environment variable value
 func Env(key string, defaultValue ...string) string {
 	// First try to get from .env file
+	envMu.RLock()
 	if envConfig != nil {
 		if value, exists := envConfig.values[key]; exists {
+			envMu.RUnlock()
 			return value
 		}
 	}
+	envMu.RUnlock()
 
 	// Then try system environment variables
 	if value := os.Getenv(key); value != "" {
@@ -139,46 +160,118 @@ func EnvBool(key string, defaultValue ...bool) bool {
 	return value == "true" || value == "1" || value == "yes" || value == "on"
 }
 
-// AutoConnect automatically connects to database using .env configuration
+// AutoConnect automatically connects to database using .env configuration.
+// Besides the default connection configured by DB_* variables, every name listed in
+// DB_CONNECTIONS (comma separated) is connected using DB_<NAME>_* variables.
 func AutoConnect() error {
 	// Load .env file if not already loaded
-	if envConfig == nil {
+	if !envLoaded() {
 		if err := LoadEnv(); err != nil {
 			return fmt.Errorf("failed to load .env file: %w", err)
 		}
 	}
 
+	if err := connectFromEnv("default", "DB_"); err != nil {
+		return err
+	}
+
+	for _, name := range envConnectionNames() {
+		prefix := "DB_" + strings.ToUpper(name) + "_"
+		if err := connectFromEnv(name, prefix); err != nil {
+			return fmt.Errorf("failed to configure connection '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// envConnectionNames returns the extra connection names listed in DB_CONNECTIONS
+func envConnectionNames() []string {
+	var names []string
+	for _, name := range strings.Split(Env("DB_CONNECTIONS"), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && name != "default" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// connectFromEnv adds a named connection configured by environment variables with the given prefix
+func connectFromEnv(name, prefix string) error {
+	config, err := envConnectionConfig(prefix)
+	if err != nil {
+		return err
+	}
+	return GetManager().AddConnection(name, config)
+}
+
+// envConnectionConfig builds a connection config from environment variables with the given prefix
+func envConnectionConfig(prefix string) (ConnectionConfig, error) {
+	// A database URL takes precedence over individual settings
+	rawURL := Env(prefix + "URL")
+	if rawURL == "" && prefix == "DB_" {
+		rawURL = Env("DATABASE_URL")
+	}
+	if rawURL != "" {
+		config, err := ParseDatabaseURL(rawURL)
+		if err != nil {
+			return config, err
+		}
+		config.Prefix = Env(prefix + "PREFIX")
+		config.Schema = Env(prefix+"SCHEMA", config.Schema)
+
+		// Password files and secret references override the password in the URL
+		password, err := envPassword(prefix)
+		if err != nil {
+			return config, err
+		}
+		if password != "" {
+			config.Password = password
+		}
+		return config, nil
+	}
+
 	// Get database connection type
-	dbConnection := Env("DB_CONNECTION", "pgsql")
+	dbConnection := Env(prefix+"CONNECTION", "pgsql")
 
 	// Build connection config from environment variables
 	config := ConnectionConfig{
-		Host:     Env("DB_HOST", "localhost"),
-		Port:     EnvInt("DB_PORT", getDefaultPort(dbConnection)),
-		Database: Env("DB_DATABASE", ""),
-		Username: Env("DB_USERNAME", ""),
-		Password: Env("DB_PASSWORD", ""),
-		Charset:  Env("DB_CHARSET", ""),
+		Host:     Env(prefix+"HOST", "localhost"),
+		Port:     EnvInt(prefix+"PORT", getDefaultPort(dbConnection)),
+		Database: Env(prefix+"DATABASE", ""),
+		Username: Env(prefix+"USERNAME", ""),
+		Charset:  Env(prefix+"CHARSET", ""),
+		Prefix:   Env(prefix+"PREFIX", ""),
+		Schema:   Env(prefix+"SCHEMA", ""),
 		Options:  make(map[string]string),
 	}
 
+	password, err := envPassword(prefix)
+	if err != nil {
+		return config, err
+	}
+	config.Password = password
+
 	// Validate required fields
 	if config.Database == "" {
-		return fmt.Errorf("DB_DATABASE is required in .env file or environment variables")
+		return config, fmt.Errorf("%sDATABASE is required in .env file or environment variables", prefix)
 	}
 	if config.Username == "" {
-		return fmt.Errorf("DB_USERNAME is required in .env file or environment variables")
+		return config, fmt.Errorf("%sUSERNAME is required in .env file or environment variables", prefix)
 	}
 
-	// Connect based on DB_CONNECTION type
+	// Resolve the driver based on DB_CONNECTION type
 	switch dbConnection {
 	case "pgsql", "postgres", "postgresql":
-		return PostgreSQL(config)
+		config.Driver = "postgres"
 	case "mysql":
-		return MySQL(config)
+		config.Driver = "mysql"
 	default:
-		return fmt.Errorf("unsupported DB_CONNECTION type: %s (supported: pgsql, mysql)", dbConnection)
+		return config, fmt.Errorf("unsupported %sCONNECTION type: %s (supported: pgsql, mysql)", prefix, dbConnection)
 	}
+
+	return config, nil
 }
 
 // getDefaultPort returns the default port for a database connection type
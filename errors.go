@@ -0,0 +1,119 @@
+package eloquent
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	// ErrDuplicateKey is returned when a write violates a unique index or primary key
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrForeignKeyViolation is returned when a write references a missing row, or
+	// deletes a row other rows still reference
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+
+	// ErrCheckViolation is returned when a write violates a check constraint
+	ErrCheckViolation = errors.New("check constraint violation")
+
+	// ErrSerialization is returned when the database aborts a transaction that conflicts
+	// with a concurrent one, including deadlocks; the transaction can be retried
+	ErrSerialization = errors.New("serialization failure")
+)
+
+// DatabaseError is a driver error translated into one of ErrDuplicateKey,
+// ErrForeignKeyViolation, ErrCheckViolation and ErrSerialization. errors.Is matches
+// it against that error and errors.As still reaches the driver's error:
+//
+//	if errors.Is(err, eloquent.ErrDuplicateKey) {
+//		return fmt.Errorf("email %s is taken", email)
+//	}
+type DatabaseError struct {
+	// Kind is the package error the driver error was translated into
+	Kind error
+	// Code is the SQLSTATE on PostgreSQL, the error number on MySQL and the extended
+	// result code on SQLite
+	Code string
+	// Constraint is the violated constraint, when the driver reports it
+	Constraint string
+	// Err is the driver's error
+	Err error
+}
+
+func (e *DatabaseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the package error and the driver's error
+func (e *DatabaseError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// TranslateError returns err as a *DatabaseError when it is a PostgreSQL, MySQL or SQLite
+// constraint or serialization error, and err unchanged otherwise. Connections translate
+// the errors of the queries they run, so it is only needed for statements run on a
+// transaction or the underlying sqlx.DB.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var translated *DatabaseError
+	if errors.As(err, &translated) {
+		return err
+	}
+
+	var pqErr *pq.Error
+	var mysqlErr *mysql.MySQLError
+	var sqliteErr sqlite3.Error
+	switch {
+	case errors.As(err, &pqErr):
+		return classifyError(err, string(pqErr.Code), pqErr.Constraint, postgresErrorKinds[string(pqErr.Code)])
+	case errors.As(err, &mysqlErr):
+		return classifyError(err, strconv.Itoa(int(mysqlErr.Number)), "", mysqlErrorKinds[mysqlErr.Number])
+	case errors.As(err, &sqliteErr):
+		return classifyError(err, strconv.Itoa(int(sqliteErr.ExtendedCode)), "", sqliteErrorKinds[sqliteErr.ExtendedCode])
+	}
+	return err
+}
+
+// classifyError wraps err in a DatabaseError of kind, or returns it unchanged when the
+// driver code has no matching kind
+func classifyError(err error, code, constraint string, kind error) error {
+	if kind == nil {
+		return err
+	}
+	return &DatabaseError{Kind: kind, Code: code, Constraint: constraint, Err: err}
+}
+
+// postgresErrorKinds maps SQLSTATE codes to package errors
+var postgresErrorKinds = map[string]error{
+	"23505": ErrDuplicateKey,
+	"23503": ErrForeignKeyViolation,
+	"23514": ErrCheckViolation,
+	"40001": ErrSerialization,
+	"40P01": ErrSerialization, // deadlock detected
+}
+
+// mysqlErrorKinds maps MySQL error numbers to package errors
+var mysqlErrorKinds = map[uint16]error{
+	1062: ErrDuplicateKey,        // ER_DUP_ENTRY
+	1586: ErrDuplicateKey,        // ER_DUP_ENTRY_WITH_KEY_NAME
+	1216: ErrForeignKeyViolation, // ER_NO_REFERENCED_ROW
+	1217: ErrForeignKeyViolation, // ER_ROW_IS_REFERENCED
+	1451: ErrForeignKeyViolation, // ER_ROW_IS_REFERENCED_2
+	1452: ErrForeignKeyViolation, // ER_NO_REFERENCED_ROW_2
+	3819: ErrCheckViolation,      // ER_CHECK_CONSTRAINT_VIOLATED
+	1213: ErrSerialization,       // ER_LOCK_DEADLOCK
+}
+
+// sqliteErrorKinds maps SQLite extended result codes to package errors
+var sqliteErrorKinds = map[sqlite3.ErrNoExtended]error{
+	sqlite3.ErrConstraintUnique:     ErrDuplicateKey,
+	sqlite3.ErrConstraintPrimaryKey: ErrDuplicateKey,
+	sqlite3.ErrConstraintForeignKey: ErrForeignKeyViolation,
+	sqlite3.ErrConstraintCheck:      ErrCheckViolation,
+}
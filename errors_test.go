@@ -0,0 +1,104 @@
+package eloquent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestErrorTranslation(t *testing.T) {
+	conn := NewTestSQLite(t, SQLiteOptions{ForeignKeys: true})
+	for _, statement := range []string{
+		"CREATE TABLE teams (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE members (id INTEGER PRIMARY KEY, email TEXT UNIQUE, age INTEGER CHECK (age >= 0), team_id INTEGER REFERENCES teams(id))",
+		"INSERT INTO teams (id, name) VALUES (1, 'Core')",
+		"INSERT INTO members (id, email, age, team_id) VALUES (1, 'ada@example.com', 36, 1)",
+	} {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up tables: %v", err)
+		}
+	}
+
+	members := func() *QueryBuilder { return NewQueryBuilder(conn).Table("members") }
+	_, err := conn.Insert("INSERT INTO members (email, age, team_id) VALUES (?, ?, ?)", "ada@example.com", 20, 1)
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected ErrDuplicateKey, got %v", err)
+	}
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		t.Errorf("Expected the driver error to stay reachable, got %v", err)
+	}
+
+	_, err = conn.Insert("INSERT INTO members (id, email, age, team_id) VALUES (?, ?, ?, ?)", 1, "grace@example.com", 20, 1)
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected ErrDuplicateKey for a primary key, got %v", err)
+	}
+
+	_, err = conn.Insert("INSERT INTO members (email, age, team_id) VALUES (?, ?, ?)", "grace@example.com", 20, 9)
+	if !errors.Is(err, ErrForeignKeyViolation) {
+		t.Errorf("Expected ErrForeignKeyViolation, got %v", err)
+	}
+	_, err = NewQueryBuilder(conn).Table("teams").Where("id", 1).Delete()
+	if !errors.Is(err, ErrForeignKeyViolation) {
+		t.Errorf("Expected ErrForeignKeyViolation deleting a referenced row, got %v", err)
+	}
+
+	_, err = members().Where("id", 1).Update(map[string]interface{}{"age": -1})
+	if !errors.Is(err, ErrCheckViolation) {
+		t.Errorf("Expected ErrCheckViolation, got %v", err)
+	}
+
+	err = conn.Transaction(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("INSERT INTO members (email, age, team_id) VALUES (?, ?, ?)", "ada@example.com", 20, 1)
+		return err
+	})
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected transactions to translate errors, got %v", err)
+	}
+
+	_, err = members().Where("missing", 1).Get()
+	var translated *DatabaseError
+	if err == nil || errors.As(err, &translated) {
+		t.Errorf("Expected other errors to stay untranslated, got %v", err)
+	}
+}
+
+func TestTranslateDriverErrors(t *testing.T) {
+	tests := []struct {
+		err        error
+		kind       error
+		code       string
+		constraint string
+	}{
+		{&pq.Error{Code: "23505", Constraint: "users_email_key"}, ErrDuplicateKey, "23505", "users_email_key"},
+		{&pq.Error{Code: "23503"}, ErrForeignKeyViolation, "23503", ""},
+		{&pq.Error{Code: "23514"}, ErrCheckViolation, "23514", ""},
+		{&pq.Error{Code: "40001"}, ErrSerialization, "40001", ""},
+		{&pq.Error{Code: "40P01"}, ErrSerialization, "40P01", ""},
+		{&mysql.MySQLError{Number: 1062}, ErrDuplicateKey, "1062", ""},
+		{&mysql.MySQLError{Number: 1452}, ErrForeignKeyViolation, "1452", ""},
+		{&mysql.MySQLError{Number: 3819}, ErrCheckViolation, "3819", ""},
+		{fmt.Errorf("saving: %w", &mysql.MySQLError{Number: 1213}), ErrSerialization, "1213", ""},
+	}
+	for _, tt := range tests {
+		err := TranslateError(tt.err)
+		var translated *DatabaseError
+		if !errors.Is(err, tt.kind) || !errors.As(err, &translated) || translated.Code != tt.code || translated.Constraint != tt.constraint {
+			t.Errorf("Expected %v to translate to %v with code %s, got %#v", tt.err, tt.kind, tt.code, err)
+		}
+		if TranslateError(err) != err {
+			t.Errorf("Expected translating twice to return the same error")
+		}
+	}
+
+	for _, err := range []error{nil, &pq.Error{Code: "42P01"}, &mysql.MySQLError{Number: 1146}, ErrNotFound} {
+		if translated := TranslateError(err); translated != err {
+			t.Errorf("Expected %v to stay untranslated, got %v", err, translated)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package eloquent
+
+import (
+	"context"
+	"sync"
+)
+
+// EventFake records the model events of a test instead of publishing them, so code that
+// reacts to model writes can be tested without the publisher and the systems behind it.
+// Events are named "<model>.<type>", such as "user.created"; "<table>.<type>" also
+// matches. It is safe for concurrent use.
+type EventFake struct {
+	t TestingT
+
+	mu     sync.Mutex
+	events []ModelEvent
+}
+
+// FakeEvents replaces the event publisher with an EventFake, which captures every model
+// event. The previous publisher is restored when the test ends.
+func FakeEvents(t TestingT) *EventFake {
+	t.Helper()
+
+	fake := &EventFake{t: t}
+	previous := GetEventPublisher()
+	SetEventPublisher(fake)
+	t.Cleanup(func() {
+		SetEventPublisher(previous)
+	})
+	return fake
+}
+
+// Publish records events
+func (f *EventFake) Publish(_ context.Context, events []ModelEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, events...)
+	return nil
+}
+
+// Events returns the recorded events in the order they were published
+func (f *EventFake) Events() []ModelEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]ModelEvent(nil), f.events...)
+}
+
+// Dispatched returns the recorded events with the given name
+func (f *EventFake) Dispatched(name string) []ModelEvent {
+	var matched []ModelEvent
+	for _, event := range f.Events() {
+		if event.Model+"."+event.Type == name || event.Table+"."+event.Type == name {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// AssertDispatched fails the test unless exactly n events with the given name were
+// published; without n, at least one must have been
+func (f *EventFake) AssertDispatched(name string, n ...int) {
+	f.t.Helper()
+	got := len(f.Dispatched(name))
+	if len(n) == 0 && got == 0 {
+		f.t.Fatalf("expected %s to be dispatched, got %v", name, f.names())
+	}
+	if len(n) > 0 && got != n[0] {
+		f.t.Fatalf("expected %s to be dispatched %d times, got %d: %v", name, n[0], got, f.names())
+	}
+}
+
+// AssertNotDispatched fails the test if an event with the given name was published
+func (f *EventFake) AssertNotDispatched(name string) {
+	f.t.Helper()
+	if got := len(f.Dispatched(name)); got > 0 {
+		f.t.Fatalf("expected %s not to be dispatched, got it %d times", name, got)
+	}
+}
+
+// Reset forgets the recorded events
+func (f *EventFake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = nil
+}
+
+// names returns the names of the recorded events, for failure messages
+func (f *EventFake) names() []string {
+	events := f.Events()
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Model + "." + event.Type
+	}
+	return names
+}
@@ -0,0 +1,39 @@
+package eloquent
+
+// UpdatingHook is implemented by models that run logic before an existing record is updated.
+// The hook sees the new values; returning an error aborts the update.
+// Values changed with SetAttribute inside the hook are written with the update.
+type UpdatingHook interface {
+	Updating() error
+}
+
+// UpdatedHook is implemented by models that run logic after an existing record is updated
+type UpdatedHook interface {
+	Updated()
+}
+
+// fireUpdating runs the model's Updating hook, if it has one
+func (m *BaseModel) fireUpdating() error {
+	if hook, ok := m.outerModel().(UpdatingHook); ok {
+		return hook.Updating()
+	}
+	return nil
+}
+
+// fireUpdated runs the model's Updated hook, if it has one
+func (m *BaseModel) fireUpdated() {
+	if hook, ok := m.outerModel().(UpdatedHook); ok {
+		hook.Updated()
+	}
+}
+
+// TransitioningHook is implemented by models that run logic before TransitionTo writes a
+// new state; returning an error aborts the transition
+type TransitioningHook interface {
+	Transitioning(column, from, to string) error
+}
+
+// TransitionedHook is implemented by models that run logic after TransitionTo wrote a new state
+type TransitionedHook interface {
+	Transitioned(column, from, to string)
+}
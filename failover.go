@@ -0,0 +1,47 @@
+package eloquent
+
+import (
+	"context"
+	"time"
+)
+
+// ActiveHost returns the host new connections are opened against.
+// For connections without standby hosts this is empty.
+func (c *Connection) ActiveHost() string {
+	if c.connector == nil || len(c.connector.hosts) < 2 {
+		return ""
+	}
+	return c.connector.hosts[c.connector.active.Load()]
+}
+
+// CheckHealth pings the connection and, after a failover, promotes the primary
+// host back once it is reachable. Idle connections to the standby are recycled
+// by the pool according to ConnMaxLifetime.
+func (c *Connection) CheckHealth(ctx context.Context) error {
+	if c.connector != nil && len(c.connector.hosts) > 1 {
+		if err := c.connector.promotePrimary(ctx); err != nil {
+			GetLogger().Debug("eloquent: primary host still unavailable", "connection", c.Name, "error", err)
+		}
+	}
+	return c.DB.PingContext(ctx)
+}
+
+// MonitorHealth runs CheckHealth for every managed connection at the given interval
+// until the context is cancelled
+func (cm *ConnectionManager) MonitorHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for name, conn := range cm.connectionsSnapshot() {
+				if err := conn.CheckHealth(ctx); err != nil {
+					GetLogger().Error("eloquent: health check failed", "connection", name, "error", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package eloquent
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeFailoverDriver refuses connections to hosts listed in down
+type fakeFailoverDriver struct {
+	down map[string]bool
+}
+
+type fakeFailoverConn struct {
+	driver.Conn
+	dsn string
+}
+
+func (c *fakeFailoverConn) Close() error { return nil }
+
+func (d *fakeFailoverDriver) Open(dsn string) (driver.Conn, error) {
+	for host := range d.down {
+		if strings.Contains(dsn, "("+host+":") {
+			return nil, fmt.Errorf("host %s is down", host)
+		}
+	}
+	return &fakeFailoverConn{dsn: dsn}, nil
+}
+
+func TestApplyHost(t *testing.T) {
+	config := ConnectionConfig{Host: "primary", Port: 3306}
+
+	if err := applyHost(&config, "standby"); err != nil || config.Host != "standby" || config.Port != 3306 {
+		t.Errorf("Expected host without port to keep port 3306, got %+v (%v)", config, err)
+	}
+	if err := applyHost(&config, "standby:3307"); err != nil || config.Host != "standby" || config.Port != 3307 {
+		t.Errorf("Expected host:port to set both, got %+v (%v)", config, err)
+	}
+}
+
+func TestConnectorFailoverAndPromotion(t *testing.T) {
+	drv := &fakeFailoverDriver{down: map[string]bool{"primary": true}}
+	connector := &configConnector{
+		driver: drv,
+		config: ConnectionConfig{Driver: "mysql", Host: "primary", Port: 3306, Database: "app", Username: "app"},
+		hosts:  []string{"primary", "standby:3307"},
+	}
+
+	conn, err := connector.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected failover to standby, got %v", err)
+	}
+	if !strings.Contains(conn.(*fakeFailoverConn).dsn, "(standby:3307)") {
+		t.Errorf("Expected connection to standby, got DSN %s", conn.(*fakeFailoverConn).dsn)
+	}
+	if connector.active.Load() != 1 {
+		t.Errorf("Expected standby to become active, got index %d", connector.active.Load())
+	}
+
+	if err := connector.promotePrimary(context.Background()); err == nil {
+		t.Error("Expected promotion to fail while primary is down")
+	}
+
+	delete(drv.down, "primary")
+	if err := connector.promotePrimary(context.Background()); err != nil {
+		t.Fatalf("Expected primary to be promoted, got %v", err)
+	}
+	if connector.active.Load() != 0 {
+		t.Errorf("Expected primary to be active again, got index %d", connector.active.Load())
+	}
+}
+
+func TestConnectorAllHostsDown(t *testing.T) {
+	connector := &configConnector{
+		driver: &fakeFailoverDriver{down: map[string]bool{"primary": true, "standby": true}},
+		config: ConnectionConfig{Driver: "mysql", Host: "primary", Port: 3306, Database: "app", Username: "app"},
+		hosts:  []string{"primary", "standby"},
+	}
+
+	if _, err := connector.Connect(context.Background()); err == nil {
+		t.Error("Expected error when every host is down")
+	}
+}
@@ -0,0 +1,254 @@
+package eloquent
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// RecordedQuery is a statement captured by a FakeDB
+type RecordedQuery struct {
+	SQL  string
+	Args []interface{}
+}
+
+// FakeDB records the queries sent to a fake connection instead of running them, for unit
+// tests of query construction. Selects return no rows unless results are queued with
+// QueueRows, and every other statement reports one affected row.
+// It is safe for concurrent use.
+type FakeDB struct {
+	t TestingT
+
+	mu      sync.Mutex
+	queries []RecordedQuery
+	results [][]map[string]interface{}
+}
+
+// Fake replaces the default connection of the global manager with a FakeDB.
+// The previous connection is restored when the test ends.
+func Fake(t TestingT) *FakeDB {
+	t.Helper()
+	runPendingBoot()
+
+	fake := &FakeDB{t: t}
+	cm := GetManager()
+
+	cm.mu.RLock()
+	name := cm.default_
+	cm.mu.RUnlock()
+
+	conn := NewConnectionFromDB(sql.OpenDB(fakeConnector{fake: fake}), "fake")
+	conn.Name = name
+	t.Cleanup(func() {
+		_ = conn.DB.Close()
+	})
+	WithTestConnection(t, conn)
+
+	return fake
+}
+
+// QueueRows queues the rows returned by the next select
+func (f *FakeDB) QueueRows(rows ...map[string]interface{}) *FakeDB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, rows)
+	return f
+}
+
+// Queries returns the recorded queries in execution order
+func (f *FakeDB) Queries() []RecordedQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RecordedQuery(nil), f.queries...)
+}
+
+// QueriesFor returns the recorded queries that read from or write to the given table
+func (f *FakeDB) QueriesFor(table string) []RecordedQuery {
+	pattern := regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|JOIN)\s+` + regexp.QuoteMeta(table) + `\b`)
+
+	var matched []RecordedQuery
+	for _, query := range f.Queries() {
+		if pattern.MatchString(query.SQL) {
+			matched = append(matched, query)
+		}
+	}
+	return matched
+}
+
+// AssertQueried fails the test unless exactly n recorded queries touched the given table
+func (f *FakeDB) AssertQueried(table string, n int) {
+	f.t.Helper()
+	if got := len(f.QueriesFor(table)); got != n {
+		f.t.Fatalf("expected %d queries on %s, got %d: %v", n, table, got, f.Queries())
+	}
+}
+
+// Reset forgets the recorded queries and queued rows
+func (f *FakeDB) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = nil
+	f.results = nil
+}
+
+// record captures a statement and its arguments
+func (f *FakeDB) record(query string, args []driver.NamedValue) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, RecordedQuery{SQL: query, Args: values})
+}
+
+// nextRows returns the rows queued for the next select
+func (f *FakeDB) nextRows() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.results) == 0 {
+		return nil
+	}
+	rows := f.results[0]
+	f.results = f.results[1:]
+	return rows
+}
+
+// fakeConnector opens connections that report to a FakeDB
+type fakeConnector struct {
+	fake *FakeDB
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{fake: c.fake}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// fakeDriver only exists to satisfy driver.Connector; connections come from fakeConnector
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrSkip
+}
+
+// fakeConn records statements without executing them
+type fakeConn struct {
+	fake *FakeDB
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{conn: c, query: query}, nil
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+// CheckNamedValue accepts arguments of any type so they are recorded unchanged
+func (c fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.fake.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.fake.record(query, args)
+	return newFakeRows(c.fake.nextRows()), nil
+}
+
+// fakeStmt forwards prepared statements to the connection
+type fakeStmt struct {
+	conn  fakeConn
+	query string
+}
+
+func (s fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+// namedValues converts positional driver values to named values
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// fakeTx is a no-op transaction
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+// fakeRows serves queued rows
+type fakeRows struct {
+	columns []string
+	rows    []map[string]interface{}
+}
+
+// newFakeRows returns rows whose columns are the sorted union of the rows' keys
+func newFakeRows(rows []map[string]interface{}) *fakeRows {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	return &fakeRows{columns: columns, rows: rows}
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+
+	for i, column := range r.columns {
+		dest[i] = row[column]
+	}
+	return nil
+}
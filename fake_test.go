@@ -0,0 +1,112 @@
+package eloquent
+
+import (
+	"testing"
+)
+
+func TestFakeRecordsQueries(t *testing.T) {
+	fake := Fake(t)
+
+	fake.QueueRows(
+		map[string]interface{}{"id": int64(1), "name": "Ada"},
+		map[string]interface{}{"id": int64(2), "name": "Grace"},
+	)
+	users, err := DB().Table("users").Where("active", true).OrderBy("name", "asc").Get()
+	if err != nil {
+		t.Fatalf("Failed to query fake: %v", err)
+	}
+	if len(users) != 2 || users[1]["name"] != "Grace" {
+		t.Errorf("Expected queued rows, got %v", users)
+	}
+
+	affected, err := DB().Table("users").Where("id", 1).Update(map[string]interface{}{"name": "Ada L."})
+	if err != nil {
+		t.Fatalf("Failed to update through fake: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("Expected 1 affected row, got %d", affected)
+	}
+
+	if _, err := DB().Table("posts").Get(); err != nil {
+		t.Fatalf("Failed to query fake: %v", err)
+	}
+
+	fake.AssertQueried("users", 2)
+	fake.AssertQueried("posts", 1)
+	fake.AssertQueried("comments", 0)
+
+	queries := fake.Queries()
+	if queries[0].SQL != "SELECT * FROM users WHERE active = ? ORDER BY name ASC" {
+		t.Errorf("Unexpected SQL: %s", queries[0].SQL)
+	}
+	if len(queries[1].Args) != 2 || queries[1].Args[0] != "Ada L." || queries[1].Args[1] != 1 {
+		t.Errorf("Unexpected update args: %v", queries[1].Args)
+	}
+
+	fake.Reset()
+	if len(fake.Queries()) != 0 {
+		t.Errorf("Expected Reset to clear queries, got %v", fake.Queries())
+	}
+}
+
+func TestFakeRestoresConnection(t *testing.T) {
+	conn := NewTestSQLite(t)
+	cm := GetManager()
+	previous := cm.setConnection("default", conn)
+	defer cm.setConnection("default", previous)
+
+	t.Run("faked", func(t *testing.T) {
+		Fake(t)
+		if DB().Driver != "fake" {
+			t.Errorf("Expected fake default connection, got %s", DB().Driver)
+		}
+	})
+
+	if DB() != conn {
+		t.Error("Expected the default connection to be restored after the test")
+	}
+}
+
+func TestFakeEvents(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	SetEventPublisher(publisher)
+	t.Cleanup(func() { SetEventPublisher(nil) })
+
+	t.Run("faked", func(t *testing.T) {
+		events := FakeEvents(t)
+
+		customer := newEventCustomer(conn, map[string]interface{}{"name": "Ada"})
+		if err := customer.Save(); err != nil {
+			t.Fatalf("Failed to save customer: %v", err)
+		}
+		if err := customer.Update(map[string]interface{}{"name": "Ada Lovelace"}); err != nil {
+			t.Fatalf("Failed to update customer: %v", err)
+		}
+		if err := customer.Update(map[string]interface{}{"name": "Countess Lovelace"}); err != nil {
+			t.Fatalf("Failed to update customer: %v", err)
+		}
+
+		events.AssertDispatched("customers.created", 1)
+		events.AssertDispatched("customers.updated", 2)
+		events.AssertDispatched("customers.updated")
+		events.AssertNotDispatched("customers.deleted")
+
+		model := events.Events()[0].Model
+		events.AssertDispatched(model+".created", 1)
+
+		events.Reset()
+		events.AssertNotDispatched("customers.created")
+	})
+
+	if GetEventPublisher() != publisher {
+		t.Error("Expected the event publisher to be restored after the test")
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("Expected faked events not to reach the publisher, got %v", publisher.events)
+	}
+}
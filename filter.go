@@ -0,0 +1,203 @@
+package eloquent
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AllowedFilters whitelists the query string parameters FilterFromQuery translates
+type AllowedFilters struct {
+	// Filters maps each filterable column to the operators allowed on it, from
+	// eq, ne, gt, gte, lt, lte, like and in. No operators allows eq and in only.
+	Filters map[string][]string
+	// Sorts lists the columns that may be sorted on
+	Sorts []string
+	// DefaultPerPage is used when the query string has no page size, 15 if zero
+	DefaultPerPage int
+	// MaxPerPage caps the requested page size, 100 if zero
+	MaxPerPage int
+}
+
+// QueryFilter holds the constraints parsed from a query string
+type QueryFilter struct {
+	Page    int
+	PerPage int
+
+	wheres []filterWhere
+	orders []OrderClause
+}
+
+// filterWhere is one parsed filter[column][operator]=value parameter
+type filterWhere struct {
+	column   string
+	operator string
+	value    string
+}
+
+// filterOperators maps query string operators to SQL operators
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "!=",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "LIKE",
+	"in":   "IN",
+}
+
+// filterParamPattern matches filter[column] and filter[column][operator]
+var filterParamPattern = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// FilterFromQuery translates JSON:API-style query parameters into query constraints:
+//
+//	?filter[status]=active&filter[age][gte]=18&filter[role]=admin,editor&sort=-created_at,name&page=2&per_page=25
+//
+// Comma-separated filter values match any of the values. page[number] and page[size]
+// are accepted as well. Filters or sorts that are not allowed fail with ErrInvalidQuery,
+// which handlers can report as a bad request; other parameters are ignored.
+func FilterFromQuery(values url.Values, allowed AllowedFilters) (*QueryFilter, error) {
+	filter := &QueryFilter{Page: 1, PerPage: allowed.DefaultPerPage}
+	if filter.PerPage <= 0 {
+		filter.PerPage = 15
+	}
+	maxPerPage := allowed.MaxPerPage
+	if maxPerPage <= 0 {
+		maxPerPage = 100
+	}
+
+	// Visit parameters in a stable order so the generated SQL is deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values.Get(key)
+
+		switch key {
+		case "sort":
+			orders, err := parseFilterSort(value, allowed.Sorts)
+			if err != nil {
+				return nil, err
+			}
+			filter.orders = orders
+			continue
+		case "page", "page[number]":
+			page, err := strconv.Atoi(value)
+			if err != nil || page < 1 {
+				return nil, fmt.Errorf("%w: page %q", ErrInvalidQuery, value)
+			}
+			filter.Page = page
+			continue
+		case "per_page", "page[size]":
+			perPage, err := strconv.Atoi(value)
+			if err != nil || perPage < 1 {
+				return nil, fmt.Errorf("%w: page size %q", ErrInvalidQuery, value)
+			}
+			if perPage > maxPerPage {
+				perPage = maxPerPage
+			}
+			filter.PerPage = perPage
+			continue
+		}
+
+		match := filterParamPattern.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		where, err := parseFilterWhere(match[1], match[2], value, allowed.Filters)
+		if err != nil {
+			return nil, err
+		}
+		filter.wheres = append(filter.wheres, where)
+	}
+
+	return filter, nil
+}
+
+// parseFilterWhere validates one filter parameter against the allowed filters
+func parseFilterWhere(column, operator, value string, allowed map[string][]string) (filterWhere, error) {
+	operators, ok := allowed[column]
+	if !ok {
+		return filterWhere{}, fmt.Errorf("%w: filter %q is not allowed", ErrInvalidQuery, column)
+	}
+	if err := validateColumn(column); err != nil {
+		return filterWhere{}, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+
+	if operator == "" {
+		operator = "eq"
+	}
+	if len(operators) == 0 {
+		operators = []string{"eq", "in"}
+	}
+	if _, ok := filterOperators[operator]; !ok || !containsString(operators, operator) {
+		return filterWhere{}, fmt.Errorf("%w: operator %q is not allowed on filter %q", ErrInvalidQuery, operator, column)
+	}
+	if operator == "eq" && strings.Contains(value, ",") {
+		if !containsString(operators, "in") {
+			return filterWhere{}, fmt.Errorf("%w: filter %q does not accept lists", ErrInvalidQuery, column)
+		}
+		operator = "in"
+	}
+
+	return filterWhere{column: column, operator: operator, value: value}, nil
+}
+
+// parseFilterSort parses a comma-separated sort list, where a leading - sorts descending
+func parseFilterSort(value string, allowed []string) ([]OrderClause, error) {
+	var orders []OrderClause
+	for _, field := range strings.Split(value, ",") {
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+		if field == "" {
+			continue
+		}
+		if !containsString(allowed, field) {
+			return nil, fmt.Errorf("%w: sort %q is not allowed", ErrInvalidQuery, field)
+		}
+		orders = append(orders, OrderClause{Column: field, Direction: direction})
+	}
+	return orders, nil
+}
+
+// Apply adds the parsed filters and sorts to a query. Pagination is left to the caller,
+// for example qb.Paginate(filter.Page, filter.PerPage).
+func (f *QueryFilter) Apply(qb *QueryBuilder) *QueryBuilder {
+	return qb.apply(func(qb *QueryBuilder) {
+		for _, where := range f.wheres {
+			if where.operator == "in" {
+				values := strings.Split(where.value, ",")
+				in := make([]interface{}, len(values))
+				for i, value := range values {
+					in[i] = value
+				}
+				qb.WhereIn(where.column, in)
+				continue
+			}
+			qb.Where(where.column, filterOperators[where.operator], where.value)
+		}
+		for _, order := range f.orders {
+			qb.OrderBy(order.Column, order.Direction)
+		}
+	})
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package eloquent
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFilterFromQuery(t *testing.T) {
+	allowed := AllowedFilters{
+		Filters: map[string][]string{
+			"status": nil,
+			"age":    {"gte", "lt"},
+			"name":   {"like"},
+		},
+		Sorts:      []string{"created_at", "name"},
+		MaxPerPage: 50,
+	}
+
+	values, _ := url.ParseQuery("filter[status]=active,pending&filter[age][gte]=18&filter[name][like]=J%25&sort=-created_at,name&page=2&per_page=500&include=posts")
+	filter, err := FilterFromQuery(values, allowed)
+	if err != nil {
+		t.Fatalf("Failed to parse filters: %v", err)
+	}
+	if filter.Page != 2 || filter.PerPage != 50 {
+		t.Errorf("Expected page 2 capped at 50 per page, got %d/%d", filter.Page, filter.PerPage)
+	}
+
+	sql, args := filter.Apply(NewQueryBuilder(&Connection{Driver: "sqlite3"}).Table("users")).ToSQL()
+	expected := "SELECT * FROM users WHERE age >= ? AND name LIKE ? AND status IN (?, ?) ORDER BY created_at DESC, name ASC"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"18", "J%", "active", "pending"}) {
+		t.Errorf("Unexpected bindings: %v", args)
+	}
+
+	// JSON:API page parameters and defaults
+	filter, err = FilterFromQuery(url.Values{"page[number]": {"3"}}, allowed)
+	if err != nil {
+		t.Fatalf("Failed to parse page: %v", err)
+	}
+	if filter.Page != 3 || filter.PerPage != 15 {
+		t.Errorf("Expected page 3 with 15 per page, got %d/%d", filter.Page, filter.PerPage)
+	}
+
+	// Anything outside the whitelist is rejected
+	rejected := []string{
+		"filter[password]=secret",
+		"filter[age][ne]=18",
+		"filter[name]=John",
+		"sort=password",
+		"page=0",
+		"per_page=lots",
+	}
+	for _, query := range rejected {
+		values, _ := url.ParseQuery(query)
+		if _, err := FilterFromQuery(values, allowed); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery for %q, got %v", query, err)
+		}
+	}
+}
+
+func TestFilterFromQueryRunsQuery(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	values, _ := url.ParseQuery("filter[status]=active&sort=-age&per_page=1")
+	filter, err := FilterFromQuery(values, AllowedFilters{
+		Filters: map[string][]string{"status": nil},
+		Sorts:   []string{"age"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse filters: %v", err)
+	}
+
+	page, err := filter.Apply(NewQueryBuilder(DB()).Table("users")).Paginate(filter.Page, filter.PerPage)
+	if err != nil {
+		t.Fatalf("Failed to paginate filtered users: %v", err)
+	}
+	if len(page.Data) != 1 || page.Total < 1 {
+		t.Errorf("Expected one row of the active users, got %d of %d", len(page.Data), page.Total)
+	}
+}
@@ -0,0 +1,98 @@
+package eloquent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConnectionHealth is the outcome of pinging one connection
+type ConnectionHealth struct {
+	Driver  string        `json:"driver"`
+	Healthy bool          `json:"healthy"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HealthReport holds the outcome of pinging every connection of a manager. It encodes
+// to JSON for readiness endpoints; Healthy is false when any connection failed or no
+// connection is registered.
+type HealthReport struct {
+	Healthy     bool                        `json:"healthy"`
+	Connections map[string]ConnectionHealth `json:"connections"`
+	CheckedAt   time.Time                   `json:"checked_at"`
+}
+
+// Err returns an error naming the unhealthy connections, or nil when the report is healthy
+func (r HealthReport) Err() error {
+	if r.Healthy {
+		return nil
+	}
+	if len(r.Connections) == 0 {
+		return errors.New("eloquent: no database connections registered")
+	}
+
+	var failed []string
+	for name, health := range r.Connections {
+		if !health.Healthy {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, health.Error))
+		}
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("eloquent: unhealthy connections: %s", strings.Join(failed, "; "))
+}
+
+// HealthCheck pings every managed connection concurrently with CheckHealth, recording
+// the latency of each ping. Bound the check with a context deadline; a connection that
+// has not answered when it expires is reported unhealthy.
+func (cm *ConnectionManager) HealthCheck(ctx context.Context) HealthReport {
+	connections := cm.connectionsSnapshot()
+	report := HealthReport{
+		Healthy:     len(connections) > 0,
+		Connections: make(map[string]ConnectionHealth, len(connections)),
+		CheckedAt:   time.Now(),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, conn := range connections {
+		wg.Add(1)
+		go func(name string, conn *Connection) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := conn.CheckHealth(ctx)
+			health := ConnectionHealth{
+				Driver:  conn.Driver,
+				Healthy: err == nil,
+				Latency: time.Since(start),
+			}
+			if err != nil {
+				health.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Connections[name] = health
+			if err != nil {
+				report.Healthy = false
+			}
+		}(name, conn)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// HealthCheck pings every connection of the global manager, first connecting when Boot
+// was called with WithLazyConnect
+func HealthCheck(ctx context.Context) HealthReport {
+	runPendingBoot()
+	return GetManager().HealthCheck(ctx)
+}
@@ -0,0 +1,80 @@
+package eloquent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	cm := NewConnectionManager()
+	if report := cm.HealthCheck(context.Background()); report.Healthy || report.Err() == nil {
+		t.Error("Expected a manager without connections to be unhealthy")
+	}
+
+	if err := cm.AddConnection("primary", ConnectionConfig{Driver: "sqlite3", Database: ":memory:"}); err != nil {
+		t.Fatalf("Failed to add connection: %v", err)
+	}
+	if err := cm.AddConnection("broken", ConnectionConfig{Driver: "sqlite3", Database: ":memory:"}); err != nil {
+		t.Fatalf("Failed to add connection: %v", err)
+	}
+	defer cm.CloseAll()
+
+	report := cm.HealthCheck(context.Background())
+	if !report.Healthy || report.Err() != nil {
+		t.Fatalf("Expected healthy report, got %+v", report)
+	}
+	if primary := report.Connections["primary"]; !primary.Healthy || primary.Driver != "sqlite3" || primary.Latency <= 0 {
+		t.Errorf("Expected primary to be healthy with a latency, got %+v", primary)
+	}
+
+	cm.GetConnection("broken").DB.Close()
+	report = cm.HealthCheck(context.Background())
+	if report.Healthy {
+		t.Fatal("Expected a closed connection to make the report unhealthy")
+	}
+	if broken := report.Connections["broken"]; broken.Healthy || broken.Error == "" {
+		t.Errorf("Expected broken to report its error, got %+v", broken)
+	}
+	if !report.Connections["primary"].Healthy {
+		t.Error("Expected primary to stay healthy")
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "broken") || strings.Contains(err.Error(), "primary") {
+		t.Errorf("Expected error naming only the broken connection, got %v", err)
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Failed to encode report: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"healthy":false`) || !strings.Contains(string(encoded), `"broken":{`) {
+		t.Errorf("Unexpected encoded report: %s", encoded)
+	}
+}
+
+func TestBootMustBeHealthy(t *testing.T) {
+	WithTestConnection(t, NewTestSQLite(t))
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	path := writeBootEnvFile(t, "")
+
+	if err := Boot(WithEnvFile(path), MustBeHealthyAtBoot()); err != nil {
+		t.Fatalf("Expected healthy Boot, got %v", err)
+	}
+
+	// Registered connections are not pinged until the health check runs
+	broken := NewTestSQLite(t)
+	broken.DB.Close()
+	GetManager().RegisterConnection("boot_health_broken", broken)
+	t.Cleanup(func() {
+		_ = GetManager().RemoveConnection("boot_health_broken")
+	})
+
+	if err := Boot(WithEnvFile(path)); err != nil {
+		t.Fatalf("Expected Boot without the option to ignore the broken connection, got %v", err)
+	}
+	err := Boot(WithEnvFile(path), MustBeHealthyAtBoot())
+	if err == nil || !strings.Contains(err.Error(), "boot_health_broken") {
+		t.Errorf("Expected Boot to fail naming the broken connection, got %v", err)
+	}
+}
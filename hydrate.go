@@ -0,0 +1,243 @@
+package eloquent
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+)
+
+// RowFiller is implemented by models whose fields are filled from a row without
+// reflection, by code the eloquent-gen command generates. Hydration uses it instead of
+// the reflection-based field mapping when a model implements it.
+type RowFiller interface {
+	FillFromRow(row map[string]interface{})
+}
+
+// FieldIterator is implemented by models that list their fields without reflection, by
+// code the eloquent-gen command generates. Saving uses it to copy changed fields into
+// the attributes it compares with the originals. fn receives each field's column, its
+// value and whether the value is the zero value of its type.
+type FieldIterator interface {
+	EachField(fn func(column string, value interface{}, zero bool))
+}
+
+// fieldPlan maps the columns of a row to the fields of a model struct, worked out once
+// per type instead of for every hydrated row
+type fieldPlan struct {
+	// baseModel is the index of the embedded *BaseModel field, or -1
+	baseModel int
+	fields    []plannedField
+}
+
+// plannedField is an exported struct field and the column it is filled from
+type plannedField struct {
+	index  int
+	column string
+}
+
+// fieldPlans caches a fieldPlan per struct type until the naming strategy changes
+var fieldPlans sync.Map
+
+// fieldPlanOf returns the plan for filling structs of type t, which must be a struct
+func fieldPlanOf(t reflect.Type) *fieldPlan {
+	if plan, ok := fieldPlans.Load(t); ok {
+		return plan.(*fieldPlan)
+	}
+
+	plan := &fieldPlan{baseModel: -1}
+	baseModelType := reflect.TypeOf((*BaseModel)(nil))
+	strategy := GetNamingStrategy()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type == baseModelType {
+			if plan.baseModel < 0 {
+				plan.baseModel = i
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		// Get the database column name from the db tag, or use field name
+		column := field.Tag.Get("db")
+		if column == "" {
+			column = strategy.ColumnName(field.Name)
+		}
+		plan.fields = append(plan.fields, plannedField{index: i, column: column})
+	}
+
+	actual, _ := fieldPlans.LoadOrStore(t, plan)
+	return actual.(*fieldPlan)
+}
+
+// forgetFieldPlans drops the cached plans, whose column names depend on the naming strategy
+func forgetFieldPlans() {
+	fieldPlans.Range(func(t, _ interface{}) bool {
+		fieldPlans.Delete(t)
+		return true
+	})
+}
+
+// each runs the query and passes each row to fn as it is read, like Get without holding
+// the whole result
+func (qb *QueryBuilder) each(fn func(row map[string]interface{}) error) error {
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return err
+	}
+	sql, args := qb.ToSQL()
+	return qb.connection.SelectEach(sql, args, fn)
+}
+
+// stream runs qb and hydrates a typed model from each row as it is read. Columns are
+// scanned straight into the struct fields the model's fieldPlan maps them to, and only
+// columns without a field are kept as attributes alone. Models with generated FillFromRow
+// code are filled from the row as a map instead.
+func (tmqb *TypedModelQueryBuilder[T]) stream(qb *QueryBuilder) ([]T, error) {
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
+	if modelType.Kind() != reflect.Ptr || modelType.Elem().Kind() != reflect.Struct || modelType.Implements(rowFillerType) {
+		return tmqb.streamRows(qb)
+	}
+
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return nil, err
+	}
+	query, args := qb.ToSQL()
+
+	var models []T
+	mqb := &ModelQueryBuilder{QueryBuilder: tmqb.QueryBuilder}
+	err := qb.connection.queryRows(query, args, func(rows *sql.Rows) error {
+		scanner, err := newRowScanner(mqb, qb.connection, rows, fieldPlanOf(modelType.Elem()))
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			model := tmqb.modelFactory()
+			row, err := scanner.scan(rows, reflect.ValueOf(model).Elem())
+			if err != nil {
+				return err
+			}
+			mqb.model = model
+			mqb.hydrateAttributes(model, row, true)
+			afterHydrate(model)
+			models = append(models, model)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// streamRows is stream for models that are filled from rows read into maps
+func (tmqb *TypedModelQueryBuilder[T]) streamRows(qb *QueryBuilder) ([]T, error) {
+	var models []T
+	mqb := &ModelQueryBuilder{QueryBuilder: tmqb.QueryBuilder}
+	err := qb.each(func(row map[string]interface{}) error {
+		model := tmqb.modelFactory()
+		mqb.model = model
+		mqb.hydrate(model, row, true)
+		models = append(models, model)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+var rowFillerType = reflect.TypeOf((*RowFiller)(nil)).Elem()
+
+// rowScanner scans result rows into model structs, passing a destination per column to
+// rows.Scan that sets the column's field directly
+type rowScanner struct {
+	columns []string
+	fields  []int // struct field index per column, or -1 when no field maps to it
+	dests   []columnDest
+	ptrs    []interface{}
+}
+
+// columnDest is the scan destination of one column. It converts the driver's value the
+// way rows read into maps are converted, keeps it for the attributes and sets the field.
+type columnDest struct {
+	mqb        *ModelQueryBuilder
+	conn       *Connection
+	columnType ColumnType
+	kind       columnKind
+	field      reflect.Value
+	value      interface{}
+}
+
+// newRowScanner matches the columns of rows to the fields of plan
+func newRowScanner(mqb *ModelQueryBuilder, conn *Connection, rows *sql.Rows, plan *fieldPlan) (*rowScanner, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types := columnTypes(rows, columns)
+
+	byColumn := make(map[string]int, len(plan.fields))
+	for _, field := range plan.fields {
+		byColumn[field.column] = field.index
+	}
+
+	scanner := &rowScanner{
+		columns: columns,
+		fields:  make([]int, len(columns)),
+		dests:   make([]columnDest, len(columns)),
+		ptrs:    make([]interface{}, len(columns)),
+	}
+	last := make(map[string]int, len(columns))
+	for i, column := range columns {
+		scanner.fields[i] = -1
+		if index, ok := byColumn[column]; ok {
+			// A column repeated by a join fills the field from its last occurrence, as
+			// the last value wins in the attributes
+			if previous, ok := last[column]; ok {
+				scanner.fields[previous] = -1
+			}
+			last[column] = i
+			scanner.fields[i] = index
+		}
+		scanner.dests[i] = columnDest{mqb: mqb, conn: conn, columnType: types[i], kind: columnKindOf(types[i].DatabaseType)}
+		scanner.ptrs[i] = &scanner.dests[i]
+	}
+	return scanner, nil
+}
+
+// scan reads the current row into the fields of model, a struct value, and returns the
+// row's attributes
+func (s *rowScanner) scan(rows *sql.Rows, model reflect.Value) (map[string]interface{}, error) {
+	for i, index := range s.fields {
+		if index >= 0 {
+			s.dests[i].field = model.Field(index)
+		} else {
+			s.dests[i].field = reflect.Value{}
+		}
+	}
+	if err := rows.Scan(s.ptrs...); err != nil {
+		return nil, err
+	}
+
+	row := acquireRow(len(s.columns))
+	for i, column := range s.columns {
+		row[column] = s.dests[i].value
+	}
+	return row, nil
+}
+
+// Scan implements sql.Scanner
+func (d *columnDest) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok && d.conn.valueConverter != nil {
+		// The driver may reuse the buffer for the next row
+		src = append([]byte(nil), b...)
+	}
+	d.value = d.conn.convertValue(d.columnType, d.kind, src)
+	if d.field.IsValid() && d.value != nil {
+		d.mqb.setFieldValue(d.field, d.value)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package eloquent
+
+import (
+	"errors"
+	"testing"
+)
+
+type hydratedAccount struct {
+	*BaseModel
+	ID        int64
+	Name      string
+	Email     string `db:"email_address"`
+	CreatedAt string
+	secret    string
+}
+
+func TestTypedHydration(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT, email_address TEXT, plan TEXT, secret TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO accounts (name, email_address, secret) VALUES ('Ada', 'ada@example.com', 'x'), ('Grace', 'grace@example.com', 'y')"); err != nil {
+		t.Fatalf("Failed to insert accounts: %v", err)
+	}
+
+	accounts := NewModelStatic(func() *hydratedAccount {
+		account := &hydratedAccount{BaseModel: NewBaseModel()}
+		account.Table("accounts").WithoutTimestamps().Connection(conn.Name)
+		account.SetParentModel(account)
+		account.SetAttribute("plan", "free")
+		return account
+	})
+
+	models, err := accounts.Query().OrderBy("id", "asc").Get()
+	if err != nil {
+		t.Fatalf("Failed to query accounts: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("Expected 2 accounts, got %d", len(models))
+	}
+	ada := models[0]
+	if ada.ID != 1 || ada.Name != "Ada" || ada.Email != "ada@example.com" || ada.secret != "" {
+		t.Errorf("Expected fields filled from their columns, got %+v", ada)
+	}
+	if ada.GetAttribute("secret") != "x" || ada.GetOriginal("name") != "Ada" || !ada.Exists() || ada.IsDirty() {
+		t.Errorf("Expected attributes and originals from the row, got %v", ada.ToMap())
+	}
+	if ada.GetAttribute("plan") != nil {
+		t.Errorf("Expected the NULL column to replace the default, got %v", ada.GetAttribute("plan"))
+	}
+
+	ada.SetAttribute("name", "Countess")
+	if models[1].GetAttribute("name") != "Grace" || ada.GetOriginal("name") != "Ada" {
+		t.Error("Expected each model to own its attributes")
+	}
+
+	first, err := accounts.Query().Where("name", "Grace").First()
+	if err != nil || first.Name != "Grace" {
+		t.Fatalf("Unexpected first account %+v, %v", first, err)
+	}
+	if _, err := accounts.Query().Where("name", "Linus").First(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// NULL columns leave their fields at the zero value
+	if _, err := conn.Exec("INSERT INTO accounts (id, email_address) VALUES (3, 'anon@example.com')"); err != nil {
+		t.Fatalf("Failed to insert account: %v", err)
+	}
+	anonymous, err := accounts.Query().Where("id", 3).First()
+	if err != nil {
+		t.Fatalf("Failed to query the account with NULL columns: %v", err)
+	}
+	if anonymous.Name != "" || anonymous.Email != "anon@example.com" || anonymous.GetAttribute("name") != nil {
+		t.Errorf("Expected an empty name, got %+v", anonymous)
+	}
+
+	// Column names follow the naming strategy in effect
+	SetNamingStrategy(DefaultNamingStrategy{CamelCaseColumns: true})
+	defer SetNamingStrategy(nil)
+	if _, err := conn.Exec("ALTER TABLE accounts ADD COLUMN createdAt TEXT"); err != nil {
+		t.Fatalf("Failed to add column: %v", err)
+	}
+	if _, err := conn.Exec("UPDATE accounts SET createdAt = 'today'"); err != nil {
+		t.Fatalf("Failed to update accounts: %v", err)
+	}
+	first, err = accounts.Query().First()
+	if err != nil || first.CreatedAt != "today" {
+		t.Errorf("Expected the field filled after the naming strategy changed, got %+v, %v", first, err)
+	}
+}
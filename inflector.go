@@ -0,0 +1,144 @@
+package eloquent
+
+import (
+	"strings"
+	"unicode"
+)
+
+// irregularPlurals maps singular nouns that do not follow the suffix rules to their plurals
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"foot":   "feet",
+	"goose":  "geese",
+	"man":    "men",
+	"mouse":  "mice",
+	"ox":     "oxen",
+	"person": "people",
+	"tooth":  "teeth",
+	"woman":  "women",
+}
+
+// uncountableNouns are nouns whose plural is the same as the singular
+var uncountableNouns = map[string]bool{
+	"data":        true,
+	"deer":        true,
+	"equipment":   true,
+	"feedback":    true,
+	"fish":        true,
+	"information": true,
+	"media":       true,
+	"metadata":    true,
+	"money":       true,
+	"news":        true,
+	"series":      true,
+	"sheep":       true,
+	"species":     true,
+}
+
+// tableName derives a table name from a model's struct name, e.g. CustomerModel → customers
+func tableName(typeName string) string {
+	return pluralize(toSnakeCase(trimModelSuffix(typeName)))
+}
+
+// modelKeyName derives the singular snake_case name used in keys from a struct or table
+// name, e.g. CustomerModel → customer and order_statuses → order_status
+func modelKeyName(name string) string {
+	return singularize(toSnakeCase(trimModelSuffix(name)))
+}
+
+// trimModelSuffix drops a trailing Model from a struct name unless nothing would remain
+func trimModelSuffix(typeName string) string {
+	if trimmed := strings.TrimSuffix(typeName, "Model"); trimmed != "" {
+		return trimmed
+	}
+	return typeName
+}
+
+// pluralize returns the English plural of the last word of a snake_case name
+func pluralize(name string) string {
+	prefix, word := "", name
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		prefix, word = name[:i+1], name[i+1:]
+	}
+	return prefix + pluralizeWord(word)
+}
+
+// pluralizeWord returns the English plural of a lowercase word
+func pluralizeWord(word string) string {
+	if word == "" || uncountableNouns[word] {
+		return word
+	}
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+
+	switch {
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !isVowel(word[len(word)-2]):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "fe"):
+		return word[:len(word)-2] + "ves"
+	case strings.HasSuffix(word, "lf"):
+		return word[:len(word)-1] + "ves"
+	}
+	return word + "s"
+}
+
+// singularize returns the English singular of the last word of a snake_case name
+func singularize(name string) string {
+	prefix, word := "", name
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		prefix, word = name[:i+1], name[i+1:]
+	}
+	return prefix + singularizeWord(word)
+}
+
+// singularizeWord returns the English singular of a lowercase word
+func singularizeWord(word string) string {
+	if word == "" || uncountableNouns[word] {
+		return word
+	}
+	for singular, plural := range irregularPlurals {
+		if word == plural {
+			return singular
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "uses") && len(word) > 4 && !strings.ContainsRune("aeiour", rune(word[len(word)-5])):
+		// statuses and bonuses, but not houses or courses
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// isVowel reports whether b is a lowercase ASCII vowel
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+// toSnakeCase converts CamelCase to snake_case, keeping acronyms together (UserID → user_id)
+func toSnakeCase(str string) string {
+	runes := []rune(str)
+	var result strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previous != '_' && (!unicode.IsUpper(previous) || nextIsLower) {
+				result.WriteRune('_')
+			}
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+	return result.String()
+}
@@ -0,0 +1,86 @@
+package eloquent
+
+import "testing"
+
+func TestTableName(t *testing.T) {
+	cases := map[string]string{
+		"User":          "users",
+		"Company":       "companies",
+		"Category":      "categories",
+		"Person":        "people",
+		"Status":        "statuses",
+		"Box":           "boxes",
+		"Day":           "days",
+		"Knife":         "knives",
+		"News":          "news",
+		"CustomerModel": "customers",
+		"BlogPost":      "blog_posts",
+		"OrderStatus":   "order_statuses",
+		"HTTPRequest":   "http_requests",
+		"Model":         "models",
+	}
+
+	for typeName, expected := range cases {
+		if got := tableName(typeName); got != expected {
+			t.Errorf("tableName(%q): expected %q, got %q", typeName, expected, got)
+		}
+	}
+}
+
+func TestModelKeyName(t *testing.T) {
+	cases := map[string]string{
+		"users":          "user",
+		"UserModel":      "user",
+		"categories":     "category",
+		"people":         "person",
+		"order_statuses": "order_status",
+		"addresses":      "address",
+		"boxes":          "box",
+		"houses":         "house",
+		"courses":        "course",
+		"sizes":          "size",
+		"news":           "news",
+		"status":         "status",
+	}
+
+	for name, expected := range cases {
+		if got := modelKeyName(name); got != expected {
+			t.Errorf("modelKeyName(%q): expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"Name":            "name",
+		"EmailVerifiedAt": "email_verified_at",
+		"UserID":          "user_id",
+		"ID":              "id",
+		"HTMLBody":        "html_body",
+		"Already_Snake":   "already_snake",
+	}
+
+	for input, expected := range cases {
+		if got := toSnakeCase(input); got != expected {
+			t.Errorf("toSnakeCase(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
+
+type CustomerModel struct {
+	*BaseModel
+}
+
+func TestGetTableFromEmbeddingStruct(t *testing.T) {
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.SetParentModel(customer)
+
+	if got := customer.GetTable(); got != "customers" {
+		t.Errorf("Expected table derived from CustomerModel to be customers, got %q", got)
+	}
+
+	customer.Table("clients")
+	if got := customer.GetTable(); got != "clients" {
+		t.Errorf("Expected explicit table to win, got %q", got)
+	}
+}
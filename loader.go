@@ -0,0 +1,196 @@
+package eloquent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Loader batches and caches lookups of models by primary key, following the dataloader
+// pattern. Concurrent Load calls made within a short window are served by a single
+// WHERE IN query, and each key is fetched at most once for the lifetime of the loader.
+// Create one loader per request, for example per GraphQL operation, so cached models
+// don't outlive it. It is safe for concurrent use.
+type Loader[T Model] struct {
+	static   *ModelStatic[T]
+	ctx      context.Context
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[string]*loaderResult[T]
+	batch *loaderBatch[T]
+}
+
+// loaderResult is the eventual outcome of loading one key
+type loaderResult[T Model] struct {
+	done  chan struct{}
+	model T
+	err   error
+}
+
+// loaderBatch collects the keys requested during one wait window
+type loaderBatch[T Model] struct {
+	ids     []interface{}
+	results []*loaderResult[T]
+	timer   *time.Timer
+}
+
+// NewLoader returns a loader that fetches models through the given ModelStatic.
+// By default it waits a millisecond for more keys and sends at most 100 keys per query.
+func NewLoader[T Model](static *ModelStatic[T]) *Loader[T] {
+	return &Loader[T]{
+		static:   static,
+		ctx:      context.Background(),
+		wait:     time.Millisecond,
+		maxBatch: 100,
+		cache:    make(map[string]*loaderResult[T]),
+	}
+}
+
+// WithContext sets the context the loader's queries run with
+func (l *Loader[T]) WithContext(ctx context.Context) *Loader[T] {
+	l.ctx = ctx
+	return l
+}
+
+// Wait sets how long the loader collects keys before querying
+func (l *Loader[T]) Wait(d time.Duration) *Loader[T] {
+	l.wait = d
+	return l
+}
+
+// MaxBatch sets the largest number of keys sent in one query; zero means no limit
+func (l *Loader[T]) MaxBatch(n int) *Loader[T] {
+	l.maxBatch = n
+	return l
+}
+
+// Load returns the model with the given primary key, or ErrNotFound
+func (l *Loader[T]) Load(id interface{}) (T, error) {
+	result := l.enqueue(id)
+	<-result.done
+	return result.model, result.err
+}
+
+// LoadMany returns the models with the given primary keys in the same order.
+// It fails with the first error, including ErrNotFound for a missing key.
+func (l *Loader[T]) LoadMany(ids []interface{}) ([]T, error) {
+	results := make([]*loaderResult[T], len(ids))
+	for i, id := range ids {
+		results[i] = l.enqueue(id)
+	}
+
+	models := make([]T, len(ids))
+	for i, result := range results {
+		<-result.done
+		if result.err != nil {
+			return nil, result.err
+		}
+		models[i] = result.model
+	}
+	return models, nil
+}
+
+// Prime stores a model in the cache so loading its key doesn't query
+func (l *Loader[T]) Prime(model T) {
+	result := &loaderResult[T]{done: make(chan struct{}), model: model}
+	close(result.done)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cache[loaderKey(model.GetAttribute(model.GetPrimaryKey()))] = result
+}
+
+// Clear removes a key from the cache, for example after the model was updated
+func (l *Loader[T]) Clear(id interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, loaderKey(id))
+}
+
+// enqueue returns the cached result for a key, or adds the key to the pending batch
+func (l *Loader[T]) enqueue(id interface{}) *loaderResult[T] {
+	key := loaderKey(id)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if result, ok := l.cache[key]; ok {
+		return result
+	}
+
+	result := &loaderResult[T]{done: make(chan struct{})}
+	l.cache[key] = result
+
+	if l.batch == nil {
+		batch := &loaderBatch[T]{}
+		batch.timer = time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+		l.batch = batch
+	}
+	l.batch.ids = append(l.batch.ids, id)
+	l.batch.results = append(l.batch.results, result)
+
+	if l.maxBatch > 0 && len(l.batch.ids) >= l.maxBatch {
+		batch := l.batch
+		l.batch = nil
+		batch.timer.Stop()
+		go l.fetch(batch)
+	}
+	return result
+}
+
+// dispatch fetches a batch whose wait window has passed, unless it was already sent
+func (l *Loader[T]) dispatch(batch *loaderBatch[T]) {
+	l.mu.Lock()
+	if l.batch != batch {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	l.fetch(batch)
+}
+
+// fetch loads a batch with one query and resolves its results
+func (l *Loader[T]) fetch(batch *loaderBatch[T]) {
+	models, err := l.static.WithContext(l.ctx).FindMany(batch.ids)
+
+	found := make(map[string]T, len(models))
+	for _, model := range models {
+		found[loaderKey(model.GetAttribute(model.GetPrimaryKey()))] = model
+	}
+
+	for i, result := range batch.results {
+		switch model, ok := found[loaderKey(batch.ids[i])]; {
+		case err != nil:
+			result.err = err
+		case ok:
+			result.model = model
+		default:
+			result.err = ErrNotFound
+		}
+		close(result.done)
+	}
+
+	// Failed lookups are not cached so a later Load can retry them
+	if err != nil {
+		l.mu.Lock()
+		for i, id := range batch.ids {
+			if key := loaderKey(id); l.cache[key] == batch.results[i] {
+				delete(l.cache, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// loaderKey normalizes a primary key so that, for example, int 1 and int64 1 match
+func loaderKey(id interface{}) string {
+	if b, ok := id.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(id)
+}
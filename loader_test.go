@@ -0,0 +1,87 @@
+package eloquent
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var loaderCustomers = NewModelStatic(func() *CustomerModel {
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.SetParentModel(customer)
+	return customer
+})
+
+func TestLoaderBatchesAndCaches(t *testing.T) {
+	fake := Fake(t)
+	fake.QueueRows(
+		map[string]interface{}{"id": int64(1), "name": "Ada"},
+		map[string]interface{}{"id": int64(2), "name": "Grace"},
+	)
+
+	loader := NewLoader(loaderCustomers).Wait(10 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i, id := range []interface{}{1, 2, 3} {
+		wg.Add(1)
+		go func(i int, id interface{}) {
+			defer wg.Done()
+			_, errs[i] = loader.Load(id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	fake.AssertQueried("customers", 1)
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("Expected found customers to load, got %v and %v", errs[0], errs[1])
+	}
+	if !errors.Is(errs[2], ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing customer, got %v", errs[2])
+	}
+
+	// Loaded keys are served from the cache, whatever integer type they are given as
+	customer, err := loader.Load(int64(2))
+	if err != nil {
+		t.Fatalf("Failed to load cached customer: %v", err)
+	}
+	if customer.GetAttribute("name") != "Grace" {
+		t.Errorf("Expected Grace, got %v", customer.GetAttribute("name"))
+	}
+	fake.AssertQueried("customers", 1)
+
+	// Cleared keys are fetched again
+	loader.Clear(2)
+	fake.QueueRows(map[string]interface{}{"id": int64(2), "name": "Grace Hopper"})
+	customer, err = loader.Load(2)
+	if err != nil {
+		t.Fatalf("Failed to reload customer: %v", err)
+	}
+	if customer.GetAttribute("name") != "Grace Hopper" {
+		t.Errorf("Expected reloaded name, got %v", customer.GetAttribute("name"))
+	}
+	fake.AssertQueried("customers", 2)
+}
+
+func TestLoaderLoadManyAndMaxBatch(t *testing.T) {
+	fake := Fake(t)
+	fake.QueueRows(
+		map[string]interface{}{"id": int64(2)},
+		map[string]interface{}{"id": int64(1)},
+	)
+	fake.QueueRows(map[string]interface{}{"id": int64(3)})
+
+	// The first two keys fill a batch and are sent at once, the third waits for the window
+	customers, err := NewLoader(loaderCustomers).MaxBatch(2).Wait(50 * time.Millisecond).LoadMany([]interface{}{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Failed to load customers: %v", err)
+	}
+
+	fake.AssertQueried("customers", 2)
+	for i, customer := range customers {
+		if id := customer.GetAttribute("id"); id != int64(i+1) {
+			t.Errorf("Expected customer %d at position %d, got %v", i+1, i, id)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package eloquent
+
+import (
+	"sync"
+	"time"
+)
+
+// Logger receives diagnostic output from the package.
+// The method set matches *slog.Logger, and zap/logrus loggers can be adapted with a thin wrapper.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// nopLogger discards all output
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+var (
+	logger             Logger = nopLogger{}
+	slowQueryThreshold        = time.Second
+	logBindings        bool
+	loggerMu           sync.RWMutex
+
+	autoConnectErr      error
+	autoConnectReported sync.Once
+)
+
+// SetLogger sets the logger used for SQL, slow query and failure output.
+// Passing nil restores the default silent logger.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+
+	loggerMu.Lock()
+	logger = l
+	loggerMu.Unlock()
+
+	// Report a failed auto-connect from package init, which ran before any logger was set,
+	// to the first logger that is not silent
+	if _, silent := l.(nopLogger); !silent && autoConnectErr != nil {
+		autoConnectReported.Do(func() {
+			l.Warn("eloquent: auto-connect from environment failed", "error", autoConnectErr)
+		})
+	}
+}
+
+// GetLogger returns the current logger
+func GetLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+// SetSlowQueryThreshold sets the duration above which queries are logged as slow.
+// A zero duration disables slow query warnings.
+func SetSlowQueryThreshold(d time.Duration) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	slowQueryThreshold = d
+}
+
+// SetLogBindings includes the bindings of queries in the log output. They are left out by
+// default, since they can hold passwords, tokens and personal data.
+func SetLogBindings(enabled bool) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	logBindings = enabled
+}
+
+// logQuery reports an executed query to the logger
+func logQuery(connection, query string, args []interface{}, duration time.Duration, err error) {
+	loggerMu.RLock()
+	logger, slowQueryThreshold, logBindings := logger, slowQueryThreshold, logBindings
+	loggerMu.RUnlock()
+
+	fields := []interface{}{"connection", connection, "sql", query}
+	if logBindings {
+		fields = append(fields, "bindings", args)
+	}
+	fields = append(fields, "duration", duration)
+
+	if err != nil {
+		logger.Error("eloquent: query failed", append(fields, "error", err)...)
+		return
+	}
+
+	if slowQueryThreshold > 0 && duration >= slowQueryThreshold {
+		logger.Warn("eloquent: slow query", fields...)
+		return
+	}
+
+	logger.Debug("eloquent: query executed", fields...)
+}
@@ -0,0 +1,120 @@
+package eloquent
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	debug []string
+	warn  []string
+	error []string
+}
+
+func (l *recordingLogger) Debug(msg string, _ ...interface{}) { l.debug = append(l.debug, msg) }
+func (l *recordingLogger) Warn(msg string, _ ...interface{})  { l.warn = append(l.warn, msg) }
+func (l *recordingLogger) Error(msg string, _ ...interface{}) { l.error = append(l.error, msg) }
+
+func TestLoggerCompatibleWithSlog(t *testing.T) {
+	var _ Logger = slog.Default()
+}
+
+func TestSetLogger(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+
+	if _, err := NewQueryBuilder(DB()).Table("users").Get(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(rec.debug) == 0 {
+		t.Error("Expected successful query to be logged at debug level")
+	}
+
+	if _, err := DB().Select("SELECT * FROM missing_table"); err == nil {
+		t.Fatal("Expected query against missing table to fail")
+	}
+	if len(rec.error) != 1 {
+		t.Errorf("Expected 1 error log, got %d", len(rec.error))
+	}
+
+	SetSlowQueryThreshold(time.Nanosecond)
+	defer SetSlowQueryThreshold(time.Second)
+
+	warnings := len(rec.warn)
+	if _, err := NewQueryBuilder(DB()).Table("users").Get(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(rec.warn) != warnings+1 {
+		t.Error("Expected slow query to be logged at warn level")
+	}
+}
+
+func TestSetLoggerNilRestoresDefault(t *testing.T) {
+	SetLogger(nil)
+
+	if _, ok := GetLogger().(nopLogger); !ok {
+		t.Errorf("Expected default silent logger, got %T", GetLogger())
+	}
+}
+
+// fieldsLogger records the key-value pairs of every debug entry
+type fieldsLogger struct {
+	recordingLogger
+	fields []map[string]interface{}
+}
+
+func (l *fieldsLogger) Debug(msg string, keysAndValues ...interface{}) {
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.fields = append(l.fields, fields)
+}
+
+func TestLogBindingsOptIn(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	rec := &fieldsLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+
+	if _, err := NewQueryBuilder(DB()).Table("users").Where("email", "ada@example.com").Get(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if _, ok := rec.fields[len(rec.fields)-1]["bindings"]; ok {
+		t.Error("Expected bindings to be left out of the log by default")
+	}
+
+	SetLogBindings(true)
+	defer SetLogBindings(false)
+	if _, err := NewQueryBuilder(DB()).Table("users").Where("email", "ada@example.com").Get(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if bindings, ok := rec.fields[len(rec.fields)-1]["bindings"]; !ok || !reflect.DeepEqual(bindings, []interface{}{"ada@example.com"}) {
+		t.Errorf("Expected the bindings to be logged once enabled, got %v", bindings)
+	}
+}
+
+func TestSetLoggerReportsAutoConnectOnce(t *testing.T) {
+	previous := autoConnectErr
+	autoConnectErr = errors.New("connection refused")
+	autoConnectReported = sync.Once{}
+	defer func() { autoConnectErr = previous }()
+	defer SetLogger(nil)
+
+	// The silent default logger does not use up the report
+	SetLogger(nil)
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	SetLogger(rec)
+	if len(rec.warn) != 1 {
+		t.Errorf("Expected the failed auto-connect to be reported once, got %v", rec.warn)
+	}
+}
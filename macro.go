@@ -0,0 +1,73 @@
+package eloquent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MacroFunc is a custom query builder method registered with Macro. It modifies the
+// builder it receives and reads its arguments from args.
+type MacroFunc func(qb *QueryBuilder, args ...interface{})
+
+var (
+	macros   = make(map[string]MacroFunc)
+	macrosMu sync.RWMutex
+)
+
+// Macro registers a custom query builder method under name, to be invoked with Call on
+// any query builder, so shared query logic can live in its own package:
+//
+//	eloquent.Macro("whereActive", func(qb *eloquent.QueryBuilder, args ...interface{}) {
+//		qb.Where("active", true)
+//	})
+//
+//	users, err := User.Query().Call("whereActive").Get()
+//
+// Registering a name again replaces the macro; passing nil removes it.
+func Macro(name string, fn MacroFunc) {
+	macrosMu.Lock()
+	defer macrosMu.Unlock()
+	if fn == nil {
+		delete(macros, name)
+		return
+	}
+	macros[name] = fn
+}
+
+// HasMacro reports whether a macro is registered under name
+func HasMacro(name string) bool {
+	macrosMu.RLock()
+	defer macrosMu.RUnlock()
+	_, ok := macros[name]
+	return ok
+}
+
+// Call runs the macro registered under name on the builder. Unknown names fail the
+// query with ErrInvalidQuery when it runs.
+func (qb *QueryBuilder) Call(name string, args ...interface{}) *QueryBuilder {
+	macrosMu.RLock()
+	fn, ok := macros[name]
+	macrosMu.RUnlock()
+	if !ok {
+		qb = qb.mutable()
+		return qb.fail(fmt.Errorf("%w: no macro named %q", ErrInvalidQuery, name))
+	}
+	return qb.apply(func(target *QueryBuilder) {
+		fn(target, args...)
+	})
+}
+
+// Call runs a macro registered with Macro, see QueryBuilder.Call
+func (mqb *ModelQueryBuilder) Call(name string, args ...interface{}) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.Call(name, args...))
+}
+
+// Call runs a macro registered with Macro, see QueryBuilder.Call
+func (tmqb *TypedModelQueryBuilder[T]) Call(name string, args ...interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.Call(name, args...))
+}
+
+// Call starts a query with a macro registered with Macro applied
+func (ms *ModelStatic[T]) Call(name string, args ...interface{}) *TypedModelQueryBuilder[T] {
+	return ms.Query().Call(name, args...)
+}
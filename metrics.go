@@ -0,0 +1,95 @@
+package eloquent
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// ConnectionMetrics holds pool and query statistics for a connection
+type ConnectionMetrics struct {
+	// Pool statistics
+	MaxOpenConnections int           `json:"max_open_connections"`
+	OpenConnections    int           `json:"open_connections"`
+	InUse              int           `json:"in_use"`
+	Idle               int           `json:"idle"`
+	WaitCount          int64         `json:"wait_count"`
+	WaitDuration       time.Duration `json:"wait_duration"`
+	MaxIdleClosed      int64         `json:"max_idle_closed"`
+	MaxLifetimeClosed  int64         `json:"max_lifetime_closed"`
+
+	// Query statistics
+	Queries       int64         `json:"queries"`
+	Errors        int64         `json:"errors"`
+	QueryDuration time.Duration `json:"query_duration"`
+}
+
+// queryMetrics holds per-connection query counters
+type queryMetrics struct {
+	queries  atomic.Int64
+	errors   atomic.Int64
+	duration atomic.Int64
+}
+
+// observe records and logs the outcome of a query that started at start
+func (c *Connection) observe(query string, args []interface{}, start time.Time, err error) {
+	duration := time.Since(start)
+	logQuery(c.Name, query, args, duration, err)
+
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.queries.Add(1)
+	c.metrics.duration.Add(int64(duration))
+	if err != nil {
+		c.metrics.errors.Add(1)
+	}
+}
+
+// Metrics returns pool and query statistics for the connection
+func (c *Connection) Metrics() ConnectionMetrics {
+	var m ConnectionMetrics
+
+	if c.DB != nil {
+		stats := c.DB.Stats()
+		m.MaxOpenConnections = stats.MaxOpenConnections
+		m.OpenConnections = stats.OpenConnections
+		m.InUse = stats.InUse
+		m.Idle = stats.Idle
+		m.WaitCount = stats.WaitCount
+		m.WaitDuration = stats.WaitDuration
+		m.MaxIdleClosed = stats.MaxIdleClosed
+		m.MaxLifetimeClosed = stats.MaxLifetimeClosed
+	}
+
+	if c.metrics != nil {
+		m.Queries = c.metrics.queries.Load()
+		m.Errors = c.metrics.errors.Load()
+		m.QueryDuration = time.Duration(c.metrics.duration.Load())
+	}
+
+	return m
+}
+
+// Metrics returns statistics for every managed connection keyed by name
+func (cm *ConnectionManager) Metrics() map[string]ConnectionMetrics {
+	connections := cm.connectionsSnapshot()
+	result := make(map[string]ConnectionMetrics, len(connections))
+	for name, conn := range connections {
+		result[name] = conn.Metrics()
+	}
+	return result
+}
+
+// Metrics returns statistics for every connection of the global manager
+func Metrics() map[string]ConnectionMetrics {
+	return GetManager().Metrics()
+}
+
+// PublishMetrics exposes the global connection metrics through expvar under the given name.
+// The values can be scraped from /debug/vars or bridged into a Prometheus collector.
+func PublishMetrics(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return Metrics()
+	}))
+}
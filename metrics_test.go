@@ -0,0 +1,43 @@
+package eloquent
+
+import (
+	"expvar"
+	"testing"
+)
+
+func TestConnectionMetrics(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	conn := DB()
+	before := conn.Metrics()
+
+	if _, err := NewQueryBuilder(conn).Table("users").Get(); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if _, err := conn.Select("SELECT * FROM missing_table"); err == nil {
+		t.Fatal("Expected query against missing table to fail")
+	}
+
+	after := conn.Metrics()
+	if after.Queries-before.Queries != 2 {
+		t.Errorf("Expected 2 queries to be recorded, got %d", after.Queries-before.Queries)
+	}
+	if after.Errors-before.Errors != 1 {
+		t.Errorf("Expected 1 error to be recorded, got %d", after.Errors-before.Errors)
+	}
+	if after.OpenConnections < 1 {
+		t.Errorf("Expected at least 1 open connection, got %d", after.OpenConnections)
+	}
+
+	if _, exists := Metrics()["default"]; !exists {
+		t.Error("Expected metrics for the default connection")
+	}
+}
+
+func TestPublishMetrics(t *testing.T) {
+	PublishMetrics("eloquent_test_metrics")
+
+	if expvar.Get("eloquent_test_metrics") == nil {
+		t.Error("Expected metrics to be published through expvar")
+	}
+}
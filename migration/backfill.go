@@ -0,0 +1,137 @@
+// Package migration provides helpers for data migrations that run alongside schema
+// changes, such as filling a new column after an ALTER TABLE. They work through large
+// tables in short chunks, so no statement locks the whole table or loads it into memory.
+//
+//	err := migration.BackfillInChunks(
+//		eloquent.DB().Table("users").WhereNull("display_name"), 1000,
+//		func(rows []map[string]interface{}) error {
+//			for _, row := range rows {
+//				// update the row
+//			}
+//			return nil
+//		},
+//		migration.Options{OnProgress: migration.LogProgress("users.display_name")},
+//	)
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/crashana/go-eloquent"
+)
+
+// Options configures a backfill
+type Options struct {
+	// Key is the unique, sortable column the rows are walked by; "id" by default
+	Key string
+	// Pause is waited between chunks to leave room for other writes
+	Pause time.Duration
+	// OnProgress is called after every chunk
+	OnProgress func(Progress)
+}
+
+// Progress reports how far a backfill has come
+type Progress struct {
+	// Processed is the number of rows passed to the callback so far
+	Processed int64
+	// Total is the number of rows the query matched when the backfill started
+	Total int64
+	// Chunks is the number of chunks processed so far
+	Chunks int
+	// Elapsed is the time since the backfill started
+	Elapsed time.Duration
+}
+
+// Percent returns the share of the rows processed, from 0 to 100
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return float64(p.Processed) * 100 / float64(p.Total)
+}
+
+// Remaining estimates the time left from the rate so far, or 0 if it is unknown
+func (p Progress) Remaining() time.Duration {
+	if p.Processed == 0 || p.Processed >= p.Total {
+		return 0
+	}
+	perRow := p.Elapsed / time.Duration(p.Processed)
+	return perRow * time.Duration(p.Total-p.Processed)
+}
+
+// BackfillInChunks passes the rows matched by query to fn, size rows at a time, ordered by
+// the key column. Chunks are read with keyset pagination, so each one is a short indexed
+// query and fn may update the rows it is given, even so that they no longer match. Returning an
+// error from fn stops the backfill; it also stops between chunks when the query's context
+// is cancelled. Chunks are not wrapped in a transaction; start one in fn if needed.
+func BackfillInChunks(query *eloquent.QueryBuilder, size int, fn func(rows []map[string]interface{}) error, options ...Options) error {
+	var opts Options
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Key == "" {
+		opts.Key = "id"
+	}
+
+	start := time.Now()
+	progress := Progress{}
+	if opts.OnProgress != nil {
+		total, err := query.Clone().Count()
+		if err != nil {
+			return fmt.Errorf("failed to count the rows to backfill: %w", err)
+		}
+		progress.Total = total
+	}
+
+	ctx := query.Context()
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := query.Clone().KeysetPaginate(opts.Key, cursor, size)
+		if err != nil {
+			return err
+		}
+		if len(page.Data) > 0 {
+			if err := fn(page.Data); err != nil {
+				return fmt.Errorf("backfill stopped after %d rows: %w", progress.Processed, err)
+			}
+		}
+
+		progress.Processed += int64(len(page.Data))
+		progress.Chunks++
+		progress.Elapsed = time.Since(start)
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+
+		if opts.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+	}
+}
+
+// LogProgress returns an OnProgress callback reporting a backfill's progress to the
+// eloquent logger at debug level
+func LogProgress(name string) func(Progress) {
+	return func(p Progress) {
+		eloquent.GetLogger().Debug("eloquent: backfill progress",
+			"backfill", name,
+			"processed", p.Processed,
+			"total", p.Total,
+			"percent", fmt.Sprintf("%.1f", p.Percent()),
+			"remaining", p.Remaining().Round(time.Second))
+	}
+}
@@ -0,0 +1,94 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/crashana/go-eloquent"
+)
+
+func setupUsers(t *testing.T, count int) *eloquent.Connection {
+	t.Helper()
+
+	conn := eloquent.NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, display_name TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i := 1; i <= count; i++ {
+		if _, err := conn.Exec("INSERT INTO users (name) VALUES (?)", fmt.Sprintf("user %d", i)); err != nil {
+			t.Fatalf("Failed to insert user: %v", err)
+		}
+	}
+	return conn
+}
+
+func TestBackfillInChunks(t *testing.T) {
+	conn := setupUsers(t, 25)
+
+	var chunks []int
+	var reports []Progress
+	err := BackfillInChunks(conn.Table("users").WhereNull("display_name"), 10, func(rows []map[string]interface{}) error {
+		chunks = append(chunks, len(rows))
+		for _, row := range rows {
+			_, err := conn.Table("users").Where("id", row["id"]).Update(map[string]interface{}{
+				"display_name": fmt.Sprintf("%s!", row["name"]),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, Options{OnProgress: func(p Progress) { reports = append(reports, p) }})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+
+	if fmt.Sprint(chunks) != "[10 10 5]" {
+		t.Errorf("Expected chunks of 10, 10 and 5 rows, got %v", chunks)
+	}
+	if remaining, _ := conn.Table("users").WhereNull("display_name").Count(); remaining != 0 {
+		t.Errorf("Expected every row to be backfilled, %d are left", remaining)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("Expected a progress report per chunk, got %d", len(reports))
+	}
+	last := reports[2]
+	if last.Processed != 25 || last.Total != 25 || last.Chunks != 3 || last.Percent() != 100 {
+		t.Errorf("Unexpected final progress %+v", last)
+	}
+	if reports[0].Percent() != 40 {
+		t.Errorf("Expected 40%% after the first chunk, got %v", reports[0].Percent())
+	}
+}
+
+func TestBackfillInChunksStops(t *testing.T) {
+	conn := setupUsers(t, 5)
+
+	failure := errors.New("boom")
+	calls := 0
+	err := BackfillInChunks(conn.Table("users"), 2, func(rows []map[string]interface{}) error {
+		calls++
+		return failure
+	})
+	if !errors.Is(err, failure) || calls != 1 {
+		t.Errorf("Expected the callback's error after one chunk, got %v after %d", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = BackfillInChunks(conn.Table("users").WithContext(ctx), 2, func(rows []map[string]interface{}) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Expected the backfill to stop when cancelled, got %v after %d chunks", err, calls)
+	}
+
+	err = BackfillInChunks(conn.Table("users"), 0, func(rows []map[string]interface{}) error { return nil })
+	if !errors.Is(err, eloquent.ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an empty chunk size, got %v", err)
+	}
+}
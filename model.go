@@ -1,14 +1,22 @@
 package eloquent
 
 import (
+	"context"
 	cryptoRand "crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"time"
-	"unicode"
 )
 
+// ErrReadOnly is returned when a write is attempted on a read-only model or connection
+var ErrReadOnly = errors.New("model is read-only")
+
+// Accessor computes a derived attribute from a model, such as a full name from first and last names
+type Accessor func(Model) interface{}
+
 // Model represents the base model interface
 type Model interface {
 	GetTable() string
@@ -39,10 +47,19 @@ type Model interface {
 	GetAttribute(key string) interface{}
 	SetAttribute(key string, value interface{})
 	GetOriginal(key string) interface{}
+	GetOriginalAll() map[string]interface{}
+	GetRawOriginal(key string) interface{}
 	GetDirty() map[string]interface{}
+	GetChanges() map[string]interface{}
+	GetPrevious() map[string]interface{}
+	SyncChanges()
 	IsDirty(key ...string) bool
 	IsClean(key ...string) bool
 
+	// Lifecycle
+	Exists() bool
+	WasRecentlyCreated() bool
+
 	// Serialization
 	ToMap() map[string]interface{}
 	ToJSON() ([]byte, error)
@@ -52,6 +69,7 @@ type Model interface {
 type BaseModel struct {
 	// Configuration
 	table      string
+	schema     string
 	primaryKey string
 	connection string
 	fillable   []string
@@ -64,13 +82,69 @@ type BaseModel struct {
 	createdAt  string
 	updatedAt  string
 	deletedAt  string
+	readOnly   bool
+
+	// Whether inserts write only fillable and maintained columns
+	strictInserts bool
+
+	// Columns recording who soft-deleted a record and why
+	deletedBy    string
+	deleteReason string
+
+	// Columns recording who created and last updated a record
+	createdBy string
+	updatedBy string
+
+	// Slug column generated from a source column
+	slugColumn      string
+	slugFrom        string
+	regenerateSlugs bool
+
+	// Position column and the columns splitting the table into ordered lists
+	sortColumn string
+	sortGroup  []string
+
+	// Relationships eager loaded by every query
+	alwaysWith []string
+
+	// Orders of queries that set no order of their own
+	defaultOrders []defaultOrder
+
+	// Allowed transitions of status columns, in the order they were configured
+	stateMachines map[string]StateMachine
+	stateColumns  []string
+
+	// Casts overriding casts when serializing for a named context
+	contextCasts map[string]map[string]string
+
+	// Computed attributes added to ToMap and ToJSON
+	appends   []string
+	accessors map[string]Accessor
+
+	// Connection manager the model is bound to; nil uses the global manager
+	manager *ConnectionManager
+
+	// Sharding
+	shardKey      string
+	shardResolver ShardResolver
+
+	// History table receiving a copy of each row before it is updated or deleted
+	historyTable string
+
+	// Shared configuration the model was created from, see ModelDefinition
+	definition *ModelDefinition
 
 	// State
 	attributes         map[string]interface{}
 	original           map[string]interface{}
+	changes            map[string]interface{}
+	previous           map[string]interface{}
 	exists             bool
 	wasRecentlyCreated bool
 
+	// Context the model's writes resolve their actor from
+	ctx context.Context
+
 	// Relationships
 	relations map[string]interface{}
 
@@ -91,15 +165,67 @@ type TypedModelQueryBuilder[T Model] struct {
 	modelFactory func() T
 }
 
-// NewModelQueryBuilder creates a new model query builder
-func NewModelQueryBuilder(model Model) *ModelQueryBuilder {
-	db := DB()
-	if db == nil {
-		panic("Database connection not initialized")
+// wrap returns the model builder for qb, the builder a chained call returned: mqb itself,
+// or a new model builder around the copy an immutable builder made
+func (mqb *ModelQueryBuilder) wrap(qb *QueryBuilder) *ModelQueryBuilder {
+	if qb == mqb.QueryBuilder {
+		return mqb
+	}
+	return &ModelQueryBuilder{QueryBuilder: qb, model: mqb.model}
+}
+
+// wrap returns the typed builder for qb, see ModelQueryBuilder.wrap
+func (tmqb *TypedModelQueryBuilder[T]) wrap(qb *QueryBuilder) *TypedModelQueryBuilder[T] {
+	if qb == tmqb.QueryBuilder {
+		return tmqb
 	}
+	return &TypedModelQueryBuilder[T]{QueryBuilder: qb, model: tmqb.model, modelFactory: tmqb.modelFactory}
+}
 
+// Immutable returns an immutable copy of the query, see QueryBuilder.Immutable
+func (mqb *ModelQueryBuilder) Immutable() *ModelQueryBuilder {
+	return &ModelQueryBuilder{QueryBuilder: mqb.QueryBuilder.Immutable(), model: mqb.model}
+}
+
+// Clone returns an independent copy of the query
+func (mqb *ModelQueryBuilder) Clone() *ModelQueryBuilder {
+	return &ModelQueryBuilder{QueryBuilder: mqb.QueryBuilder.Clone(), model: mqb.model}
+}
+
+// Immutable returns an immutable copy of the query, see QueryBuilder.Immutable
+func (tmqb *TypedModelQueryBuilder[T]) Immutable() *TypedModelQueryBuilder[T] {
+	return &TypedModelQueryBuilder[T]{QueryBuilder: tmqb.QueryBuilder.Immutable(), model: tmqb.model, modelFactory: tmqb.modelFactory}
+}
+
+// Clone returns an independent copy of the query
+func (tmqb *TypedModelQueryBuilder[T]) Clone() *TypedModelQueryBuilder[T] {
+	return &TypedModelQueryBuilder[T]{QueryBuilder: tmqb.QueryBuilder.Clone(), model: tmqb.model, modelFactory: tmqb.modelFactory}
+}
+
+// NewModelQueryBuilder creates a new model query builder.
+// Without a database connection, running the query returns ErrNoConnection.
+func NewModelQueryBuilder(model Model) *ModelQueryBuilder {
+	bootModel(model)
+	db := modelConnection(model)
+	var connectionErr error
+	if m := baseModelOf(model); m != nil && m.shardKey != "" {
+		// Reads on a sharded model must not fall back to the default connection either
+		db, connectionErr = m.resolveConnection()
+		if connectionErr != nil {
+			connectionErr = fmt.Errorf("query on a model sharded by '%s' needs OnShard or GetAcrossShards: %w", m.shardKey, connectionErr)
+		}
+	}
 	qb := NewQueryBuilder(db)
-	qb.Table(model.GetTable())
+	qb.connectionErr = connectionErr
+	qb.Table(modelTable(model))
+	if m := baseModelOf(model); m != nil {
+		qb.manager = m.manager
+		qb.applyDefaultOrders(m.defaultOrders)
+		qb.With(m.alwaysWith...)
+	}
+	if GetStableOrdering() {
+		qb.Tiebreaker(model.GetPrimaryKey())
+	}
 
 	return &ModelQueryBuilder{
 		QueryBuilder: qb,
@@ -118,9 +244,13 @@ func (mqb *ModelQueryBuilder) Get() ([]Model, error) {
 	for _, result := range results {
 		model := mqb.newModelInstance()
 		mqb.fillModelFromMap(model, result)
+		releaseRow(result)
 		models = append(models, model)
 	}
 
+	if err := mqb.loadEager(models); err != nil {
+		return nil, err
+	}
 	return models, nil
 }
 
@@ -133,6 +263,10 @@ func (mqb *ModelQueryBuilder) First() (Model, error) {
 
 	model := mqb.newModelInstance()
 	mqb.fillModelFromMap(model, result)
+	releaseRow(result)
+	if err := mqb.loadEager([]Model{model}); err != nil {
+		return nil, err
+	}
 	return model, nil
 }
 
@@ -145,103 +279,321 @@ func (mqb *ModelQueryBuilder) FirstOrFail() (Model, error) {
 
 	model := mqb.newModelInstance()
 	mqb.fillModelFromMap(model, result)
+	releaseRow(result)
+	if err := mqb.loadEager([]Model{model}); err != nil {
+		return nil, err
+	}
 	return model, nil
 }
 
 // Find finds a model by primary key
 func (mqb *ModelQueryBuilder) Find(id interface{}) (Model, error) {
-	result, err := mqb.QueryBuilder.Find(id)
-	if err != nil {
-		return nil, err
-	}
-
-	model := mqb.newModelInstance()
-	mqb.fillModelFromMap(model, result)
-	return model, nil
+	return mqb.WhereKey(id).First()
 }
 
 // FindOrFail finds a model by primary key or fails
 func (mqb *ModelQueryBuilder) FindOrFail(id interface{}) (Model, error) {
-	result, err := mqb.QueryBuilder.FindOrFail(id)
-	if err != nil {
-		return nil, err
+	return mqb.WhereKey(id).FirstOrFail()
+}
+
+// FindOr finds a model by primary key, or returns the result of fallback when none exists
+func (mqb *ModelQueryBuilder) FindOr(id interface{}, fallback func() (Model, error)) (Model, error) {
+	return mqb.WhereKey(id).FirstOr(fallback)
+}
+
+// FirstOr returns the first model, or the result of fallback when none exists.
+// Errors other than ErrNotFound are returned without calling fallback.
+func (mqb *ModelQueryBuilder) FirstOr(fallback func() (Model, error)) (Model, error) {
+	model, err := mqb.First()
+	if errors.Is(err, ErrNotFound) {
+		return fallback()
 	}
+	return model, err
+}
 
-	model := mqb.newModelInstance()
-	mqb.fillModelFromMap(model, result)
-	return model, nil
+// FindMany finds the models with the given primary keys
+func (mqb *ModelQueryBuilder) FindMany(ids []interface{}) ([]Model, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return mqb.WhereKey(ids).Get()
+}
+
+// WhereKey adds a where clause on the model's primary key. A []interface{} matches any of its keys.
+func (mqb *ModelQueryBuilder) WhereKey(id interface{}) *ModelQueryBuilder {
+	if ids, ok := id.([]interface{}); ok {
+		return mqb.WhereIn(mqb.model.GetPrimaryKey(), ids)
+	}
+	return mqb.Where(mqb.model.GetPrimaryKey(), id)
+}
+
+// WhereKeyNot excludes the given primary key, or any of the keys in a []interface{}
+func (mqb *ModelQueryBuilder) WhereKeyNot(id interface{}) *ModelQueryBuilder {
+	if ids, ok := id.([]interface{}); ok {
+		return mqb.WhereNotIn(mqb.model.GetPrimaryKey(), ids)
+	}
+	return mqb.Where(mqb.model.GetPrimaryKey(), "!=", id)
 }
 
 // Where adds a where clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) Where(column string, args ...interface{}) *ModelQueryBuilder {
-	mqb.QueryBuilder.Where(column, args...)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.Where(column, args...))
 }
 
 // OrWhere adds an OR where clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) OrWhere(column string, args ...interface{}) *ModelQueryBuilder {
-	mqb.QueryBuilder.OrWhere(column, args...)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.OrWhere(column, args...))
 }
 
 // WhereIn adds a where in clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) WhereIn(column string, values []interface{}) *ModelQueryBuilder {
-	mqb.QueryBuilder.WhereIn(column, values)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.WhereIn(column, values))
 }
 
 // WhereNotIn adds a where not in clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) WhereNotIn(column string, values []interface{}) *ModelQueryBuilder {
-	mqb.QueryBuilder.WhereNotIn(column, values)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.WhereNotIn(column, values))
+}
+
+// WhereNamed adds a raw where clause with named parameters and returns ModelQueryBuilder
+func (mqb *ModelQueryBuilder) WhereNamed(sql string, arg interface{}) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.WhereNamed(sql, arg))
 }
 
 // WhereNull adds a where null clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) WhereNull(column string) *ModelQueryBuilder {
-	mqb.QueryBuilder.WhereNull(column)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.WhereNull(column))
 }
 
 // WhereNotNull adds a where not null clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) WhereNotNull(column string) *ModelQueryBuilder {
-	mqb.QueryBuilder.WhereNotNull(column)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.WhereNotNull(column))
 }
 
 // OrderBy adds an order by clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) OrderBy(column, direction string) *ModelQueryBuilder {
-	mqb.QueryBuilder.OrderBy(column, direction)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.OrderBy(column, direction))
 }
 
 // OrderByDesc adds an order by desc clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) OrderByDesc(column string) *ModelQueryBuilder {
-	mqb.QueryBuilder.OrderByDesc(column)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.OrderByDesc(column))
 }
 
 // Limit adds a limit clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) Limit(limit int) *ModelQueryBuilder {
-	mqb.QueryBuilder.Limit(limit)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.Limit(limit))
 }
 
 // Take adds a limit clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) Take(limit int) *ModelQueryBuilder {
-	mqb.QueryBuilder.Take(limit)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.Take(limit))
 }
 
 // Offset adds an offset clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) Offset(offset int) *ModelQueryBuilder {
-	mqb.QueryBuilder.Offset(offset)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.Offset(offset))
 }
 
 // Skip adds an offset clause and returns ModelQueryBuilder
 func (mqb *ModelQueryBuilder) Skip(offset int) *ModelQueryBuilder {
-	mqb.QueryBuilder.Skip(offset)
-	return mqb
+	return mqb.wrap(mqb.QueryBuilder.Skip(offset))
+}
+
+// updateModelsChunkSize is the number of models UpdateModels loads per query
+const updateModelsChunkSize = 100
+
+// UpdateModels updates every matching record through its model rather than with a single
+// UPDATE statement, so mass assignment rules apply and Updating and Updated hooks run.
+// The matching keys are read a chunk at a time, walking the primary key, and each chunk
+// of models is loaded and saved like SaveAll, in one transaction. A failing chunk is
+// rolled back and stops the update, leaving the earlier chunks committed. Models the
+// attributes leave unchanged are skipped. It returns the number of models updated.
+func (mqb *ModelQueryBuilder) UpdateModels(attributes map[string]interface{}) (int64, error) {
+	primaryKey := mqb.model.GetPrimaryKey()
+
+	keyQB := mqb.QueryBuilder.Clone().groupWheres(0)
+	keyQB.columns = []string{primaryKey}
+
+	// A limited query reads its bounded set of keys at once, in its own order
+	limited := keyQB.limitValue != nil || keyQB.offsetValue != nil
+	if !limited {
+		keyQB.orders = nil
+		keyQB = keyQB.OrderBy(primaryKey, "asc").Limit(updateModelsChunkSize)
+	}
+
+	var updated int64
+	var last interface{}
+	for {
+		page := keyQB
+		if last != nil {
+			page = keyQB.Clone().Where(primaryKey, ">", last)
+		}
+		rows, err := page.Get()
+		if err != nil {
+			return updated, err
+		}
+
+		for start := 0; start < len(rows); start += updateModelsChunkSize {
+			end := start + updateModelsChunkSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+
+			keys := make([]interface{}, 0, end-start)
+			for _, row := range rows[start:end] {
+				keys = append(keys, row[primaryKey])
+			}
+			count, err := mqb.updateChunk(keys, attributes)
+			if err != nil {
+				return updated, err
+			}
+			updated += count
+		}
+
+		if limited || len(rows) < updateModelsChunkSize {
+			return updated, nil
+		}
+		last = rows[len(rows)-1][primaryKey]
+	}
+}
+
+// updateChunk loads the models with the given keys, fills them with attributes and saves
+// the changed ones together. It returns the number of models saved.
+func (mqb *ModelQueryBuilder) updateChunk(keys []interface{}, attributes map[string]interface{}) (int64, error) {
+	chunk := &ModelQueryBuilder{
+		QueryBuilder: NewQueryBuilder(mqb.connection).Table(mqb.table).WhereIn(mqb.model.GetPrimaryKey(), keys),
+		model:        mqb.model,
+	}
+	models, err := chunk.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := make([]Model, 0, len(models))
+	for _, model := range models {
+		m := baseModelOf(model)
+		if m == nil {
+			return 0, fmt.Errorf("model %T does not support UpdateModels", model)
+		}
+		m.Fill(attributes)
+		if !m.IsDirty() {
+			continue
+		}
+		// Let the Updating hook see the new values on the struct fields
+		m.syncAttributesToFields()
+		changed = append(changed, model)
+	}
+
+	if err := SaveAll(changed); err != nil {
+		return 0, err
+	}
+	return int64(len(changed)), nil
+}
+
+// Delete deletes every matching record. Models that use soft deletes have their deleted_at
+// column set instead, skipping records that are already trashed.
+// It returns the number of affected rows.
+func (mqb *ModelQueryBuilder) Delete() (int64, error) {
+	return mqb.DeleteWithReason("")
+}
+
+// DeleteWithReason deletes every matching record like Delete, recording the reason and the
+// actor resolved from the query's context on models with delete metadata columns
+func (mqb *ModelQueryBuilder) DeleteWithReason(reason string) (int64, error) {
+	m := baseModelOf(mqb.model)
+	if m == nil || !m.usesSoftDeletes() {
+		return mqb.ForceDelete()
+	}
+	if m.IsReadOnly() {
+		return 0, ErrReadOnly
+	}
+
+	now := time.Now()
+	values := m.deleteMetadata(mqb.Context(), reason)
+	values[m.deletedAt] = now
+	if m.timestamps {
+		values[m.updatedAt] = now
+	}
+	m.blameValues(mqb.Context(), values, false)
+	return mqb.QueryBuilder.Clone().groupWheres(0).WhereNull(m.deletedAt).Update(values)
+}
+
+// ForceDelete permanently deletes every matching record, even for models that use soft deletes.
+// It returns the number of affected rows.
+func (mqb *ModelQueryBuilder) ForceDelete() (int64, error) {
+	if m := baseModelOf(mqb.model); m != nil && m.IsReadOnly() {
+		return 0, ErrReadOnly
+	}
+	return mqb.QueryBuilder.Delete()
+}
+
+// Connection runs the query on the named connection and returns ModelQueryBuilder
+func (mqb *ModelQueryBuilder) Connection(name string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.Connection(name))
+}
+
+// WithContext associates the query with a context and returns ModelQueryBuilder
+func (mqb *ModelQueryBuilder) WithContext(ctx context.Context) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.WithContext(ctx))
+}
+
+// Max returns the largest value of column, with the model's cast for the column applied
+func (mqb *ModelQueryBuilder) Max(column string) (interface{}, error) {
+	value, err := mqb.QueryBuilder.Max(column)
+	if err != nil {
+		return nil, err
+	}
+	return mqb.castAggregate(column, value), nil
+}
+
+// Min returns the smallest value of column, with the model's cast for the column applied
+func (mqb *ModelQueryBuilder) Min(column string) (interface{}, error) {
+	value, err := mqb.QueryBuilder.Min(column)
+	if err != nil {
+		return nil, err
+	}
+	return mqb.castAggregate(column, value), nil
+}
+
+// castAggregate applies the model's cast for column to an aggregate result; NULL stays nil
+func (mqb *ModelQueryBuilder) castAggregate(column string, value interface{}) interface{} {
+	m := baseModelOf(mqb.model)
+	if m == nil || value == nil {
+		return value
+	}
+	if castType, hasCast := m.casts[column]; hasCast {
+		return m.castAttribute(column, value, castType)
+	}
+	return value
+}
+
+// OnlyTrashed limits the query to soft-deleted records
+func (mqb *ModelQueryBuilder) OnlyTrashed() *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.apply(func(target *QueryBuilder) {
+		target.groupWheres(0)
+		OnlyTrashedScope().Apply(target, mqb.model)
+	}))
+}
+
+// Restore clears the deleted_at column, and any delete metadata, of every matching
+// soft-deleted record. It returns the number of restored records.
+func (mqb *ModelQueryBuilder) Restore() (int64, error) {
+	m := baseModelOf(mqb.model)
+	if m == nil || !m.usesSoftDeletes() {
+		return 0, fmt.Errorf("model does not use soft deletes")
+	}
+	if m.IsReadOnly() {
+		return 0, ErrReadOnly
+	}
+
+	values := m.restoreMetadata()
+	values[m.deletedAt] = nil
+	if m.timestamps {
+		values[m.updatedAt] = time.Now()
+	}
+	m.blameValues(mqb.Context(), values, false)
+	return mqb.QueryBuilder.Clone().groupWheres(0).WhereNotNull(m.deletedAt).Update(values)
 }
 
 // newModelInstance creates a new instance of the model
@@ -269,32 +621,49 @@ func (mqb *ModelQueryBuilder) newModelInstance() Model {
 
 // fillModelFromMap fills a model with data from a map
 func (mqb *ModelQueryBuilder) fillModelFromMap(model Model, data map[string]interface{}) {
-	// Use reflection to find the embedded BaseModel
+	mqb.hydrate(model, data, false)
+}
+
+// hydrate fills a model with a row. An owned row is not used by the caller afterwards and
+// becomes the model's attributes instead of being copied into them.
+func (mqb *ModelQueryBuilder) hydrate(model Model, data map[string]interface{}, owned bool) {
+	mqb.hydrateAttributes(model, data, owned)
+
+	// Auto-sync attributes to struct fields
+	mqb.autoSyncAttributes(model, data)
+	afterHydrate(model)
+}
+
+// hydrateAttributes sets data as the attributes and originals of model's BaseModel and
+// marks it as loaded from the database, without touching the model's own fields
+func (mqb *ModelQueryBuilder) hydrateAttributes(model Model, data map[string]interface{}, owned bool) {
 	modelValue := reflect.ValueOf(model)
 	if modelValue.Kind() == reflect.Ptr {
 		modelValue = modelValue.Elem()
 	}
+	plan := fieldPlanOf(modelValue.Type())
 
 	// Look for embedded BaseModel field
 	var baseModel *BaseModel
-	for i := 0; i < modelValue.NumField(); i++ {
-		field := modelValue.Field(i)
-		if field.Type() == reflect.TypeOf((*BaseModel)(nil)) {
-			baseModel = field.Interface().(*BaseModel)
-			break
-		}
+	if plan.baseModel >= 0 {
+		baseModel = modelValue.Field(plan.baseModel).Interface().(*BaseModel)
 	}
 
 	if baseModel != nil {
-		if baseModel.attributes == nil {
-			baseModel.attributes = make(map[string]interface{})
+		if owned && len(baseModel.attributes) == 0 {
+			baseModel.attributes = data
+		} else {
+			if baseModel.attributes == nil {
+				baseModel.attributes = make(map[string]interface{}, len(data))
+			}
+			for key, value := range data {
+				baseModel.attributes[key] = value
+			}
 		}
 		if baseModel.original == nil {
-			baseModel.original = make(map[string]interface{})
+			baseModel.original = make(map[string]interface{}, len(data))
 		}
-
 		for key, value := range data {
-			baseModel.attributes[key] = value
 			baseModel.original[key] = value
 		}
 
@@ -306,6 +675,9 @@ func (mqb *ModelQueryBuilder) fillModelFromMap(model Model, data map[string]inte
 
 		// Copy table configuration from the template model
 		if mqb.model != nil {
+			if template := baseModelOf(mqb.model); template != nil {
+				baseModel.copyConfig(template)
+			}
 			baseModel.table = mqb.model.GetTable()
 			baseModel.primaryKey = mqb.model.GetPrimaryKey()
 			baseModel.fillable = mqb.model.GetFillable()
@@ -318,41 +690,26 @@ func (mqb *ModelQueryBuilder) fillModelFromMap(model Model, data map[string]inte
 			baseModel.createdAt = mqb.model.GetCreatedAtColumn()
 			baseModel.updatedAt = mqb.model.GetUpdatedAtColumn()
 			baseModel.deletedAt = mqb.model.GetDeletedAtColumn()
+			baseModel.connection = mqb.model.GetConnection()
 		}
 	}
-
-	// Auto-sync attributes to struct fields
-	mqb.autoSyncAttributes(model, data)
 }
 
 // autoSyncAttributes automatically syncs database attributes to struct fields
 func (mqb *ModelQueryBuilder) autoSyncAttributes(model Model, data map[string]interface{}) {
+	if filler, ok := model.(RowFiller); ok {
+		filler.FillFromRow(data)
+		return
+	}
+
 	modelValue := reflect.ValueOf(model)
 	if modelValue.Kind() == reflect.Ptr {
 		modelValue = modelValue.Elem()
 	}
 
-	modelType := modelValue.Type()
-
-	// Iterate through all struct fields
-	for i := 0; i < modelValue.NumField(); i++ {
-		field := modelValue.Field(i)
-		fieldType := modelType.Field(i)
-
-		// Skip unexported fields and BaseModel
-		if !field.CanSet() || fieldType.Type == reflect.TypeOf((*BaseModel)(nil)) {
-			continue
-		}
-
-		// Get the database column name from the db tag, or use field name
-		dbTag := fieldType.Tag.Get("db")
-		if dbTag == "" {
-			dbTag = toSnakeCase(fieldType.Name)
-		}
-
-		// Check if we have data for this field
-		if value, exists := data[dbTag]; exists && value != nil {
-			mqb.setFieldValue(field, value)
+	for _, field := range fieldPlanOf(modelValue.Type()).fields {
+		if value, exists := data[field.column]; exists && value != nil {
+			mqb.setFieldValue(modelValue.Field(field.index), value)
 		}
 	}
 }
@@ -461,12 +818,60 @@ func (m *BaseModel) SetParentModel(parent Model) {
 	m.parentModel = parent
 }
 
+// copyConfig copies the template's configuration, leaving attributes and state alone
+func (m *BaseModel) copyConfig(template *BaseModel) {
+	m.table = template.table
+	m.schema = template.schema
+	m.primaryKey = template.primaryKey
+	m.fillable = template.fillable
+	m.guarded = template.guarded
+	m.hidden = template.hidden
+	m.visible = template.visible
+	m.casts = template.casts
+	m.dates = template.dates
+	m.timestamps = template.timestamps
+	m.createdAt = template.createdAt
+	m.updatedAt = template.updatedAt
+	m.deletedAt = template.deletedAt
+	m.connection = template.connection
+	m.readOnly = template.readOnly
+	m.strictInserts = template.strictInserts
+	m.appends = template.appends
+	m.contextCasts = template.contextCasts
+	m.accessors = template.accessors
+	m.manager = template.manager
+	m.shardKey = template.shardKey
+	m.shardResolver = template.shardResolver
+	m.historyTable = template.historyTable
+	m.deletedBy = template.deletedBy
+	m.deleteReason = template.deleteReason
+	m.createdBy = template.createdBy
+	m.updatedBy = template.updatedBy
+	m.slugColumn = template.slugColumn
+	m.slugFrom = template.slugFrom
+	m.regenerateSlugs = template.regenerateSlugs
+	m.sortColumn = template.sortColumn
+	m.sortGroup = template.sortGroup
+	m.stateMachines = template.stateMachines
+	m.stateColumns = template.stateColumns
+	m.defaultOrders = template.defaultOrders
+	m.alwaysWith = template.alwaysWith
+	m.definition = template.definition
+}
+
 // Table configuration methods
 func (m *BaseModel) Table(table string) *BaseModel {
 	m.table = table
 	return m
 }
 
+// Schema places the model's table in a PostgreSQL schema, or a MySQL database, so its
+// queries use schema.table names, e.g. for a schema per tenant
+func (m *BaseModel) Schema(schema string) *BaseModel {
+	m.schema = schema
+	return m
+}
+
 func (m *BaseModel) PrimaryKey(key string) *BaseModel {
 	m.primaryKey = key
 	return m
@@ -477,6 +882,12 @@ func (m *BaseModel) Connection(conn string) *BaseModel {
 	return m
 }
 
+// Manager binds the model to a connection manager instead of the global one
+func (m *BaseModel) Manager(cm *ConnectionManager) *BaseModel {
+	m.manager = cm
+	return m
+}
+
 func (m *BaseModel) Fillable(fields ...string) *BaseModel {
 	m.fillable = fields
 	return m
@@ -497,11 +908,74 @@ func (m *BaseModel) Visible(fields ...string) *BaseModel {
 	return m
 }
 
+// MakeVisible shows the given attributes when this instance is serialized, overriding Hidden.
+// Other instances of the model are not affected.
+func (m *BaseModel) MakeVisible(fields ...string) *BaseModel {
+	hidden := make([]string, 0, len(m.hidden))
+	for _, field := range m.hidden {
+		if !m.contains(fields, field) {
+			hidden = append(hidden, field)
+		}
+	}
+	m.hidden = hidden
+
+	if len(m.visible) > 0 {
+		visible := append([]string{}, m.visible...)
+		for _, field := range fields {
+			if !m.contains(visible, field) {
+				visible = append(visible, field)
+			}
+		}
+		m.visible = visible
+	}
+	return m
+}
+
+// MakeHidden hides the given attributes when this instance is serialized.
+// Other instances of the model are not affected.
+func (m *BaseModel) MakeHidden(fields ...string) *BaseModel {
+	hidden := append([]string{}, m.hidden...)
+	for _, field := range fields {
+		if !m.contains(hidden, field) {
+			hidden = append(hidden, field)
+		}
+	}
+	m.hidden = hidden
+	return m
+}
+
+// Appends adds computed attributes to ToMap and ToJSON; each needs an Accessor registered under its name
+func (m *BaseModel) Appends(fields ...string) *BaseModel {
+	m.appends = fields
+	return m
+}
+
+// Accessor registers the function that computes the named attribute
+func (m *BaseModel) Accessor(name string, accessor Accessor) *BaseModel {
+	m.detachDefinition()
+	if m.accessors == nil {
+		m.accessors = make(map[string]Accessor)
+	}
+	m.accessors[name] = accessor
+	return m
+}
+
 func (m *BaseModel) Casts(casts map[string]string) *BaseModel {
 	m.casts = casts
 	return m
 }
 
+// CastsFor registers casts that override the model's casts when serializing with ToMapFor or
+// ToJSONFor in the given context, e.g. "timestamp" for internal and "datetime:2006-01-02" for public APIs
+func (m *BaseModel) CastsFor(context string, casts map[string]string) *BaseModel {
+	m.detachDefinition()
+	if m.contextCasts == nil {
+		m.contextCasts = make(map[string]map[string]string)
+	}
+	m.contextCasts[context] = casts
+	return m
+}
+
 func (m *BaseModel) Dates(dates ...string) *BaseModel {
 	m.dates = dates
 	return m
@@ -517,17 +991,51 @@ func (m *BaseModel) WithSoftDeletes() *BaseModel {
 	return m
 }
 
+// ReadOnly marks the model as read-only so that writes return ErrReadOnly
+func (m *BaseModel) ReadOnly() *BaseModel {
+	m.readOnly = true
+	return m
+}
+
+// IsView marks the model as backed by a database view, which makes it read-only
+func (m *BaseModel) IsView() *BaseModel {
+	m.readOnly = true
+	return m
+}
+
 // Getter methods
 func (m *BaseModel) GetTable() string {
 	if m.table != "" {
 		return m.table
 	}
-	// Auto-generate table name from struct name
-	modelType := reflect.TypeOf(m)
+	// Derive the table name from the struct embedding this model
+	modelType := reflect.TypeOf(m.outerModel())
 	if modelType.Kind() == reflect.Ptr {
 		modelType = modelType.Elem()
 	}
-	return toSnakeCase(modelType.Name()) + "s"
+	return GetNamingStrategy().TableName(modelType.Name())
+}
+
+// GetSchema returns the schema set with Schema
+func (m *BaseModel) GetSchema() string {
+	return m.schema
+}
+
+// qualifiedTable returns the table name queries use, qualified with the model's schema
+func (m *BaseModel) qualifiedTable() string {
+	if m.schema == "" {
+		return m.GetTable()
+	}
+	return m.schema + "." + m.GetTable()
+}
+
+// modelTable returns the table name queries for model use
+func modelTable(model Model) string {
+	table := model.GetTable()
+	if m := baseModelOf(model); m != nil && m.schema != "" {
+		return m.schema + "." + table
+	}
+	return table
 }
 
 func (m *BaseModel) GetPrimaryKey() string {
@@ -578,6 +1086,17 @@ func (m *BaseModel) GetDeletedAtColumn() string {
 	return m.deletedAt
 }
 
+// IsReadOnly reports whether the model or its connection rejects writes
+func (m *BaseModel) IsReadOnly() bool {
+	if m.readOnly {
+		return true
+	}
+	if db, err := m.resolveConnection(); err == nil {
+		return db.ReadOnly
+	}
+	return false
+}
+
 // Attribute methods
 func (m *BaseModel) GetAttribute(key string) interface{} {
 	value, exists := m.attributes[key]
@@ -597,7 +1116,41 @@ func (m *BaseModel) SetAttribute(key string, value interface{}) {
 	m.attributes[key] = value
 }
 
+// GetRelation returns a relationship loaded with LoadRelation, or nil
+func (m *BaseModel) GetRelation(name string) interface{} {
+	return m.relations[name]
+}
+
+// SetRelation sets a loaded relationship, which ToMap and ToJSON include under its name
+func (m *BaseModel) SetRelation(name string, value interface{}) {
+	m.relations[name] = value
+}
+
+// GetOriginal returns the value an attribute had when the model was loaded or last saved, with casts applied
 func (m *BaseModel) GetOriginal(key string) interface{} {
+	value, exists := m.original[key]
+	if !exists {
+		return nil
+	}
+
+	if castType, hasCast := m.casts[key]; hasCast {
+		return m.castAttribute(key, value, castType)
+	}
+
+	return value
+}
+
+// GetOriginalAll returns every original attribute value, with casts applied
+func (m *BaseModel) GetOriginalAll() map[string]interface{} {
+	original := make(map[string]interface{}, len(m.original))
+	for key := range m.original {
+		original[key] = m.GetOriginal(key)
+	}
+	return original
+}
+
+// GetRawOriginal returns the original value of an attribute as read from the database, without casts
+func (m *BaseModel) GetRawOriginal(key string) interface{} {
 	return m.original[key]
 }
 
@@ -634,6 +1187,44 @@ func (m *BaseModel) IsClean(keys ...string) bool {
 	return !m.IsDirty(keys...)
 }
 
+// GetChanges returns the attributes that were changed by the last update, with their new values
+func (m *BaseModel) GetChanges() map[string]interface{} {
+	changes := make(map[string]interface{}, len(m.changes))
+	for key, value := range m.changes {
+		changes[key] = value
+	}
+	return changes
+}
+
+// GetPrevious returns the values the changed attributes had before the last update
+func (m *BaseModel) GetPrevious() map[string]interface{} {
+	previous := make(map[string]interface{}, len(m.previous))
+	for key, value := range m.previous {
+		previous[key] = value
+	}
+	return previous
+}
+
+// SyncChanges records the dirty attributes and their original values as the model's changes.
+// Updates call it automatically just before the new values become the originals.
+func (m *BaseModel) SyncChanges() {
+	m.changes = m.GetDirty()
+	m.previous = make(map[string]interface{}, len(m.changes))
+	for key := range m.changes {
+		m.previous[key] = m.original[key]
+	}
+}
+
+// Exists reports whether the model is backed by a database record
+func (m *BaseModel) Exists() bool {
+	return m.exists
+}
+
+// WasRecentlyCreated reports whether the model's record was inserted by this instance
+func (m *BaseModel) WasRecentlyCreated() bool {
+	return m.wasRecentlyCreated
+}
+
 // Fill method
 func (m *BaseModel) Fill(attributes map[string]interface{}) Model {
 	for key, value := range attributes {
@@ -646,14 +1237,36 @@ func (m *BaseModel) Fill(attributes map[string]interface{}) Model {
 
 // Save method
 func (m *BaseModel) Save() error {
+	return m.save(false)
+}
+
+// SaveQuietly saves the model like Save without running its Updating and Updated hooks
+// or publishing events
+func (m *BaseModel) SaveQuietly() error {
+	return m.save(true)
+}
+
+// save inserts or updates the model, firing hooks and publishing events unless quiet
+func (m *BaseModel) save(quiet bool) error {
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
+	bootModel(m)
+
 	// Only sync struct fields to attributes for existing models (updates)
 	// For new models, we want to preserve the attributes set by Fill()
-	if m.exists {
+	updating := m.exists
+	if updating {
 		m.syncFieldsToAttributes()
 	}
 
 	var err error
-	if m.exists {
+	if updating {
+		if !quiet {
+			if err = m.fireUpdating(); err != nil {
+				return err
+			}
+		}
 		err = m.performUpdate()
 	} else {
 		err = m.performInsert()
@@ -665,74 +1278,274 @@ func (m *BaseModel) Save() error {
 
 	// Sync attributes back to struct fields after successful save
 	m.syncAttributesToFields()
+	if quiet {
+		return nil
+	}
+	if updating {
+		m.fireUpdated()
+		m.publishEvent(EventUpdated)
+	} else {
+		m.publishEvent(EventCreated)
+	}
 	return nil
 }
 
+// insertOrIgnore inserts a new model unless the insert conflicts with a unique index or
+// primary key, and reports whether it was inserted. Ignored models are not marked as
+// existing and publish no event.
+func (m *BaseModel) insertOrIgnore() (bool, error) {
+	if m.IsReadOnly() {
+		return false, ErrReadOnly
+	}
+	bootModel(m)
+
+	inserted, err := m.insert(true)
+	if err != nil || !inserted {
+		return false, err
+	}
+	m.syncAttributesToFields()
+	m.publishEvent(EventCreated)
+	return true, nil
+}
+
 // Delete methods
 func (m *BaseModel) Delete() error {
+	return m.DeleteWithReason(m.Context(), "")
+}
+
+// DeleteWithReason deletes the model like Delete. Models with delete metadata columns
+// record the reason and the actor resolved from ctx with their soft delete.
+func (m *BaseModel) DeleteWithReason(ctx context.Context, reason string) error {
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
 	if m.usesSoftDeletes() {
-		return m.runSoftDelete()
+		if err := m.runSoftDelete(ctx, reason); err != nil {
+			return err
+		}
+		m.publishEvent(EventDeleted)
+		return nil
 	}
-	return m.performDelete()
+	return m.ForceDelete()
 }
 
 func (m *BaseModel) ForceDelete() error {
-	return m.performDelete()
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
+	if err := m.performDelete(); err != nil {
+		return err
+	}
+
+	m.exists = false
+	m.publishEvent(EventDeleted)
+	return nil
+}
+
+func (m *BaseModel) Restore() error {
+	if !m.usesSoftDeletes() {
+		return fmt.Errorf("model does not use soft deletes")
+	}
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
+	if err := m.performRestore(); err != nil {
+		return err
+	}
+	m.publishEvent(EventRestored)
+	return nil
+}
+
+// Update method
+func (m *BaseModel) Update(attributes map[string]interface{}) error {
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
+
+	m.Fill(attributes)
+
+	// Let the Updating hook see the new values on the struct fields,
+	// keeping a primary key that was changed directly on the struct
+	m.syncPrimaryKeyToAttributes()
+	m.syncAttributesToFields()
+	if err := m.fireUpdating(); err != nil {
+		return err
+	}
+
+	err := m.performUpdate()
+	if err != nil {
+		return err
+	}
+
+	// Sync attributes back to struct fields after successful update
+	m.syncAttributesToFields()
+	m.fireUpdated()
+	m.publishEvent(EventUpdated)
+	return nil
+}
+
+func (m *BaseModel) Fresh() (Model, error) {
+	// Implementation would query fresh data from database
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *BaseModel) Refresh() error {
+	// Implementation would refresh current model from database
+	return fmt.Errorf("not implemented")
+}
+
+// Serialization methods
+func (m *BaseModel) ToMap() map[string]interface{} {
+	return m.serialize("")
+}
+
+// ToMapFor converts the model to a map using the casts registered for the given context with CastsFor
+func (m *BaseModel) ToMapFor(context string) map[string]interface{} {
+	return m.serialize(context)
+}
+
+// ToJSON converts the model to JSON. Keys are renamed and omitted according to the
+// json tags on the embedding struct's fields, like encoding/json does for structs.
+func (m *BaseModel) ToJSON() ([]byte, error) {
+	return json.Marshal(m.applyJSONTags(m.serialize("")))
+}
+
+// ToJSONFor converts the model to JSON using the casts registered for the given context with CastsFor
+func (m *BaseModel) ToJSONFor(context string) ([]byte, error) {
+	return json.Marshal(m.applyJSONTags(m.serialize(context)))
 }
 
-func (m *BaseModel) Restore() error {
-	if !m.usesSoftDeletes() {
-		return fmt.Errorf("model does not use soft deletes")
+// serialize converts the model to a map, applying the casts of the given context
+func (m *BaseModel) serialize(context string) map[string]interface{} {
+	result := make(map[string]interface{})
+	casts := m.castsFor(context)
+
+	for key, value := range m.attributes {
+		if m.isHidden(key) {
+			continue
+		}
+		if castType, hasCast := casts[key]; hasCast {
+			value = m.castAttribute(key, value, castType)
+		}
+		result[key] = value
+	}
+
+	// Add relations
+	for key, relation := range m.relations {
+		if !m.isHidden(key) {
+			result[key] = relation
+		}
+	}
+
+	// Add computed attributes
+	for _, key := range m.appends {
+		if accessor, ok := m.accessors[key]; ok && !m.isHidden(key) {
+			result[key] = accessor(m.outerModel())
+		}
+	}
+
+	return result
+}
+
+// castsFor returns the model's casts overridden by those registered for the given context
+func (m *BaseModel) castsFor(context string) map[string]string {
+	overrides := m.contextCasts[context]
+	if context == "" || len(overrides) == 0 {
+		return m.casts
+	}
+
+	casts := make(map[string]string, len(m.casts)+len(overrides))
+	for key, castType := range m.casts {
+		casts[key] = castType
+	}
+	for key, castType := range overrides {
+		casts[key] = castType
 	}
-	return m.performRestore()
+	return casts
 }
 
-// Update method
-func (m *BaseModel) Update(attributes map[string]interface{}) error {
-	m.Fill(attributes)
-	err := m.performUpdate()
-	if err != nil {
-		return err
+// applyJSONTags renames and omits serialized attributes following the json tags of the
+// embedding struct's fields; attributes without a tagged field are kept as they are
+func (m *BaseModel) applyJSONTags(data map[string]interface{}) map[string]interface{} {
+	if m.parentModel == nil {
+		return data
 	}
 
-	// Sync attributes back to struct fields after successful update
-	m.syncAttributesToFields()
-	return nil
-}
+	modelValue := reflect.ValueOf(m.parentModel)
+	if modelValue.Kind() == reflect.Ptr {
+		modelValue = modelValue.Elem()
+	}
+	if modelValue.Kind() != reflect.Struct {
+		return data
+	}
+	modelType := modelValue.Type()
 
-func (m *BaseModel) Fresh() (Model, error) {
-	// Implementation would query fresh data from database
-	return nil, fmt.Errorf("not implemented")
-}
+	result := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		result[key] = value
+	}
 
-func (m *BaseModel) Refresh() error {
-	// Implementation would refresh current model from database
-	return fmt.Errorf("not implemented")
-}
+	for i := 0; i < modelType.NumField(); i++ {
+		fieldType := modelType.Field(i)
 
-// Serialization methods
-func (m *BaseModel) ToMap() map[string]interface{} {
-	result := make(map[string]interface{})
+		// Skip unexported fields and BaseModel
+		if !fieldType.IsExported() || fieldType.Type == reflect.TypeOf((*BaseModel)(nil)) {
+			continue
+		}
 
-	for key := range m.attributes {
-		if !m.isHidden(key) {
-			result[key] = m.GetAttribute(key)
+		jsonTag, tagged := fieldType.Tag.Lookup("json")
+		if !tagged {
+			continue
 		}
-	}
 
-	// Add relations
-	for key, relation := range m.relations {
-		if !m.isHidden(key) {
-			result[key] = relation
+		column := fieldType.Tag.Get("db")
+		if column == "" {
+			column = GetNamingStrategy().ColumnName(fieldType.Name)
+		}
+		value, exists := result[column]
+		if !exists {
+			continue
+		}
+		delete(result, column)
+
+		name, options, _ := strings.Cut(jsonTag, ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if name == "" {
+			name = column
+		}
+		if m.contains(strings.Split(options, ","), "omitempty") && isEmptyJSONValue(value) {
+			continue
 		}
+		result[name] = value
 	}
 
 	return result
 }
 
-func (m *BaseModel) ToJSON() ([]byte, error) {
-	// Implementation would marshal to JSON
-	return nil, fmt.Errorf("not implemented")
+// isEmptyJSONValue reports whether encoding/json's omitempty would drop the value
+func isEmptyJSONValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
 }
 
 // Helper methods
@@ -749,11 +1562,11 @@ func (m *BaseModel) isFillable(key string) bool {
 }
 
 func (m *BaseModel) isHidden(key string) bool {
-	if len(m.visible) > 0 {
-		return !m.contains(m.visible, key)
+	if m.contains(m.hidden, key) {
+		return true
 	}
 
-	return m.contains(m.hidden, key)
+	return len(m.visible) > 0 && !m.contains(m.visible, key)
 }
 
 func (m *BaseModel) contains(slice []string, item string) bool {
@@ -773,18 +1586,63 @@ func (m *BaseModel) usesSoftDeletes() bool {
 	return m.deletedAt != ""
 }
 
+// resolveConnection returns the connection the model reads from and writes to
+func (m *BaseModel) resolveConnection() (*Connection, error) {
+	if m.shardKey != "" {
+		shard, err := m.resolveShard(m.GetAttribute(m.shardKey))
+		if err != nil {
+			return nil, err
+		}
+		// Writes must not fall back to the default connection when a shard is missing
+		return shardConnection(m.getManager(), shard)
+	}
+
+	db := m.getManager().GetConnection(m.connection)
+	if db == nil {
+		return nil, fmt.Errorf("database connection not initialized")
+	}
+	return db, nil
+}
+
+// getManager returns the connection manager the model is bound to
+func (m *BaseModel) getManager() *ConnectionManager {
+	if m.manager != nil {
+		return m.manager
+	}
+	runPendingBoot()
+	return GetManager()
+}
+
+// modelConnection returns the connection a model's queries run on
+func modelConnection(model Model) *Connection {
+	if bm := baseModelOf(model); bm != nil {
+		return bm.getManager().GetConnection(model.GetConnection())
+	}
+	return DB(model.GetConnection())
+}
+
 func (m *BaseModel) castAttribute(_ string, val interface{}, castType string) interface{} {
 	switch castType {
 	case "string":
 		return fmt.Sprintf("%v", val)
 	case "int":
-		if v, ok := val.(int); ok {
+		switch v := val.(type) {
+		case int:
 			return v
+		case int64:
+			return int(v)
+		case float64:
+			return int(v)
 		}
 		return 0
 	case "float":
-		if v, ok := val.(float64); ok {
+		switch v := val.(type) {
+		case float64:
 			return v
+		case int64:
+			return float64(v)
+		case int:
+			return float64(v)
 		}
 		return 0.0
 	case "bool":
@@ -796,16 +1654,58 @@ func (m *BaseModel) castAttribute(_ string, val interface{}, castType string) in
 		if v, ok := val.(time.Time); ok {
 			return v
 		}
+		if v, ok := val.(string); ok {
+			return parseDateTime(v)
+		}
 		return time.Time{}
+	case "timestamp":
+		if v, ok := val.(time.Time); ok {
+			return v.Unix()
+		}
+		return val
+	}
+
+	// "datetime:<layout>" formats times with a time package layout, e.g. "datetime:2006-01-02"
+	if layout, ok := strings.CutPrefix(castType, "datetime:"); ok {
+		if v, ok := val.(time.Time); ok {
+			return v.Format(layout)
+		}
 	}
 	return val
 }
 
+// dateTimeLayouts are the formats drivers use for datetimes they return as text,
+// such as SQLite aggregates over DATETIME columns
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseDateTime parses a datetime returned as text, or returns the zero time
+func parseDateTime(value string) time.Time {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 // Database operation methods (to be implemented with actual DB connection)
 func (m *BaseModel) performInsert() error {
-	db := DB()
-	if db == nil {
-		return fmt.Errorf("database connection not initialized")
+	_, err := m.insert(false)
+	return err
+}
+
+// insert writes the model as a new record and reports whether it was inserted. With
+// ignoreConflicts, rows conflicting with a unique index or primary key are skipped with
+// INSERT IGNORE on MySQL and ON CONFLICT DO NOTHING elsewhere.
+func (m *BaseModel) insert(ignoreConflicts bool) (bool, error) {
+	db, err := m.resolveConnection()
+	if err != nil {
+		return false, err
 	}
 
 	if m.timestamps {
@@ -813,12 +1713,18 @@ func (m *BaseModel) performInsert() error {
 		m.SetAttribute(m.createdAt, now)
 		m.SetAttribute(m.updatedAt, now)
 	}
+	m.blame(true)
+	if err := m.fillSlug(db, true, nil); err != nil {
+		return false, err
+	}
+	if err := m.fillPosition(db, nil); err != nil {
+		return false, err
+	}
 
 	// Generate ID for primary key if needed
 	if m.GetAttribute(m.primaryKey) == nil {
 		// For PostgreSQL, let the database generate the UUID
-		db := DB()
-		if db != nil && db.Driver == "postgres" {
+		if db.Driver == "postgres" {
 			// Use PostgreSQL's gen_random_uuid() function
 			var id string
 			err := db.DB.QueryRow("SELECT gen_random_uuid()").Scan(&id)
@@ -833,21 +1739,34 @@ func (m *BaseModel) performInsert() error {
 		}
 	}
 
+	attributes, err := m.insertAttributes()
+	if err != nil {
+		return false, err
+	}
+
 	// Build INSERT query
 	var columns []string
 	var values []interface{}
 	var placeholders []string
 
-	for key, value := range m.attributes {
+	for key, value := range attributes {
 		columns = append(columns, key)
 		values = append(values, value)
 		placeholders = append(placeholders, "?")
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		m.GetTable(),
+	insert := "INSERT INTO"
+	if ignoreConflicts && db.Driver == "mysql" {
+		insert = "INSERT IGNORE INTO"
+	}
+	query := fmt.Sprintf("%s %s (%s) VALUES (%s)",
+		insert,
+		db.prefixTable(m.qualifiedTable()),
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "))
+	if ignoreConflicts && db.Driver != "mysql" {
+		query += " ON CONFLICT DO NOTHING"
+	}
 
 	// Convert ? to $1, $2, etc. for PostgreSQL
 	if db.Driver == "postgres" {
@@ -856,31 +1775,43 @@ func (m *BaseModel) performInsert() error {
 		}
 	}
 
-	_, err := db.Exec(query, values...)
+	result, err := db.Exec(query, values...)
 	if err != nil {
-		return fmt.Errorf("failed to insert record: %w", err)
+		return false, fmt.Errorf("failed to insert record: %w", err)
+	}
+	if ignoreConflicts {
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return false, err
+		}
 	}
 
 	m.exists = true
 	m.wasRecentlyCreated = true
 	m.syncOriginal()
-	return nil
+	return true, nil
 }
 
 func (m *BaseModel) performUpdate() error {
-	db := DB()
-	if db == nil {
-		return fmt.Errorf("database connection not initialized")
+	db, err := m.resolveConnection()
+	if err != nil {
+		return err
 	}
 
 	// Always sync the primary key field to attributes to handle direct struct field changes
 	// This ensures that direct struct field changes (like user.ID = "new-id") are reflected in attributes
 	m.syncPrimaryKeyToAttributes()
 
+	now := time.Now()
 	if m.timestamps {
-		m.SetAttribute(m.updatedAt, time.Now())
+		m.SetAttribute(m.updatedAt, now)
+	}
+	m.blame(false)
+	if err := m.fillSlug(db, false, nil); err != nil {
+		return err
 	}
 
+	m.warnUnknownColumns()
+
 	// Build UPDATE query
 	var setParts []string
 	var values []interface{}
@@ -897,7 +1828,7 @@ func (m *BaseModel) performUpdate() error {
 	values = append(values, primaryKeyValue)
 
 	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?",
-		m.GetTable(),
+		db.prefixTable(m.qualifiedTable()),
 		strings.Join(setParts, ", "),
 		m.primaryKey)
 
@@ -910,7 +1841,7 @@ func (m *BaseModel) performUpdate() error {
 		}
 	}
 
-	result, err := db.Exec(query, values...)
+	result, err := m.execWrite(db, query, values, now)
 	if err != nil {
 		return fmt.Errorf("failed to update record: %w", err)
 	}
@@ -925,14 +1856,15 @@ func (m *BaseModel) performUpdate() error {
 		return fmt.Errorf("no rows were updated, record may not exist")
 	}
 
+	m.SyncChanges()
 	m.syncOriginal()
 	return nil
 }
 
 func (m *BaseModel) performDelete() error {
-	db := DB()
-	if db == nil {
-		return fmt.Errorf("database connection not initialized")
+	db, err := m.resolveConnection()
+	if err != nil {
+		return err
 	}
 
 	// Always sync the primary key field to attributes to handle direct struct field changes
@@ -944,14 +1876,14 @@ func (m *BaseModel) performDelete() error {
 		return fmt.Errorf("cannot delete record without primary key")
 	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", m.GetTable(), m.primaryKey)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", db.prefixTable(m.qualifiedTable()), m.primaryKey)
 
 	// Convert ? to $1 for PostgreSQL
 	if db.Driver == "postgres" {
 		query = strings.Replace(query, "?", "$1", 1)
 	}
 
-	result, err := db.Exec(query, primaryKeyValue)
+	result, err := m.execWrite(db, query, []interface{}{primaryKeyValue}, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to delete record: %w", err)
 	}
@@ -969,15 +1901,21 @@ func (m *BaseModel) performDelete() error {
 	return nil
 }
 
-func (m *BaseModel) runSoftDelete() error {
+func (m *BaseModel) runSoftDelete(ctx context.Context, reason string) error {
 	// Implementation would set deleted_at timestamp
 	m.SetAttribute(m.deletedAt, time.Now())
+	for column, value := range m.deleteMetadata(ctx, reason) {
+		m.SetAttribute(column, value)
+	}
 	return m.performUpdate()
 }
 
 func (m *BaseModel) performRestore() error {
 	// Implementation would set deleted_at to null
 	m.SetAttribute(m.deletedAt, nil)
+	for column, value := range m.restoreMetadata() {
+		m.SetAttribute(column, value)
+	}
 	return m.performUpdate()
 }
 
@@ -1004,6 +1942,14 @@ func (m *BaseModel) syncFieldsToAttributes() {
 	if m.parentModel == nil {
 		return
 	}
+	if fields, ok := m.parentModel.(FieldIterator); ok {
+		fields.EachField(func(column string, value interface{}, zero bool) {
+			if !zero || m.GetAttribute(column) != nil {
+				m.SetAttribute(column, value)
+			}
+		})
+		return
+	}
 
 	modelValue := reflect.ValueOf(m.parentModel)
 	if modelValue.Kind() == reflect.Ptr {
@@ -1025,7 +1971,7 @@ func (m *BaseModel) syncFieldsToAttributes() {
 		// Get the database column name from the db tag, or use field name
 		dbTag := fieldType.Tag.Get("db")
 		if dbTag == "" {
-			dbTag = toSnakeCase(fieldType.Name)
+			dbTag = GetNamingStrategy().ColumnName(fieldType.Name)
 		}
 
 		// Get the field value and store in attributes
@@ -1043,6 +1989,14 @@ func (m *BaseModel) syncPrimaryKeyToAttributes() {
 	if m.parentModel == nil {
 		return
 	}
+	if fields, ok := m.parentModel.(FieldIterator); ok {
+		fields.EachField(func(column string, value interface{}, zero bool) {
+			if column == m.primaryKey && !zero {
+				m.SetAttribute(column, value)
+			}
+		})
+		return
+	}
 
 	modelValue := reflect.ValueOf(m.parentModel)
 	if modelValue.Kind() == reflect.Ptr {
@@ -1064,7 +2018,7 @@ func (m *BaseModel) syncPrimaryKeyToAttributes() {
 		// Get the database column name from the db tag, or use field name
 		dbTag := fieldType.Tag.Get("db")
 		if dbTag == "" {
-			dbTag = toSnakeCase(fieldType.Name)
+			dbTag = GetNamingStrategy().ColumnName(fieldType.Name)
 		}
 
 		// Only sync the primary key field
@@ -1080,16 +2034,41 @@ func (m *BaseModel) syncPrimaryKeyToAttributes() {
 
 // Helper utility functions
 
-// toSnakeCase converts CamelCase to snake_case
-func toSnakeCase(str string) string {
-	var result strings.Builder
-	for i, r := range str {
-		if i > 0 && unicode.IsUpper(r) {
-			result.WriteRune('_')
+// outerModel returns the model embedding this BaseModel, or the BaseModel itself
+func (m *BaseModel) outerModel() Model {
+	if m.parentModel != nil {
+		return m.parentModel
+	}
+	return m
+}
+
+// baseModelOf returns the BaseModel backing a model, looking through an embedding struct if needed
+func baseModelOf(model Model) *BaseModel {
+	if bm, ok := model.(*BaseModel); ok {
+		return bm
+	}
+
+	modelValue := reflect.ValueOf(model)
+	if modelValue.Kind() == reflect.Ptr {
+		if modelValue.IsNil() {
+			return nil
+		}
+		modelValue = modelValue.Elem()
+	}
+	if modelValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < modelValue.NumField(); i++ {
+		field := modelValue.Field(i)
+		if field.Type() == reflect.TypeOf((*BaseModel)(nil)) {
+			if field.IsNil() {
+				return nil
+			}
+			return field.Interface().(*BaseModel)
 		}
-		result.WriteRune(unicode.ToLower(r))
 	}
-	return result.String()
+	return nil
 }
 
 // generateID generates a UUID-like ID for PostgreSQL compatibility
@@ -1160,17 +2139,45 @@ func NewModelStatic[T Model](factory func() T) *ModelStatic[T] {
 	}
 }
 
-// Where creates a new query with where clause (static-like)
-func (ms *ModelStatic[T]) Where(column string, args ...interface{}) *TypedModelQueryBuilder[T] {
+// WithManager returns a ModelStatic whose models are bound to the given connection manager
+func (ms *ModelStatic[T]) WithManager(cm *ConnectionManager) *ModelStatic[T] {
+	factory := ms.modelFactory
+	return &ModelStatic[T]{
+		modelFactory: func() T {
+			model := factory()
+			if bm := baseModelOf(model); bm != nil {
+				bm.manager = cm
+			}
+			return model
+		},
+	}
+}
+
+// Query starts a new typed query (static-like)
+func (ms *ModelStatic[T]) Query() *TypedModelQueryBuilder[T] {
 	model := ms.modelFactory()
-	qb := NewModelQueryBuilder(model).Where(column, args...)
 	return &TypedModelQueryBuilder[T]{
-		QueryBuilder: qb.QueryBuilder,
+		QueryBuilder: NewModelQueryBuilder(model).QueryBuilder,
 		model:        model,
 		modelFactory: ms.modelFactory,
 	}
 }
 
+// Where creates a new query with where clause (static-like)
+func (ms *ModelStatic[T]) Where(column string, args ...interface{}) *TypedModelQueryBuilder[T] {
+	return ms.Query().Where(column, args...)
+}
+
+// WithContext starts a query associated with a context (static-like), see WithQueryDefaults
+func (ms *ModelStatic[T]) WithContext(ctx context.Context) *TypedModelQueryBuilder[T] {
+	return ms.Query().WithContext(ctx)
+}
+
+// OnlyTrashed starts a query limited to soft-deleted records (static-like)
+func (ms *ModelStatic[T]) OnlyTrashed() *TypedModelQueryBuilder[T] {
+	return ms.Query().OnlyTrashed()
+}
+
 // First gets the first record (static-like) - returns the typed model directly
 func (ms *ModelStatic[T]) First() (T, error) {
 	model := ms.modelFactory()
@@ -1208,8 +2215,51 @@ func (ms *ModelStatic[T]) Find(id interface{}) (T, error) {
 	return result.(T), nil
 }
 
+// FindOr finds a record by primary key, or returns the result of fallback when none exists (static-like)
+func (ms *ModelStatic[T]) FindOr(id interface{}, fallback func() (T, error)) (T, error) {
+	return ms.Query().FindOr(id, fallback)
+}
+
+// FirstOr returns the first record, or the result of fallback when none exists (static-like)
+func (ms *ModelStatic[T]) FirstOr(fallback func() (T, error)) (T, error) {
+	return ms.Query().FirstOr(fallback)
+}
+
+// FindMany finds the models with the given primary keys (static-like)
+func (ms *ModelStatic[T]) FindMany(ids []interface{}) ([]T, error) {
+	return ms.Query().FindMany(ids)
+}
+
+// WhereKey creates a new query on the model's primary key (static-like)
+func (ms *ModelStatic[T]) WhereKey(id interface{}) *TypedModelQueryBuilder[T] {
+	return ms.Query().WhereKey(id)
+}
+
 // Create creates a new record (static-like) - returns the typed model directly
 func (ms *ModelStatic[T]) Create(attributes map[string]interface{}) (T, error) {
+	return ms.create(attributes, (*BaseModel).Save)
+}
+
+// CreateQuietly creates a new record like Create without publishing events
+func (ms *ModelStatic[T]) CreateQuietly(attributes map[string]interface{}) (T, error) {
+	return ms.create(attributes, (*BaseModel).SaveQuietly)
+}
+
+// CreateOrIgnore creates a new record unless it conflicts with a unique index or primary
+// key, for idempotent ingestion, and reports whether it was created. It inserts with
+// INSERT IGNORE on MySQL and ON CONFLICT DO NOTHING elsewhere; a model that was not
+// created holds the given attributes but is not marked as existing.
+func (ms *ModelStatic[T]) CreateOrIgnore(attributes map[string]interface{}) (T, bool, error) {
+	var created bool
+	model, err := ms.create(attributes, func(m *BaseModel) (err error) {
+		created, err = m.insertOrIgnore()
+		return err
+	})
+	return model, created, err
+}
+
+// create fills a new model with attributes and stores it with save
+func (ms *ModelStatic[T]) create(attributes map[string]interface{}, save func(*BaseModel) error) (T, error) {
 	model := ms.modelFactory()
 
 	// Use reflection to find the embedded BaseModel
@@ -1233,7 +2283,7 @@ func (ms *ModelStatic[T]) Create(attributes map[string]interface{}) (T, error) {
 		baseModel.parentModel = model
 
 		baseModel.Fill(attributes)
-		err := baseModel.Save()
+		err := save(baseModel)
 		if err != nil {
 			var zero T
 			return zero, err
@@ -1258,131 +2308,250 @@ func (ms *ModelStatic[T]) Get() ([]T, error) {
 	return ms.All()
 }
 
+// Count returns the number of records (static-like)
+func (ms *ModelStatic[T]) Count() (int64, error) {
+	return ms.Query().Count()
+}
+
+// Max returns the largest value of column with the model's casts applied (static-like)
+func (ms *ModelStatic[T]) Max(column string) (interface{}, error) {
+	return ms.Query().Max(column)
+}
+
+// Min returns the smallest value of column with the model's casts applied (static-like)
+func (ms *ModelStatic[T]) Min(column string) (interface{}, error) {
+	return ms.Query().Min(column)
+}
+
+// Sum returns the sum of column (static-like)
+func (ms *ModelStatic[T]) Sum(column string) (float64, error) {
+	return ms.Query().Sum(column)
+}
+
+// Avg returns the average of column (static-like)
+func (ms *ModelStatic[T]) Avg(column string) (float64, error) {
+	return ms.Query().Avg(column)
+}
+
+// Exists reports whether any record exists (static-like)
+func (ms *ModelStatic[T]) Exists() (bool, error) {
+	return ms.Query().Exists()
+}
+
+// DoesntExist reports whether no record exists (static-like)
+func (ms *ModelStatic[T]) DoesntExist() (bool, error) {
+	return ms.Query().DoesntExist()
+}
+
 // Methods for TypedModelQueryBuilder
 
 // First returns the first typed model instance
 func (tmqb *TypedModelQueryBuilder[T]) First() (T, error) {
-	result, err := tmqb.QueryBuilder.First()
+	var zero T
+	models, err := tmqb.stream(tmqb.QueryBuilder.Limit(1))
 	if err != nil {
-		var zero T
 		return zero, err
 	}
+	if len(models) == 0 {
+		return zero, ErrNotFound
+	}
 
-	model := tmqb.modelFactory()
-	mqb := &ModelQueryBuilder{
-		QueryBuilder: tmqb.QueryBuilder,
-		model:        model,
+	model := models[0]
+	if err := tmqb.loadEager([]Model{model}); err != nil {
+		return zero, err
 	}
-	mqb.fillModelFromMap(model, result)
 	return model, nil
 }
 
 // Get returns multiple typed model instances
 func (tmqb *TypedModelQueryBuilder[T]) Get() ([]T, error) {
-	results, err := tmqb.QueryBuilder.Get()
+	models, err := tmqb.stream(tmqb.QueryBuilder)
 	if err != nil {
 		return nil, err
 	}
 
-	var models []T
-	for _, result := range results {
-		model := tmqb.modelFactory()
-		mqb := &ModelQueryBuilder{
-			QueryBuilder: tmqb.QueryBuilder,
-			model:        model,
-		}
-		mqb.fillModelFromMap(model, result)
-		models = append(models, model)
+	loaded := make([]Model, len(models))
+	for i, model := range models {
+		loaded[i] = model
+	}
+	if err := tmqb.loadEager(loaded); err != nil {
+		return nil, err
 	}
-
 	return models, nil
 }
 
 // Find finds a typed model by primary key
 func (tmqb *TypedModelQueryBuilder[T]) Find(id interface{}) (T, error) {
-	result, err := tmqb.QueryBuilder.Find(id)
-	if err != nil {
-		var zero T
-		return zero, err
+	return tmqb.WhereKey(id).First()
+}
+
+// FindOr finds a typed model by primary key, or returns the result of fallback when none exists
+func (tmqb *TypedModelQueryBuilder[T]) FindOr(id interface{}, fallback func() (T, error)) (T, error) {
+	return tmqb.WhereKey(id).FirstOr(fallback)
+}
+
+// FirstOr returns the first typed model, or the result of fallback when none exists.
+// Errors other than ErrNotFound are returned without calling fallback.
+func (tmqb *TypedModelQueryBuilder[T]) FirstOr(fallback func() (T, error)) (T, error) {
+	model, err := tmqb.First()
+	if errors.Is(err, ErrNotFound) {
+		return fallback()
 	}
+	return model, err
+}
 
-	model := tmqb.modelFactory()
-	mqb := &ModelQueryBuilder{
-		QueryBuilder: tmqb.QueryBuilder,
-		model:        model,
+// FindMany finds the typed models with the given primary keys
+func (tmqb *TypedModelQueryBuilder[T]) FindMany(ids []interface{}) ([]T, error) {
+	if len(ids) == 0 {
+		return nil, nil
 	}
-	mqb.fillModelFromMap(model, result)
-	return model, nil
+	return tmqb.WhereKey(ids).Get()
+}
+
+// WhereKey adds a where clause on the model's primary key and returns TypedModelQueryBuilder
+func (tmqb *TypedModelQueryBuilder[T]) WhereKey(id interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().WhereKey(id).QueryBuilder)
+}
+
+// WhereKeyNot excludes the given primary keys and returns TypedModelQueryBuilder
+func (tmqb *TypedModelQueryBuilder[T]) WhereKeyNot(id interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().WhereKeyNot(id).QueryBuilder)
 }
 
 // Where adds a where clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) Where(column string, args ...interface{}) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.Where(column, args...)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.Where(column, args...))
 }
 
 // OrWhere adds an OR where clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) OrWhere(column string, args ...interface{}) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.OrWhere(column, args...)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.OrWhere(column, args...))
 }
 
 // WhereIn adds a where in clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) WhereIn(column string, values []interface{}) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.WhereIn(column, values)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.WhereIn(column, values))
 }
 
 // WhereNotIn adds a where not in clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) WhereNotIn(column string, values []interface{}) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.WhereNotIn(column, values)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.WhereNotIn(column, values))
+}
+
+// WhereNamed adds a raw where clause with named parameters and returns TypedModelQueryBuilder
+func (tmqb *TypedModelQueryBuilder[T]) WhereNamed(sql string, arg interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.WhereNamed(sql, arg))
 }
 
 // WhereNull adds a where null clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) WhereNull(column string) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.WhereNull(column)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.WhereNull(column))
 }
 
 // WhereNotNull adds a where not null clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) WhereNotNull(column string) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.WhereNotNull(column)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.WhereNotNull(column))
 }
 
 // OrderBy adds an order by clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) OrderBy(column, direction string) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.OrderBy(column, direction)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.OrderBy(column, direction))
 }
 
 // OrderByDesc adds an order by desc clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) OrderByDesc(column string) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.OrderByDesc(column)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.OrderByDesc(column))
 }
 
 // Limit adds a limit clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) Limit(limit int) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.Limit(limit)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.Limit(limit))
 }
 
 // Take adds a limit clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) Take(limit int) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.Take(limit)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.Take(limit))
 }
 
 // Offset adds an offset clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) Offset(offset int) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.Offset(offset)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.Offset(offset))
 }
 
 // Skip adds an offset clause and returns TypedModelQueryBuilder
 func (tmqb *TypedModelQueryBuilder[T]) Skip(offset int) *TypedModelQueryBuilder[T] {
-	tmqb.QueryBuilder.Skip(offset)
-	return tmqb
+	return tmqb.wrap(tmqb.QueryBuilder.Skip(offset))
+}
+
+// Count returns the number of models matching the query
+func (tmqb *TypedModelQueryBuilder[T]) Count(columns ...string) (int64, error) {
+	return tmqb.QueryBuilder.Count(columns...)
+}
+
+// Exists reports whether any model matches the query
+func (tmqb *TypedModelQueryBuilder[T]) Exists() (bool, error) {
+	return tmqb.QueryBuilder.Exists()
+}
+
+// DoesntExist reports whether no model matches the query
+func (tmqb *TypedModelQueryBuilder[T]) DoesntExist() (bool, error) {
+	return tmqb.QueryBuilder.DoesntExist()
+}
+
+// Max returns the largest value of column with the model's casts applied, see ModelQueryBuilder.Max
+func (tmqb *TypedModelQueryBuilder[T]) Max(column string) (interface{}, error) {
+	return tmqb.modelQuery().Max(column)
+}
+
+// Min returns the smallest value of column with the model's casts applied, see ModelQueryBuilder.Min
+func (tmqb *TypedModelQueryBuilder[T]) Min(column string) (interface{}, error) {
+	return tmqb.modelQuery().Min(column)
+}
+
+// UpdateModels updates every matching record through its model, see ModelQueryBuilder.UpdateModels
+func (tmqb *TypedModelQueryBuilder[T]) UpdateModels(attributes map[string]interface{}) (int64, error) {
+	return tmqb.modelQuery().UpdateModels(attributes)
+}
+
+// Delete deletes every matching record, see ModelQueryBuilder.Delete
+func (tmqb *TypedModelQueryBuilder[T]) Delete() (int64, error) {
+	return tmqb.modelQuery().Delete()
+}
+
+// DeleteWithReason deletes every matching record, see ModelQueryBuilder.DeleteWithReason
+func (tmqb *TypedModelQueryBuilder[T]) DeleteWithReason(reason string) (int64, error) {
+	return tmqb.modelQuery().DeleteWithReason(reason)
+}
+
+// ForceDelete permanently deletes every matching record, see ModelQueryBuilder.ForceDelete
+func (tmqb *TypedModelQueryBuilder[T]) ForceDelete() (int64, error) {
+	return tmqb.modelQuery().ForceDelete()
+}
+
+// Connection runs the query on the named connection and returns TypedModelQueryBuilder
+func (tmqb *TypedModelQueryBuilder[T]) Connection(name string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.Connection(name))
+}
+
+// WithContext associates the query with a context and returns TypedModelQueryBuilder
+func (tmqb *TypedModelQueryBuilder[T]) WithContext(ctx context.Context) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.WithContext(ctx))
+}
+
+// OnlyTrashed limits the query to soft-deleted records and returns TypedModelQueryBuilder
+func (tmqb *TypedModelQueryBuilder[T]) OnlyTrashed() *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().OnlyTrashed().QueryBuilder)
+}
+
+// Restore restores every matching soft-deleted record, see ModelQueryBuilder.Restore
+func (tmqb *TypedModelQueryBuilder[T]) Restore() (int64, error) {
+	return tmqb.modelQuery().Restore()
+}
+
+// modelQuery returns an untyped model builder sharing this builder's query
+func (tmqb *TypedModelQueryBuilder[T]) modelQuery() *ModelQueryBuilder {
+	return &ModelQueryBuilder{
+		QueryBuilder: tmqb.QueryBuilder,
+		model:        tmqb.model,
+	}
 }
@@ -0,0 +1,50 @@
+package eloquent
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCreateOrIgnoreAndQuietly(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT UNIQUE, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	events := FakeEvents(t)
+	customers := NewModelStatic(func() *CustomerModel { return newEventCustomer(conn, nil) })
+
+	ada, created, err := customers.CreateOrIgnore(map[string]interface{}{"name": "Ada"})
+	if err != nil || !created {
+		t.Fatalf("Expected the customer to be created, got %v, %v", created, err)
+	}
+	if !ada.Exists() || !ada.WasRecentlyCreated() {
+		t.Error("Expected the created customer to exist")
+	}
+
+	duplicate, created, err := customers.CreateOrIgnore(map[string]interface{}{"name": "Ada"})
+	if err != nil || created {
+		t.Fatalf("Expected the duplicate to be ignored, got %v, %v", created, err)
+	}
+	if duplicate.Exists() || duplicate.GetAttribute("name") != "Ada" {
+		t.Error("Expected the ignored customer to keep its attributes without existing")
+	}
+	events.AssertDispatched("customers.created", 1)
+
+	grace, err := customers.CreateQuietly(map[string]interface{}{"name": "Grace"})
+	if err != nil || !grace.Exists() {
+		t.Fatalf("Failed to create quietly: %v", err)
+	}
+	grace.SetAttribute("name", "Grace Hopper")
+	if err := grace.SaveQuietly(); err != nil {
+		t.Fatalf("Failed to save quietly: %v", err)
+	}
+	events.AssertDispatched("customers.created", 1)
+	events.AssertNotDispatched("customers.updated")
+
+	if count, err := customers.Count(); err != nil || count != 2 {
+		t.Errorf("Expected 2 customers, got %d, %v", count, err)
+	}
+	if _, err := customers.Create(map[string]interface{}{"name": "Ada"}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Expected Create to fail with ErrDuplicateKey, got %v", err)
+	}
+}
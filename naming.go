@@ -0,0 +1,105 @@
+package eloquent
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NamingStrategy maps Go names to database names. It is used to infer table names from
+// model structs, columns from struct fields without a db tag, and relationship keys.
+type NamingStrategy interface {
+	// TableName returns the table for a model struct name
+	TableName(typeName string) string
+	// ColumnName returns the column for a struct field name
+	ColumnName(fieldName string) string
+	// ForeignKey returns the column referencing a model, given its singular snake_case name
+	ForeignKey(model string) string
+}
+
+// DefaultNamingStrategy follows the Laravel conventions of plural snake_case tables,
+// snake_case columns and <name>_id foreign keys. Its options cover schemas that don't.
+type DefaultNamingStrategy struct {
+	// SingularTables infers customer instead of customers for CustomerModel
+	SingularTables bool
+	// CamelCaseColumns maps EmailVerifiedAt to emailVerifiedAt instead of email_verified_at
+	CamelCaseColumns bool
+	// ForeignKeySuffix replaces the _id (or Id for camelCase columns) suffix of foreign keys
+	ForeignKeySuffix string
+	// PluralForeignKeys restores the table-based keys (users_id) inferred by earlier versions
+	PluralForeignKeys bool
+}
+
+// TableName returns the table for a model struct name
+func (s DefaultNamingStrategy) TableName(typeName string) string {
+	if s.SingularTables {
+		return toSnakeCase(trimModelSuffix(typeName))
+	}
+	return tableName(typeName)
+}
+
+// ColumnName returns the column for a struct field name
+func (s DefaultNamingStrategy) ColumnName(fieldName string) string {
+	if s.CamelCaseColumns {
+		return toCamelCase(fieldName)
+	}
+	return toSnakeCase(fieldName)
+}
+
+// ForeignKey returns the column referencing a model, given its singular snake_case name
+func (s DefaultNamingStrategy) ForeignKey(model string) string {
+	if s.PluralForeignKeys {
+		model = pluralize(model)
+	}
+
+	suffix := s.ForeignKeySuffix
+	if suffix == "" {
+		suffix = "_id"
+		if s.CamelCaseColumns {
+			suffix = "Id"
+		}
+	}
+	return s.ColumnName(model) + suffix
+}
+
+var (
+	naming   NamingStrategy = DefaultNamingStrategy{}
+	namingMu sync.RWMutex
+)
+
+// SetNamingStrategy sets the strategy used to infer table, column and key names.
+// Passing nil restores the default Laravel conventions.
+func SetNamingStrategy(strategy NamingStrategy) {
+	if strategy == nil {
+		strategy = DefaultNamingStrategy{}
+	}
+
+	namingMu.Lock()
+	defer namingMu.Unlock()
+	naming = strategy
+	forgetFieldPlans()
+}
+
+// GetNamingStrategy returns the current naming strategy
+func GetNamingStrategy() NamingStrategy {
+	namingMu.RLock()
+	defer namingMu.RUnlock()
+	return naming
+}
+
+// toCamelCase converts CamelCase or snake_case to camelCase (EmailVerifiedAt → emailVerifiedAt)
+func toCamelCase(str string) string {
+	var result strings.Builder
+	for _, word := range strings.Split(toSnakeCase(str), "_") {
+		if word == "" {
+			continue
+		}
+		if result.Len() > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		result.WriteString(word)
+	}
+	return result.String()
+}
@@ -0,0 +1,56 @@
+package eloquent
+
+import "testing"
+
+func TestDefaultNamingStrategy(t *testing.T) {
+	laravel := DefaultNamingStrategy{}
+	if got := laravel.TableName("CustomerModel"); got != "customers" {
+		t.Errorf("Expected customers, got %q", got)
+	}
+	if got := laravel.ColumnName("EmailVerifiedAt"); got != "email_verified_at" {
+		t.Errorf("Expected email_verified_at, got %q", got)
+	}
+	if got := laravel.ForeignKey("users"); got != "users_id" {
+		t.Errorf("Expected users_id, got %q", got)
+	}
+
+	custom := DefaultNamingStrategy{SingularTables: true, CamelCaseColumns: true}
+	if got := custom.TableName("OrderStatusModel"); got != "order_status" {
+		t.Errorf("Expected order_status, got %q", got)
+	}
+	if got := custom.ColumnName("EmailVerifiedAt"); got != "emailVerifiedAt" {
+		t.Errorf("Expected emailVerifiedAt, got %q", got)
+	}
+	if got := custom.ColumnName("UserID"); got != "userId" {
+		t.Errorf("Expected userId, got %q", got)
+	}
+	if got := custom.ForeignKey("customer"); got != "customerId" {
+		t.Errorf("Expected customerId, got %q", got)
+	}
+
+	suffixed := DefaultNamingStrategy{ForeignKeySuffix: "_fk"}
+	if got := suffixed.ForeignKey("Customer"); got != "customer_fk" {
+		t.Errorf("Expected customer_fk, got %q", got)
+	}
+}
+
+func TestSetNamingStrategy(t *testing.T) {
+	SetNamingStrategy(DefaultNamingStrategy{SingularTables: true, CamelCaseColumns: true})
+	defer SetNamingStrategy(nil)
+
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.SetParentModel(customer)
+	if got := customer.GetTable(); got != "customer" {
+		t.Errorf("Expected singular table customer, got %q", got)
+	}
+
+	relationship := NewRelationshipBuilder(customer).HasMany("orders", "OrderModel")
+	if relationship.ForeignKey != "customerId" {
+		t.Errorf("Expected foreign key customerId, got %q", relationship.ForeignKey)
+	}
+
+	SetNamingStrategy(nil)
+	if _, ok := GetNamingStrategy().(DefaultNamingStrategy); !ok {
+		t.Errorf("Expected nil to restore the default strategy, got %T", GetNamingStrategy())
+	}
+}
@@ -0,0 +1,141 @@
+package eloquent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlterRunner applies an ALTER TABLE to a MySQL table with an online schema change tool
+// such as gh-ost or pt-online-schema-change, which copy the table in the background
+// instead of locking it. alter holds the clauses after the table name, the form both
+// tools take in their --alter option:
+//
+//	ghost := func(table, alter string) error {
+//		return exec.Command("gh-ost", "--table="+table, "--alter="+alter, "--execute").Run()
+//	}
+//	err := eloquent.Schema().Table("orders", func(t *eloquent.Blueprint) {
+//		t.Using(ghost)
+//		t.String("coupon").Nullable()
+//	})
+type AlterRunner func(table, alter string) error
+
+// mysqlAlgorithms and mysqlLocks are the values accepted by Algorithm and Lock
+var (
+	mysqlAlgorithms = map[string]bool{"DEFAULT": true, "INSTANT": true, "INPLACE": true, "COPY": true}
+	mysqlLocks      = map[string]bool{"DEFAULT": true, "NONE": true, "SHARED": true, "EXCLUSIVE": true}
+)
+
+// Online asks MySQL to change the table in place without blocking reads or writes, the
+// same as Algorithm("INPLACE") and Lock("NONE"). MySQL refuses changes it cannot make
+// that way instead of falling back to locking the table.
+func (b *Blueprint) Online() *Blueprint {
+	return b.Algorithm("INPLACE").Lock("NONE")
+}
+
+// Algorithm sets the ALGORITHM of a MySQL table change: "INSTANT", "INPLACE", "COPY" or
+// "DEFAULT". Once an algorithm, lock or runner is set, the changes of Schema().Table are
+// made in a single ALTER TABLE on MySQL, so the table is rebuilt at most once. The
+// setting is ignored when creating tables and on other databases.
+func (b *Blueprint) Algorithm(algorithm string) *Blueprint {
+	algorithm = strings.ToUpper(algorithm)
+	if !mysqlAlgorithms[algorithm] && b.err == nil {
+		b.err = fmt.Errorf("%w: unknown ALTER TABLE algorithm %q", ErrInvalidQuery, algorithm)
+	}
+	b.algorithm = algorithm
+	return b
+}
+
+// Lock sets the LOCK of a MySQL table change: "NONE", "SHARED", "EXCLUSIVE" or "DEFAULT",
+// see Algorithm
+func (b *Blueprint) Lock(lock string) *Blueprint {
+	lock = strings.ToUpper(lock)
+	if !mysqlLocks[lock] && b.err == nil {
+		b.err = fmt.Errorf("%w: unknown ALTER TABLE lock %q", ErrInvalidQuery, lock)
+	}
+	b.lock = lock
+	return b
+}
+
+// Using hands the changes of a MySQL table to runner instead of running them on the
+// connection, see AlterRunner. Algorithm and Lock are not passed to the runner.
+func (b *Blueprint) Using(runner AlterRunner) *Blueprint {
+	b.runner = runner
+	return b
+}
+
+// online reports whether the blueprint changes a MySQL table in a single ALTER TABLE
+func (b *Blueprint) online(driver string) bool {
+	return driver == "mysql" && !b.creating && (b.algorithm != "" || b.lock != "" || b.runner != nil)
+}
+
+// hints returns the ALGORITHM and LOCK clauses of an online change
+func (b *Blueprint) hints() []string {
+	var hints []string
+	if b.algorithm != "" {
+		hints = append(hints, "ALGORITHM="+b.algorithm)
+	}
+	if b.lock != "" {
+		hints = append(hints, "LOCK="+b.lock)
+	}
+	return hints
+}
+
+// alterClauses returns the blueprint's changes as clauses of one MySQL ALTER TABLE
+func (b *Blueprint) alterClauses(constraints []string) []string {
+	var clauses []string
+	for _, drop := range b.dropConstraints {
+		clauses = append(clauses, drop.clause("mysql"))
+	}
+	for _, name := range b.dropIndexes {
+		clauses = append(clauses, "DROP INDEX "+name)
+	}
+	for _, column := range b.columns {
+		clauses = append(clauses, "ADD COLUMN "+column.compile("mysql"))
+	}
+	for _, constraint := range constraints {
+		clauses = append(clauses, "ADD "+constraint)
+	}
+	for _, index := range b.indexes {
+		if index.kind != indexPrimary {
+			clauses = append(clauses, index.alterClause())
+		}
+	}
+	return clauses
+}
+
+// alterClause returns the ALTER TABLE clause adding the index on MySQL
+func (i *IndexDefinition) alterClause() string {
+	kind := ""
+	switch i.kind {
+	case indexUnique:
+		kind = "UNIQUE "
+	case indexFullText:
+		kind = "FULLTEXT "
+	case indexSpatial:
+		kind = "SPATIAL "
+	}
+	return fmt.Sprintf("ADD %sINDEX %s (%s)", kind, i.name, strings.Join(i.columns, ", "))
+}
+
+// runAlter hands a blueprint's changes to its runner
+func (sb *SchemaBuilder) runAlter(blueprint *Blueprint) error {
+	if blueprint.err != nil {
+		return blueprint.err
+	}
+	if err := validateColumn(blueprint.table); err != nil {
+		return err
+	}
+	if sb.connection.ReadOnly {
+		return ErrReadOnly
+	}
+
+	constraints, err := blueprint.tableConstraints(sb.connection)
+	if err != nil {
+		return err
+	}
+	clauses := blueprint.alterClauses(constraints)
+	if len(clauses) == 0 {
+		return nil
+	}
+	return blueprint.runner(sb.connection.prefixTable(blueprint.table), strings.Join(clauses, ", "))
+}
@@ -0,0 +1,300 @@
+package eloquent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	stableOrdering   bool
+	stableOrderingMu sync.RWMutex
+)
+
+// SetStableOrdering makes model queries order by their primary key after any other
+// order, so rows that tie on the requested order keep the same order from one query to
+// the next and pages neither repeat nor skip them. Paginated model queries without an
+// order are ordered by the primary key. Plain table queries opt in with Tiebreaker.
+func SetStableOrdering(enabled bool) {
+	stableOrderingMu.Lock()
+	defer stableOrderingMu.Unlock()
+	stableOrdering = enabled
+}
+
+// GetStableOrdering reports whether model queries break order ties by primary key
+func GetStableOrdering() bool {
+	stableOrderingMu.RLock()
+	defer stableOrderingMu.RUnlock()
+	return stableOrdering
+}
+
+// Tiebreaker orders the query by a unique column, usually the primary key, after its
+// other orders, in the direction of the last one. It is added when the query is ordered
+// or has an offset, unless the column is already ordered or the query is grouped.
+func (qb *QueryBuilder) Tiebreaker(column string) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
+	qb.tiebreaker = column
+	return qb
+}
+
+// effectiveOrders returns the query's orders, or its default orders if it has none,
+// followed by its tiebreaker if it applies
+func (qb *QueryBuilder) effectiveOrders() []OrderClause {
+	orders := qb.orders
+	if len(orders) == 0 {
+		orders = qb.defaultOrders
+	}
+	if qb.tiebreaker == "" || len(qb.groups) > 0 || (len(orders) == 0 && qb.offsetValue == nil) {
+		return orders
+	}
+
+	column := qb.tiebreaker
+	if len(qb.joins) > 0 && !strings.Contains(column, ".") {
+		table := qb.table
+		if qb.alias != "" {
+			table = qb.alias
+		}
+		column = table + "." + column
+	}
+	for _, order := range orders {
+		if order.Column == column || order.Column == qb.tiebreaker {
+			return orders
+		}
+	}
+
+	direction := "asc"
+	if len(orders) > 0 {
+		direction = orders[len(orders)-1].Direction
+	}
+	return append(orders[:len(orders):len(orders)], OrderClause{Column: column, Direction: direction})
+}
+
+// OrderByNullsFirst orders by column, "asc" unless a direction is given, with NULLs
+// before other values
+func (qb *QueryBuilder) OrderByNullsFirst(column string, direction ...string) *QueryBuilder {
+	return qb.addNullsOrder(column, direction, "first")
+}
+
+// OrderByNullsLast orders by column, "asc" unless a direction is given, with NULLs after
+// other values
+func (qb *QueryBuilder) OrderByNullsLast(column string, direction ...string) *QueryBuilder {
+	return qb.addNullsOrder(column, direction, "last")
+}
+
+// addNullsOrder adds an order placing NULLs first or last
+func (qb *QueryBuilder) addNullsOrder(column string, direction []string, nulls string) *QueryBuilder {
+	qb = qb.mutable()
+	order, err := newOrderClause(column, strings.Join(direction, " "))
+	if err != nil {
+		return qb.fail(err)
+	}
+	order.Nulls = nulls
+	qb.orders = append(qb.orders, order)
+	return qb
+}
+
+// OrderByRaw orders by a raw expression with ? bindings, such as
+// OrderByRaw("ABS(score - ?) ASC", target). Never build the expression from user input.
+func (qb *QueryBuilder) OrderByRaw(sql string, bindings ...interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	qb.orders = append(qb.orders, OrderClause{Column: sql, Direction: "asc", Type: "raw", Values: bindings})
+	return qb
+}
+
+// OrderByField orders rows by the position of column's value in values, with rows whose
+// value is not listed last, for custom orders such as statuses in workflow order:
+//
+//	q.OrderByField("status", "draft", "review", "published")
+//
+// MySQL compiles it to FIELD and other databases to a CASE expression.
+func (qb *QueryBuilder) OrderByField(column string, values ...interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
+	if len(values) == 0 {
+		return qb.fail(fmt.Errorf("%w: order by field %s has no values", ErrInvalidQuery, column))
+	}
+	qb.orders = append(qb.orders, OrderClause{Column: column, Direction: "asc", Type: "field", Values: values})
+	return qb
+}
+
+// compileOrder writes an order to sql and returns its arguments
+func (qb *QueryBuilder) compileOrder(sql *strings.Builder, order OrderClause, getPlaceholder func() string) []interface{} {
+	mysql := qb.connection != nil && qb.connection.Driver == "mysql"
+
+	switch order.Type {
+	case "raw":
+		writeRaw(sql, order.Column, getPlaceholder)
+		return order.Values
+	case "field":
+		if mysql {
+			// FIELD is 0 for values not listed, so list the values in reverse and sort
+			// descending to put those rows last
+			sql.WriteString("FIELD(")
+			sql.WriteString(order.Column)
+			args := make([]interface{}, len(order.Values))
+			for i := range order.Values {
+				sql.WriteString(", ")
+				sql.WriteString(getPlaceholder())
+				args[i] = order.Values[len(order.Values)-1-i]
+			}
+			sql.WriteString(") DESC")
+			return args
+		}
+		sql.WriteString("CASE ")
+		sql.WriteString(order.Column)
+		for i := range order.Values {
+			sql.WriteString(" WHEN ")
+			sql.WriteString(getPlaceholder())
+			sql.WriteString(" THEN ")
+			sql.WriteString(strconv.Itoa(i))
+		}
+		sql.WriteString(" ELSE ")
+		sql.WriteString(strconv.Itoa(len(order.Values)))
+		sql.WriteString(" END")
+		return order.Values
+	}
+
+	if order.Nulls != "" && mysql {
+		// MySQL has no NULLS FIRST or LAST and sorts NULLs as the smallest values
+		sql.WriteString(order.Column)
+		if order.Nulls == "first" {
+			sql.WriteString(" IS NULL DESC, ")
+		} else {
+			sql.WriteString(" IS NULL ASC, ")
+		}
+	}
+	sql.WriteString(order.Column)
+	sql.WriteString(" ")
+	sql.WriteString(sqlKeyword(order.Direction))
+	if order.Nulls != "" && !mysql {
+		sql.WriteString(" NULLS ")
+		sql.WriteString(sqlKeyword(order.Nulls))
+	}
+	return nil
+}
+
+// DefaultOrder orders the model's queries by column when they set no order of their own,
+// for example DefaultOrder("created_at", "desc"). Calls add further columns. Aggregates
+// and counts ignore it.
+func (m *BaseModel) DefaultOrder(column, direction string) *BaseModel {
+	m.defaultOrders = append(m.defaultOrders, defaultOrder{column: column, direction: direction})
+	return m
+}
+
+// defaultOrder is an order configured with DefaultOrder, validated when a query uses it
+type defaultOrder struct {
+	column    string
+	direction string
+}
+
+// applyDefaultOrders sets the model's default orders on a new query
+func (qb *QueryBuilder) applyDefaultOrders(orders []defaultOrder) *QueryBuilder {
+	for _, configured := range orders {
+		order, err := newOrderClause(configured.column, configured.direction)
+		if err != nil {
+			return qb.fail(err)
+		}
+		qb.defaultOrders = append(qb.defaultOrders, order)
+	}
+	return qb
+}
+
+// latestColumn returns the column Latest and Oldest order by: the given one, or the
+// model's created at column
+func (mqb *ModelQueryBuilder) latestColumn(column []string) string {
+	if len(column) > 0 {
+		return column[0]
+	}
+	return mqb.model.GetCreatedAtColumn()
+}
+
+// Latest orders by the model's created at column, or the given column, newest first
+func (mqb *ModelQueryBuilder) Latest(column ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrderByDesc(mqb.latestColumn(column)))
+}
+
+// Oldest orders by the model's created at column, or the given column, oldest first
+func (mqb *ModelQueryBuilder) Oldest(column ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrderBy(mqb.latestColumn(column), "asc"))
+}
+
+// Latest orders newest first, see ModelQueryBuilder.Latest
+func (tmqb *TypedModelQueryBuilder[T]) Latest(column ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().Latest(column...).QueryBuilder)
+}
+
+// Oldest orders oldest first, see ModelQueryBuilder.Oldest
+func (tmqb *TypedModelQueryBuilder[T]) Oldest(column ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().Oldest(column...).QueryBuilder)
+}
+
+// OrderBy starts a query with an order by clause (static-like)
+func (ms *ModelStatic[T]) OrderBy(column, direction string) *TypedModelQueryBuilder[T] {
+	return ms.Query().OrderBy(column, direction)
+}
+
+// OrderByDesc starts a query with an order by desc clause (static-like)
+func (ms *ModelStatic[T]) OrderByDesc(column string) *TypedModelQueryBuilder[T] {
+	return ms.Query().OrderByDesc(column)
+}
+
+// Latest starts a query ordered newest first (static-like)
+func (ms *ModelStatic[T]) Latest(column ...string) *TypedModelQueryBuilder[T] {
+	return ms.Query().Latest(column...)
+}
+
+// Oldest starts a query ordered oldest first (static-like)
+func (ms *ModelStatic[T]) Oldest(column ...string) *TypedModelQueryBuilder[T] {
+	return ms.Query().Oldest(column...)
+}
+
+// OrderByNullsFirst orders with NULLs first, see QueryBuilder.OrderByNullsFirst
+func (mqb *ModelQueryBuilder) OrderByNullsFirst(column string, direction ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrderByNullsFirst(column, direction...))
+}
+
+// OrderByNullsLast orders with NULLs last, see QueryBuilder.OrderByNullsLast
+func (mqb *ModelQueryBuilder) OrderByNullsLast(column string, direction ...string) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrderByNullsLast(column, direction...))
+}
+
+// OrderByRaw orders by a raw expression with ? bindings
+func (mqb *ModelQueryBuilder) OrderByRaw(sql string, bindings ...interface{}) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrderByRaw(sql, bindings...))
+}
+
+// OrderByField orders by the position of column's value in values, see QueryBuilder.OrderByField
+func (mqb *ModelQueryBuilder) OrderByField(column string, values ...interface{}) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrderByField(column, values...))
+}
+
+// OrderByNullsFirst orders with NULLs first, see QueryBuilder.OrderByNullsFirst
+func (tmqb *TypedModelQueryBuilder[T]) OrderByNullsFirst(column string, direction ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.OrderByNullsFirst(column, direction...))
+}
+
+// OrderByNullsLast orders with NULLs last, see QueryBuilder.OrderByNullsLast
+func (tmqb *TypedModelQueryBuilder[T]) OrderByNullsLast(column string, direction ...string) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.OrderByNullsLast(column, direction...))
+}
+
+// OrderByRaw orders by a raw expression with ? bindings
+func (tmqb *TypedModelQueryBuilder[T]) OrderByRaw(sql string, bindings ...interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.OrderByRaw(sql, bindings...))
+}
+
+// OrderByField orders by the position of column's value in values, see QueryBuilder.OrderByField
+func (tmqb *TypedModelQueryBuilder[T]) OrderByField(column string, values ...interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.OrderByField(column, values...))
+}
+
+// OrderByField starts a query ordered by the position of column's value in values
+func (ms *ModelStatic[T]) OrderByField(column string, values ...interface{}) *TypedModelQueryBuilder[T] {
+	return ms.Query().OrderByField(column, values...)
+}
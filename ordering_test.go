@@ -0,0 +1,137 @@
+package eloquent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestModelOrdering(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i, name := range []string{"Ada", "Grace", "Barbara"} {
+		customer := newEventCustomer(conn, map[string]interface{}{"name": name})
+		customer.SetAttribute("id", string(rune('a'+i)))
+		customer.SetAttribute("created_at", "2024-01-0"+string(rune('1'+i))+" 00:00:00")
+		if err := customer.Save(); err != nil {
+			t.Fatalf("Failed to save customer: %v", err)
+		}
+	}
+
+	customers := NewModelStatic(func() *CustomerModel {
+		customer := newEventCustomer(conn, nil)
+		customer.DefaultOrder("name", "asc")
+		return customer
+	})
+	names := func(query *TypedModelQueryBuilder[*CustomerModel]) []interface{} {
+		t.Helper()
+		found, err := query.Get()
+		if err != nil {
+			t.Fatalf("Failed to query customers: %v", err)
+		}
+		names := make([]interface{}, len(found))
+		for i, customer := range found {
+			names[i] = customer.GetAttribute("name")
+		}
+		return names
+	}
+
+	tests := []struct {
+		query *TypedModelQueryBuilder[*CustomerModel]
+		want  string
+	}{
+		{customers.Query(), "[Ada Barbara Grace]"},
+		{customers.Latest().Limit(2), "[Barbara Grace]"},
+		{customers.Oldest(), "[Ada Grace Barbara]"},
+		{customers.Where("name", "!=", "Ada").Latest("name"), "[Grace Barbara]"},
+		{customers.OrderByDesc("id"), "[Barbara Grace Ada]"},
+	}
+	for _, test := range tests {
+		if got := names(test.query); fmt.Sprint(got) != test.want {
+			t.Errorf("Expected %s, got %v", test.want, got)
+		}
+	}
+
+	if count, err := customers.Query().Count(); err != nil || count != 3 {
+		t.Errorf("Expected counts to ignore the default order, got %d, %v", count, err)
+	}
+
+	invalid := NewModelStatic(func() *CustomerModel {
+		customer := newEventCustomer(conn, nil)
+		customer.DefaultOrder("name", "sideways")
+		return customer
+	})
+	if _, err := invalid.Query().Get(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an invalid default order, got %v", err)
+	}
+}
+
+func TestExpressionOrdering(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE tickets (id INTEGER PRIMARY KEY, status TEXT, due DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	_, err := conn.Exec(`INSERT INTO tickets (id, status, due) VALUES
+		(1, 'closed', '2024-01-03'), (2, 'open', NULL), (3, 'review', '2024-01-01'), (4, 'spam', '2024-01-02')`)
+	if err != nil {
+		t.Fatalf("Failed to insert tickets: %v", err)
+	}
+
+	ids := func(query *QueryBuilder) string {
+		t.Helper()
+		rows, err := query.Get()
+		if err != nil {
+			t.Fatalf("Failed to query tickets: %v", err)
+		}
+		ids := make([]interface{}, len(rows))
+		for i, row := range rows {
+			ids[i] = row["id"]
+		}
+		return fmt.Sprint(ids)
+	}
+
+	tickets := func() *QueryBuilder { return NewQueryBuilder(conn).Table("tickets").Select("id") }
+	tests := []struct {
+		query *QueryBuilder
+		want  string
+	}{
+		{tickets().OrderByNullsLast("due"), "[3 4 1 2]"},
+		{tickets().OrderByNullsFirst("due", "desc"), "[2 1 4 3]"},
+		{tickets().OrderByField("status", "open", "review", "closed"), "[2 3 1 4]"},
+		{tickets().OrderByRaw("ABS(id - ?) ASC", 3).OrderBy("id", "asc"), "[3 2 4 1]"},
+	}
+	for _, test := range tests {
+		if got := ids(test.query); got != test.want {
+			t.Errorf("Expected %s, got %s", test.want, got)
+		}
+	}
+
+	mysql := NewQueryBuilder(&Connection{Driver: "mysql"}).Table("tickets").
+		OrderByField("status", "open", "review").
+		OrderByNullsLast("due", "desc")
+	sql, args := mysql.ToSQL()
+	if sql != "SELECT * FROM tickets ORDER BY FIELD(status, ?, ?) DESC, due IS NULL ASC, due DESC" || fmt.Sprint(args) != "[review open]" {
+		t.Errorf("Unexpected MySQL ordering: %s %v", sql, args)
+	}
+
+	postgres := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("tickets").
+		Where("id", ">", 0).
+		OrderByField("status", "open", "review").
+		OrderByNullsFirst("due")
+	sql, args = postgres.ToSQL()
+	if sql != "SELECT * FROM tickets WHERE id > $1 ORDER BY CASE status WHEN $2 THEN 0 WHEN $3 THEN 1 ELSE 2 END, due ASC NULLS FIRST" || len(args) != 3 {
+		t.Errorf("Unexpected PostgreSQL ordering: %s %v", sql, args)
+	}
+
+	for _, query := range []*QueryBuilder{
+		tickets().OrderByField("status"),
+		tickets().OrderByField("status; --", "open"),
+		tickets().OrderByNullsLast("due", "sideways"),
+	} {
+		if err := query.Err(); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery, got %v", err)
+		}
+	}
+}
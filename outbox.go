@@ -0,0 +1,191 @@
+package eloquent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Outbox stores events in a table instead of sending them, the transactional outbox
+// pattern: events are written in the transaction that changes the data, so they cannot be
+// lost or delivered for changes that rolled back, and a relay delivers them afterwards.
+//
+//	outbox := eloquent.NewOutbox(eloquent.DB(), "event_outbox")
+//	eloquent.SetEventPublisher(outbox) // store model events
+//
+//	uow.PublishOnCommit("order.placed", order) // store application events
+//
+//	go outbox.Run(ctx, deliver, time.Second, 100) // in a background worker
+//
+// As an EventPublisher, events from SaveAll and UnitOfWork are stored in their transaction
+// and events from single model writes right after the write.
+type Outbox struct {
+	conn  *Connection
+	table string
+}
+
+// OutboxMessage is an event stored in an outbox. Model events are stored under
+// "<table>.<type>", such as "orders.created", with the ModelEvent as payload.
+type OutboxMessage struct {
+	ID        int64
+	Event     string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// Decode unmarshals the message's JSON payload into v
+func (m OutboxMessage) Decode(v interface{}) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+// OutboxHandler delivers relayed messages, for example to NATS, Kafka or AMQP.
+// Returning an error leaves the messages in the outbox to be delivered again.
+type OutboxHandler func(ctx context.Context, messages []OutboxMessage) error
+
+// NewOutbox creates an outbox storing events in the given table on conn
+func NewOutbox(conn *Connection, table string) *Outbox {
+	return &Outbox{conn: conn, table: table}
+}
+
+// CreateTable creates the outbox table if it does not exist
+func (o *Outbox) CreateTable() error {
+	columns := "id INTEGER PRIMARY KEY AUTOINCREMENT, event VARCHAR(255) NOT NULL, payload TEXT NOT NULL, created_at DATETIME NOT NULL"
+	switch o.conn.Driver {
+	case "postgres":
+		columns = "id BIGSERIAL PRIMARY KEY, event VARCHAR(255) NOT NULL, payload TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL"
+	case "mysql":
+		columns = "id BIGINT AUTO_INCREMENT PRIMARY KEY, event VARCHAR(255) NOT NULL, payload LONGTEXT NOT NULL, created_at DATETIME(6) NOT NULL"
+	}
+	_, err := o.conn.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", o.conn.prefixTable(o.table), columns))
+	return err
+}
+
+// Store writes an event in tx, to be delivered once tx has committed. Use it inside
+// Connection.Transaction; units of work have PublishOnCommit.
+func (o *Outbox) Store(tx *sqlx.Tx, event string, payload interface{}) error {
+	message, err := newOutboxMessage(event, payload)
+	if err != nil {
+		return err
+	}
+	return o.storeTx(tx, []OutboxMessage{message})
+}
+
+// Publish stores model events outside a transaction, for single model writes
+func (o *Outbox) Publish(ctx context.Context, events []ModelEvent) error {
+	messages, err := modelEventMessages(events)
+	if err != nil {
+		return err
+	}
+	query, args := o.insert(messages)
+	_, err = o.conn.Exec(o.conn.DB.Rebind(query), args...)
+	return err
+}
+
+// PublishTx stores model events in the transaction that writes the models
+func (o *Outbox) PublishTx(tx *sqlx.Tx, events []ModelEvent) error {
+	messages, err := modelEventMessages(events)
+	if err != nil {
+		return err
+	}
+	return o.storeTx(tx, messages)
+}
+
+// storeTx writes messages in tx
+func (o *Outbox) storeTx(tx *sqlx.Tx, messages []OutboxMessage) error {
+	query, args := o.insert(messages)
+	start := time.Now()
+	_, err := tx.Exec(tx.Rebind(query), args...)
+	o.conn.observe(query, args, start, err)
+	return err
+}
+
+// insert builds the statement storing messages
+func (o *Outbox) insert(messages []OutboxMessage) (string, []interface{}) {
+	rows := make([]string, len(messages))
+	args := make([]interface{}, 0, 3*len(messages))
+	for i, message := range messages {
+		rows[i] = "(?, ?, ?)"
+		args = append(args, message.Event, string(message.Payload), message.CreatedAt)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (event, payload, created_at) VALUES %s",
+		o.conn.prefixTable(o.table), strings.Join(rows, ", "))
+	return query, args
+}
+
+// Relay delivers up to limit stored messages, oldest first, and deletes them once the
+// handler accepted them. It returns the number of messages relayed. Delivery is at least
+// once: messages are delivered again if deleting them fails or several relays overlap.
+func (o *Outbox) Relay(ctx context.Context, handler OutboxHandler, limit int) (int, error) {
+	rows, err := NewQueryBuilder(o.conn).WithContext(ctx).Table(o.table).OrderBy("id", "asc").Limit(limit).Get()
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	messages := make([]OutboxMessage, len(rows))
+	ids := make([]interface{}, len(rows))
+	for i, row := range rows {
+		ids[i] = row["id"]
+		messages[i] = OutboxMessage{Event: fmt.Sprint(row["event"]), Payload: json.RawMessage(fmt.Sprint(row["payload"]))}
+		messages[i].ID, _ = row["id"].(int64)
+		messages[i].CreatedAt, _ = row["created_at"].(time.Time)
+	}
+
+	if err := handler(ctx, messages); err != nil {
+		return 0, err
+	}
+	if _, err := NewQueryBuilder(o.conn).Table(o.table).WhereIn("id", ids).Delete(); err != nil {
+		return 0, err
+	}
+	return len(messages), nil
+}
+
+// Run relays messages until ctx is cancelled, in batches of up to limit. It waits for
+// interval whenever the outbox is empty or delivery fails; failures are reported to the
+// logger and retried. It returns ctx's error.
+func (o *Outbox) Run(ctx context.Context, handler OutboxHandler, interval time.Duration, limit int) error {
+	for {
+		relayed, err := o.Relay(ctx, handler, limit)
+		if err != nil && ctx.Err() == nil {
+			GetLogger().Error("eloquent: relaying outbox failed", "table", o.table, "error", err)
+		}
+		if err == nil && relayed > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// newOutboxMessage encodes an application event's payload as JSON
+func newOutboxMessage(event string, payload interface{}) (OutboxMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxMessage{}, fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+	return OutboxMessage{Event: event, Payload: data, CreatedAt: time.Now()}, nil
+}
+
+// modelEventMessages encodes model events as outbox messages
+func modelEventMessages(events []ModelEvent) ([]OutboxMessage, error) {
+	messages := make([]OutboxMessage, len(events))
+	for i, event := range events {
+		message, err := newOutboxMessage(event.Table+"."+event.Type, event)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = message
+	}
+	return messages, nil
+}
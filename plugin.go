@@ -0,0 +1,140 @@
+package eloquent
+
+import "sync"
+
+// Plugin extends the package from a separate module, such as a query cache, tenancy or
+// an audit trail. A plugin implements any of QueryPlugin, HydratePlugin, ModelBootPlugin
+// and ConnectionPlugin to hook into the matching extension point:
+//
+//	type tenancy struct{}
+//
+//	func (tenancy) Name() string { return "tenancy" }
+//
+//	func (tenancy) BeforeQuery(q *eloquent.QueryBuilder) error {
+//		tenant, ok := TenantFrom(q.Context())
+//		if !ok {
+//			return errors.New("no tenant in context")
+//		}
+//		q.Where("tenant_id", tenant)
+//		return nil
+//	}
+//
+//	eloquent.RegisterPlugin(tenancy{})
+type Plugin interface {
+	Name() string
+}
+
+// QueryPlugin runs before every query is compiled, including updates and deletes through
+// the query builder, with the query's context available from Context. Constraints it adds
+// apply like WithQueryDefaults; returning an error fails the query with it.
+type QueryPlugin interface {
+	Plugin
+	BeforeQuery(qb *QueryBuilder) error
+}
+
+// HydratePlugin runs after a model query fills a model from a database row
+type HydratePlugin interface {
+	Plugin
+	AfterHydrate(model Model)
+}
+
+// ModelBootPlugin runs once per model type, the first time the type is queried or saved
+type ModelBootPlugin interface {
+	Plugin
+	ModelBooted(model Model)
+}
+
+// ConnectionPlugin runs when a connection is added to or registered with a manager
+type ConnectionPlugin interface {
+	Plugin
+	ConnectionCreated(conn *Connection)
+}
+
+var (
+	plugins   []Plugin
+	pluginsMu sync.RWMutex
+
+	// bootedModels holds the model types ModelBootPlugins have seen
+	bootedModels sync.Map
+)
+
+// RegisterPlugin adds a plugin, replacing any plugin with the same name. Plugins run in
+// the order they were registered; register them at startup, since model types booted
+// and connections created before a plugin is registered are not reported to it.
+func RegisterPlugin(plugin Plugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	for i, registered := range plugins {
+		if registered.Name() == plugin.Name() {
+			plugins[i] = plugin
+			return
+		}
+	}
+	plugins = append(plugins, plugin)
+}
+
+// UnregisterPlugin removes the plugin with the given name
+func UnregisterPlugin(name string) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	for i, registered := range plugins {
+		if registered.Name() == name {
+			plugins = append(plugins[:i:i], plugins[i+1:]...)
+			return
+		}
+	}
+}
+
+// Plugins returns the registered plugins in the order they run
+func Plugins() []Plugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	return append([]Plugin(nil), plugins...)
+}
+
+// queryPlugins returns the registered plugins hooking into queries
+func queryPlugins() []QueryPlugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	var hooks []QueryPlugin
+	for _, plugin := range plugins {
+		if hook, ok := plugin.(QueryPlugin); ok {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// afterHydrate reports a model filled from a database row to the plugins
+func afterHydrate(model Model) {
+	for _, plugin := range Plugins() {
+		if hook, ok := plugin.(HydratePlugin); ok {
+			hook.AfterHydrate(model)
+		}
+	}
+}
+
+// bootModel reports a model type to the plugins the first time it is used
+func bootModel(model Model) {
+	if _, booted := bootedModels.LoadOrStore(modelTypeKey(model), true); booted {
+		return
+	}
+	if bm, ok := model.(*BaseModel); ok && bm.parentModel != nil {
+		model = bm.parentModel
+	}
+
+	for _, plugin := range Plugins() {
+		if hook, ok := plugin.(ModelBootPlugin); ok {
+			hook.ModelBooted(model)
+		}
+	}
+}
+
+// connectionCreated reports a new managed connection to the plugins
+func connectionCreated(conn *Connection) {
+	for _, plugin := range Plugins() {
+		if hook, ok := plugin.(ConnectionPlugin); ok {
+			hook.ConnectionCreated(conn)
+		}
+	}
+}
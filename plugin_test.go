@@ -0,0 +1,97 @@
+package eloquent
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recordingPlugin hooks into every extension point and records what it saw
+type recordingPlugin struct {
+	connections []string
+	booted      []string
+	hydrated    []interface{}
+	err         error
+}
+
+func (p *recordingPlugin) Name() string { return "recording" }
+
+func (p *recordingPlugin) BeforeQuery(qb *QueryBuilder) error {
+	if p.err != nil {
+		return p.err
+	}
+	qb.Where("deleted", false)
+	return nil
+}
+
+func (p *recordingPlugin) AfterHydrate(model Model) {
+	p.hydrated = append(p.hydrated, model.GetAttribute("name"))
+}
+
+func (p *recordingPlugin) ModelBooted(model Model) {
+	p.booted = append(p.booted, modelNameOf(model))
+}
+
+func (p *recordingPlugin) ConnectionCreated(conn *Connection) {
+	p.connections = append(p.connections, conn.Name)
+}
+
+func TestPlugins(t *testing.T) {
+	plugin := &recordingPlugin{}
+	RegisterPlugin(plugin)
+	defer UnregisterPlugin(plugin.Name())
+	bootedModels.Delete(reflect.TypeOf(&CustomerModel{}))
+
+	conn := NewTestSQLite(t)
+	if len(plugin.connections) != 1 || plugin.connections[0] != conn.Name {
+		t.Errorf("Expected the new connection to be reported, got %v", plugin.connections)
+	}
+
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, deleted BOOLEAN, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO customers (id, name, deleted) VALUES ('1', 'Ada', false), ('2', 'Grace', true)"); err != nil {
+		t.Fatalf("Failed to insert customers: %v", err)
+	}
+
+	query := conn.Table("customers")
+	if sql, _ := query.ToSQL(); sql != "SELECT * FROM customers WHERE deleted = ?" {
+		t.Errorf("Expected the plugin's constraint, got %s", sql)
+	}
+	if sql, _ := query.ToSQL(); sql != "SELECT * FROM customers WHERE deleted = ?" {
+		t.Errorf("Expected the constraint to be added once, got %s", sql)
+	}
+
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.Connection(conn.Name)
+	customer.SetParentModel(customer)
+	var customers []*CustomerModel
+	for i := 0; i < 2; i++ {
+		models, err := NewModelQueryBuilder(customer).Get()
+		if err != nil {
+			t.Fatalf("Failed to query customers: %v", err)
+		}
+		for _, model := range models {
+			customers = append(customers, model.(*CustomerModel))
+		}
+	}
+	if len(customers) != 2 || len(plugin.hydrated) != 2 || plugin.hydrated[0] != "Ada" {
+		t.Errorf("Expected 2 hydrated customers, got %d and %v", len(customers), plugin.hydrated)
+	}
+	if len(plugin.booted) != 1 || plugin.booted[0] != "customer" {
+		t.Errorf("Expected the model type to boot once, got %v", plugin.booted)
+	}
+
+	plugin.err = errors.New("no tenant")
+	if _, err := conn.Table("customers").Get(); err != plugin.err {
+		t.Errorf("Expected the plugin's error, got %v", err)
+	}
+
+	UnregisterPlugin(plugin.Name())
+	if len(Plugins()) != 0 {
+		t.Errorf("Expected no plugins, got %v", Plugins())
+	}
+	if sql, _ := conn.Table("customers").ToSQL(); sql != "SELECT * FROM customers" {
+		t.Errorf("Expected no constraint after unregistering, got %s", sql)
+	}
+}
@@ -0,0 +1,143 @@
+package eloquent
+
+import "sync"
+
+var (
+	pooling   bool
+	poolingMu sync.RWMutex
+
+	rowPool          sync.Pool
+	baseModelPool    = sync.Pool{New: func() interface{} { return NewBaseModel() }}
+	queryBuilderPool = sync.Pool{New: func() interface{} { return NewQueryBuilder(nil) }}
+)
+
+// SetPooling reuses the maps rows are scanned into once model queries have copied them
+// into models, lowering garbage collector pressure on servers hydrating many rows.
+// Maps returned by QueryBuilder.Get and Select are never reused. Models and builders are
+// pooled explicitly with AcquireBaseModel and AcquireQueryBuilder.
+func SetPooling(enabled bool) {
+	poolingMu.Lock()
+	defer poolingMu.Unlock()
+	pooling = enabled
+}
+
+// GetPooling reports whether row maps are reused during hydration
+func GetPooling() bool {
+	poolingMu.RLock()
+	defer poolingMu.RUnlock()
+	return pooling
+}
+
+// acquireRow returns an empty map to scan a row into
+func acquireRow(columns int) map[string]interface{} {
+	if GetPooling() {
+		if row, ok := rowPool.Get().(map[string]interface{}); ok {
+			return row
+		}
+	}
+	return make(map[string]interface{}, columns)
+}
+
+// releaseRow returns a row map to the pool once hydration no longer needs it
+func releaseRow(row map[string]interface{}) {
+	if row == nil || !GetPooling() {
+		return
+	}
+	clear(row)
+	rowPool.Put(row)
+}
+
+// AcquireBaseModel returns a BaseModel from the pool, in the state NewBaseModel creates.
+// Pass it to ReleaseBaseModel once the model and everything referencing it are no
+// longer used.
+func AcquireBaseModel() *BaseModel {
+	return baseModelPool.Get().(*BaseModel)
+}
+
+// ReleaseBaseModel resets a model and returns it to the pool
+func ReleaseBaseModel(m *BaseModel) {
+	m.Reset()
+	baseModelPool.Put(m)
+}
+
+// Reset returns the model to the state NewBaseModel creates, keeping the maps it has
+// allocated for attributes so they can be filled again
+func (m *BaseModel) Reset() {
+	attributes, original, relations := m.attributes, m.original, m.relations
+	clear(attributes)
+	clear(original)
+	clear(relations)
+
+	*m = BaseModel{
+		primaryKey: "id",
+		timestamps: true,
+		createdAt:  "created_at",
+		updatedAt:  "updated_at",
+		attributes: attributes,
+		original:   original,
+		relations:  relations,
+	}
+	if m.attributes == nil {
+		m.attributes = make(map[string]interface{})
+	}
+	if m.original == nil {
+		m.original = make(map[string]interface{})
+	}
+	if m.relations == nil {
+		m.relations = make(map[string]interface{})
+	}
+}
+
+// AcquireQueryBuilder returns an empty builder for conn from the pool. Pass it to
+// ReleaseQueryBuilder once its results have been read; builders shared with other
+// goroutines or kept as base queries must not be released.
+func AcquireQueryBuilder(conn *Connection) *QueryBuilder {
+	qb := queryBuilderPool.Get().(*QueryBuilder)
+	qb.connection = conn
+	return qb
+}
+
+// ReleaseQueryBuilder resets a builder and returns it to the pool
+func ReleaseQueryBuilder(qb *QueryBuilder) {
+	qb.Reset()
+	qb.connection = nil
+	qb.manager = nil
+	queryBuilderPool.Put(qb)
+}
+
+// Reset clears the query so the builder can build another one on the same connection,
+// keeping the space it has allocated for clauses
+func (qb *QueryBuilder) Reset() *QueryBuilder {
+	clear(qb.wheres)
+	clear(qb.orders)
+	clear(qb.joins)
+	clear(qb.groupArgs)
+	clear(qb.havings)
+	clear(qb.eagerLoad)
+
+	qb.table = ""
+	qb.alias = ""
+	qb.indexHints = nil
+	qb.wheres = qb.wheres[:0]
+	qb.orders = qb.orders[:0]
+	qb.joins = qb.joins[:0]
+	qb.groups = qb.groups[:0]
+	qb.groupArgs = qb.groupArgs[:0]
+	qb.havings = qb.havings[:0]
+	qb.limitValue = nil
+	qb.offsetValue = nil
+	qb.columns = []string{"*"} // Select may have stored the caller's slice
+	qb.distinct = false
+	qb.immutable = false
+	qb.ctx = nil
+	qb.err = nil
+	qb.asOf = nil
+	qb.tiebreaker = ""
+	qb.defaultOrders = nil
+	qb.hooked = false
+	if qb.eagerLoad == nil {
+		qb.eagerLoad = make(map[string]func(*QueryBuilder))
+	}
+	qb.compiled.Store(nil)
+	return qb
+}
@@ -0,0 +1,75 @@
+package eloquent
+
+import "testing"
+
+func TestPooling(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO customers (id, name) VALUES ('1', 'Ada'), ('2', 'Grace')"); err != nil {
+		t.Fatalf("Failed to insert customers: %v", err)
+	}
+
+	SetPooling(true)
+	defer SetPooling(false)
+
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.Connection(conn.Name)
+	customer.SetParentModel(customer)
+	for i := 0; i < 3; i++ {
+		models, err := NewModelQueryBuilder(customer).OrderBy("id", "asc").Get()
+		if err != nil {
+			t.Fatalf("Failed to query customers: %v", err)
+		}
+		if len(models) != 2 || models[0].GetAttribute("name") != "Ada" || models[1].GetAttribute("name") != "Grace" {
+			t.Fatalf("Expected pooled rows to hydrate correctly, got %v", models)
+		}
+	}
+
+	qb := AcquireQueryBuilder(conn)
+	rows, err := qb.Table("customers").Select("name").Where("id", "2").Get()
+	if err != nil || len(rows) != 1 || rows[0]["name"] != "Grace" {
+		t.Fatalf("Unexpected rows %v, %v", rows, err)
+	}
+	qb.Reset()
+	if sql, _ := qb.Table("customers").ToSQL(); sql != "SELECT * FROM customers" {
+		t.Errorf("Expected Reset to clear the query, got %s", sql)
+	}
+	ReleaseQueryBuilder(qb)
+
+	m := AcquireBaseModel()
+	m.Table("customers").Fillable("name").ReadOnly()
+	m.SetAttribute("name", "Ada")
+	ReleaseBaseModel(m)
+
+	m = AcquireBaseModel()
+	if m.GetAttribute("name") != nil || m.IsReadOnly() || len(m.GetFillable()) != 0 || m.GetPrimaryKey() != "id" || !m.GetTimestamps() {
+		t.Errorf("Expected a pooled model to be reset, got %v", m.ToMap())
+	}
+}
+
+func BenchmarkModelQueryBuilderGetPooled(b *testing.B) {
+	conn := NewTestSQLite(b)
+	if _, err := conn.Exec("CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		b.Fatalf("Failed to create table: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := conn.Exec("INSERT INTO customers (name) VALUES ('customer')"); err != nil {
+			b.Fatalf("Failed to insert customer: %v", err)
+		}
+	}
+
+	SetPooling(true)
+	defer SetPooling(false)
+
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.Connection(conn.Name)
+	customer.SetParentModel(customer)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewModelQueryBuilder(customer).Get(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package eloquent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Model event types sent to an EventPublisher
+const (
+	EventCreated  = "created"
+	EventUpdated  = "updated"
+	EventDeleted  = "deleted"
+	EventRestored = "restored"
+)
+
+// ModelEvent describes a model write, for systems downstream of the database.
+// Attributes are serialized like ToMap, so hidden attributes are left out.
+type ModelEvent struct {
+	Type       string                 `json:"type"`
+	Model      string                 `json:"model"`
+	Table      string                 `json:"table"`
+	Key        interface{}            `json:"key"`
+	Attributes map[string]interface{} `json:"attributes"`
+	// Changes holds the attributes an update changed, with their new values
+	Changes    map[string]interface{} `json:"changes,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// EventPublisher sends model events to an external queue such as NATS, Kafka or AMQP.
+// Adapters wrap the queue's client and encode the events, for example as JSON.
+type EventPublisher interface {
+	Publish(ctx context.Context, events []ModelEvent) error
+}
+
+// TxEventPublisher is implemented by publishers that store events in the transaction
+// that writes the models, such as Outbox. SaveAll and UnitOfWork call PublishTx before
+// committing, so the events are stored if and only if the writes are.
+type TxEventPublisher interface {
+	EventPublisher
+	PublishTx(tx *sqlx.Tx, events []ModelEvent) error
+}
+
+var (
+	eventPublisher   EventPublisher
+	eventPublisherMu sync.RWMutex
+)
+
+// SetEventPublisher sets the publisher that receives an event after every model is created,
+// updated, deleted or restored. Events are published once the write has committed: after
+// the statement for single models, and after the transaction for SaveAll and UnitOfWork.
+// Publish failures cannot undo the write and are reported to the logger; use an Outbox to
+// deliver events reliably. Passing nil stops publishing.
+func SetEventPublisher(publisher EventPublisher) {
+	eventPublisherMu.Lock()
+	defer eventPublisherMu.Unlock()
+	eventPublisher = publisher
+}
+
+// GetEventPublisher returns the current event publisher, or nil
+func GetEventPublisher() EventPublisher {
+	eventPublisherMu.RLock()
+	defer eventPublisherMu.RUnlock()
+	return eventPublisher
+}
+
+// newModelEvent describes the model's current state for an event of the given type
+func (m *BaseModel) newModelEvent(eventType string, changes map[string]interface{}) ModelEvent {
+	event := ModelEvent{
+		Type:       eventType,
+		Model:      modelNameOf(m.outerModel()),
+		Table:      m.GetTable(),
+		Key:        m.GetAttribute(m.primaryKey),
+		Attributes: m.ToMap(),
+		OccurredAt: time.Now(),
+	}
+	for key, value := range changes {
+		if m.isHidden(key) {
+			continue
+		}
+		if event.Changes == nil {
+			event.Changes = make(map[string]interface{}, len(changes))
+		}
+		event.Changes[key] = value
+	}
+	return event
+}
+
+// publishEvent publishes an event for a single model write, if a publisher is set
+func (m *BaseModel) publishEvent(eventType string) {
+	publisher := GetEventPublisher()
+	if publisher == nil {
+		return
+	}
+
+	var changes map[string]interface{}
+	if eventType == EventUpdated {
+		changes = m.changes
+	}
+	publishEvents(publisher, []ModelEvent{m.newModelEvent(eventType, changes)})
+}
+
+// publishEvents sends events to the publisher, reporting failures to the logger
+func publishEvents(publisher EventPublisher, events []ModelEvent) {
+	if len(events) == 0 {
+		return
+	}
+	if err := publisher.Publish(context.Background(), events); err != nil {
+		GetLogger().Error("eloquent: publishing model events failed", "events", len(events), "error", err)
+	}
+}
@@ -0,0 +1,184 @@
+package eloquent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// recordingPublisher collects the events published to it
+type recordingPublisher struct {
+	events []ModelEvent
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, events []ModelEvent) error {
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func newEventCustomer(conn *Connection, attributes map[string]interface{}) *CustomerModel {
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.Fillable("name").Connection(conn.Name)
+	customer.SetParentModel(customer)
+	customer.Fill(attributes)
+	return customer
+}
+
+func TestEventPublisher(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	SetEventPublisher(publisher)
+	t.Cleanup(func() { SetEventPublisher(nil) })
+
+	customer := newEventCustomer(conn, map[string]interface{}{"name": "Ada"})
+	if err := customer.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+	if err := customer.Update(map[string]interface{}{"name": "Ada Lovelace"}); err != nil {
+		t.Fatalf("Failed to update customer: %v", err)
+	}
+	if err := customer.Delete(); err != nil {
+		t.Fatalf("Failed to delete customer: %v", err)
+	}
+
+	if len(publisher.events) != 3 {
+		t.Fatalf("Expected 3 events, got %v", publisher.events)
+	}
+	created, updated, deleted := publisher.events[0], publisher.events[1], publisher.events[2]
+	if created.Type != EventCreated || created.Model != "customer" || created.Table != "customers" ||
+		created.Key != customer.GetAttribute("id") || created.Attributes["name"] != "Ada" {
+		t.Errorf("Unexpected created event: %+v", created)
+	}
+	if updated.Type != EventUpdated || updated.Changes["name"] != "Ada Lovelace" {
+		t.Errorf("Unexpected updated event: %+v", updated)
+	}
+	if deleted.Type != EventDeleted {
+		t.Errorf("Unexpected deleted event: %+v", deleted)
+	}
+
+	// Batched writes publish once their transaction has committed
+	publisher.events = nil
+	if err := SaveAll([]Model{newEventCustomer(conn, map[string]interface{}{"name": "Grace"})}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != EventCreated {
+		t.Errorf("Expected one created event from SaveAll, got %v", publisher.events)
+	}
+
+	// Units committed into the caller's transaction hold their events until it commits
+	publisher.events = nil
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	uow := NewUnitOfWork().Register(newEventCustomer(conn, map[string]interface{}{"name": "Linus"}))
+	if err := uow.CommitTx(tx); err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("Expected no events before the transaction commits, got %v", publisher.events)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+	uow.PublishCommitted()
+	uow.PublishCommitted()
+	if len(publisher.events) != 1 || publisher.events[0].Type != EventCreated {
+		t.Errorf("Expected one created event after the commit, got %v", publisher.events)
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	outbox := NewOutbox(conn, "event_outbox")
+	if err := outbox.CreateTable(); err != nil {
+		t.Fatalf("Failed to create outbox table: %v", err)
+	}
+	SetEventPublisher(outbox)
+	t.Cleanup(func() { SetEventPublisher(nil) })
+
+	ada := newEventCustomer(conn, map[string]interface{}{"name": "Ada"})
+	err := NewUnitOfWork().
+		Register(ada).
+		PublishOnCommit("customer.welcomed", map[string]string{"name": "Ada"}).
+		Commit()
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Events of rolled back writes are never stored
+	duplicate := newEventCustomer(conn, map[string]interface{}{"name": "Duplicate"})
+	duplicate.SetAttribute("id", ada.GetAttribute("id"))
+	err = NewUnitOfWork().
+		Register(newEventCustomer(conn, map[string]interface{}{"name": "Linus"}), duplicate).
+		PublishOnCommit("customer.welcomed", map[string]string{"name": "Linus"}).
+		Commit()
+	if err == nil {
+		t.Fatal("Expected a primary key violation")
+	}
+	if count, _ := conn.Table("event_outbox").Count(); count != 2 {
+		t.Fatalf("Expected 2 stored events, got %d", count)
+	}
+
+	var relayed []OutboxMessage
+	n, err := outbox.Relay(context.Background(), func(_ context.Context, messages []OutboxMessage) error {
+		relayed = append(relayed, messages...)
+		return nil
+	}, 10)
+	if err != nil {
+		t.Fatalf("Relay failed: %v", err)
+	}
+	if n != 2 || len(relayed) != 2 {
+		t.Fatalf("Expected both events to be relayed, got %d %v", n, relayed)
+	}
+	for _, message := range relayed {
+		switch message.Event {
+		case "customers.created":
+			var event ModelEvent
+			if err := message.Decode(&event); err != nil || event.Attributes["name"] != "Ada" {
+				t.Errorf("Expected the model event as payload, got %+v (%v)", event, err)
+			}
+		case "customer.welcomed":
+			if string(message.Payload) != `{"name":"Ada"}` {
+				t.Errorf("Expected the application payload, got %s", message.Payload)
+			}
+		default:
+			t.Errorf("Unexpected event %q", message.Event)
+		}
+	}
+	if count, _ := conn.Table("event_outbox").Count(); count != 0 {
+		t.Errorf("Expected relayed events to be deleted, got %d", count)
+	}
+
+	// Failed deliveries stay in the outbox
+	if err := conn.Transaction(func(tx *sqlx.Tx) error {
+		return outbox.Store(tx, "customer.audited", nil)
+	}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if _, err := outbox.Relay(context.Background(), func(context.Context, []OutboxMessage) error {
+		return errors.New("broker down")
+	}, 10); err == nil {
+		t.Error("Expected the delivery error")
+	}
+	if count, _ := conn.Table("event_outbox").Count(); count != 1 {
+		t.Errorf("Expected the undelivered event to be kept, got %d", count)
+	}
+
+	if err := NewUnitOfWork().PublishOnCommit("x", nil).Commit(); err != nil {
+		t.Errorf("Expected the event publisher to be used as the outbox, got %v", err)
+	}
+	SetEventPublisher(nil)
+	if err := NewUnitOfWork().PublishOnCommit("x", nil).Commit(); err == nil {
+		t.Error("Expected an error without an outbox")
+	}
+}
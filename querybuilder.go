@@ -1,26 +1,80 @@
 package eloquent
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
-// QueryBuilder provides fluent query building interface
+// ErrInvalidQuery is returned when a query is built with an unsupported operator,
+// a malformed column name or the wrong number of arguments
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrNotFound is returned by First and Find when no record matches the query
+var ErrNotFound = errors.New("no records found")
+
+// ErrNoConnection is returned when a query is executed without a database connection
+var ErrNoConnection = errors.New("database connection not initialized")
+
+// QueryBuilder provides fluent query building interface.
+// A builder is mutated in place by every chained call and must not be shared
+// between goroutines unless it is made Immutable or copied with Clone first;
+// connections and managers, by contrast, are safe for concurrent use.
 type QueryBuilder struct {
 	connection  *Connection
+	manager     *ConnectionManager
 	table       string
+	alias       string
+	indexHints  []indexHint
 	wheres      []WhereClause
 	orders      []OrderClause
 	joins       []JoinClause
 	groups      []string
+	groupArgs   []interface{}
 	havings     []HavingClause
 	limitValue  *int
 	offsetValue *int
 	columns     []string
 	distinct    bool
+	immutable   bool
+	ctx         context.Context
+	err         error
+
+	// connectionErr explains why the builder has no connection, see NewModelQueryBuilder
+	connectionErr error
+
+	// asOf reads the table as it was at a point in time, see ModelQueryBuilder.AsOf
+	asOf *temporalTable
+
+	// tiebreaker is the unique column ordered last, see Tiebreaker
+	tiebreaker string
+
+	// defaultOrders apply while the query has no orders of its own, see BaseModel.DefaultOrder
+	defaultOrders []OrderClause
+
+	// hooked is set once QueryPlugins have been applied to the query
+	hooked bool
 
 	// For relations
 	eagerLoad map[string]func(*QueryBuilder)
+
+	// compiled caches the SQL from ToSQL until the builder is modified
+	compiled atomic.Pointer[compiledQuery]
+}
+
+// compiledQuery is a statement compiled by ToSQL for the connection it was compiled for
+type compiledQuery struct {
+	connection *Connection
+	sql        string
+	args       []interface{}
 }
 
 // WhereClause represents a where condition
@@ -29,14 +83,18 @@ type WhereClause struct {
 	Operator string
 	Value    interface{}
 	Boolean  string        // "and" or "or"
-	Type     string        // "basic", "in", "null", "between", "exists", "raw"
+	Type     string        // "basic", "in", "null", "between", "exists", "raw", "nested"
 	Values   []interface{} // for IN clauses
+	Wheres   []WhereClause // for nested groups
 }
 
 // OrderClause represents an order by clause
 type OrderClause struct {
 	Column    string
 	Direction string
+	Nulls     string        // "first" or "last", empty for the database's default
+	Type      string        // "basic" (or empty), "raw" or "field"
+	Values    []interface{} // bindings of raw orders, listed values of field orders
 }
 
 // JoinClause represents a join
@@ -46,6 +104,30 @@ type JoinClause struct {
 	Operator string
 	Second   string
 	Type     string // "inner", "left", "right", "cross"
+
+	// Conditions replace First, Operator and Second for joins built with JoinOn
+	Conditions []JoinCondition
+	// Query is the subquery joined under the Table alias by JoinSub
+	Query *QueryBuilder
+}
+
+// JoinCondition is one comparison in the ON clause of a join
+type JoinCondition struct {
+	First    string
+	Operator string
+	Second   string
+	Boolean  string // "and" or "or"
+
+	// Bound marks conditions that compare First with Value instead of the Second column
+	Bound bool
+	Value interface{}
+}
+
+// JoinBuilder collects the ON conditions of a join built with JoinOn or LeftJoinOn.
+// On compares two columns; Where compares a column with a bound value.
+type JoinBuilder struct {
+	conditions []JoinCondition
+	err        error
 }
 
 // HavingClause represents a having condition
@@ -54,6 +136,14 @@ type HavingClause struct {
 	Operator string
 	Value    interface{}
 	Boolean  string
+	Type     string        // "basic" (or empty), "between", "null", "raw"
+	Values   []interface{} // for between and raw clauses
+}
+
+// indexHint is a MySQL index hint such as USE INDEX (idx_status)
+type indexHint struct {
+	kind    string // "USE", "FORCE" or "IGNORE"
+	indexes []string
 }
 
 // NewQueryBuilder creates a new query builder
@@ -67,18 +157,72 @@ func NewQueryBuilder(connection *Connection) *QueryBuilder {
 
 // Table sets the table name
 func (qb *QueryBuilder) Table(table string) *QueryBuilder {
+	qb = qb.mutable()
+	qb.table = table
+	return qb
+}
+
+// Connection runs the query on the named connection instead of the one the builder was created with.
+// An unknown name makes the query fail when it runs.
+func (qb *QueryBuilder) Connection(name string) *QueryBuilder {
+	qb = qb.mutable()
+	conn, ok := qb.getManager().lookup(name)
+	if !ok {
+		return qb.fail(fmt.Errorf("connection '%s' not found", name))
+	}
+	qb.connection = conn
+	return qb
+}
+
+// getManager returns the manager Connection resolves names in: the one the builder's
+// model or manager is bound to, or the global manager
+func (qb *QueryBuilder) getManager() *ConnectionManager {
+	if qb.manager != nil {
+		return qb.manager
+	}
+	runPendingBoot()
+	return GetManager()
+}
+
+// From sets the table name and an optional alias, e.g. From("users", "u")
+func (qb *QueryBuilder) From(table string, alias ...string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.table = table
+	qb.alias = ""
+	if len(alias) > 0 && alias[0] != "" {
+		if err := validateColumn(alias[0]); err != nil {
+			return qb.fail(err)
+		}
+		qb.alias = alias[0]
+	}
 	return qb
 }
 
+// UseIndex suggests indexes to MySQL. Other drivers ignore index hints.
+func (qb *QueryBuilder) UseIndex(indexes ...string) *QueryBuilder {
+	return qb.addIndexHint("USE", indexes)
+}
+
+// ForceIndex forces MySQL to use one of the given indexes. Other drivers ignore index hints.
+func (qb *QueryBuilder) ForceIndex(indexes ...string) *QueryBuilder {
+	return qb.addIndexHint("FORCE", indexes)
+}
+
+// IgnoreIndex tells MySQL not to use the given indexes. Other drivers ignore index hints.
+func (qb *QueryBuilder) IgnoreIndex(indexes ...string) *QueryBuilder {
+	return qb.addIndexHint("IGNORE", indexes)
+}
+
 // Select specifies columns to select
 func (qb *QueryBuilder) Select(columns ...string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.columns = columns
 	return qb
 }
 
 // Distinct adds distinct clause
 func (qb *QueryBuilder) Distinct() *QueryBuilder {
+	qb = qb.mutable()
 	qb.distinct = true
 	return qb
 }
@@ -93,8 +237,24 @@ func (qb *QueryBuilder) OrWhere(column string, args ...interface{}) *QueryBuilde
 	return qb.addWhere(column, "or", args...)
 }
 
+// WhereNamed adds a raw where clause whose :name parameters are bound from a map or struct,
+// e.g. WhereNamed("age >= :min_age AND age < :max_age", filter). Struct fields are matched
+// by their db tag. A parameter with no matching key or field fails the query.
+func (qb *QueryBuilder) WhereNamed(sql string, arg interface{}) *QueryBuilder {
+	return qb.addNamedWhere(sql, arg, "and")
+}
+
+// OrWhereNamed adds a raw or where clause with named parameters, see WhereNamed
+func (qb *QueryBuilder) OrWhereNamed(sql string, arg interface{}) *QueryBuilder {
+	return qb.addNamedWhere(sql, arg, "or")
+}
+
 // WhereIn adds a where in clause
 func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
 	qb.wheres = append(qb.wheres, WhereClause{
 		Column:  column,
 		Type:    "in",
@@ -106,6 +266,10 @@ func (qb *QueryBuilder) WhereIn(column string, values []interface{}) *QueryBuild
 
 // WhereNotIn adds a where not in clause
 func (qb *QueryBuilder) WhereNotIn(column string, values []interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
 	qb.wheres = append(qb.wheres, WhereClause{
 		Column:   column,
 		Operator: "not in",
@@ -118,6 +282,10 @@ func (qb *QueryBuilder) WhereNotIn(column string, values []interface{}) *QueryBu
 
 // WhereNull adds a where null clause
 func (qb *QueryBuilder) WhereNull(column string) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
 	qb.wheres = append(qb.wheres, WhereClause{
 		Column:  column,
 		Type:    "null",
@@ -128,6 +296,10 @@ func (qb *QueryBuilder) WhereNull(column string) *QueryBuilder {
 
 // WhereNotNull adds a where not null clause
 func (qb *QueryBuilder) WhereNotNull(column string) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
 	qb.wheres = append(qb.wheres, WhereClause{
 		Column:   column,
 		Operator: "not null",
@@ -139,6 +311,10 @@ func (qb *QueryBuilder) WhereNotNull(column string) *QueryBuilder {
 
 // WhereBetween adds a where between clause
 func (qb *QueryBuilder) WhereBetween(column string, min, max interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateColumn(column); err != nil {
+		return qb.fail(err)
+	}
 	qb.wheres = append(qb.wheres, WhereClause{
 		Column:  column,
 		Type:    "between",
@@ -175,6 +351,7 @@ func (qb *QueryBuilder) WhereDay(column string, operator string, value interface
 
 // Join adds an inner join
 func (qb *QueryBuilder) Join(table, first, operator, second string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.joins = append(qb.joins, JoinClause{
 		Table:    table,
 		First:    first,
@@ -185,8 +362,45 @@ func (qb *QueryBuilder) Join(table, first, operator, second string) *QueryBuilde
 	return qb
 }
 
+// JoinOn adds an inner join whose ON clause is built by callback, e.g.
+// JoinOn("posts", func(j *JoinBuilder) { j.On("posts.user_id", "=", "users.id").OrOn("posts.editor_id", "=", "users.id") })
+func (qb *QueryBuilder) JoinOn(table string, callback func(*JoinBuilder)) *QueryBuilder {
+	return qb.addJoinOn(table, "inner", callback)
+}
+
+// LeftJoinOn adds a left join whose ON clause is built by callback, see JoinOn
+func (qb *QueryBuilder) LeftJoinOn(table string, callback func(*JoinBuilder)) *QueryBuilder {
+	return qb.addJoinOn(table, "left", callback)
+}
+
+// JoinWhere adds an inner join whose ON clause compares column with a bound value,
+// e.g. JoinWhere("orders", "orders.status", "=", "paid")
+func (qb *QueryBuilder) JoinWhere(table, column, operator string, value interface{}) *QueryBuilder {
+	return qb.addJoinOn(table, "inner", func(j *JoinBuilder) {
+		j.Where(column, operator, value)
+	})
+}
+
+// LeftJoinWhere adds a left join whose ON clause compares column with a bound value
+func (qb *QueryBuilder) LeftJoinWhere(table, column, operator string, value interface{}) *QueryBuilder {
+	return qb.addJoinOn(table, "left", func(j *JoinBuilder) {
+		j.Where(column, operator, value)
+	})
+}
+
+// JoinSub adds an inner join on a subquery available under alias
+func (qb *QueryBuilder) JoinSub(sub *QueryBuilder, alias, first, operator, second string) *QueryBuilder {
+	return qb.addJoinSub(sub, alias, first, operator, second, "inner")
+}
+
+// LeftJoinSub adds a left join on a subquery available under alias
+func (qb *QueryBuilder) LeftJoinSub(sub *QueryBuilder, alias, first, operator, second string) *QueryBuilder {
+	return qb.addJoinSub(sub, alias, first, operator, second, "left")
+}
+
 // LeftJoin adds a left join
 func (qb *QueryBuilder) LeftJoin(table, first, operator, second string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.joins = append(qb.joins, JoinClause{
 		Table:    table,
 		First:    first,
@@ -199,6 +413,7 @@ func (qb *QueryBuilder) LeftJoin(table, first, operator, second string) *QueryBu
 
 // RightJoin adds a right join
 func (qb *QueryBuilder) RightJoin(table, first, operator, second string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.joins = append(qb.joins, JoinClause{
 		Table:    table,
 		First:    first,
@@ -211,6 +426,7 @@ func (qb *QueryBuilder) RightJoin(table, first, operator, second string) *QueryB
 
 // CrossJoin adds a cross join
 func (qb *QueryBuilder) CrossJoin(table string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.joins = append(qb.joins, JoinClause{
 		Table: table,
 		Type:  "cross",
@@ -220,14 +436,28 @@ func (qb *QueryBuilder) CrossJoin(table string) *QueryBuilder {
 
 // OrderBy adds an order by clause
 func (qb *QueryBuilder) OrderBy(column, direction string) *QueryBuilder {
+	qb = qb.mutable()
+	order, err := newOrderClause(column, direction)
+	if err != nil {
+		return qb.fail(err)
+	}
+	qb.orders = append(qb.orders, order)
+	return qb
+}
+
+// newOrderClause validates an order, "asc" when direction is empty
+func newOrderClause(column, direction string) (OrderClause, error) {
 	if direction == "" {
 		direction = "asc"
 	}
-	qb.orders = append(qb.orders, OrderClause{
-		Column:    column,
-		Direction: strings.ToLower(direction),
-	})
-	return qb
+	direction = strings.ToLower(direction)
+	if direction != "asc" && direction != "desc" {
+		return OrderClause{}, fmt.Errorf("%w: order direction %q", ErrInvalidQuery, direction)
+	}
+	if err := validateColumn(column); err != nil {
+		return OrderClause{}, err
+	}
+	return OrderClause{Column: column, Direction: direction}, nil
 }
 
 // OrderByDesc adds a descending order by clause
@@ -255,12 +485,30 @@ func (qb *QueryBuilder) Oldest(column ...string) *QueryBuilder {
 
 // GroupBy adds group by columns
 func (qb *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
+	qb = qb.mutable()
 	qb.groups = append(qb.groups, columns...)
 	return qb
 }
 
+// GroupByRaw adds a raw group by expression, such as "DATE(created_at)".
+// Bindings replace ? placeholders in the expression.
+func (qb *QueryBuilder) GroupByRaw(sql string, bindings ...interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	qb.groups = append(qb.groups, sql)
+	qb.groupArgs = append(qb.groupArgs, bindings...)
+	return qb
+}
+
 // Having adds a having clause
 func (qb *QueryBuilder) Having(column, operator string, value interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	operator, err := normalizeOperator(operator)
+	if err == nil {
+		err = validateHavingColumn(column)
+	}
+	if err != nil {
+		return qb.fail(err)
+	}
 	qb.havings = append(qb.havings, HavingClause{
 		Column:   column,
 		Operator: operator,
@@ -272,6 +520,14 @@ func (qb *QueryBuilder) Having(column, operator string, value interface{}) *Quer
 
 // OrHaving adds an OR having clause
 func (qb *QueryBuilder) OrHaving(column, operator string, value interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	operator, err := normalizeOperator(operator)
+	if err == nil {
+		err = validateHavingColumn(column)
+	}
+	if err != nil {
+		return qb.fail(err)
+	}
 	qb.havings = append(qb.havings, HavingClause{
 		Column:   column,
 		Operator: operator,
@@ -281,8 +537,45 @@ func (qb *QueryBuilder) OrHaving(column, operator string, value interface{}) *Qu
 	return qb
 }
 
+// HavingBetween adds a having between clause
+func (qb *QueryBuilder) HavingBetween(column string, min, max interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateHavingColumn(column); err != nil {
+		return qb.fail(err)
+	}
+	qb.havings = append(qb.havings, HavingClause{
+		Column:  column,
+		Boolean: "and",
+		Type:    "between",
+		Values:  []interface{}{min, max},
+	})
+	return qb
+}
+
+// HavingNull adds a having null clause
+func (qb *QueryBuilder) HavingNull(column string) *QueryBuilder {
+	return qb.addHavingNull(column, "null")
+}
+
+// HavingNotNull adds a having not null clause
+func (qb *QueryBuilder) HavingNotNull(column string) *QueryBuilder {
+	return qb.addHavingNull(column, "not null")
+}
+
+// HavingRaw adds a raw having clause, such as "SUM(price * quantity) > ?".
+// Bindings replace ? placeholders in the expression.
+func (qb *QueryBuilder) HavingRaw(sql string, bindings ...interface{}) *QueryBuilder {
+	return qb.addRawHaving(sql, "and", bindings)
+}
+
+// OrHavingRaw adds a raw OR having clause
+func (qb *QueryBuilder) OrHavingRaw(sql string, bindings ...interface{}) *QueryBuilder {
+	return qb.addRawHaving(sql, "or", bindings)
+}
+
 // Limit sets the limit
 func (qb *QueryBuilder) Limit(limit int) *QueryBuilder {
+	qb = qb.mutable()
 	qb.limitValue = &limit
 	return qb
 }
@@ -294,6 +587,7 @@ func (qb *QueryBuilder) Take(limit int) *QueryBuilder {
 
 // Offset sets the offset
 func (qb *QueryBuilder) Offset(offset int) *QueryBuilder {
+	qb = qb.mutable()
 	qb.offsetValue = &offset
 	return qb
 }
@@ -305,6 +599,7 @@ func (qb *QueryBuilder) Skip(offset int) *QueryBuilder {
 
 // With adds eager loading
 func (qb *QueryBuilder) With(relations ...string) *QueryBuilder {
+	qb = qb.mutable()
 	for _, relation := range relations {
 		qb.eagerLoad[relation] = nil
 	}
@@ -313,6 +608,7 @@ func (qb *QueryBuilder) With(relations ...string) *QueryBuilder {
 
 // WithCallback adds eager loading with callback
 func (qb *QueryBuilder) WithCallback(relation string, callback func(*QueryBuilder)) *QueryBuilder {
+	qb = qb.mutable()
 	qb.eagerLoad[relation] = callback
 	return qb
 }
@@ -320,15 +616,84 @@ func (qb *QueryBuilder) WithCallback(relation string, callback func(*QueryBuilde
 // Scopes
 func (qb *QueryBuilder) When(condition bool, callback func(*QueryBuilder)) *QueryBuilder {
 	if condition {
-		callback(qb)
+		return qb.apply(callback)
 	}
 	return qb
 }
 
 func (qb *QueryBuilder) Unless(condition bool, callback func(*QueryBuilder)) *QueryBuilder {
 	if !condition {
+		return qb.apply(callback)
+	}
+	return qb
+}
+
+// apply runs a callback that modifies the builder in place. In immutable mode the
+// callback receives a private copy, which is returned.
+func (qb *QueryBuilder) apply(callback func(*QueryBuilder)) *QueryBuilder {
+	if !qb.immutable {
 		callback(qb)
+		return qb
+	}
+
+	target := qb.clone()
+	target.immutable = false
+	callback(target)
+	target.immutable = true
+	return target
+}
+
+// Immutable returns a copy of the builder in which every chained call returns a
+// new builder instead of modifying the receiver, so a base query can be shared
+// between goroutines and extended independently. Callbacks that modify a builder
+// in place, such as scopes, should be applied through When or Unless.
+func (qb *QueryBuilder) Immutable() *QueryBuilder {
+	clone := qb.clone()
+	clone.immutable = true
+	return clone
+}
+
+// Clone returns an independent copy of the builder
+func (qb *QueryBuilder) Clone() *QueryBuilder {
+	return qb.clone()
+}
+
+// Err returns the first error recorded while building the query, such as an unsupported
+// operator or a missing connection. Get, Update, Delete and the methods built on them
+// return it without querying.
+func (qb *QueryBuilder) Err() error {
+	return qb.err
+}
+
+// executable returns the error that prevents the query from running, if any
+func (qb *QueryBuilder) executable() error {
+	if qb.err != nil {
+		return qb.err
+	}
+	if qb.connection == nil {
+		if qb.connectionErr != nil {
+			return qb.connectionErr
+		}
+		return ErrNoConnection
+	}
+	return nil
+}
+
+// fail records err, keeping the first error, and returns the builder unchanged otherwise
+func (qb *QueryBuilder) fail(err error) *QueryBuilder {
+	if qb.err == nil {
+		qb.err = err
+	}
+	return qb
+}
+
+// mutable returns the builder a chained call should modify: the receiver itself,
+// or a copy of it in immutable mode
+func (qb *QueryBuilder) mutable() *QueryBuilder {
+	if qb.immutable {
+		return qb.clone()
 	}
+	qb.compiled.Store(nil)
 	return qb
 }
 
@@ -336,19 +701,22 @@ func (qb *QueryBuilder) Unless(condition bool, callback func(*QueryBuilder)) *Qu
 
 // Get retrieves all records
 func (qb *QueryBuilder) Get() ([]map[string]interface{}, error) {
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return nil, err
+	}
 	sql, args := qb.ToSQL()
 	return qb.connection.Select(sql, args...)
 }
 
 // First retrieves the first record
 func (qb *QueryBuilder) First() (map[string]interface{}, error) {
-	qb.Limit(1)
-	results, err := qb.Get()
+	results, err := qb.Limit(1).Get()
 	if err != nil {
 		return nil, err
 	}
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no records found")
+		return nil, ErrNotFound
 	}
 	return results[0], nil
 }
@@ -386,6 +754,7 @@ func (qb *QueryBuilder) Count(columns ...string) (int64, error) {
 	countQB := qb.clone()
 	countQB.columns = []string{fmt.Sprintf("COUNT(%s) as count", column)}
 	countQB.orders = nil
+	countQB.defaultOrders = nil
 	countQB.limitValue = nil
 	countQB.offsetValue = nil
 
@@ -448,9 +817,91 @@ type PaginationResult struct {
 	To          int64                    `json:"to"`
 }
 
+// KeysetPaginate returns the rows after a cursor, ordered by orderColumn with id as the
+// tie breaker. Prefix the column with - to page in descending order, e.g. "-created_at".
+// Unlike Paginate it compares against the last row seen with WHERE (column, id) > (?, ?)
+// instead of skipping rows with OFFSET, so pages stay stable while rows are inserted and
+// deep pages stay cheap when (column, id) is indexed. Pass an empty cursor for the first page
+// and the returned NextCursor for the following ones.
+func (qb *QueryBuilder) KeysetPaginate(orderColumn, after string, limit int) (*KeysetPage, error) {
+	direction, operator := "ASC", ">"
+	if strings.HasPrefix(orderColumn, "-") {
+		direction, operator = "DESC", "<"
+		orderColumn = orderColumn[1:]
+	}
+	if err := validateColumn(orderColumn); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: keyset page size %d", ErrInvalidQuery, limit)
+	}
+
+	keyColumn := "id"
+	if i := strings.LastIndex(orderColumn, "."); i >= 0 {
+		keyColumn = orderColumn[:i+1] + keyColumn
+	}
+	tied := orderColumn != keyColumn
+
+	page := qb.clone()
+	if after != "" {
+		values, err := DecodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tied && len(values) == 2:
+			page = page.addRawWhere("("+orderColumn+", "+keyColumn+") "+operator+" (?, ?)", values)
+		case !tied && len(values) == 1:
+			page = page.addRawWhere(orderColumn+" "+operator+" ?", values)
+		default:
+			return nil, fmt.Errorf("%w: cursor does not match the order", ErrInvalidQuery)
+		}
+	}
+
+	page = page.OrderBy(orderColumn, direction)
+	if tied {
+		page = page.OrderBy(keyColumn, direction)
+	}
+	rows, err := page.Limit(limit + 1).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &KeysetPage{Data: rows, PerPage: int64(limit)}
+	if len(rows) > limit {
+		result.Data = rows[:limit]
+
+		last := result.Data[limit-1]
+		values := []interface{}{last[unqualified(orderColumn)]}
+		if tied {
+			values = append(values, last[unqualified(keyColumn)])
+		}
+		if result.NextCursor, err = EncodeCursor(values...); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// KeysetPage holds one page of keyset pagination. NextCursor is empty on the last page.
+type KeysetPage struct {
+	Data       []map[string]interface{} `json:"data"`
+	PerPage    int64                    `json:"per_page"`
+	NextCursor string                   `json:"next_cursor"`
+}
+
+// unqualified strips the table from a column reference such as posts.created_at
+func unqualified(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return column[i+1:]
+	}
+	return column
+}
+
 // Aggregate methods
 func (qb *QueryBuilder) Sum(column string) (float64, error) {
 	sumQB := qb.clone()
+	sumQB.defaultOrders = nil
 	sumQB.columns = []string{fmt.Sprintf("SUM(%s) as sum", column)}
 
 	result, err := sumQB.First()
@@ -458,15 +909,12 @@ func (qb *QueryBuilder) Sum(column string) (float64, error) {
 		return 0, err
 	}
 
-	if sum, ok := result["sum"].(float64); ok {
-		return sum, nil
-	}
-
-	return 0, nil
+	return aggregateFloat(result["sum"]), nil
 }
 
 func (qb *QueryBuilder) Avg(column string) (float64, error) {
 	avgQB := qb.clone()
+	avgQB.defaultOrders = nil
 	avgQB.columns = []string{fmt.Sprintf("AVG(%s) as avg", column)}
 
 	result, err := avgQB.First()
@@ -474,15 +922,26 @@ func (qb *QueryBuilder) Avg(column string) (float64, error) {
 		return 0, err
 	}
 
-	if avg, ok := result["avg"].(float64); ok {
-		return avg, nil
-	}
+	return aggregateFloat(result["avg"]), nil
+}
 
-	return 0, nil
+// aggregateFloat converts the numeric result of an aggregate to float64; NULL becomes 0
+func aggregateFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
 }
 
 func (qb *QueryBuilder) Max(column string) (interface{}, error) {
 	maxQB := qb.clone()
+	maxQB.defaultOrders = nil
 	maxQB.columns = []string{fmt.Sprintf("MAX(%s) as max", column)}
 
 	result, err := maxQB.First()
@@ -495,6 +954,7 @@ func (qb *QueryBuilder) Max(column string) (interface{}, error) {
 
 func (qb *QueryBuilder) Min(column string) (interface{}, error) {
 	minQB := qb.clone()
+	minQB.defaultOrders = nil
 	minQB.columns = []string{fmt.Sprintf("MIN(%s) as min", column)}
 
 	result, err := minQB.First()
@@ -505,8 +965,236 @@ func (qb *QueryBuilder) Min(column string) (interface{}, error) {
 	return result["min"], nil
 }
 
+// Update sets the given columns on every matching record and returns the number of affected rows.
+// Joined tables narrow the update down and can be compared against in where clauses; see
+// UpdateFrom for how the statement is written per driver.
+func (qb *QueryBuilder) Update(values map[string]interface{}) (int64, error) {
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no values to update")
+	}
+	return qb.update(values, nil)
+}
+
+// UpdateFrom sets columns of the matching records to columns of the rows sub selects,
+// joined under alias on first operator second. The keys of columns are the columns to
+// set and the values the columns they are copied from:
+//
+//	totals := eloquent.NewQueryBuilder(conn).Table("order_items").
+//		Select("order_id", "SUM(price) AS total").GroupBy("order_id")
+//	n, err := eloquent.NewQueryBuilder(conn).Table("orders").
+//		UpdateFrom(totals, "totals", "totals.order_id", "=", "orders.id", map[string]string{"total": "totals.total"})
+//
+// MySQL joins the tables in the UPDATE clause; PostgreSQL and SQLite list them in FROM,
+// so only inner and cross joins can be used there.
+func (qb *QueryBuilder) UpdateFrom(sub *QueryBuilder, alias, first, operator, second string, columns map[string]string) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("no values to update")
+	}
+	if sub == nil {
+		return 0, fmt.Errorf("%w: update from %s has no select", ErrInvalidQuery, alias)
+	}
+	for column, source := range columns {
+		for _, identifier := range []string{column, source} {
+			if err := validateColumn(identifier); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return qb.clone().JoinSub(sub, alias, first, operator, second).update(nil, columns)
+}
+
+// update sets columns to bound values and to other columns
+func (qb *QueryBuilder) update(values map[string]interface{}, columns map[string]string) (int64, error) {
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return 0, err
+	}
+	if qb.asOf != nil {
+		return 0, ErrReadOnly
+	}
+
+	targets := make([]string, 0, len(values)+len(columns))
+	for column := range values {
+		targets = append(targets, column)
+	}
+	for column := range columns {
+		targets = append(targets, column)
+	}
+	sort.Strings(targets)
+
+	var sql strings.Builder
+	var args []interface{}
+	getPlaceholder := qb.placeholders()
+	joinsInFrom := len(qb.joins) > 0 && qb.connection.Driver != "mysql"
+
+	sql.WriteString("UPDATE ")
+	qb.writeTable(&sql, false)
+	if len(qb.joins) > 0 && !joinsInFrom {
+		args = append(args, qb.compileJoins(&sql, getPlaceholder)...)
+	}
+	sql.WriteString(" SET ")
+	for i, column := range targets {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(column)
+		sql.WriteString(" = ")
+		if source, ok := columns[column]; ok {
+			sql.WriteString(source)
+			continue
+		}
+		sql.WriteString(getPlaceholder())
+		args = append(args, values[column])
+	}
+	if joinsInFrom {
+		sql.WriteString(" FROM ")
+		joinArgs, err := qb.compileJoinsAsTables(&sql, getPlaceholder)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, joinArgs...)
+	} else {
+		args = append(args, qb.compileWheres(&sql, getPlaceholder)...)
+	}
+
+	result, err := qb.connection.Update(sql.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Delete deletes every matching record and returns the number of affected rows. Joined
+// tables narrow the delete down: MySQL deletes from the joined statement, PostgreSQL
+// joins in a USING clause, so only inner and cross joins can be used there, and other
+// drivers delete the rows a joined select finds by rowid.
+func (qb *QueryBuilder) Delete() (int64, error) {
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return 0, err
+	}
+	if qb.asOf != nil {
+		return 0, ErrReadOnly
+	}
+
+	var sql strings.Builder
+	var args []interface{}
+	getPlaceholder := qb.placeholders()
+
+	switch {
+	case len(qb.joins) == 0:
+		sql.WriteString("DELETE FROM ")
+		qb.writeTable(&sql, false)
+		args = qb.compileWheres(&sql, getPlaceholder)
+	case qb.connection.Driver == "mysql":
+		sql.WriteString("DELETE ")
+		sql.WriteString(qb.tableReference())
+		sql.WriteString(" FROM ")
+		qb.writeTable(&sql, false)
+		args = append(qb.compileJoins(&sql, getPlaceholder), qb.compileWheres(&sql, getPlaceholder)...)
+	case qb.connection.Driver == "postgres":
+		sql.WriteString("DELETE FROM ")
+		qb.writeTable(&sql, false)
+		sql.WriteString(" USING ")
+		var err error
+		if args, err = qb.compileJoinsAsTables(&sql, getPlaceholder); err != nil {
+			return 0, err
+		}
+	default:
+		matches := qb.clone()
+		matches.columns = []string{qb.tableReference() + ".rowid"}
+		matches.distinct = false
+		sql.WriteString("DELETE FROM ")
+		sql.WriteString(qb.connection.prefixTable(qb.table))
+		sql.WriteString(" WHERE rowid IN (")
+		args = matches.compileSelect(&sql, getPlaceholder)
+		sql.WriteString(")")
+	}
+
+	result, err := qb.connection.Delete(sql.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// InsertUsing inserts the rows sub selects into the given columns of the builder's table
+// and returns the number of inserted rows:
+//
+//	archived := eloquent.NewQueryBuilder(conn).Table("orders").
+//		Select("id", "total").Where("created_at", "<", cutoff)
+//	n, err := eloquent.NewQueryBuilder(conn).Table("archived_orders").InsertUsing([]string{"id", "total"}, archived)
+func (qb *QueryBuilder) InsertUsing(columns []string, sub *QueryBuilder) (int64, error) {
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return 0, err
+	}
+	if qb.asOf != nil {
+		return 0, ErrReadOnly
+	}
+	if sub == nil {
+		return 0, fmt.Errorf("%w: insert into %s has no select", ErrInvalidQuery, qb.table)
+	}
+	sub = sub.withDefaults()
+	if sub.err != nil {
+		return 0, sub.err
+	}
+	for _, column := range columns {
+		if err := validateColumn(column); err != nil {
+			return 0, err
+		}
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	qb.writeTable(&sql, false)
+	if len(columns) > 0 {
+		sql.WriteString(" (")
+		sql.WriteString(strings.Join(columns, ", "))
+		sql.WriteString(")")
+	}
+	sql.WriteString(" ")
+	args := sub.compileSelect(&sql, qb.placeholders())
+
+	result, err := qb.connection.Insert(sql.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// IntoTemp materializes the query into a new temporary table and returns a builder over
+// it, so multi-step reports can query intermediate results:
+//
+//	spenders, err := eloquent.NewQueryBuilder(conn).Table("orders").
+//		Select("user_id", "SUM(total) AS spent").GroupBy("user_id").IntoTemp("tmp_spenders")
+//	top, err := spenders.Where("spent", ">", 1000).Get()
+//
+// Temporary tables are visible only to the database session that created them. Queries
+// run on any of the connection's pooled sessions, so materialize on connections limited
+// to a single open session with DB.SetMaxOpenConns(1). The table is dropped when the
+// session closes; drop it earlier with the schema builder's DropIfExists.
+func (qb *QueryBuilder) IntoTemp(table string) (*QueryBuilder, error) {
+	if err := validateColumn(table); err != nil {
+		return nil, err
+	}
+	qb = qb.withDefaults()
+	if err := qb.executable(); err != nil {
+		return nil, err
+	}
+
+	query, args := qb.ToSQL()
+	statement := "CREATE TEMPORARY TABLE " + qb.connection.prefixTable(table) + " AS " + query
+	if _, err := qb.connection.Exec(statement, args...); err != nil {
+		return nil, err
+	}
+	return NewQueryBuilder(qb.connection).Table(table), nil
+}
+
 // Helper methods
 func (qb *QueryBuilder) addWhere(column, boolean string, args ...interface{}) *QueryBuilder {
+	qb = qb.mutable()
+
 	var operator string = "="
 	var value interface{}
 
@@ -514,10 +1202,22 @@ func (qb *QueryBuilder) addWhere(column, boolean string, args ...interface{}) *Q
 	case 1:
 		value = args[0]
 	case 2:
-		operator = args[0].(string)
+		op, ok := args[0].(string)
+		if !ok {
+			return qb.fail(fmt.Errorf("%w: operator for %s must be a string, got %T", ErrInvalidQuery, column, args[0]))
+		}
+		normalized, err := normalizeOperator(op)
+		if err != nil {
+			return qb.fail(err)
+		}
+		operator = normalized
 		value = args[1]
 	default:
-		panic("Invalid number of arguments for where clause")
+		return qb.fail(fmt.Errorf("%w: where on %s expects a value or an operator and a value, got %d arguments", ErrInvalidQuery, column, len(args)))
+	}
+
+	if err := validateWhereColumn(column); err != nil {
+		return qb.fail(err)
 	}
 
 	qb.wheres = append(qb.wheres, WhereClause{
@@ -531,30 +1231,228 @@ func (qb *QueryBuilder) addWhere(column, boolean string, args ...interface{}) *Q
 	return qb
 }
 
-func (qb *QueryBuilder) clone() *QueryBuilder {
-	clone := &QueryBuilder{
-		connection: qb.connection,
-		table:      qb.table,
-		wheres:     make([]WhereClause, len(qb.wheres)),
-		orders:     make([]OrderClause, len(qb.orders)),
-		joins:      make([]JoinClause, len(qb.joins)),
-		groups:     make([]string, len(qb.groups)),
-		havings:    make([]HavingClause, len(qb.havings)),
-		columns:    make([]string, len(qb.columns)),
-		distinct:   qb.distinct,
-		eagerLoad:  make(map[string]func(*QueryBuilder)),
+// addIndexHint records an index hint after validating the index names
+func (qb *QueryBuilder) addIndexHint(kind string, indexes []string) *QueryBuilder {
+	qb = qb.mutable()
+	if len(indexes) == 0 {
+		return qb.fail(fmt.Errorf("%w: %s INDEX needs at least one index", ErrInvalidQuery, kind))
 	}
-
-	copy(clone.wheres, qb.wheres)
-	copy(clone.orders, qb.orders)
-	copy(clone.joins, qb.joins)
-	copy(clone.groups, qb.groups)
-	copy(clone.havings, qb.havings)
-	copy(clone.columns, qb.columns)
-
-	if qb.limitValue != nil {
-		val := *qb.limitValue
-		clone.limitValue = &val
+	for _, index := range indexes {
+		if err := validateColumn(index); err != nil {
+			return qb.fail(err)
+		}
+	}
+	qb.indexHints = append(qb.indexHints, indexHint{kind: kind, indexes: indexes})
+	return qb
+}
+
+// writeTable writes the table name with its alias and, for selects on MySQL, its index hints
+func (qb *QueryBuilder) writeTable(sql *strings.Builder, withHints bool) {
+	sql.WriteString(qb.connection.prefixTable(qb.table))
+	if qb.alias != "" {
+		sql.WriteString(" AS ")
+		sql.WriteString(qb.alias)
+	}
+	if !withHints || qb.connection == nil || qb.connection.Driver != "mysql" {
+		return
+	}
+	for _, hint := range qb.indexHints {
+		sql.WriteString(" ")
+		sql.WriteString(hint.kind)
+		sql.WriteString(" INDEX (")
+		sql.WriteString(strings.Join(hint.indexes, ", "))
+		sql.WriteString(")")
+	}
+}
+
+// addJoinOn adds a join with the conditions collected by callback
+func (qb *QueryBuilder) addJoinOn(table, joinType string, callback func(*JoinBuilder)) *QueryBuilder {
+	qb = qb.mutable()
+
+	join := &JoinBuilder{}
+	callback(join)
+	if join.err == nil && len(join.conditions) == 0 {
+		join.err = fmt.Errorf("%w: join on %s has no conditions", ErrInvalidQuery, table)
+	}
+	if join.err != nil {
+		return qb.fail(join.err)
+	}
+
+	qb.joins = append(qb.joins, JoinClause{
+		Table:      table,
+		Type:       joinType,
+		Conditions: join.conditions,
+	})
+	return qb
+}
+
+// addJoinSub adds a join on a copy of sub
+func (qb *QueryBuilder) addJoinSub(sub *QueryBuilder, alias, first, operator, second, joinType string) *QueryBuilder {
+	qb = qb.mutable()
+
+	if sub.err != nil {
+		return qb.fail(sub.err)
+	}
+	operator, err := normalizeOperator(operator)
+	for _, identifier := range []string{alias, first, second} {
+		if err == nil {
+			err = validateColumn(identifier)
+		}
+	}
+	if err != nil {
+		return qb.fail(err)
+	}
+
+	qb.joins = append(qb.joins, JoinClause{
+		Table:    alias,
+		First:    first,
+		Operator: operator,
+		Second:   second,
+		Type:     joinType,
+		Query:    sub.Clone(),
+	})
+	return qb
+}
+
+// On adds a column comparison to the join
+func (jb *JoinBuilder) On(first, operator, second string) *JoinBuilder {
+	return jb.addCondition(JoinCondition{First: first, Operator: operator, Second: second, Boolean: "and"})
+}
+
+// OrOn adds an OR column comparison to the join
+func (jb *JoinBuilder) OrOn(first, operator, second string) *JoinBuilder {
+	return jb.addCondition(JoinCondition{First: first, Operator: operator, Second: second, Boolean: "or"})
+}
+
+// Where adds a comparison of column with a bound value to the join
+func (jb *JoinBuilder) Where(column, operator string, value interface{}) *JoinBuilder {
+	return jb.addCondition(JoinCondition{First: column, Operator: operator, Boolean: "and", Bound: true, Value: value})
+}
+
+// OrWhere adds an OR comparison of column with a bound value to the join
+func (jb *JoinBuilder) OrWhere(column, operator string, value interface{}) *JoinBuilder {
+	return jb.addCondition(JoinCondition{First: column, Operator: operator, Boolean: "or", Bound: true, Value: value})
+}
+
+// addCondition validates and records a join condition, keeping the first error
+func (jb *JoinBuilder) addCondition(condition JoinCondition) *JoinBuilder {
+	if jb.err != nil {
+		return jb
+	}
+
+	operator, err := normalizeOperator(condition.Operator)
+	if err == nil {
+		err = validateColumn(condition.First)
+	}
+	if err == nil && !condition.Bound {
+		err = validateColumn(condition.Second)
+	}
+	if err != nil {
+		jb.err = err
+		return jb
+	}
+
+	condition.Operator = operator
+	jb.conditions = append(jb.conditions, condition)
+	return jb
+}
+
+// addHavingNull adds a having null or having not null clause
+func (qb *QueryBuilder) addHavingNull(column, operator string) *QueryBuilder {
+	qb = qb.mutable()
+	if err := validateHavingColumn(column); err != nil {
+		return qb.fail(err)
+	}
+	qb.havings = append(qb.havings, HavingClause{
+		Column:   column,
+		Operator: operator,
+		Boolean:  "and",
+		Type:     "null",
+	})
+	return qb
+}
+
+// addRawHaving adds a raw having clause with positional bindings
+func (qb *QueryBuilder) addRawHaving(sql, boolean string, bindings []interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	qb.havings = append(qb.havings, HavingClause{
+		Column:  sql,
+		Boolean: boolean,
+		Type:    "raw",
+		Values:  bindings,
+	})
+	return qb
+}
+
+// addRawWhere adds a raw where clause with positional bindings
+func (qb *QueryBuilder) addRawWhere(sql string, bindings []interface{}) *QueryBuilder {
+	qb = qb.mutable()
+	qb.wheres = append(qb.wheres, WhereClause{
+		Column:  sql,
+		Boolean: "and",
+		Type:    "raw",
+		Values:  bindings,
+	})
+	return qb
+}
+
+// addNamedWhere compiles the named parameters of sql to positional ones and adds it as a raw clause
+func (qb *QueryBuilder) addNamedWhere(sql string, arg interface{}, boolean string) *QueryBuilder {
+	qb = qb.mutable()
+
+	query, args, err := sqlx.Named(sql, arg)
+	if err != nil {
+		return qb.fail(fmt.Errorf("%w: %v", ErrInvalidQuery, err))
+	}
+
+	qb.wheres = append(qb.wheres, WhereClause{
+		Column:  query,
+		Boolean: boolean,
+		Type:    "raw",
+		Values:  args,
+	})
+
+	return qb
+}
+
+func (qb *QueryBuilder) clone() *QueryBuilder {
+	clone := &QueryBuilder{
+		connection:    qb.connection,
+		manager:       qb.manager,
+		table:         qb.table,
+		alias:         qb.alias,
+		indexHints:    make([]indexHint, len(qb.indexHints)),
+		wheres:        make([]WhereClause, len(qb.wheres)),
+		orders:        make([]OrderClause, len(qb.orders)),
+		joins:         make([]JoinClause, len(qb.joins)),
+		groups:        make([]string, len(qb.groups)),
+		groupArgs:     make([]interface{}, len(qb.groupArgs)),
+		havings:       make([]HavingClause, len(qb.havings)),
+		columns:       make([]string, len(qb.columns)),
+		distinct:      qb.distinct,
+		immutable:     qb.immutable,
+		ctx:           qb.ctx,
+		err:           qb.err,
+		connectionErr: qb.connectionErr,
+		asOf:          qb.asOf,
+		tiebreaker:    qb.tiebreaker,
+		defaultOrders: qb.defaultOrders,
+		hooked:        qb.hooked,
+		eagerLoad:     make(map[string]func(*QueryBuilder)),
+	}
+
+	copy(clone.wheres, qb.wheres)
+	copy(clone.orders, qb.orders)
+	copy(clone.joins, qb.joins)
+	copy(clone.indexHints, qb.indexHints)
+	copy(clone.groups, qb.groups)
+	copy(clone.groupArgs, qb.groupArgs)
+	copy(clone.havings, qb.havings)
+	copy(clone.columns, qb.columns)
+
+	if qb.limitValue != nil {
+		val := *qb.limitValue
+		clone.limitValue = &val
 	}
 
 	if qb.offsetValue != nil {
@@ -569,102 +1467,92 @@ func (qb *QueryBuilder) clone() *QueryBuilder {
 	return clone
 }
 
-// ToSQL converts the query to SQL
+// ToSQL converts the query to SQL for the builder's connection, with placeholders
+// numbered $1, $2 on PostgreSQL and ? elsewhere. The result is cached until the builder
+// is modified, so running an unchanged query again skips compiling it. The returned
+// arguments are shared with the cache and must not be modified; appending to them is safe.
 func (qb *QueryBuilder) ToSQL() (string, []interface{}) {
+	qb = qb.withDefaults()
+
+	if compiled := qb.compiled.Load(); compiled != nil && compiled.connection == qb.connection {
+		return compiled.sql, compiled.args[:len(compiled.args):len(compiled.args)]
+	}
+
 	var sql strings.Builder
-	var args []interface{}
-	var placeholderIndex int
+	sql.Grow(64 + 32*(len(qb.wheres)+len(qb.joins)+len(qb.orders)+len(qb.columns)))
+	args := qb.compileSelect(&sql, qb.placeholders())
 
-	// Helper function to get the correct placeholder based on database driver
-	getPlaceholder := func() string {
-		placeholderIndex++
-		if qb.connection != nil && qb.connection.Driver == "postgres" {
-			return fmt.Sprintf("$%d", placeholderIndex)
+	compiled := &compiledQuery{connection: qb.connection, sql: sql.String(), args: args}
+	qb.compiled.Store(compiled)
+	return compiled.sql, compiled.args[:len(compiled.args):len(compiled.args)]
+}
+
+// ToSQLFor converts the query to SQL as it would run on the given driver, "postgres",
+// "mysql" or "sqlite3", whatever connection the builder uses, to inspect queries across
+// dialects. The connection's table prefix is kept.
+func (qb *QueryBuilder) ToSQLFor(driver string) (string, []interface{}) {
+	switch strings.ToLower(driver) {
+	case "postgres", "postgresql", "pgsql":
+		driver = "postgres"
+	case "sqlite", "sqlite3":
+		driver = "sqlite3"
+	}
+
+	target := &Connection{Driver: driver}
+	if qb.connection != nil {
+		target.Name, target.Prefix = qb.connection.Name, qb.connection.Prefix
+	}
+	return qb.withDefaults().retarget(target).ToSQL()
+}
+
+// retarget returns a copy of the builder, and of the subqueries it joins, compiled for conn
+func (qb *QueryBuilder) retarget(conn *Connection) *QueryBuilder {
+	clone := qb.clone()
+	clone.connection = conn
+	for i, join := range clone.joins {
+		if join.Query != nil {
+			clone.joins[i].Query = join.Query.retarget(conn)
 		}
-		return "?"
 	}
+	return clone
+}
+
+// compileSelect writes the select statement to sql and returns its arguments.
+// Subqueries share the outer query's getPlaceholder so placeholders stay numbered in order.
+func (qb *QueryBuilder) compileSelect(sql *strings.Builder, getPlaceholder func() string) []interface{} {
+	args := make([]interface{}, 0, len(qb.wheres)+len(qb.groupArgs)+len(qb.havings)+2)
 
 	// SELECT clause
 	sql.WriteString("SELECT ")
 	if qb.distinct {
 		sql.WriteString("DISTINCT ")
 	}
-	sql.WriteString(strings.Join(qb.columns, ", "))
+	for i, column := range qb.columns {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(column)
+	}
 
 	// FROM clause
 	sql.WriteString(" FROM ")
-	sql.WriteString(qb.table)
+	if qb.asOf != nil {
+		args = append(args, qb.writeAsOf(sql, getPlaceholder)...)
+	} else {
+		qb.writeTable(sql, true)
+	}
 
 	// JOIN clauses
-	for _, join := range qb.joins {
-		sql.WriteString(" ")
-		sql.WriteString(strings.ToUpper(join.Type))
-		sql.WriteString(" JOIN ")
-		sql.WriteString(join.Table)
-		if join.Type != "cross" {
-			sql.WriteString(" ON ")
-			sql.WriteString(join.First)
-			sql.WriteString(" ")
-			sql.WriteString(join.Operator)
-			sql.WriteString(" ")
-			sql.WriteString(join.Second)
-		}
-	}
+	args = append(args, qb.compileJoins(sql, getPlaceholder)...)
 
 	// WHERE clauses
-	if len(qb.wheres) > 0 {
-		sql.WriteString(" WHERE ")
-		for i, where := range qb.wheres {
-			if i > 0 {
-				sql.WriteString(" ")
-				sql.WriteString(strings.ToUpper(where.Boolean))
-				sql.WriteString(" ")
-			}
-
-			switch where.Type {
-			case "basic":
-				sql.WriteString(where.Column)
-				sql.WriteString(" ")
-				sql.WriteString(where.Operator)
-				sql.WriteString(" ")
-				sql.WriteString(getPlaceholder())
-				args = append(args, where.Value)
-			case "in":
-				sql.WriteString(where.Column)
-				if where.Operator == "not in" {
-					sql.WriteString(" NOT IN (")
-				} else {
-					sql.WriteString(" IN (")
-				}
-				placeholders := make([]string, len(where.Values))
-				for j, val := range where.Values {
-					placeholders[j] = getPlaceholder()
-					args = append(args, val)
-				}
-				sql.WriteString(strings.Join(placeholders, ", "))
-				sql.WriteString(")")
-			case "null":
-				sql.WriteString(where.Column)
-				if where.Operator == "not null" {
-					sql.WriteString(" IS NOT NULL")
-				} else {
-					sql.WriteString(" IS NULL")
-				}
-			case "between":
-				sql.WriteString(where.Column)
-				sql.WriteString(" BETWEEN ")
-				sql.WriteString(getPlaceholder())
-				sql.WriteString(" AND ")
-				sql.WriteString(getPlaceholder())
-				args = append(args, where.Values[0], where.Values[1])
-			}
-		}
-	}
+	args = append(args, qb.compileWheres(sql, getPlaceholder)...)
 
 	// GROUP BY clause
 	if len(qb.groups) > 0 {
 		sql.WriteString(" GROUP BY ")
-		sql.WriteString(strings.Join(qb.groups, ", "))
+		writeRaw(sql, strings.Join(qb.groups, ", "), getPlaceholder)
+		args = append(args, qb.groupArgs...)
 	}
 
 	// HAVING clauses
@@ -673,26 +1561,50 @@ func (qb *QueryBuilder) ToSQL() (string, []interface{}) {
 		for i, having := range qb.havings {
 			if i > 0 {
 				sql.WriteString(" ")
-				sql.WriteString(strings.ToUpper(having.Boolean))
+				sql.WriteString(sqlKeyword(having.Boolean))
 				sql.WriteString(" ")
 			}
-			sql.WriteString(having.Column)
-			sql.WriteString(" ")
-			sql.WriteString(having.Operator)
-			sql.WriteString(" ")
-			sql.WriteString(getPlaceholder())
-			args = append(args, having.Value)
+
+			switch having.Type {
+			case "between":
+				sql.WriteString(qb.havingExpression(having.Column))
+				sql.WriteString(" BETWEEN ")
+				sql.WriteString(getPlaceholder())
+				sql.WriteString(" AND ")
+				sql.WriteString(getPlaceholder())
+				args = append(args, having.Values[0], having.Values[1])
+			case "null":
+				sql.WriteString(qb.havingExpression(having.Column))
+				if having.Operator == "not null" {
+					sql.WriteString(" IS NOT NULL")
+				} else {
+					sql.WriteString(" IS NULL")
+				}
+			case "raw":
+				sql.WriteString("(")
+				writeRaw(sql, having.Column, getPlaceholder)
+				sql.WriteString(")")
+				args = append(args, having.Values...)
+			default:
+				sql.WriteString(qb.havingExpression(having.Column))
+				sql.WriteString(" ")
+				sql.WriteString(having.Operator)
+				sql.WriteString(" ")
+				sql.WriteString(getPlaceholder())
+				args = append(args, having.Value)
+			}
 		}
 	}
 
 	// ORDER BY clause
-	if len(qb.orders) > 0 {
+	if orders := qb.effectiveOrders(); len(orders) > 0 {
 		sql.WriteString(" ORDER BY ")
-		orderClauses := make([]string, len(qb.orders))
-		for i, order := range qb.orders {
-			orderClauses[i] = order.Column + " " + strings.ToUpper(order.Direction)
+		for i, order := range orders {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			args = append(args, qb.compileOrder(sql, order, getPlaceholder)...)
 		}
-		sql.WriteString(strings.Join(orderClauses, ", "))
 	}
 
 	// LIMIT and OFFSET clauses
@@ -718,5 +1630,353 @@ func (qb *QueryBuilder) ToSQL() (string, []interface{}) {
 		args = append(args, *qb.offsetValue)
 	}
 
-	return sql.String(), args
+	return args
+}
+
+// placeholders returns a function yielding the next bind placeholder for the connection's driver
+func (qb *QueryBuilder) placeholders() func() string {
+	if qb.connection == nil || qb.connection.Driver != "postgres" {
+		return func() string { return "?" }
+	}
+
+	var placeholderIndex int
+	return func() string {
+		placeholderIndex++
+		return "$" + strconv.Itoa(placeholderIndex)
+	}
+}
+
+// sqlKeyword returns an SQL keyword such as a boolean or sort direction in upper case,
+// without allocating for the common ones
+func sqlKeyword(word string) string {
+	switch word {
+	case "and", "AND":
+		return "AND"
+	case "or", "OR":
+		return "OR"
+	case "asc", "ASC":
+		return "ASC"
+	case "desc", "DESC":
+		return "DESC"
+	}
+	return strings.ToUpper(word)
+}
+
+// compileJoins writes the join clauses to sql and returns their arguments
+func (qb *QueryBuilder) compileJoins(sql *strings.Builder, getPlaceholder func() string) []interface{} {
+	var args []interface{}
+	for _, join := range qb.joins {
+		sql.WriteString(" ")
+		sql.WriteString(strings.ToUpper(join.Type))
+		sql.WriteString(" JOIN ")
+		args = append(args, qb.writeJoinTable(sql, join, getPlaceholder)...)
+		if join.Type == "cross" {
+			continue
+		}
+
+		sql.WriteString(" ON ")
+		args = append(args, writeJoinConditions(sql, join, getPlaceholder)...)
+	}
+	return args
+}
+
+// writeJoinTable writes the table or subquery a join reads from
+func (qb *QueryBuilder) writeJoinTable(sql *strings.Builder, join JoinClause, getPlaceholder func() string) []interface{} {
+	if join.Query == nil {
+		sql.WriteString(qb.connection.prefixTable(join.Table))
+		return nil
+	}
+	sql.WriteString("(")
+	args := join.Query.withDefaults().compileSelect(sql, getPlaceholder)
+	sql.WriteString(") AS ")
+	sql.WriteString(join.Table)
+	return args
+}
+
+// writeJoinConditions writes the conditions of a join's ON clause
+func writeJoinConditions(sql *strings.Builder, join JoinClause, getPlaceholder func() string) []interface{} {
+	if len(join.Conditions) == 0 {
+		sql.WriteString(join.First)
+		sql.WriteString(" ")
+		sql.WriteString(join.Operator)
+		sql.WriteString(" ")
+		sql.WriteString(join.Second)
+		return nil
+	}
+
+	var args []interface{}
+	for i, condition := range join.Conditions {
+		if i > 0 {
+			sql.WriteString(" ")
+			sql.WriteString(sqlKeyword(condition.Boolean))
+			sql.WriteString(" ")
+		}
+		sql.WriteString(condition.First)
+		sql.WriteString(" ")
+		sql.WriteString(condition.Operator)
+		sql.WriteString(" ")
+		if condition.Bound {
+			sql.WriteString(getPlaceholder())
+			args = append(args, condition.Value)
+		} else {
+			sql.WriteString(condition.Second)
+		}
+	}
+	return args
+}
+
+// compileJoinsAsTables writes the joined tables as a comma-separated list, for the FROM
+// clause of an update or the USING clause of a delete, followed by a WHERE clause holding
+// their join conditions and the builder's where clauses
+func (qb *QueryBuilder) compileJoinsAsTables(sql *strings.Builder, getPlaceholder func() string) ([]interface{}, error) {
+	var args []interface{}
+	for i, join := range qb.joins {
+		if join.Type != "inner" && join.Type != "cross" {
+			return nil, fmt.Errorf("%w: %s joins are not supported when updating or deleting on %s", ErrInvalidQuery, join.Type, qb.connection.Driver)
+		}
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		args = append(args, qb.writeJoinTable(sql, join, getPlaceholder)...)
+	}
+
+	conditions := 0
+	for _, join := range qb.joins {
+		if join.Type == "cross" {
+			continue
+		}
+		if conditions == 0 {
+			sql.WriteString(" WHERE ")
+		} else {
+			sql.WriteString(" AND ")
+		}
+		conditions++
+		sql.WriteString("(")
+		args = append(args, writeJoinConditions(sql, join, getPlaceholder)...)
+		sql.WriteString(")")
+	}
+
+	var wheres strings.Builder
+	args = append(args, qb.compileWheres(&wheres, getPlaceholder)...)
+	if wheres.Len() > 0 {
+		if conditions == 0 {
+			sql.WriteString(wheres.String())
+		} else {
+			sql.WriteString(" AND (")
+			sql.WriteString(strings.TrimPrefix(wheres.String(), " WHERE "))
+			sql.WriteString(")")
+		}
+	}
+	return args, nil
+}
+
+// tableReference returns the name the builder's table is referred to by in the query
+func (qb *QueryBuilder) tableReference() string {
+	if qb.alias != "" {
+		return qb.alias
+	}
+	return qb.connection.prefixTable(qb.table)
+}
+
+// havingExpression returns what a having clause on column compares. PostgreSQL doesn't
+// resolve select aliases in HAVING, so there an alias of a selected expression, such as
+// count in "COUNT(*) AS count", is replaced with the expression; MySQL and SQLite
+// resolve aliases themselves.
+func (qb *QueryBuilder) havingExpression(column string) string {
+	if qb.connection == nil || qb.connection.Driver != "postgres" {
+		return column
+	}
+	for _, selected := range qb.columns {
+		if expression, alias, ok := splitSelectAlias(selected); ok && strings.EqualFold(alias, column) {
+			return expression
+		}
+	}
+	return column
+}
+
+// splitSelectAlias splits a selected column such as "COUNT(*) AS count" into its
+// expression and alias
+func splitSelectAlias(column string) (expression, alias string, ok bool) {
+	index := strings.LastIndex(strings.ToLower(column), " as ")
+	if index < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(column[:index]), strings.TrimSpace(column[index+len(" as "):]), true
+}
+
+// writeRaw writes a raw SQL fragment, replacing its ? placeholders with the driver's placeholders
+func writeRaw(sql *strings.Builder, fragment string, getPlaceholder func() string) {
+	for i, part := range strings.Split(fragment, "?") {
+		if i > 0 {
+			sql.WriteString(getPlaceholder())
+		}
+		sql.WriteString(part)
+	}
+}
+
+// compileWheres writes the WHERE clause to sql and returns its arguments
+func (qb *QueryBuilder) compileWheres(sql *strings.Builder, getPlaceholder func() string) []interface{} {
+	if len(qb.wheres) == 0 {
+		return nil
+	}
+	sql.WriteString(" WHERE ")
+	return qb.compileWhereClauses(sql, qb.wheres, getPlaceholder)
+}
+
+// compileWhereClauses writes where clauses joined by their booleans and returns their arguments
+func (qb *QueryBuilder) compileWhereClauses(sql *strings.Builder, wheres []WhereClause, getPlaceholder func() string) []interface{} {
+	var args []interface{}
+
+	for i, where := range wheres {
+		if i > 0 {
+			sql.WriteString(" ")
+			sql.WriteString(sqlKeyword(where.Boolean))
+			sql.WriteString(" ")
+		}
+
+		switch where.Type {
+		case "basic":
+			sql.WriteString(where.Column)
+			sql.WriteString(" ")
+			sql.WriteString(where.Operator)
+			sql.WriteString(" ")
+			sql.WriteString(getPlaceholder())
+			args = append(args, where.Value)
+		case "in":
+			args = append(args, qb.compileWhereIn(sql, where, getPlaceholder)...)
+		case "null":
+			sql.WriteString(where.Column)
+			if where.Operator == "not null" {
+				sql.WriteString(" IS NOT NULL")
+			} else {
+				sql.WriteString(" IS NULL")
+			}
+		case "between":
+			sql.WriteString(where.Column)
+			sql.WriteString(" BETWEEN ")
+			sql.WriteString(getPlaceholder())
+			sql.WriteString(" AND ")
+			sql.WriteString(getPlaceholder())
+			args = append(args, where.Values[0], where.Values[1])
+		case "raw":
+			sql.WriteString("(")
+			writeRaw(sql, where.Column, getPlaceholder)
+			sql.WriteString(")")
+			args = append(args, where.Values...)
+		case "nested":
+			sql.WriteString("(")
+			args = append(args, qb.compileWhereClauses(sql, where.Wheres, getPlaceholder)...)
+			sql.WriteString(")")
+		}
+	}
+
+	return args
+}
+
+// maxInListSize is the number of values above which IN lists are split into groups,
+// or sent as a single array on PostgreSQL
+const maxInListSize = 1000
+
+// compileWhereIn writes an IN or NOT IN clause to sql and returns its arguments.
+// Long lists would produce statements some drivers reject, so on PostgreSQL they are
+// bound as one array and elsewhere split into IN groups of at most maxInListSize values.
+func (qb *QueryBuilder) compileWhereIn(sql *strings.Builder, where WhereClause, getPlaceholder func() string) []interface{} {
+	not := where.Operator == "not in"
+
+	if len(where.Values) > maxInListSize && qb.connection != nil && qb.connection.Driver == "postgres" {
+		sql.WriteString(where.Column)
+		if not {
+			sql.WriteString(" <> ALL(")
+		} else {
+			sql.WriteString(" = ANY(")
+		}
+		sql.WriteString(getPlaceholder())
+		sql.WriteString(")")
+		return []interface{}{pq.Array(where.Values)}
+	}
+
+	keyword, boolean := " IN (", " OR "
+	if not {
+		keyword, boolean = " NOT IN (", " AND "
+	}
+
+	groups := (len(where.Values) + maxInListSize - 1) / maxInListSize
+	if groups > 1 {
+		sql.WriteString("(")
+	}
+	for start := 0; start < len(where.Values) || start == 0; start += maxInListSize {
+		end := start + maxInListSize
+		if end > len(where.Values) {
+			end = len(where.Values)
+		}
+		if start > 0 {
+			sql.WriteString(boolean)
+		}
+
+		sql.WriteString(where.Column)
+		sql.WriteString(keyword)
+		for j := start; j < end; j++ {
+			if j > start {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(getPlaceholder())
+		}
+		sql.WriteString(")")
+	}
+	if groups > 1 {
+		sql.WriteString(")")
+	}
+
+	return where.Values
+}
+
+// allowedOperators lists the comparison operators accepted by where and having clauses
+var allowedOperators = map[string]bool{
+	"=": true, "!=": true, "<>": true, "<": true, ">": true, "<=": true, ">=": true, "<=>": true,
+	"like": true, "not like": true, "ilike": true, "not ilike": true,
+	"regexp": true, "not regexp": true, "similar to": true, "not similar to": true,
+}
+
+const identifierPattern = `[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*){0,2}`
+
+var (
+	// identifierRegexp matches column, table.column and schema.table.column
+	identifierRegexp = regexp.MustCompile(`^` + identifierPattern + `$`)
+	// datePartRegexp matches the columns produced by WhereDate, WhereTime and friends
+	datePartRegexp = regexp.MustCompile(`^(?:DATE|TIME|YEAR|MONTH|DAY)\(` + identifierPattern + `\)$`)
+	// aggregateRegexp matches the aggregates allowed in having clauses
+	aggregateRegexp = regexp.MustCompile(`(?i)^(?:COUNT|SUM|AVG|MIN|MAX)\((?:\*|` + identifierPattern + `)\)$`)
+)
+
+// normalizeOperator collapses whitespace in operator and checks it against allowedOperators
+func normalizeOperator(operator string) (string, error) {
+	normalized := strings.Join(strings.Fields(operator), " ")
+	if !allowedOperators[strings.ToLower(normalized)] {
+		return "", fmt.Errorf("%w: unsupported operator %q", ErrInvalidQuery, operator)
+	}
+	return normalized, nil
+}
+
+// validateColumn checks that column is a plain, optionally qualified, identifier
+func validateColumn(column string) error {
+	if !identifierRegexp.MatchString(column) {
+		return fmt.Errorf("%w: invalid column %q", ErrInvalidQuery, column)
+	}
+	return nil
+}
+
+// validateWhereColumn also accepts the date functions applied by WhereDate and friends
+func validateWhereColumn(column string) error {
+	if datePartRegexp.MatchString(column) {
+		return nil
+	}
+	return validateColumn(column)
+}
+
+// validateHavingColumn also accepts aggregates such as COUNT(*)
+func validateHavingColumn(column string) error {
+	if aggregateRegexp.MatchString(column) {
+		return nil
+	}
+	return validateColumn(column)
 }
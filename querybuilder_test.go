@@ -1,24 +1,21 @@
 package eloquent
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
 func setupQueryBuilderTestDB(t *testing.T) {
-	// Set up in-memory SQLite database for testing
-	err := SQLite(":memory:")
-	if err != nil {
-		t.Fatalf("Failed to set up test database: %v", err)
-	}
-
-	// Create test tables
-	conn := DB()
-	if conn == nil {
-		t.Fatal("Failed to get database connection")
-	}
+	// Use a private in-memory SQLite database as the default connection
+	conn := NewTestSQLite(t)
+	WithTestConnection(t, conn)
 
 	// Create users table
-	_, err = conn.Exec(`
+	_, err := conn.Exec(`
 		CREATE TABLE users (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			name TEXT NOT NULL,
@@ -75,13 +72,8 @@ func setupQueryBuilderTestDB(t *testing.T) {
 	}
 }
 
-func teardownQueryBuilderTestDB() {
-	_ = GetManager().CloseAll()
-}
-
 func TestQueryBuilderBasicSelect(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -107,7 +99,6 @@ func TestQueryBuilderBasicSelect(t *testing.T) {
 
 func TestQueryBuilderSelectColumns(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -137,7 +128,6 @@ func TestQueryBuilderSelectColumns(t *testing.T) {
 
 func TestQueryBuilderWhere(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -162,7 +152,6 @@ func TestQueryBuilderWhere(t *testing.T) {
 
 func TestQueryBuilderWhereOperators(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 
@@ -198,7 +187,6 @@ func TestQueryBuilderWhereOperators(t *testing.T) {
 
 func TestQueryBuilderWhereIn(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -236,7 +224,6 @@ func TestQueryBuilderWhereIn(t *testing.T) {
 
 func TestQueryBuilderWhereNotIn(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -262,7 +249,6 @@ func TestQueryBuilderWhereNotIn(t *testing.T) {
 
 func TestQueryBuilderWhereNull(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	conn := DB()
@@ -292,7 +278,6 @@ func TestQueryBuilderWhereNull(t *testing.T) {
 
 func TestQueryBuilderWhereNotNull(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	conn := DB()
@@ -325,7 +310,6 @@ func TestQueryBuilderWhereNotNull(t *testing.T) {
 
 func TestQueryBuilderWhereBetween(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -351,7 +335,6 @@ func TestQueryBuilderWhereBetween(t *testing.T) {
 
 func TestQueryBuilderOrWhere(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -387,9 +370,54 @@ func TestQueryBuilderOrWhere(t *testing.T) {
 	}
 }
 
+func TestQueryBuilderWhereNamed(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	type ageRange struct {
+		Min int `db:"min_age"`
+		Max int `db:"max_age"`
+	}
+
+	// Test struct bindings
+	results, err := NewQueryBuilder(DB()).Table("users").
+		WhereNamed("age >= :min_age AND age < :max_age", ageRange{Min: 28, Max: 35}).
+		OrderBy("age", "asc").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute WhereNamed query: %v", err)
+	}
+	if len(results) != 2 || results[0]["name"] != "Alice Brown" || results[1]["name"] != "Jane Smith" {
+		t.Errorf("Expected Alice Brown and Jane Smith, got %v", results)
+	}
+
+	// Test map bindings mixed with positional clauses
+	results, err = NewQueryBuilder(DB()).Table("users").
+		Where("status", "active").
+		OrWhereNamed("name = :name", map[string]interface{}{"name": "Bob Johnson"}).
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute OrWhereNamed query: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("Expected 4 users, got %d", len(results))
+	}
+
+	// Test placeholder numbering for postgres
+	sql, args := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("users").
+		Where("status", "active").
+		WhereNamed("age BETWEEN :min_age AND :max_age", ageRange{Min: 20, Max: 30}).
+		ToSQL()
+	expected := "SELECT * FROM users WHERE status = $1 AND (age BETWEEN $2 AND $3)"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+	if len(args) != 3 || args[1] != 20 || args[2] != 30 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
 func TestQueryBuilderOrderBy(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -432,7 +460,6 @@ func TestQueryBuilderOrderBy(t *testing.T) {
 
 func TestQueryBuilderOrderByDesc(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -455,7 +482,6 @@ func TestQueryBuilderOrderByDesc(t *testing.T) {
 
 func TestQueryBuilderLimit(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -473,7 +499,6 @@ func TestQueryBuilderLimit(t *testing.T) {
 
 func TestQueryBuilderOffset(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -497,7 +522,6 @@ func TestQueryBuilderOffset(t *testing.T) {
 
 func TestQueryBuilderLimitOffset(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -523,7 +547,6 @@ func TestQueryBuilderLimitOffset(t *testing.T) {
 
 func TestQueryBuilderJoin(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -561,9 +584,365 @@ func TestQueryBuilderJoin(t *testing.T) {
 	}
 }
 
+func TestQueryBuilderJoinOn(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	// Multiple conditions with OrOn
+	results, err := NewQueryBuilder(DB()).Table("users").
+		Select("users.name", "posts.title").
+		JoinOn("posts", func(j *JoinBuilder) {
+			j.On("posts.user_id", "=", "users.id").OrOn("posts.views", "=", "users.age")
+		}).
+		Where("users.name", "Jane Smith").
+		OrderBy("posts.title", "asc").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute JoinOn query: %v", err)
+	}
+	if len(results) != 2 || results[0]["title"] != "Fourth Post" || results[1]["title"] != "Third Post" {
+		t.Errorf("Expected Jane's two posts, got %v", results)
+	}
+
+	// Left joins keep users without matching posts
+	results, err = NewQueryBuilder(DB()).Table("users").
+		Select("users.name", "posts.id as post_id").
+		LeftJoinOn("posts", func(j *JoinBuilder) {
+			j.On("posts.user_id", "=", "users.id").On("posts.published", "=", "users.is_admin")
+		}).
+		Where("users.name", "Bob Johnson").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute LeftJoinOn query: %v", err)
+	}
+	if len(results) != 1 || results[0]["post_id"] != nil {
+		t.Errorf("Expected Bob without posts, got %v", results)
+	}
+
+	sql, _ := NewQueryBuilder(DB()).Table("users").
+		JoinOn("posts", func(j *JoinBuilder) {
+			j.On("posts.user_id", "=", "users.id").OrOn("posts.editor_id", "=", "users.id")
+		}).
+		ToSQL()
+	expected := "SELECT * FROM users INNER JOIN posts ON posts.user_id = users.id OR posts.editor_id = users.id"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+
+	// Invalid or missing conditions are reported
+	err = NewQueryBuilder(DB()).Table("users").JoinOn("posts", func(j *JoinBuilder) {
+		j.On("posts.user_id", "= users.id OR 1 =", "1")
+	}).Err()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for invalid operator, got %v", err)
+	}
+	err = NewQueryBuilder(DB()).Table("users").JoinOn("posts", func(j *JoinBuilder) {}).Err()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for join without conditions, got %v", err)
+	}
+}
+
+func TestQueryBuilderJoinWhere(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	// Left joins with a bound value keep users without matching posts
+	results, err := NewQueryBuilder(DB()).Table("users").
+		Select("users.name", "COUNT(posts.id) as popular_posts").
+		LeftJoinOn("posts", func(j *JoinBuilder) {
+			j.On("posts.user_id", "=", "users.id").Where("posts.views", ">=", 150)
+		}).
+		GroupBy("users.id", "users.name").
+		OrderBy("users.id", "asc").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute join with bound value: %v", err)
+	}
+	counts := make([]int64, len(results))
+	for i, result := range results {
+		counts[i] = result["popular_posts"].(int64)
+	}
+	if fmt.Sprint(counts) != "[0 2 0 0]" {
+		t.Errorf("Expected popular post counts [0 2 0 0], got %v", counts)
+	}
+
+	results, err = NewQueryBuilder(DB()).Table("posts").
+		JoinWhere("users", "users.status", "=", "inactive").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute JoinWhere query: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("Expected every post paired with the inactive user, got %d rows", len(results))
+	}
+
+	// Join bindings precede where bindings
+	sql, args := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("users").
+		LeftJoinWhere("orders", "orders.status", "=", "paid").
+		JoinOn("posts", func(j *JoinBuilder) {
+			j.On("posts.user_id", "=", "users.id").OrWhere("posts.views", ">", 100)
+		}).
+		Where("users.status", "active").
+		ToSQL()
+	expected := "SELECT * FROM users LEFT JOIN orders ON orders.status = $1 " +
+		"INNER JOIN posts ON posts.user_id = users.id OR posts.views > $2 WHERE users.status = $3"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+	if fmt.Sprint(args) != "[paid 100 active]" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+func TestQueryBuilderJoinSub(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	totals := NewQueryBuilder(DB()).Table("posts").
+		Select("user_id", "SUM(views) as total_views").
+		Where("published", true).
+		GroupBy("user_id")
+
+	results, err := NewQueryBuilder(DB()).Table("users").
+		Select("users.name", "totals.total_views").
+		JoinSub(totals, "totals", "totals.user_id", "=", "users.id").
+		Where("users.status", "active").
+		OrderBy("users.name", "asc").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute JoinSub query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 users with published posts, got %d", len(results))
+	}
+	if results[0]["name"] != "Jane Smith" || results[0]["total_views"] != int64(350) {
+		t.Errorf("Expected Jane Smith with 350 views, got %v", results[0])
+	}
+	if results[1]["name"] != "John Doe" || results[1]["total_views"] != int64(100) {
+		t.Errorf("Expected John Doe with 100 views, got %v", results[1])
+	}
+
+	// Subquery bindings come first and share the placeholder numbering
+	sub := NewQueryBuilder(nil).Table("posts").Select("user_id").Where("published", true)
+	sql, args := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("users").
+		LeftJoinSub(sub, "published_posts", "published_posts.user_id", "=", "users.id").
+		Where("users.status", "active").
+		ToSQL()
+	expected := "SELECT * FROM users LEFT JOIN (SELECT user_id FROM posts WHERE published = $1) AS published_posts " +
+		"ON published_posts.user_id = users.id WHERE users.status = $2"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+	if len(args) != 2 || args[0] != true || args[1] != "active" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// Later changes to the subquery do not affect the join
+	joined := NewQueryBuilder(nil).Table("users").JoinSub(sub, "p", "p.user_id", "=", "users.id")
+	sub.Where("views", ">", 10)
+	if again, _ := joined.ToSQL(); strings.Contains(again, "views >") {
+		t.Errorf("Expected subquery to be copied, got %q", again)
+	}
+
+	// Invalid aliases are rejected
+	err = NewQueryBuilder(DB()).Table("users").JoinSub(totals, "t; DROP TABLE users", "t.user_id", "=", "users.id").Err()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for invalid alias, got %v", err)
+	}
+}
+
+func TestQueryBuilderFromAliasAndIndexHints(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	results, err := NewQueryBuilder(DB()).From("users", "u").
+		Select("u.name", "p.title").
+		Join("posts AS p", "p.user_id", "=", "u.id").
+		Where("u.age", ">", 28).
+		UseIndex("idx_users_age").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute aliased query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected Jane's 2 posts, got %d", len(results))
+	}
+
+	affected, err := NewQueryBuilder(DB()).From("users", "u").Where("u.status", "inactive").Update(map[string]interface{}{"age": 36})
+	if err != nil {
+		t.Fatalf("Failed to update through alias: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("Expected 1 updated user, got %d", affected)
+	}
+
+	// Hints are only compiled for MySQL
+	mysql := NewQueryBuilder(&Connection{Driver: "mysql"}).From("orders", "o").
+		ForceIndex("idx_status", "idx_created").
+		IgnoreIndex("idx_legacy").
+		Where("o.status", "paid")
+	sql, _ := mysql.ToSQL()
+	expected := "SELECT * FROM orders AS o FORCE INDEX (idx_status, idx_created) IGNORE INDEX (idx_legacy) WHERE o.status = ?"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+
+	sql, _ = NewQueryBuilder(&Connection{Driver: "postgres"}).From("orders", "o").UseIndex("idx_status").ToSQL()
+	if sql != "SELECT * FROM orders AS o" {
+		t.Errorf("Expected hints to be ignored on postgres, got %q", sql)
+	}
+
+	// Aliases and index names are validated
+	if err := NewQueryBuilder(DB()).From("users", "u; DROP TABLE users").Err(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for invalid alias, got %v", err)
+	}
+	if err := NewQueryBuilder(DB()).Table("users").UseIndex("idx) UNION SELECT (1").Err(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for invalid index, got %v", err)
+	}
+}
+
+func TestQueryBuilderConnectionAndTablePrefix(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	err := GetManager().AddConnection("analytics", ConnectionConfig{
+		Driver:   "sqlite3",
+		Database: UniqueMemorySQLiteDSN(t.Name()),
+		Prefix:   "stats_",
+	})
+	if err != nil {
+		t.Fatalf("Failed to add analytics connection: %v", err)
+	}
+	defer func() { _ = GetManager().RemoveConnection("analytics") }()
+
+	analytics := DB("analytics")
+	if _, err := analytics.Exec("CREATE TABLE stats_events (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("Failed to create events table: %v", err)
+	}
+	if _, err := analytics.Exec("INSERT INTO stats_events (name) VALUES ('signup'), ('login')"); err != nil {
+		t.Fatalf("Failed to insert events: %v", err)
+	}
+
+	// The builder was created on the default connection but runs on analytics
+	count, err := NewQueryBuilder(DB()).Connection("analytics").Table("events").Count()
+	if err != nil {
+		t.Fatalf("Failed to count events: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 events, got %d", count)
+	}
+
+	// The prefix applies to tables and joins but not to qualified names
+	qb := NewQueryBuilder(analytics).From("events", "e").
+		Join("users AS u", "u.id", "=", "e.user_id").
+		Join("main.accounts", "main.accounts.id", "=", "u.account_id")
+	sql, _ := qb.ToSQL()
+	expected := "SELECT * FROM stats_events AS e INNER JOIN stats_users AS u ON u.id = e.user_id INNER JOIN main.accounts ON main.accounts.id = u.account_id"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+
+	if err := NewQueryBuilder(DB()).Connection("missing").Table("users").Err(); err == nil {
+		t.Error("Expected error for unknown connection")
+	}
+}
+
+func TestQueryBuilderKeysetPaginate(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE scores (id INTEGER PRIMARY KEY, score INTEGER)"); err != nil {
+		t.Fatalf("Failed to create scores table: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO scores (score) VALUES (5), (3), (5), (1), (3), (5), (2)"); err != nil {
+		t.Fatalf("Failed to insert scores: %v", err)
+	}
+
+	pages := func(order string) [][]int64 {
+		var ids [][]int64
+		cursor := ""
+		for {
+			page, err := NewQueryBuilder(conn).Table("scores").KeysetPaginate(order, cursor, 3)
+			if err != nil {
+				t.Fatalf("Failed to fetch page: %v", err)
+			}
+			var pageIDs []int64
+			for _, row := range page.Data {
+				pageIDs = append(pageIDs, row["id"].(int64))
+			}
+			ids = append(ids, pageIDs)
+			if page.NextCursor == "" {
+				return ids
+			}
+			cursor = page.NextCursor
+		}
+	}
+
+	// Ties on score are broken by id, so no row is skipped or repeated between pages
+	expected := [][]int64{{4, 7, 2}, {5, 1, 3}, {6}}
+	if got := pages("score"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected ascending pages %v, got %v", expected, got)
+	}
+	expected = [][]int64{{6, 3, 1}, {5, 2, 7}, {4}}
+	if got := pages("-score"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected descending pages %v, got %v", expected, got)
+	}
+	expected = [][]int64{{1, 2, 3}, {4, 5, 6}, {7}}
+	if got := pages("id"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected pages by id %v, got %v", expected, got)
+	}
+
+	if _, err := NewQueryBuilder(conn).Table("scores").KeysetPaginate("score", "garbage", 3); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for a malformed cursor, got %v", err)
+	}
+	if _, err := NewQueryBuilder(conn).Table("scores").KeysetPaginate("score; DROP TABLE scores", "", 3); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an invalid column, got %v", err)
+	}
+}
+
+func TestQueryBuilderLongWhereIn(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("Failed to create items table: %v", err)
+	}
+	if _, err := conn.Exec("WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 3000) INSERT INTO items (id) SELECT i FROM n"); err != nil {
+		t.Fatalf("Failed to insert items: %v", err)
+	}
+
+	ids := make([]interface{}, 2500)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	qb := NewQueryBuilder(conn).Table("items").WhereIn("id", ids)
+	sql, args := qb.ToSQL()
+	if strings.Count(sql, "id IN (") != 3 || !strings.Contains(sql, ") OR id IN (") || len(args) != 2500 {
+		t.Errorf("Expected 3 IN groups joined by OR, got %d groups and %d args", strings.Count(sql, "id IN ("), len(args))
+	}
+	count, err := qb.Count()
+	if err != nil {
+		t.Fatalf("Failed to count chunked IN: %v", err)
+	}
+	if count != 2500 {
+		t.Errorf("Expected 2500 items, got %d", count)
+	}
+
+	count, err = NewQueryBuilder(conn).Table("items").WhereNotIn("id", ids).Count()
+	if err != nil {
+		t.Fatalf("Failed to count chunked NOT IN: %v", err)
+	}
+	if count != 500 {
+		t.Errorf("Expected 500 items outside the list, got %d", count)
+	}
+
+	// PostgreSQL receives long lists as a single array parameter
+	sql, args = NewQueryBuilder(&Connection{Driver: "postgres"}).Table("items").Where("active", true).WhereNotIn("id", ids).ToSQL()
+	if sql != "SELECT * FROM items WHERE active = $1 AND id <> ALL($2)" || len(args) != 2 {
+		t.Errorf("Unexpected PostgreSQL query %q with %d args", sql, len(args))
+	}
+
+	// Short lists are unchanged
+	sql, _ = NewQueryBuilder(&Connection{Driver: "postgres"}).Table("items").WhereIn("id", ids[:3]).ToSQL()
+	if sql != "SELECT * FROM items WHERE id IN ($1, $2, $3)" {
+		t.Errorf("Unexpected short IN query %q", sql)
+	}
+}
+
 func TestQueryBuilderFirst(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -581,7 +960,6 @@ func TestQueryBuilderFirst(t *testing.T) {
 
 func TestQueryBuilderFind(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -602,7 +980,6 @@ func TestQueryBuilderFind(t *testing.T) {
 
 func TestQueryBuilderCount(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -629,9 +1006,49 @@ func TestQueryBuilderCount(t *testing.T) {
 	}
 }
 
+func TestQueryBuilderAggregates(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	posts := NewQueryBuilder(DB()).Table("posts")
+
+	// Integer columns are summed without losing the result
+	sum, err := posts.Clone().Sum("views")
+	if err != nil {
+		t.Fatalf("Failed to sum views: %v", err)
+	}
+	if sum != 500 {
+		t.Errorf("Expected sum of 500, got %v", sum)
+	}
+
+	avg, err := posts.Clone().Where("published", true).Avg("views")
+	if err != nil {
+		t.Fatalf("Failed to average views: %v", err)
+	}
+	if avg != 150 {
+		t.Errorf("Expected average of 150, got %v", avg)
+	}
+
+	max, err := posts.Clone().Max("views")
+	if err != nil {
+		t.Fatalf("Failed to get max views: %v", err)
+	}
+	if max != int64(200) {
+		t.Errorf("Expected max of 200, got %v", max)
+	}
+
+	// Aggregates over no rows are zero or nil
+	empty, err := posts.Clone().Where("views", ">", 1000).Sum("views")
+	if err != nil || empty != 0 {
+		t.Errorf("Expected empty sum of 0, got %v (%v)", empty, err)
+	}
+	min, err := posts.Clone().Where("views", ">", 1000).Min("views")
+	if err != nil || min != nil {
+		t.Errorf("Expected empty min of nil, got %v (%v)", min, err)
+	}
+}
+
 func TestQueryBuilderExists(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -660,7 +1077,6 @@ func TestQueryBuilderExists(t *testing.T) {
 
 func TestQueryBuilderChaining(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -697,7 +1113,6 @@ func TestQueryBuilderChaining(t *testing.T) {
 
 func TestQueryBuilderDistinct(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	conn := DB()
@@ -733,7 +1148,6 @@ func TestQueryBuilderDistinct(t *testing.T) {
 
 func TestQueryBuilderGroupBy(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -767,7 +1181,6 @@ func TestQueryBuilderGroupBy(t *testing.T) {
 
 func TestQueryBuilderHaving(t *testing.T) {
 	setupQueryBuilderTestDB(t)
-	defer teardownQueryBuilderTestDB()
 
 	db := DB()
 	qb := NewQueryBuilder(db)
@@ -794,4 +1207,1007 @@ func TestQueryBuilderHaving(t *testing.T) {
 	if result["count"].(int64) != 3 {
 		t.Errorf("Expected count 3, got %d", result["count"])
 	}
+
+	// Having on a select alias
+	results, err = NewQueryBuilder(db).Table("users").
+		Select("status", "COUNT(*) as count").
+		GroupBy("status").
+		Having("count", ">", 1).
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute Having on an alias: %v", err)
+	}
+	if len(results) != 1 || results[0]["status"] != "active" {
+		t.Errorf("Expected the active group, got %v", results)
+	}
+
+	// PostgreSQL compares the aliased expression instead
+	sql, args := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("users").
+		Select("status", "COUNT(*) AS count", "SUM(age) total").
+		GroupBy("status").
+		Having("count", ">", 1).
+		HavingBetween("COUNT", 1, 5).
+		OrHaving("total", ">", 100).
+		ToSQL()
+	expected := "SELECT status, COUNT(*) AS count, SUM(age) total FROM users GROUP BY status HAVING COUNT(*) > $1 AND COUNT(*) BETWEEN $2 AND $3 OR total > $4"
+	if sql != expected || len(args) != 4 {
+		t.Errorf("Unexpected PostgreSQL having SQL: %s %v", sql, args)
+	}
+}
+
+func TestQueryBuilderHavingVariants(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	posts := func() *QueryBuilder {
+		return NewQueryBuilder(DB()).Table("posts").
+			Select("user_id", "SUM(views) as total").
+			GroupBy("user_id").
+			OrderBy("user_id", "asc")
+	}
+
+	// HavingBetween
+	results, err := posts().HavingBetween("SUM(views)", 100, 200).Get()
+	if err != nil {
+		t.Fatalf("Failed to execute HavingBetween query: %v", err)
+	}
+	if len(results) != 1 || results[0]["user_id"] != int64(1) {
+		t.Errorf("Expected only user 1 with 150 views, got %v", results)
+	}
+
+	// HavingRaw with bindings
+	results, err = posts().HavingRaw("SUM(views) * ? > ?", 2, 500).Get()
+	if err != nil {
+		t.Fatalf("Failed to execute HavingRaw query: %v", err)
+	}
+	if len(results) != 1 || results[0]["user_id"] != int64(2) {
+		t.Errorf("Expected only user 2 with 350 views, got %v", results)
+	}
+
+	// HavingNotNull over a nullable aggregate
+	results, err = posts().HavingNotNull("MAX(content)").OrHavingRaw("COUNT(*) > ?", 5).Get()
+	if err != nil {
+		t.Fatalf("Failed to execute HavingNotNull query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 groups, got %d", len(results))
+	}
+
+	// GroupByRaw with a computed expression
+	results, err = NewQueryBuilder(DB()).Table("posts").
+		Select("views >= 150 as popular", "COUNT(*) as count").
+		GroupByRaw("views >= ?", 150).
+		OrderBy("popular", "asc").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute GroupByRaw query: %v", err)
+	}
+	if len(results) != 2 || results[0]["count"] != int64(2) || results[1]["count"] != int64(2) {
+		t.Errorf("Expected two groups of 2 posts, got %v", results)
+	}
+
+	// Placeholders are numbered in clause order for postgres
+	sql, args := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("posts").
+		Where("published", true).
+		GroupByRaw("DATE_TRUNC(?, created_at)", "day").
+		HavingBetween("COUNT(*)", 1, 10).
+		HavingRaw("SUM(views) > ?", 100).
+		HavingNull("MAX(deleted_at)").
+		ToSQL()
+	expected := "SELECT * FROM posts WHERE published = $1 GROUP BY DATE_TRUNC($2, created_at) " +
+		"HAVING COUNT(*) BETWEEN $3 AND $4 AND (SUM(views) > $5) AND MAX(deleted_at) IS NULL"
+	if sql != expected {
+		t.Errorf("Expected SQL %q, got %q", expected, sql)
+	}
+	if len(args) != 5 || args[1] != "day" || args[4] != 100 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	// Having columns are validated
+	if err := NewQueryBuilder(DB()).Table("posts").HavingBetween("views) OR (1", 1, 2).Err(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery, got %v", err)
+	}
+}
+
+func TestQueryBuilderImmutable(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	base := NewQueryBuilder(DB()).Table("users").Where("status", "active").Immutable()
+
+	admins := base.Where("is_admin", true)
+	older := base.Where("age", ">", 26).OrderByDesc("age")
+
+	baseSQL, baseArgs := base.ToSQL()
+	if baseSQL != "SELECT * FROM users WHERE status = ?" || len(baseArgs) != 1 {
+		t.Errorf("Expected base query to be unchanged, got %s %v", baseSQL, baseArgs)
+	}
+
+	count, err := admins.Count()
+	if err != nil {
+		t.Fatalf("Failed to count admins: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 active admins, got %d", count)
+	}
+
+	results, err := older.Get()
+	if err != nil {
+		t.Fatalf("Failed to get older users: %v", err)
+	}
+	if len(results) != 2 || results[0]["name"] != "Jane Smith" {
+		t.Errorf("Expected Jane Smith and Alice Brown, got %v", results)
+	}
+
+	// When and Unless hand the callback a private copy
+	scoped := base.When(true, func(q *QueryBuilder) { q.Limit(1) })
+	if _, args := scoped.ToSQL(); len(args) != 2 {
+		t.Errorf("Expected When to add a limit, got args %v", args)
+	}
+	if _, args := base.ToSQL(); len(args) != 1 {
+		t.Errorf("Expected When to leave base unchanged, got args %v", args)
+	}
+
+	// First must not leave a limit behind
+	if _, err := base.First(); err != nil {
+		t.Fatalf("Failed to get first user: %v", err)
+	}
+	if base.limitValue != nil {
+		t.Error("Expected First to leave the immutable builder unchanged")
+	}
+}
+
+func TestImmutableModelQueryBuilder(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	base := NewModelQueryBuilder(NewBaseModel().Table("users")).Immutable()
+	filtered := base.Where("status", "active").WhereNotNull("email").OrderBy("age", "desc").Limit(2)
+	if sql, args := filtered.ToSQL(); sql != "SELECT * FROM users WHERE status = ? AND email IS NOT NULL ORDER BY age DESC LIMIT ?" || len(args) != 2 {
+		t.Errorf("Expected the chained clauses on the copy, got %s %v", sql, args)
+	}
+	if sql, _ := base.ToSQL(); sql != "SELECT * FROM users" {
+		t.Errorf("Expected the model base query to be unchanged, got %s", sql)
+	}
+	results, err := filtered.Get()
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 users, got %d", len(results))
+	}
+
+	typedBase := NewModelStatic(func() *BaseModel { return NewBaseModel().Table("users") }).Query().Immutable()
+	typed := typedBase.Where("status", "active").OrderByDesc("age").Limit(1).With()
+	if sql, _ := typed.ToSQL(); sql != "SELECT * FROM users WHERE status = ? ORDER BY age DESC LIMIT ?" {
+		t.Errorf("Expected the chained clauses on the typed copy, got %s", sql)
+	}
+	if sql, _ := typedBase.ToSQL(); sql != "SELECT * FROM users" {
+		t.Errorf("Expected the typed base query to be unchanged, got %s", sql)
+	}
+
+	// A clone of a mutable model builder takes clauses without touching the original
+	mutable := NewModelQueryBuilder(NewBaseModel().Table("users"))
+	mutable.Clone().Where("status", "active")
+	if sql, _ := mutable.ToSQL(); sql != "SELECT * FROM users" {
+		t.Errorf("Expected the original to be unchanged by its clone, got %s", sql)
+	}
+}
+
+func TestQueryBuilderClone(t *testing.T) {
+	base := NewQueryBuilder(nil).Table("users").Where("status", "active")
+	clone := base.Clone().Where("age", ">", 30).Limit(5)
+
+	baseSQL, _ := base.ToSQL()
+	if baseSQL != "SELECT * FROM users WHERE status = ?" {
+		t.Errorf("Expected clone to leave base unchanged, got %s", baseSQL)
+	}
+
+	cloneSQL, _ := clone.ToSQL()
+	if cloneSQL != "SELECT * FROM users WHERE status = ? AND age > ? LIMIT ?" {
+		t.Errorf("Unexpected clone SQL: %s", cloneSQL)
+	}
+}
+
+func TestQueryBuilderUpdateAndDelete(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	db := DB()
+
+	affected, err := NewQueryBuilder(db).Table("users").
+		Where("status", "active").
+		Where("age", "<", 30).
+		Update(map[string]interface{}{"status": "junior", "is_admin": false})
+	if err != nil {
+		t.Fatalf("Failed to execute Update: %v", err)
+	}
+	if affected != 2 {
+		t.Errorf("Expected 2 updated users, got %d", affected)
+	}
+
+	count, err := NewQueryBuilder(db).Table("users").Where("status", "junior").Count()
+	if err != nil {
+		t.Fatalf("Failed to count updated users: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 junior users, got %d", count)
+	}
+
+	affected, err = NewQueryBuilder(db).Table("posts").WhereIn("user_id", []interface{}{1}).Delete()
+	if err != nil {
+		t.Fatalf("Failed to execute Delete: %v", err)
+	}
+	if affected != 2 {
+		t.Errorf("Expected 2 deleted posts, got %d", affected)
+	}
+
+	count, err = NewQueryBuilder(db).Table("posts").Count()
+	if err != nil {
+		t.Fatalf("Failed to count posts: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 remaining posts, got %d", count)
+	}
+}
+
+func TestQueryBuilderInsertUsing(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	db := DB()
+
+	inactive := NewQueryBuilder(db).Table("users").Select("name", "id", "age").Where("status", "inactive")
+	inserted, err := NewQueryBuilder(db).Table("posts").InsertUsing([]string{"title", "user_id", "views"}, inactive)
+	if err != nil {
+		t.Fatalf("Failed to execute InsertUsing: %v", err)
+	}
+	if inserted != 1 {
+		t.Errorf("Expected 1 inserted post, got %d", inserted)
+	}
+
+	post, err := NewQueryBuilder(db).Table("posts").Where("title", "Bob Johnson").First()
+	if err != nil {
+		t.Fatalf("Failed to find the inserted post: %v", err)
+	}
+	if post["user_id"] != int64(3) || post["views"] != int64(35) {
+		t.Errorf("Unexpected inserted post %v", post)
+	}
+
+	_, err = NewQueryBuilder(db).Table("posts").InsertUsing([]string{"title; DROP TABLE posts"}, inactive)
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an invalid column, got %v", err)
+	}
+	_, err = NewQueryBuilder(db).Table("posts").InsertUsing([]string{"title"}, NewQueryBuilder(db).Table("users").Where("age", "~", 1))
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected the select's error, got %v", err)
+	}
+}
+
+// sqlLogger records the statements queries run
+type sqlLogger struct{ statements []string }
+
+func (l *sqlLogger) record(args []interface{}) {
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "sql" {
+			l.statements = append(l.statements, args[i+1].(string))
+		}
+	}
+}
+
+func (l *sqlLogger) Debug(_ string, args ...interface{}) { l.record(args) }
+func (l *sqlLogger) Warn(_ string, args ...interface{})  { l.record(args) }
+func (l *sqlLogger) Error(_ string, args ...interface{}) { l.record(args) }
+
+func TestQueryBuilderJoinedUpdateAndDelete(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	db := DB()
+
+	affected, err := NewQueryBuilder(db).Table("posts").
+		Join("users", "posts.user_id", "=", "users.id").
+		Where("users.is_admin", true).
+		Delete()
+	if err != nil {
+		t.Fatalf("Failed to delete with a join: %v", err)
+	}
+	if affected != 2 {
+		t.Errorf("Expected 2 deleted posts, got %d", affected)
+	}
+
+	totals := NewQueryBuilder(db).Table("posts").Select("user_id", "SUM(views) AS views").GroupBy("user_id")
+	affected, err = NewQueryBuilder(db).Table("users").Where("status", "active").
+		UpdateFrom(totals, "totals", "totals.user_id", "=", "users.id", map[string]string{"age": "totals.views"})
+	if err != nil {
+		t.Fatalf("Failed to update from a subquery: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("Expected 1 updated user, got %d", affected)
+	}
+	jane, err := NewQueryBuilder(db).Table("users").Where("id", 2).First()
+	if err != nil || jane["age"] != int64(350) {
+		t.Errorf("Expected Jane's age to be her post views, got %v, %v", jane, err)
+	}
+
+	rec := &sqlLogger{}
+	SetLogger(rec)
+	defer SetLogger(nil)
+	defer func(driver string) { db.Driver = driver }(db.Driver)
+
+	db.Driver = "postgres"
+	NewQueryBuilder(db).From("posts", "p").Join("users", "p.user_id", "=", "users.id").Where("users.status", "banned").Delete()
+	NewQueryBuilder(db).Table("users").Where("status", "active").
+		UpdateFrom(totals.Where("views", ">", 10), "totals", "totals.user_id", "=", "users.id", map[string]string{"age": "totals.views"})
+	_, err = NewQueryBuilder(db).Table("posts").LeftJoin("users", "posts.user_id", "=", "users.id").Delete()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected left joins to be rejected on PostgreSQL, got %v", err)
+	}
+
+	db.Driver = "mysql"
+	NewQueryBuilder(db).From("posts", "p").Join("users", "p.user_id", "=", "users.id").Where("users.status", "banned").Delete()
+	NewQueryBuilder(db).Table("users").Join("posts", "posts.user_id", "=", "users.id").Update(map[string]interface{}{"users.status": "author"})
+
+	expected := []string{
+		"DELETE FROM posts AS p USING users WHERE (p.user_id = users.id) AND (users.status = $1)",
+		"UPDATE users SET age = totals.views FROM (SELECT user_id, SUM(views) AS views FROM posts WHERE views > $1 GROUP BY user_id) AS totals WHERE (totals.user_id = users.id) AND (status = $2)",
+		"DELETE p FROM posts AS p INNER JOIN users ON p.user_id = users.id WHERE users.status = ?",
+		"UPDATE users INNER JOIN posts ON posts.user_id = users.id SET users.status = ?",
+	}
+	if !reflect.DeepEqual(rec.statements, expected) {
+		t.Errorf("Unexpected statements:\n%s", strings.Join(rec.statements, "\n"))
+	}
+}
+
+func TestQueryBuilderIntoTemp(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	db := DB()
+	db.DB.SetMaxOpenConns(1)
+
+	views, err := NewQueryBuilder(db).Table("posts").
+		Select("user_id", "SUM(views) AS views").
+		Where("published", true).
+		GroupBy("user_id").
+		IntoTemp("tmp_views")
+	if err != nil {
+		t.Fatalf("Failed to materialize the query: %v", err)
+	}
+
+	rows, err := views.Where("views", ">", 200).Get()
+	if err != nil {
+		t.Fatalf("Failed to query the temporary table: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["user_id"] != int64(2) || rows[0]["views"] != int64(350) {
+		t.Errorf("Unexpected rows from the temporary table %v", rows)
+	}
+
+	if _, err := NewQueryBuilder(db).Table("posts").IntoTemp("tmp_views"); err == nil {
+		t.Error("Expected materializing into an existing table to fail")
+	}
+	if _, err := NewQueryBuilder(db).Table("posts").IntoTemp("tmp views"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an invalid table name, got %v", err)
+	}
+}
+
+func TestQueryBuilderWhereGroups(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	db := DB()
+
+	// Scopes applied to an immutable base query go on a grouped copy
+	softDeleting := NewBaseModel().Table("users").WithSoftDeletes()
+	registry := NewScopeRegistry()
+	registry.RegisterGlobal(ActiveScope{})
+	base := NewQueryBuilder(db).Table("users").Where("a", 1).OrWhere("b", 2).Immutable()
+
+	tests := []struct {
+		name     string
+		query    *QueryBuilder
+		expected string
+		args     int
+	}{
+		{
+			name:     "flat wheres keep SQL precedence",
+			query:    NewQueryBuilder(db).Table("users").Where("a", 1).OrWhere("b", 2).Where("c", 3),
+			expected: "SELECT * FROM users WHERE a = ? OR b = ? AND c = ?",
+			args:     3,
+		},
+		{
+			name: "group",
+			query: NewQueryBuilder(db).Table("users").Where("status", "active").WhereGroup(func(g *QueryBuilder) {
+				g.Where("age", "<", 26).OrWhere("age", ">", 29)
+			}),
+			expected: "SELECT * FROM users WHERE status = ? AND (age < ? OR age > ?)",
+			args:     3,
+		},
+		{
+			name: "nested or group",
+			query: NewQueryBuilder(db).Table("users").Where("is_admin", true).OrWhereGroup(func(g *QueryBuilder) {
+				g.Where("status", "active").WhereGroup(func(inner *QueryBuilder) {
+					inner.WhereNull("email").OrWhere("age", 25)
+				})
+			}),
+			expected: "SELECT * FROM users WHERE is_admin = ? OR (status = ? AND (email IS NULL OR age = ?))",
+			args:     3,
+		},
+		{
+			name:     "empty group",
+			query:    NewQueryBuilder(db).Table("users").Where("a", 1).WhereGroup(func(g *QueryBuilder) {}),
+			expected: "SELECT * FROM users WHERE a = ?",
+			args:     1,
+		},
+		{
+			name: "context defaults after or",
+			query: NewQueryBuilder(db).Table("users").Where("a", 1).OrWhere("b", 2).
+				WithContext(WithQueryDefaults(context.Background(), func(q *QueryBuilder) { q.Where("tenant_id", 7) })),
+			expected: "SELECT * FROM users WHERE (a = ? OR b = ?) AND tenant_id = ?",
+			args:     3,
+		},
+		{
+			name:     "search scope",
+			query:    ApplyScope(NewQueryBuilder(db).Table("users").Where("status", "active"), SearchScope("jo", "name", "email")),
+			expected: "SELECT * FROM users WHERE status = ? AND ((LOWER(name) LIKE ?) OR (LOWER(email) LIKE ?))",
+			args:     3,
+		},
+		{
+			name:     "global scopes on immutable builder",
+			query:    registry.ApplyGlobal(base, softDeleting),
+			expected: "SELECT * FROM users WHERE (a = ? OR b = ?) AND deleted_at IS NULL",
+			args:     2,
+		},
+		{
+			name:     "only trashed on immutable builder",
+			query:    (&ModelQueryBuilder{QueryBuilder: base, model: softDeleting}).OnlyTrashed().QueryBuilder,
+			expected: "SELECT * FROM users WHERE (a = ? OR b = ?) AND deleted_at IS NOT NULL",
+			args:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.query.ToSQL()
+			if sql != tt.expected || len(args) != tt.args {
+				t.Errorf("Expected %s with %d args, got %s %v", tt.expected, tt.args, sql, args)
+			}
+		})
+	}
+
+	if sql, _ := base.ToSQL(); sql != "SELECT * FROM users WHERE a = ? OR b = ?" {
+		t.Errorf("Expected the immutable base query to be unchanged, got %s", sql)
+	}
+
+	count, err := tests[1].query.Count()
+	if err != nil {
+		t.Fatalf("Failed to count grouped query: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 active users younger than 26 or older than 29, got %d", count)
+	}
+
+	_, err = NewQueryBuilder(db).Table("users").WhereGroup(func(g *QueryBuilder) {
+		g.Where("age", "~", 1)
+	}).Get()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected the group's error, got %v", err)
+	}
+}
+
+func TestQueryBuilderToSQLFor(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	latest := NewQueryBuilder(DB()).Table("posts").
+		Select("user_id", "MAX(views) AS views").
+		Where("published", true).
+		GroupBy("user_id")
+	qb := NewQueryBuilder(DB()).Table("users").
+		JoinSub(latest, "latest", "latest.user_id", "=", "users.id").
+		Where("status", "active").
+		UseIndex("users_status_index")
+
+	sql, args := qb.ToSQL()
+	expected := "SELECT * FROM users INNER JOIN (SELECT user_id, MAX(views) AS views FROM posts WHERE published = ? GROUP BY user_id) AS latest ON latest.user_id = users.id WHERE status = ?"
+	if sql != expected || len(args) != 2 {
+		t.Errorf("Unexpected SQLite SQL: %s %v", sql, args)
+	}
+
+	sql, args = qb.ToSQLFor("postgresql")
+	expected = "SELECT * FROM users INNER JOIN (SELECT user_id, MAX(views) AS views FROM posts WHERE published = $1 GROUP BY user_id) AS latest ON latest.user_id = users.id WHERE status = $2"
+	if sql != expected || len(args) != 2 {
+		t.Errorf("Unexpected PostgreSQL SQL: %s %v", sql, args)
+	}
+
+	sql, _ = qb.ToSQLFor("mysql")
+	expected = "SELECT * FROM users USE INDEX (users_status_index) INNER JOIN (SELECT user_id, MAX(views) AS views FROM posts WHERE published = ? GROUP BY user_id) AS latest ON latest.user_id = users.id WHERE status = ?"
+	if sql != expected {
+		t.Errorf("Unexpected MySQL SQL: %s", sql)
+	}
+
+	if sql, _ := qb.ToSQL(); strings.Contains(sql, "$") {
+		t.Errorf("Expected ToSQLFor not to change the builder, got %s", sql)
+	}
+}
+
+func TestQueryBuilderContextDefaults(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	db := DB()
+	ctx := WithQueryDefaults(context.Background(), func(q *QueryBuilder) {
+		q.Where("status", "active")
+	})
+
+	qb := NewQueryBuilder(db).Table("users").WithContext(ctx)
+	count, err := qb.Count()
+	if err != nil {
+		t.Fatalf("Failed to count with defaults: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 active users, got %d", count)
+	}
+	if len(qb.wheres) != 0 {
+		t.Errorf("Expected defaults not to modify the builder, got %d wheres", len(qb.wheres))
+	}
+
+	// Defaults accumulate across nested contexts
+	adminCtx := WithQueryDefaults(ctx, func(q *QueryBuilder) {
+		q.Where("is_admin", true)
+	})
+	sql, args := NewQueryBuilder(db).Table("users").WithContext(adminCtx).ToSQL()
+	if sql != "SELECT * FROM users WHERE status = ? AND is_admin = ?" || len(args) != 2 {
+		t.Errorf("Unexpected SQL with nested defaults: %s %v", sql, args)
+	}
+
+	// Immutable builders apply defaults as well
+	results, err := NewQueryBuilder(db).Table("users").Immutable().WithContext(adminCtx).Get()
+	if err != nil {
+		t.Fatalf("Failed to get with defaults: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 active admins, got %d", len(results))
+	}
+
+	// Writes are constrained too
+	affected, err := NewQueryBuilder(db).Table("users").WithContext(ctx).Update(map[string]interface{}{"age": 40})
+	if err != nil {
+		t.Fatalf("Failed to update with defaults: %v", err)
+	}
+	if affected != 3 {
+		t.Errorf("Expected 3 updated users, got %d", affected)
+	}
+
+	// Queries without the context are unaffected
+	count, err = NewQueryBuilder(db).Table("users").Count()
+	if err != nil {
+		t.Fatalf("Failed to count without defaults: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 users, got %d", count)
+	}
+}
+
+func TestQueryBuilderInvalidInput(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	tests := []struct {
+		name  string
+		build func(*QueryBuilder) *QueryBuilder
+	}{
+		{"unsupported operator", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.Where("age", "= 1 OR 1 =", 1)
+		}},
+		{"non-string operator", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.Where("age", 1, 2)
+		}},
+		{"missing value", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.Where("age")
+		}},
+		{"too many arguments", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.OrWhere("age", ">", 1, 2)
+		}},
+		{"injected column", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.Where("1=1 OR name", "x")
+		}},
+		{"injected where in column", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.WhereIn("id) OR (1=1", []interface{}{1})
+		}},
+		{"injected order column", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.OrderBy("name; DROP TABLE users", "asc")
+		}},
+		{"invalid order direction", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.OrderBy("name", "asc, (SELECT 1)")
+		}},
+		{"unsupported having operator", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.GroupBy("status").Having("COUNT(*)", "> 0 --", 1)
+		}},
+		{"missing named parameter", func(qb *QueryBuilder) *QueryBuilder {
+			return qb.WhereNamed("age > :min_age", map[string]interface{}{})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := tt.build(NewQueryBuilder(DB()).Table("users"))
+			if !errors.Is(qb.Err(), ErrInvalidQuery) {
+				t.Fatalf("Expected ErrInvalidQuery, got %v", qb.Err())
+			}
+			if _, err := qb.Get(); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Expected Get to return ErrInvalidQuery, got %v", err)
+			}
+			if _, err := qb.Delete(); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Expected Delete to return ErrInvalidQuery, got %v", err)
+			}
+		})
+	}
+
+	// Nothing was deleted
+	count, err := NewQueryBuilder(DB()).Table("users").Count()
+	if err != nil || count != 4 {
+		t.Errorf("Expected 4 users, got %d (%v)", count, err)
+	}
+
+	// Valid operators are accepted regardless of case and spacing
+	results, err := NewQueryBuilder(DB()).Table("users").
+		Where("users.name", "NOT  LIKE", "J%").
+		WhereDate("created_at", ">=", "2000-01-01").
+		Get()
+	if err != nil {
+		t.Fatalf("Failed to execute valid query: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 users, got %d", len(results))
+	}
+}
+
+func TestQueryBuilderWithoutConnection(t *testing.T) {
+	if _, err := NewQueryBuilder(nil).Table("users").Get(); !errors.Is(err, ErrNoConnection) {
+		t.Errorf("Expected ErrNoConnection from Get, got %v", err)
+	}
+	if _, err := NewQueryBuilder(nil).Table("users").Count(); !errors.Is(err, ErrNoConnection) {
+		t.Errorf("Expected ErrNoConnection from Count, got %v", err)
+	}
+
+	// Model builders on a manager without connections no longer panic
+	model := NewBaseModel()
+	model.Table("users").Manager(NewConnectionManager())
+
+	mqb := NewModelQueryBuilder(model).Where("name", "John Doe")
+	if _, err := mqb.First(); !errors.Is(err, ErrNoConnection) {
+		t.Errorf("Expected ErrNoConnection from First, got %v", err)
+	}
+	if _, err := mqb.Delete(); !errors.Is(err, ErrNoConnection) {
+		t.Errorf("Expected ErrNoConnection from Delete, got %v", err)
+	}
+
+	// The first build error wins over the missing connection
+	_, err := NewQueryBuilder(nil).Table("users").Where("age", "===", 1).Get()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery, got %v", err)
+	}
+}
+
+func FuzzQueryBuilderWhere(f *testing.F) {
+	f.Add("name", "=")
+	f.Add("users.name", "not like")
+	f.Add("name = 'x' OR 1", "=")
+	f.Add("name", "= ? OR 1 =")
+	f.Add("DATE(created_at)", ">=")
+	f.Add("DATE(created_at) OR 1=1 --", ">=")
+
+	f.Fuzz(func(t *testing.T, column, operator string) {
+		qb := NewQueryBuilder(&Connection{Driver: "sqlite3"}).Table("users").Where(column, operator, "value")
+		sql, args := qb.ToSQL()
+
+		if qb.Err() != nil {
+			if sql != "SELECT * FROM users" || len(args) != 0 {
+				t.Fatalf("Rejected clause leaked into query: %q %v", sql, args)
+			}
+			return
+		}
+
+		if validateWhereColumn(column) != nil {
+			t.Fatalf("Accepted invalid column %q", column)
+		}
+		normalized, err := normalizeOperator(operator)
+		if err != nil {
+			t.Fatalf("Accepted invalid operator %q", operator)
+		}
+		expected := "SELECT * FROM users WHERE " + column + " " + normalized + " ?"
+		if sql != expected || len(args) != 1 {
+			t.Fatalf("Unexpected query structure: %q %v", sql, args)
+		}
+	})
+}
+
+func FuzzQueryBuilderOrderBy(f *testing.F) {
+	f.Add("name", "asc")
+	f.Add("name", "DESC")
+	f.Add("name; DROP TABLE users", "asc")
+	f.Add("name", "asc, id")
+
+	f.Fuzz(func(t *testing.T, column, direction string) {
+		qb := NewQueryBuilder(&Connection{Driver: "sqlite3"}).Table("users").OrderBy(column, direction)
+		sql, _ := qb.ToSQL()
+
+		if qb.Err() != nil {
+			if sql != "SELECT * FROM users" {
+				t.Fatalf("Rejected order leaked into query: %q", sql)
+			}
+			return
+		}
+
+		if validateColumn(column) != nil {
+			t.Fatalf("Accepted invalid column %q", column)
+		}
+		if !strings.HasPrefix(sql, "SELECT * FROM users ORDER BY "+column+" ") ||
+			!(strings.HasSuffix(sql, " ASC") || strings.HasSuffix(sql, " DESC")) {
+			t.Fatalf("Unexpected query structure: %q", sql)
+		}
+	})
+}
+
+func TestQueryBuilderToSQLCache(t *testing.T) {
+	qb := NewQueryBuilder(&Connection{Driver: "postgres"}).Table("users").Where("status", "active")
+
+	sql, args := qb.ToSQL()
+	cachedSQL, cachedArgs := qb.ToSQL()
+	if cachedSQL != sql || !reflect.DeepEqual(cachedArgs, args) {
+		t.Fatalf("Expected the same query from the cache, got %q %v", cachedSQL, cachedArgs)
+	}
+
+	// Appending to the returned arguments must not write into the cache
+	_ = append(cachedArgs, "extra")
+	if _, again := qb.ToSQL(); len(again) != 1 {
+		t.Errorf("Expected the cached arguments to be unchanged, got %v", again)
+	}
+
+	qb.Where("age", ">", 18).Limit(5)
+	sql, args = qb.ToSQL()
+	if sql != "SELECT * FROM users WHERE status = $1 AND age > $2 LIMIT $3" || len(args) != 3 {
+		t.Errorf("Expected the cache to be cleared by chained calls, got %q %v", sql, args)
+	}
+
+	// Switching connections compiles for the new driver
+	qb.connection = &Connection{Driver: "sqlite3"}
+	if sql, _ = qb.ToSQL(); sql != "SELECT * FROM users WHERE status = ? AND age > ? LIMIT ?" {
+		t.Errorf("Expected the query to be compiled for the new connection, got %q", sql)
+	}
+
+	base := NewQueryBuilder(&Connection{Driver: "sqlite3"}).Table("users").Immutable()
+	base.ToSQL()
+	if sql, _ = base.Where("id", 1).ToSQL(); sql != "SELECT * FROM users WHERE id = ?" {
+		t.Errorf("Expected derived immutable queries to compile their own SQL, got %q", sql)
+	}
+	if sql, _ = base.ToSQL(); sql != "SELECT * FROM users" {
+		t.Errorf("Expected the immutable base to keep its SQL, got %q", sql)
+	}
+}
+
+func benchmarkQuery() *QueryBuilder {
+	return NewQueryBuilder(&Connection{Driver: "postgres"}).
+		Table("users").
+		Select("id", "name", "email").
+		Join("posts", "posts.user_id", "=", "users.id").
+		Where("status", "active").
+		Where("age", ">", 18).
+		WhereIn("role", []interface{}{"admin", "editor", "author"}).
+		OrderBy("name", "asc").
+		Limit(20).
+		Offset(40)
+}
+
+func BenchmarkQueryBuilderToSQL(b *testing.B) {
+	qb := benchmarkQuery()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		qb.compiled.Store(nil)
+		qb.ToSQL()
+	}
+}
+
+func BenchmarkQueryBuilderToSQLCached(b *testing.B) {
+	qb := benchmarkQuery()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		qb.ToSQL()
+	}
+}
+
+func BenchmarkQueryBuilderBuildAndCompile(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkQuery().ToSQL()
+	}
+}
+
+func TestQueryBuilderTiebreaker(t *testing.T) {
+	conn := &Connection{Driver: "sqlite3"}
+	tests := []struct {
+		query *QueryBuilder
+		want  string
+	}{
+		{NewQueryBuilder(conn).Table("users").Tiebreaker("id").OrderByDesc("age"), "SELECT * FROM users ORDER BY age DESC, id DESC"},
+		{NewQueryBuilder(conn).Table("users").Tiebreaker("id").OrderBy("age", "asc").OrderBy("id", "desc"), "SELECT * FROM users ORDER BY age ASC, id DESC"},
+		{NewQueryBuilder(conn).Table("users").Tiebreaker("id").Offset(10).Limit(5), "SELECT * FROM users ORDER BY id ASC LIMIT ? OFFSET ?"},
+		{NewQueryBuilder(conn).Table("users").Tiebreaker("id").Where("age", 30), "SELECT * FROM users WHERE age = ?"},
+		{NewQueryBuilder(conn).Table("users").Tiebreaker("id").Join("posts", "posts.user_id", "=", "users.id").OrderBy("posts.title", "asc"), "SELECT * FROM users INNER JOIN posts ON posts.user_id = users.id ORDER BY posts.title ASC, users.id ASC"},
+		{NewQueryBuilder(conn).Table("users").Tiebreaker("id").Select("status").GroupBy("status").OrderBy("status", "asc"), "SELECT status FROM users GROUP BY status ORDER BY status ASC"},
+	}
+	for _, test := range tests {
+		if sql, _ := test.query.ToSQL(); sql != test.want {
+			t.Errorf("Expected SQL %q, got %q", test.want, sql)
+		}
+	}
+
+	SetStableOrdering(true)
+	t.Cleanup(func() { SetStableOrdering(false) })
+
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.SetParentModel(customer)
+	sql, _ := NewModelQueryBuilder(customer).OrderBy("name", "asc").ToSQL()
+	if want := "SELECT * FROM customers ORDER BY name ASC, id ASC"; sql != want {
+		t.Errorf("Expected SQL %q, got %q", want, sql)
+	}
+}
+
+func TestQueryBuilderSelectExcept(t *testing.T) {
+	conn := NewTestSQLite(t)
+	schema := NewSchemaBuilder(conn)
+	err := schema.Create("accounts", func(table *Blueprint) {
+		table.Increments("id")
+		table.String("email")
+		table.String("password")
+		table.String("remember_token").Nullable()
+	})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO accounts (email, password) VALUES ('ada@example.com', 'secret')"); err != nil {
+		t.Fatalf("Failed to insert account: %v", err)
+	}
+
+	query := conn.Table("accounts").SelectExcept("password", "remember_token")
+	if sql, _ := query.ToSQL(); sql != "SELECT id, email FROM accounts" {
+		t.Errorf("Unexpected SQL: %s", sql)
+	}
+	row, err := query.First()
+	if err != nil {
+		t.Fatalf("Failed to query accounts: %v", err)
+	}
+	if _, ok := row["password"]; ok || row["email"] != "ada@example.com" {
+		t.Errorf("Expected the password to be left out, got %v", row)
+	}
+
+	// Joined queries qualify the columns with the alias, or with the prefixed table
+	joined := conn.Table("accounts").From("accounts", "a").Join("sessions", "sessions.account_id", "=", "a.id").SelectExcept("password", "remember_token")
+	if sql, _ := joined.ToSQL(); !strings.HasPrefix(sql, "SELECT a.id, a.email FROM accounts AS a") {
+		t.Errorf("Expected the alias to qualify the columns, got %s", sql)
+	}
+	err = GetManager().AddConnection("prefixed_accounts", ConnectionConfig{
+		Driver:   "sqlite3",
+		Database: UniqueMemorySQLiteDSN(t.Name()),
+		Prefix:   "app_",
+	})
+	if err != nil {
+		t.Fatalf("Failed to add prefixed connection: %v", err)
+	}
+	defer func() { _ = GetManager().RemoveConnection("prefixed_accounts") }()
+	prefixedConn := DB("prefixed_accounts")
+	if _, err := prefixedConn.Exec("CREATE TABLE app_accounts (id INTEGER PRIMARY KEY, email TEXT, password TEXT)"); err != nil {
+		t.Fatalf("Failed to create prefixed table: %v", err)
+	}
+	prefixed := prefixedConn.Table("accounts").Join("sessions", "sessions.account_id", "=", "accounts.id").SelectExcept("password")
+	if sql, _ := prefixed.ToSQL(); !strings.HasPrefix(sql, "SELECT app_accounts.id, app_accounts.email FROM app_accounts") {
+		t.Errorf("Expected the prefixed table to qualify the columns, got %s", sql)
+	}
+
+	// Schema changes through the builder refresh the cached column list
+	if err := schema.Table("accounts", func(table *Blueprint) { table.String("name").Nullable() }); err != nil {
+		t.Fatalf("Failed to alter table: %v", err)
+	}
+	if sql, _ := conn.Table("accounts").SelectExcept("password").ToSQL(); sql != "SELECT id, email, remember_token, name FROM accounts" {
+		t.Errorf("Expected the new column to be selected, got %s", sql)
+	}
+
+	if _, err := conn.Table("missing").SelectExcept("password").Get(); err == nil {
+		t.Error("Expected an error for an unknown table")
+	}
+}
+
+func TestQueryBuilderMacros(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	Macro("whereActive", func(qb *QueryBuilder, args ...interface{}) {
+		qb.Where("status", "active")
+	})
+	Macro("olderThan", func(qb *QueryBuilder, args ...interface{}) {
+		qb.Where("age", ">", args[0])
+	})
+	defer Macro("whereActive", nil)
+	defer Macro("olderThan", nil)
+
+	if !HasMacro("whereActive") || HasMacro("whereArchived") {
+		t.Error("Expected HasMacro to report registered macros")
+	}
+
+	users, err := NewQueryBuilder(DB()).Table("users").Call("whereActive").Call("olderThan", 26).Get()
+	if err != nil {
+		t.Fatalf("Failed to query users: %v", err)
+	}
+	if len(users) != 2 {
+		t.Errorf("Expected 2 active users older than 26, got %d", len(users))
+	}
+
+	// Immutable builders leave the base query untouched
+	base := NewQueryBuilder(DB()).Table("users").Immutable()
+	active := base.Call("whereActive")
+	if sql, _ := base.ToSQL(); strings.Contains(sql, "status") {
+		t.Errorf("Expected the base query to be unchanged, got %s", sql)
+	}
+	if sql, _ := active.ToSQL(); !strings.Contains(sql, "status") {
+		t.Errorf("Expected the macro to apply to the copy, got %s", sql)
+	}
+
+	// Model builders return the copy instead of dropping the macro's clauses
+	modelBase := NewModelQueryBuilder(NewBaseModel().Table("users"))
+	modelBase.QueryBuilder = modelBase.QueryBuilder.Immutable()
+	if sql, _ := modelBase.Call("whereActive").ToSQL(); !strings.Contains(sql, "status") {
+		t.Errorf("Expected the macro to apply to the model builder's copy, got %s", sql)
+	}
+	typedBase := NewModelStatic(func() *BaseModel { return NewBaseModel().Table("users") }).Query()
+	typedBase.QueryBuilder = typedBase.QueryBuilder.Immutable()
+	if sql, _ := typedBase.Call("whereActive").ToSQL(); !strings.Contains(sql, "status") {
+		t.Errorf("Expected the macro to apply to the typed builder's copy, got %s", sql)
+	}
+	if sql, _ := typedBase.ToSQL(); strings.Contains(sql, "status") {
+		t.Errorf("Expected the immutable typed builder to be unchanged, got %s", sql)
+	}
+
+	_, err = NewQueryBuilder(DB()).Table("users").Call("whereArchived").Get()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an unknown macro, got %v", err)
+	}
+}
+
+type schemaCustomer struct {
+	*BaseModel
+}
+
+func TestModelSchema(t *testing.T) {
+	conn := NewTestSQLite(t)
+	// Attached databases are per connection, so keep the pool to one
+	conn.DB.SetMaxOpenConns(1)
+	if _, err := conn.Exec("ATTACH DATABASE ':memory:' AS tenant_a"); err != nil {
+		t.Fatalf("Failed to attach database: %v", err)
+	}
+	if _, err := conn.Exec("CREATE TABLE tenant_a.customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	customers := NewModelStatic(func() *schemaCustomer {
+		customer := &schemaCustomer{BaseModel: NewBaseModel()}
+		customer.Table("customers").Schema("tenant_a").Fillable("name").Connection(conn.Name)
+		customer.SetParentModel(customer)
+		return customer
+	})
+
+	sql, _ := customers.Query().ToSQL()
+	if sql != "SELECT * FROM tenant_a.customers" {
+		t.Errorf("Expected a schema-qualified table, got %s", sql)
+	}
+
+	ada, err := customers.Create(map[string]interface{}{"name": "Ada"})
+	if err != nil {
+		t.Fatalf("Failed to create in schema: %v", err)
+	}
+	if ada.GetTable() != "customers" || ada.GetSchema() != "tenant_a" {
+		t.Errorf("Expected the table name to stay unqualified, got %s in %s", ada.GetTable(), ada.GetSchema())
+	}
+
+	ada.SetAttribute("name", "Ada Lovelace")
+	if err := ada.Save(); err != nil {
+		t.Fatalf("Failed to update in schema: %v", err)
+	}
+	found, err := customers.Find(ada.GetAttribute("id"))
+	if err != nil || found.GetAttribute("name") != "Ada Lovelace" {
+		t.Fatalf("Expected to find the updated customer, got %v", err)
+	}
+	if err := found.Delete(); err != nil {
+		t.Fatalf("Failed to delete in schema: %v", err)
+	}
+	if count, _ := customers.Query().Count(); count != 0 {
+		t.Errorf("Expected no customers left, got %d", count)
+	}
 }
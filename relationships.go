@@ -1,7 +1,10 @@
 package eloquent
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 // Relationship types
@@ -19,6 +22,7 @@ const (
 
 // Relationship represents a model relationship
 type Relationship struct {
+	Name         string
 	Type         string
 	Related      string
 	ForeignKey   string
@@ -48,74 +52,121 @@ func NewRelationshipBuilder(model Model) *RelationshipBuilder {
 	}
 }
 
-// HasOne defines a has-one relationship
-func (rb *RelationshipBuilder) HasOne(name, related string, foreignKey ...string) *Relationship {
-	fk := rb.model.GetTable() + "_id"
-	if len(foreignKey) > 0 {
-		fk = foreignKey[0]
+// ownerKeys returns the foreign and local keys of a has-one or has-many relationship,
+// applying any overrides
+func (rb *RelationshipBuilder) ownerKeys(keys []string) (string, string) {
+	fk := GetNamingStrategy().ForeignKey(modelNameOf(rb.model))
+	if len(keys) > 0 && keys[0] != "" {
+		fk = keys[0]
 	}
+	localKey := rb.model.GetPrimaryKey()
+	if len(keys) > 1 && keys[1] != "" {
+		localKey = keys[1]
+	}
+	return fk, localKey
+}
+
+// modelNameOf returns the singular snake_case name of a model, taken from the struct
+// embedding its BaseModel or, for a bare BaseModel, from its table
+func modelNameOf(model Model) string {
+	if bm, ok := model.(*BaseModel); ok {
+		if bm.parentModel == nil {
+			return modelKeyName(bm.GetTable())
+		}
+		model = bm.parentModel
+	}
+
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	return modelKeyName(modelType.Name())
+}
+
+// HasOne defines a has-one relationship. The optional keys override the foreign key on the
+// related table (<model>_id by default) and the local key (the primary key by default).
+func (rb *RelationshipBuilder) HasOne(name, related string, keys ...string) *Relationship {
+	fk, localKey := rb.ownerKeys(keys)
 
 	relationship := &Relationship{
+		Name:       name,
 		Type:       HasOne,
 		Related:    related,
 		ForeignKey: fk,
-		LocalKey:   rb.model.GetPrimaryKey(),
+		LocalKey:   localKey,
 	}
 
 	rb.relationships[name] = relationship
 	return relationship
 }
 
-// HasMany defines a has-many relationship
-func (rb *RelationshipBuilder) HasMany(name, related string, foreignKey ...string) *Relationship {
-	fk := rb.model.GetTable() + "_id"
-	if len(foreignKey) > 0 {
-		fk = foreignKey[0]
-	}
+// HasMany defines a has-many relationship. The optional keys override the foreign key on the
+// related table (<model>_id by default) and the local key (the primary key by default).
+func (rb *RelationshipBuilder) HasMany(name, related string, keys ...string) *Relationship {
+	fk, localKey := rb.ownerKeys(keys)
 
 	relationship := &Relationship{
+		Name:       name,
 		Type:       HasMany,
 		Related:    related,
 		ForeignKey: fk,
-		LocalKey:   rb.model.GetPrimaryKey(),
+		LocalKey:   localKey,
 	}
 
 	rb.relationships[name] = relationship
 	return relationship
 }
 
-// BelongsTo defines a belongs-to relationship
-func (rb *RelationshipBuilder) BelongsTo(name, related string, foreignKey ...string) *Relationship {
-	fk := toSnakeCase(related) + "_id"
-	if len(foreignKey) > 0 {
-		fk = foreignKey[0]
+// BelongsTo defines a belongs-to relationship. The optional keys override the foreign key on
+// this model (<related>_id by default) and the owner key on the related model (id by default).
+func (rb *RelationshipBuilder) BelongsTo(name, related string, keys ...string) *Relationship {
+	fk := GetNamingStrategy().ForeignKey(modelKeyName(related))
+	if len(keys) > 0 && keys[0] != "" {
+		fk = keys[0]
+	}
+	ownerKey := "id" // Default primary key of related model
+	if len(keys) > 1 && keys[1] != "" {
+		ownerKey = keys[1]
 	}
 
 	relationship := &Relationship{
+		Name:       name,
 		Type:       BelongsTo,
 		Related:    related,
 		ForeignKey: fk,
-		LocalKey:   "id", // Default primary key of related model
+		LocalKey:   ownerKey,
 	}
 
 	rb.relationships[name] = relationship
 	return relationship
 }
 
-// BelongsToMany defines a many-to-many relationship
-func (rb *RelationshipBuilder) BelongsToMany(name, related string, pivotTable ...string) *Relationship {
+// BelongsToMany defines a many-to-many relationship. The optional arguments override the
+// pivot table, the pivot key referencing this model and the pivot key referencing the related one.
+func (rb *RelationshipBuilder) BelongsToMany(name, related string, pivotAndKeys ...string) *Relationship {
+	naming := GetNamingStrategy()
+
 	// Auto-generate pivot table name
-	pivot := generatePivotTableName(rb.model.GetTable(), toSnakeCase(related)+"s")
-	if len(pivotTable) > 0 {
-		pivot = pivotTable[0]
+	pivot := generatePivotTableName(rb.model.GetTable(), naming.TableName(related))
+	if len(pivotAndKeys) > 0 && pivotAndKeys[0] != "" {
+		pivot = pivotAndKeys[0]
+	}
+	firstKey := naming.ForeignKey(modelNameOf(rb.model))
+	if len(pivotAndKeys) > 1 && pivotAndKeys[1] != "" {
+		firstKey = pivotAndKeys[1]
+	}
+	secondKey := naming.ForeignKey(modelKeyName(related))
+	if len(pivotAndKeys) > 2 && pivotAndKeys[2] != "" {
+		secondKey = pivotAndKeys[2]
 	}
 
 	relationship := &Relationship{
+		Name:       name,
 		Type:       BelongsToMany,
 		Related:    related,
 		PivotTable: pivot,
-		FirstKey:   rb.model.GetTable() + "_id",
-		SecondKey:  toSnakeCase(related) + "_id",
+		FirstKey:   firstKey,
+		SecondKey:  secondKey,
 		LocalKey:   rb.model.GetPrimaryKey(),
 	}
 
@@ -126,6 +177,7 @@ func (rb *RelationshipBuilder) BelongsToMany(name, related string, pivotTable ..
 // HasOneThrough defines a has-one-through relationship
 func (rb *RelationshipBuilder) HasOneThrough(name, related, through string, firstKey, secondKey string) *Relationship {
 	relationship := &Relationship{
+		Name:         name,
 		Type:         HasOneThrough,
 		Related:      related,
 		ThroughModel: through,
@@ -141,6 +193,7 @@ func (rb *RelationshipBuilder) HasOneThrough(name, related, through string, firs
 // HasManyThrough defines a has-many-through relationship
 func (rb *RelationshipBuilder) HasManyThrough(name, related, through string, firstKey, secondKey string) *Relationship {
 	relationship := &Relationship{
+		Name:         name,
 		Type:         HasManyThrough,
 		Related:      related,
 		ThroughModel: through,
@@ -156,6 +209,7 @@ func (rb *RelationshipBuilder) HasManyThrough(name, related, through string, fir
 // MorphOne defines a morph-one relationship
 func (rb *RelationshipBuilder) MorphOne(name, related, morphName string) *Relationship {
 	relationship := &Relationship{
+		Name:      name,
 		Type:      MorphOne,
 		Related:   related,
 		MorphType: morphName + "_type",
@@ -170,6 +224,7 @@ func (rb *RelationshipBuilder) MorphOne(name, related, morphName string) *Relati
 // MorphMany defines a morph-many relationship
 func (rb *RelationshipBuilder) MorphMany(name, related, morphName string) *Relationship {
 	relationship := &Relationship{
+		Name:      name,
 		Type:      MorphMany,
 		Related:   related,
 		MorphType: morphName + "_type",
@@ -184,6 +239,7 @@ func (rb *RelationshipBuilder) MorphMany(name, related, morphName string) *Relat
 // MorphTo defines a morph-to relationship
 func (rb *RelationshipBuilder) MorphTo(name, morphName string) *Relationship {
 	relationship := &Relationship{
+		Name:      name,
 		Type:      MorphTo,
 		MorphType: morphName + "_type",
 		MorphId:   morphName + "_id",
@@ -321,7 +377,7 @@ func (r *Relationship) buildQuery() *QueryBuilder {
 
 	// Apply constraints
 	for _, constraint := range r.Constraints {
-		constraint(qb)
+		qb.applyGrouped(constraint)
 	}
 
 	return qb
@@ -337,24 +393,132 @@ func generatePivotTableName(table1, table2 string) string {
 	return table1 + "_" + table2
 }
 
+// relationshipType is the type returned by relationship methods
+var relationshipType = reflect.TypeOf((*Relationship)(nil))
+
+// RelationsOf returns the relationships a model declares, found by calling its exported
+// methods that take no arguments and return *Relationship, ordered by method name.
+// Relationships whose builder was given no name are named after their method.
+func RelationsOf(model Model) []*Relationship {
+	modelValue := reflect.ValueOf(model)
+	if !modelValue.IsValid() {
+		return nil
+	}
+	modelType := modelValue.Type()
+
+	var relations []*Relationship
+	for i := 0; i < modelType.NumMethod(); i++ {
+		method := modelType.Method(i)
+		if method.Type.NumIn() != 1 || method.Type.NumOut() != 1 || method.Type.Out(0) != relationshipType {
+			continue
+		}
+
+		relation, _ := modelValue.Method(i).Call(nil)[0].Interface().(*Relationship)
+		if relation == nil {
+			continue
+		}
+		if relation.Name == "" {
+			relation.Name = method.Name
+		}
+		relations = append(relations, relation)
+	}
+	return relations
+}
+
+// relationOf returns the relationship a model declares under the given name
+func relationOf(model Model, name string) (*Relationship, error) {
+	for _, relation := range RelationsOf(model) {
+		if relation.Name == name {
+			return relation, nil
+		}
+	}
+	return nil, fmt.Errorf("relationship '%s' is not defined on %T", name, model)
+}
+
 // Relationship loading methods
 
-// LoadRelation loads a relationship for a model
+// LoadRelation runs the query of a relationship the model declares and stores the result
+// on the model, where GetRelation, ToMap and ToJSON see it. Related records are loaded as
+// rows: a map for has-one and belongs-to (nil when there is none) and a slice otherwise.
 func LoadRelation(model Model, relationName string) error {
-	// Implementation would:
-	// 1. Get the relationship definition
-	// 2. Execute the query
-	// 3. Set the result on the model's relations
-	return fmt.Errorf("relationship loading not yet implemented")
+	return loadRelation(model, relationName, nil)
+}
+
+// loadRelation loads a relationship, narrowing its query with callback if it is not nil
+func loadRelation(model Model, relationName string, callback func(*QueryBuilder)) error {
+	relation, err := relationOf(model, relationName)
+	if err != nil {
+		return err
+	}
+	bm := baseModelOf(model)
+	if bm == nil {
+		return fmt.Errorf("cannot store relationship '%s' on %T", relationName, model)
+	}
+
+	table := relatedTable(relation.Related)
+	qb := NewQueryBuilder(modelConnection(model)).Table(table)
+
+	switch relation.Type {
+	case HasOne, HasMany:
+		qb.Where(relation.ForeignKey, model.GetAttribute(relation.LocalKey))
+	case BelongsTo:
+		qb.Where(relation.LocalKey, model.GetAttribute(relation.ForeignKey))
+	case BelongsToMany:
+		qb.Select(table+".*").
+			Join(relation.PivotTable, table+".id", "=", relation.PivotTable+"."+relation.SecondKey).
+			Where(relation.PivotTable+"."+relation.FirstKey, model.GetAttribute(relation.LocalKey))
+	default:
+		return fmt.Errorf("loading %s relationships is not supported", relation.Type)
+	}
+
+	for _, constraint := range relation.Constraints {
+		qb.applyGrouped(constraint)
+	}
+	if callback != nil {
+		qb.applyGrouped(callback)
+	}
+
+	var result interface{}
+	switch relation.Type {
+	case HasOne, BelongsTo:
+		row, err := qb.First()
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if row != nil {
+			result = row
+		}
+	default:
+		rows, err := qb.Get()
+		if err != nil {
+			return err
+		}
+		result = rows
+	}
+
+	bm.SetRelation(relationName, result)
+	return nil
 }
 
-// EagerLoad loads multiple relationships efficiently
+// EagerLoad loads the given relationships on each model
 func EagerLoad(models []Model, relations []string) error {
-	// Implementation would:
-	// 1. Group models by type
-	// 2. Load each relationship efficiently
-	// 3. Map results back to models
-	return fmt.Errorf("eager loading not yet implemented")
+	for _, model := range models {
+		for _, relation := range relations {
+			if err := LoadRelation(model, relation); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relatedTable returns the table of a relationship's related model, which may be
+// given as a struct name (PostModel) or directly as a table (posts)
+func relatedTable(related string) string {
+	if strings.ToLower(related) == related {
+		return related
+	}
+	return GetNamingStrategy().TableName(related)
 }
 
 // Relationship query scopes
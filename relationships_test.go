@@ -1,24 +1,17 @@
 package eloquent
 
 import (
+	"strings"
 	"testing"
 )
 
 func setupRelationshipTestDB(t *testing.T) {
-	// Set up in-memory SQLite database for testing
-	err := SQLite(":memory:")
-	if err != nil {
-		t.Fatalf("Failed to set up test database: %v", err)
-	}
-
-	// Create test tables
-	conn := DB()
-	if conn == nil {
-		t.Fatal("Failed to get database connection")
-	}
+	// Use a private in-memory SQLite database as the default connection
+	conn := NewTestSQLite(t)
+	WithTestConnection(t, conn)
 
 	// Create users table
-	_, err = conn.Exec(`
+	_, err := conn.Exec(`
 		CREATE TABLE users (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			name TEXT NOT NULL,
@@ -91,13 +84,8 @@ func setupRelationshipTestDB(t *testing.T) {
 	}
 }
 
-func teardownRelationshipTestDB() {
-	_ = GetManager().CloseAll()
-}
-
 func TestRelationshipBuilder(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock model for testing
 	model := NewBaseModel()
@@ -112,7 +100,6 @@ func TestRelationshipBuilder(t *testing.T) {
 
 func TestBelongsToRelationship(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock post model
 	postModel := NewBaseModel()
@@ -134,8 +121,8 @@ func TestBelongsToRelationship(t *testing.T) {
 		t.Errorf("Expected related 'users', got %s", relationship.Related)
 	}
 
-	if relationship.ForeignKey != "users_id" {
-		t.Errorf("Expected foreign key 'users_id', got %s", relationship.ForeignKey)
+	if relationship.ForeignKey != "user_id" {
+		t.Errorf("Expected foreign key 'user_id', got %s", relationship.ForeignKey)
 	}
 
 	if relationship.LocalKey != "id" {
@@ -145,7 +132,6 @@ func TestBelongsToRelationship(t *testing.T) {
 
 func TestHasOneRelationship(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock user model
 	userModel := NewBaseModel()
@@ -167,8 +153,8 @@ func TestHasOneRelationship(t *testing.T) {
 		t.Errorf("Expected related 'profiles', got %s", relationship.Related)
 	}
 
-	if relationship.ForeignKey != "users_id" {
-		t.Errorf("Expected foreign key 'users_id', got %s", relationship.ForeignKey)
+	if relationship.ForeignKey != "user_id" {
+		t.Errorf("Expected foreign key 'user_id', got %s", relationship.ForeignKey)
 	}
 
 	if relationship.LocalKey != "id" {
@@ -178,7 +164,6 @@ func TestHasOneRelationship(t *testing.T) {
 
 func TestHasManyRelationship(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock user model
 	userModel := NewBaseModel()
@@ -200,8 +185,8 @@ func TestHasManyRelationship(t *testing.T) {
 		t.Errorf("Expected related 'posts', got %s", relationship.Related)
 	}
 
-	if relationship.ForeignKey != "users_id" {
-		t.Errorf("Expected foreign key 'users_id', got %s", relationship.ForeignKey)
+	if relationship.ForeignKey != "user_id" {
+		t.Errorf("Expected foreign key 'user_id', got %s", relationship.ForeignKey)
 	}
 
 	if relationship.LocalKey != "id" {
@@ -211,7 +196,6 @@ func TestHasManyRelationship(t *testing.T) {
 
 func TestBelongsToManyRelationship(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock post model
 	postModel := NewBaseModel()
@@ -237,18 +221,17 @@ func TestBelongsToManyRelationship(t *testing.T) {
 		t.Errorf("Expected pivot table 'post_tags', got %s", relationship.PivotTable)
 	}
 
-	if relationship.FirstKey != "posts_id" {
-		t.Errorf("Expected first key 'posts_id', got %s", relationship.FirstKey)
+	if relationship.FirstKey != "post_id" {
+		t.Errorf("Expected first key 'post_id', got %s", relationship.FirstKey)
 	}
 
-	if relationship.SecondKey != "tags_id" {
-		t.Errorf("Expected second key 'tags_id', got %s", relationship.SecondKey)
+	if relationship.SecondKey != "tag_id" {
+		t.Errorf("Expected second key 'tag_id', got %s", relationship.SecondKey)
 	}
 }
 
 func TestRelationshipConstraints(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock model
 	model := NewBaseModel()
@@ -276,7 +259,6 @@ func TestRelationshipConstraints(t *testing.T) {
 
 func TestRelationshipMethods(t *testing.T) {
 	setupRelationshipTestDB(t)
-	defer teardownRelationshipTestDB()
 
 	// Create a mock model
 	model := NewBaseModel()
@@ -305,6 +287,88 @@ func TestRelationshipMethods(t *testing.T) {
 	}
 }
 
+func TestRelationshipKeyInference(t *testing.T) {
+	customer := &CustomerModel{BaseModel: NewBaseModel()}
+	customer.Table("clients").SetParentModel(customer)
+	rb := NewRelationshipBuilder(customer)
+
+	// Keys come from the struct name, not the table
+	if fk := rb.HasMany("orders", "OrderModel").ForeignKey; fk != "customer_id" {
+		t.Errorf("Expected foreign key 'customer_id', got %s", fk)
+	}
+	if fk := rb.BelongsTo("status", "order_statuses").ForeignKey; fk != "order_status_id" {
+		t.Errorf("Expected foreign key 'order_status_id', got %s", fk)
+	}
+
+	// Foreign, local and owner keys can be overridden
+	hasOne := rb.HasOne("account", "AccountModel", "owner_uuid", "uuid")
+	if hasOne.ForeignKey != "owner_uuid" || hasOne.LocalKey != "uuid" {
+		t.Errorf("Expected overridden keys owner_uuid/uuid, got %s/%s", hasOne.ForeignKey, hasOne.LocalKey)
+	}
+	belongsTo := rb.BelongsTo("country", "CountryModel", "", "iso_code")
+	if belongsTo.ForeignKey != "country_id" || belongsTo.LocalKey != "iso_code" {
+		t.Errorf("Expected keys country_id/iso_code, got %s/%s", belongsTo.ForeignKey, belongsTo.LocalKey)
+	}
+	belongsToMany := rb.BelongsToMany("tags", "TagModel", "", "client_id", "label_id")
+	if belongsToMany.PivotTable != "clients_tags" || belongsToMany.FirstKey != "client_id" || belongsToMany.SecondKey != "label_id" {
+		t.Errorf("Unexpected pivot %s with keys %s/%s", belongsToMany.PivotTable, belongsToMany.FirstKey, belongsToMany.SecondKey)
+	}
+
+	// Schemas built around the old table-based keys can opt back in
+	SetNamingStrategy(DefaultNamingStrategy{PluralForeignKeys: true})
+	defer SetNamingStrategy(nil)
+	if fk := rb.HasMany("orders", "OrderModel").ForeignKey; fk != "customers_id" {
+		t.Errorf("Expected plural foreign key 'customers_id', got %s", fk)
+	}
+}
+
+type authorModel struct {
+	*BaseModel
+}
+
+func (a *authorModel) Posts() *Relationship {
+	return NewRelationshipBuilder(a).HasMany("posts", "PostModel")
+}
+
+func (a *authorModel) Profile() *Relationship {
+	return NewRelationshipBuilder(a).HasOne("profile", "ProfileModel")
+}
+
+func (a *authorModel) Country() *Relationship {
+	return NewRelationshipBuilder(a).BelongsTo("", "CountryModel")
+}
+
+func TestRelationsOf(t *testing.T) {
+	author := &authorModel{BaseModel: NewBaseModel()}
+	author.SetParentModel(author)
+
+	relations := RelationsOf(author)
+	if len(relations) != 3 {
+		t.Fatalf("Expected 3 relationships, got %d", len(relations))
+	}
+
+	// Ordered by method name, unnamed relationships take the method name
+	expected := []struct{ name, typ, related, foreignKey string }{
+		{"Country", BelongsTo, "CountryModel", "country_id"},
+		{"posts", HasMany, "PostModel", "author_id"},
+		{"profile", HasOne, "ProfileModel", "author_id"},
+	}
+	for i, want := range expected {
+		got := relations[i]
+		if got.Name != want.name || got.Type != want.typ || got.Related != want.related || got.ForeignKey != want.foreignKey {
+			t.Errorf("Relationship %d: expected %+v, got %s %s %s %s", i, want, got.Name, got.Type, got.Related, got.ForeignKey)
+		}
+	}
+
+	if relations := RelationsOf(NewBaseModel()); len(relations) != 0 {
+		t.Errorf("Expected no relationships on a bare model, got %d", len(relations))
+	}
+
+	if err := LoadRelation(author, "comments"); err == nil || !strings.Contains(err.Error(), "not defined") {
+		t.Errorf("Expected undefined relationship error, got %v", err)
+	}
+}
+
 func TestRelationshipTypes(t *testing.T) {
 	// Test relationship type constants
 	if HasOne != "hasOne" {
@@ -323,3 +387,63 @@ func TestRelationshipTypes(t *testing.T) {
 		t.Errorf("Expected BelongsToMany constant to be 'belongsToMany', got %s", BelongsToMany)
 	}
 }
+
+func TestAlwaysWith(t *testing.T) {
+	conn := NewTestSQLite(t)
+	schema := []string{
+		"CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, author_id INTEGER, title TEXT)",
+		"CREATE TABLE profiles (id INTEGER PRIMARY KEY, author_id INTEGER, bio TEXT)",
+		"INSERT INTO authors (id, name) VALUES (1, 'Ursula'), (2, 'Octavia')",
+		"INSERT INTO posts (author_id, title) VALUES (1, 'Earthsea'), (1, 'The Dispossessed'), (2, 'Kindred')",
+		"INSERT INTO profiles (author_id, bio) VALUES (1, 'Portland')",
+	}
+	for _, statement := range schema {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up schema: %v", err)
+		}
+	}
+
+	authors := NewModelStatic(func() *authorModel {
+		author := &authorModel{BaseModel: NewBaseModel()}
+		author.SetParentModel(author)
+		author.Table("authors").Connection(conn.Name).AlwaysWith("posts")
+		return author
+	})
+
+	author, err := authors.Find(1)
+	if err != nil {
+		t.Fatalf("Failed to find author: %v", err)
+	}
+	if posts, ok := author.GetRelation("posts").([]map[string]interface{}); !ok || len(posts) != 2 {
+		t.Errorf("Expected the default relationship to be loaded, got %v", author.GetRelation("posts"))
+	}
+
+	found, err := authors.Query().With("profile").OrderBy("id", "asc").Get()
+	if err != nil {
+		t.Fatalf("Failed to query authors: %v", err)
+	}
+	if len(found) != 2 || found[0].GetRelation("profile") == nil || found[1].GetRelation("posts") == nil {
+		t.Errorf("Expected default and requested relationships to be loaded, got %v", found)
+	}
+
+	found, err = authors.Without("posts").Get()
+	if err != nil {
+		t.Fatalf("Failed to query authors: %v", err)
+	}
+	for _, author := range found {
+		if author.GetRelation("posts") != nil {
+			t.Errorf("Expected Without to skip the default relationship, got %v", author.GetRelation("posts"))
+		}
+	}
+
+	query := authors.Query()
+	query.WithCallback("posts", func(qb *QueryBuilder) { qb.Where("title", "Earthsea") })
+	author, err = query.Find(1)
+	if err != nil {
+		t.Fatalf("Failed to find author: %v", err)
+	}
+	if posts, ok := author.GetRelation("posts").([]map[string]interface{}); !ok || len(posts) != 1 {
+		t.Errorf("Expected the callback to narrow the relationship, got %v", author.GetRelation("posts"))
+	}
+}
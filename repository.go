@@ -0,0 +1,94 @@
+package eloquent
+
+// Repository provides CRUD access to a model. Services can depend on it instead of a
+// ModelStatic global and receive a fake implementation in unit tests.
+type Repository[T Model] interface {
+	Find(id interface{}) (T, error)
+	All() ([]T, error)
+	Create(attributes map[string]interface{}) (T, error)
+	Update(id interface{}, attributes map[string]interface{}) (T, error)
+	Delete(id interface{}) error
+	Paginate(page, perPage int) (*Page[T], error)
+}
+
+// Page holds one page of typed models
+type Page[T Model] struct {
+	Data        []T   `json:"data"`
+	Total       int64 `json:"total"`
+	PerPage     int64 `json:"per_page"`
+	CurrentPage int64 `json:"current_page"`
+	LastPage    int64 `json:"last_page"`
+	From        int64 `json:"from"`
+	To          int64 `json:"to"`
+}
+
+// modelRepository implements Repository on top of a ModelStatic
+type modelRepository[T Model] struct {
+	static *ModelStatic[T]
+}
+
+// NewRepository returns a Repository backed by the database through the given ModelStatic
+func NewRepository[T Model](static *ModelStatic[T]) Repository[T] {
+	return &modelRepository[T]{static: static}
+}
+
+// Find finds a model by primary key
+func (r *modelRepository[T]) Find(id interface{}) (T, error) {
+	return r.static.Find(id)
+}
+
+// All returns every model
+func (r *modelRepository[T]) All() ([]T, error) {
+	return r.static.All()
+}
+
+// Create inserts a new model with the given attributes
+func (r *modelRepository[T]) Create(attributes map[string]interface{}) (T, error) {
+	return r.static.Create(attributes)
+}
+
+// Update finds a model by primary key and updates it with the given attributes
+func (r *modelRepository[T]) Update(id interface{}, attributes map[string]interface{}) (T, error) {
+	model, err := r.static.Find(id)
+	if err != nil {
+		return model, err
+	}
+	if err := model.Update(attributes); err != nil {
+		var zero T
+		return zero, err
+	}
+	return model, nil
+}
+
+// Delete finds a model by primary key and deletes it, softly if the model uses soft deletes
+func (r *modelRepository[T]) Delete(id interface{}) error {
+	model, err := r.static.Find(id)
+	if err != nil {
+		return err
+	}
+	return model.Delete()
+}
+
+// Paginate returns the given page of models, counting pages from 1
+func (r *modelRepository[T]) Paginate(page, perPage int) (*Page[T], error) {
+	total, err := r.static.Query().Count()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	data, err := r.static.Query().Offset(offset).Limit(perPage).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page[T]{
+		Data:        data,
+		Total:       total,
+		PerPage:     int64(perPage),
+		CurrentPage: int64(page),
+		LastPage:    (total + int64(perPage) - 1) / int64(perPage),
+		From:        int64(offset + 1),
+		To:          int64(offset + len(data)),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package eloquent
+
+import (
+	"fmt"
+)
+
+// SchemaBuilder provides DDL helpers for a database connection
+type SchemaBuilder struct {
+	connection *Connection
+}
+
+// NewSchemaBuilder creates a new schema builder for a connection
+func NewSchemaBuilder(connection *Connection) *SchemaBuilder {
+	return &SchemaBuilder{
+		connection: connection,
+	}
+}
+
+// Schema returns a schema builder for the named (or default) connection
+func Schema(name ...string) *SchemaBuilder {
+	return NewSchemaBuilder(DB(name...))
+}
+
+// Views
+
+// CreateView creates a database view from a select query
+func (sb *SchemaBuilder) CreateView(name, query string) error {
+	return sb.exec(fmt.Sprintf("CREATE VIEW %s AS %s", name, query))
+}
+
+// CreateOrReplaceView creates a view, replacing any existing view with the same name
+func (sb *SchemaBuilder) CreateOrReplaceView(name, query string) error {
+	if sb.connection != nil && sb.connection.Driver == "sqlite3" {
+		// SQLite has no CREATE OR REPLACE VIEW
+		if err := sb.DropViewIfExists(name); err != nil {
+			return err
+		}
+		return sb.CreateView(name, query)
+	}
+	return sb.exec(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", name, query))
+}
+
+// DropView drops a database view
+func (sb *SchemaBuilder) DropView(name string) error {
+	return sb.exec(fmt.Sprintf("DROP VIEW %s", name))
+}
+
+// DropViewIfExists drops a database view if it exists
+func (sb *SchemaBuilder) DropViewIfExists(name string) error {
+	return sb.exec(fmt.Sprintf("DROP VIEW IF EXISTS %s", name))
+}
+
+// exec runs a DDL statement on the builder's connection
+func (sb *SchemaBuilder) exec(query string) error {
+	if sb.connection == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+	if sb.connection.ReadOnly {
+		return ErrReadOnly
+	}
+	_, err := sb.connection.Exec(query)
+	sb.connection.forgetColumns()
+	return err
+}
@@ -0,0 +1,366 @@
+package eloquent
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaCreateView(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	schema := Schema()
+	err := schema.CreateView("active_users", "SELECT id, name FROM users WHERE status = 'active'")
+	if err != nil {
+		t.Fatalf("Failed to create view: %v", err)
+	}
+
+	count, err := NewQueryBuilder(DB()).Table("active_users").Count()
+	if err != nil {
+		t.Fatalf("Failed to query view: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 active users in view, got %d", count)
+	}
+
+	err = schema.CreateOrReplaceView("active_users", "SELECT id, name FROM users WHERE is_admin = true")
+	if err != nil {
+		t.Fatalf("Failed to replace view: %v", err)
+	}
+
+	count, err = NewQueryBuilder(DB()).Table("active_users").Count()
+	if err != nil {
+		t.Fatalf("Failed to query replaced view: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 admin users in replaced view, got %d", count)
+	}
+
+	if err := schema.DropView("active_users"); err != nil {
+		t.Fatalf("Failed to drop view: %v", err)
+	}
+	if err := schema.DropViewIfExists("active_users"); err != nil {
+		t.Errorf("Expected DropViewIfExists to succeed for missing view, got %v", err)
+	}
+}
+
+func TestViewModelIsReadOnly(t *testing.T) {
+	setupQueryBuilderTestDB(t)
+
+	model := NewBaseModel()
+	model.Table("active_users").IsView()
+
+	if !model.IsReadOnly() {
+		t.Fatal("Expected view model to be read-only")
+	}
+
+	model.Fill(map[string]interface{}{"name": "View User"})
+	if err := model.Save(); err != ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly when saving view model, got %v", err)
+	}
+}
+
+func TestSchemaBlueprint(t *testing.T) {
+	conn := NewTestSQLite(t)
+	schema := NewSchemaBuilder(conn)
+
+	err := schema.Create("accounts", func(table *Blueprint) {
+		table.Increments("id")
+		table.String("email").Unique()
+		table.String("first_name", 100)
+		table.String("last_name", 100)
+		table.Text("bio").Nullable()
+		table.Integer("age").Default(0)
+		table.Boolean("active").Default(true)
+		table.Timestamps()
+		table.Index("last_name", "first_name")
+		table.FullTextIndex("bio")
+		table.Check("age >= 0")
+	})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	insert := func(email string, age int) error {
+		_, err := conn.Exec("INSERT INTO accounts (email, first_name, last_name, age) VALUES (?, 'Ada', 'Lovelace', ?)", email, age)
+		return err
+	}
+	if err := insert("ada@example.com", 36); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	if err := insert("ada@example.com", 36); err == nil {
+		t.Error("Expected the unique index to reject a duplicate email")
+	}
+	if err := insert("young@example.com", -1); err == nil {
+		t.Error("Expected the check constraint to reject a negative age")
+	}
+
+	indexes, err := conn.Table("sqlite_master").Where("type", "index").Where("tbl_name", "accounts").WhereNotNull("sql").Count()
+	if err != nil {
+		t.Fatalf("Failed to read indexes: %v", err)
+	}
+	if indexes != 3 {
+		t.Errorf("Expected 3 indexes, got %d", indexes)
+	}
+
+	// SQLite rebuilds the table to add a check, keeping rows and indexes
+	err = schema.Table("accounts", func(table *Blueprint) {
+		table.String("nickname").Nullable()
+		table.Check("length(email) > 3").Name("accounts_email_length")
+		table.DropIndex("accounts_bio_fulltext")
+	})
+	if err != nil {
+		t.Fatalf("Failed to alter table: %v", err)
+	}
+	if count, _ := conn.Table("accounts").WhereNull("nickname").Count(); count != 1 {
+		t.Errorf("Expected the row to survive the rebuild, got %d rows", count)
+	}
+	if _, err := conn.Exec("INSERT INTO accounts (email, first_name, last_name) VALUES ('a@b', 'A', 'B')"); err == nil {
+		t.Error("Expected the added check constraint to reject a short email")
+	}
+	if err := insert("ada@example.com", 36); err == nil {
+		t.Error("Expected the unique index to survive the rebuild")
+	}
+
+	if err := schema.DropIfExists("accounts"); err != nil {
+		t.Fatalf("Failed to drop table: %v", err)
+	}
+}
+
+func TestBlueprintDialects(t *testing.T) {
+	define := func(table *Blueprint) {
+		table.Increments("id")
+		table.Timestamp("seen_at")
+		table.Column("location", "GEOMETRY")
+		table.FullTextIndex("title", "body")
+		table.SpatialIndex("location")
+		table.Check("seen_at IS NOT NULL").Name("seen")
+	}
+
+	tests := map[string][]string{
+		"postgres": {
+			"ALTER TABLE posts ADD COLUMN id BIGSERIAL PRIMARY KEY",
+			"ALTER TABLE posts ADD COLUMN seen_at TIMESTAMPTZ NOT NULL",
+			"ALTER TABLE posts ADD COLUMN location GEOMETRY NOT NULL",
+			"ALTER TABLE posts ADD CONSTRAINT seen CHECK (seen_at IS NOT NULL)",
+			"CREATE INDEX posts_title_body_fulltext ON posts USING GIN (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, '')))",
+			"CREATE INDEX posts_location_spatial ON posts USING GIST (location)",
+		},
+		"mysql": {
+			"ALTER TABLE posts ADD COLUMN id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY",
+			"ALTER TABLE posts ADD COLUMN seen_at DATETIME(6) NOT NULL",
+			"ALTER TABLE posts ADD COLUMN location GEOMETRY NOT NULL",
+			"ALTER TABLE posts ADD CONSTRAINT seen CHECK (seen_at IS NOT NULL)",
+			"CREATE FULLTEXT INDEX posts_title_body_fulltext ON posts (title, body)",
+			"CREATE SPATIAL INDEX posts_location_spatial ON posts (location)",
+		},
+	}
+	for driver, want := range tests {
+		blueprint := &Blueprint{table: "posts"}
+		define(blueprint)
+		statements, rebuild, err := blueprint.compile(&Connection{Driver: driver})
+		if err != nil {
+			t.Fatalf("%s: compile failed: %v", driver, err)
+		}
+		if !reflect.DeepEqual(statements, want) || rebuild != nil {
+			t.Errorf("%s: unexpected statements:\n%s", driver, strings.Join(statements, "\n"))
+		}
+	}
+
+	blueprint := &Blueprint{table: "posts"}
+	blueprint.Index("title; DROP TABLE posts")
+	if _, _, err := blueprint.compile(&Connection{Driver: "sqlite3"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an invalid column, got %v", err)
+	}
+}
+
+func TestSchemaForeignKeys(t *testing.T) {
+	conn := NewTestSQLite(t, SQLiteOptions{ForeignKeys: true})
+	schema := NewSchemaBuilder(conn)
+
+	for table, define := range map[string]func(*Blueprint){
+		"authors": func(table *Blueprint) {
+			table.Increments("id")
+			table.String("name")
+		},
+		"books": func(table *Blueprint) {
+			table.Increments("id")
+			table.Integer("author_id")
+			table.String("title")
+		},
+	} {
+		if err := schema.Create(table, define); err != nil {
+			t.Fatalf("Failed to create %s: %v", table, err)
+		}
+	}
+	for _, statement := range []string{
+		"INSERT INTO authors (id, name) VALUES (1, 'Ada'), (2, 'Grace')",
+		"INSERT INTO books (author_id, title) VALUES (1, 'Notes'), (2, 'Compilers')",
+	} {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("Failed to seed: %v", err)
+		}
+	}
+
+	// SQLite rebuilds the table to add the foreign key
+	err := schema.Table("books", func(table *Blueprint) {
+		table.Foreign("author_id").References("id").On("authors").OnDelete("cascade").OnUpdate("restrict")
+	})
+	if err != nil {
+		t.Fatalf("Failed to add foreign key: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO books (author_id, title) VALUES (9, 'Orphan')"); err == nil {
+		t.Error("Expected the foreign key to reject an unknown author")
+	}
+	if _, err := conn.Exec("DELETE FROM authors WHERE id = 1"); err != nil {
+		t.Fatalf("Failed to delete author: %v", err)
+	}
+	if count, _ := conn.Table("books").Count(); count != 1 {
+		t.Errorf("Expected the author's books to be deleted in cascade, got %d books", count)
+	}
+
+	err = schema.Table("books", func(table *Blueprint) {
+		table.DropForeign("books_author_id_foreign")
+	})
+	if err != nil {
+		t.Fatalf("Failed to drop foreign key: %v", err)
+	}
+	if _, err := conn.Exec("INSERT INTO books (author_id, title) VALUES (9, 'Orphan')"); err != nil {
+		t.Errorf("Expected the foreign key to be dropped, got %v", err)
+	}
+
+	err = schema.Table("books", func(table *Blueprint) {
+		table.DropCheck("books_missing_check")
+	})
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery dropping a missing constraint, got %v", err)
+	}
+	err = schema.Table("books", func(table *Blueprint) {
+		table.Foreign("author_id").References("id").On("authors").OnDelete("explode")
+	})
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an unknown action, got %v", err)
+	}
+}
+
+func TestBlueprintForeignKeyDialects(t *testing.T) {
+	tests := map[string][]string{
+		"postgres": {
+			"ALTER TABLE app_posts DROP CONSTRAINT app_posts_old_foreign",
+			"ALTER TABLE app_posts ADD CONSTRAINT posts_user_id_foreign FOREIGN KEY (user_id) REFERENCES app_users (id) ON DELETE SET NULL ON UPDATE NO ACTION",
+		},
+		"mysql": {
+			"ALTER TABLE app_posts DROP FOREIGN KEY app_posts_old_foreign",
+			"ALTER TABLE app_posts ADD CONSTRAINT posts_user_id_foreign FOREIGN KEY (user_id) REFERENCES app_users (id) ON DELETE SET NULL ON UPDATE NO ACTION",
+		},
+	}
+	for driver, want := range tests {
+		blueprint := &Blueprint{table: "posts"}
+		blueprint.DropForeign("app_posts_old_foreign")
+		blueprint.Foreign("user_id").References("id").On("users").OnDelete("set  null").OnUpdate("no action")
+		statements, _, err := blueprint.compile(&Connection{Driver: driver, Prefix: "app_"})
+		if err != nil {
+			t.Fatalf("%s: compile failed: %v", driver, err)
+		}
+		if !reflect.DeepEqual(statements, want) {
+			t.Errorf("%s: unexpected statements:\n%s", driver, strings.Join(statements, "\n"))
+		}
+	}
+}
+
+func TestSchemaDumpAndLoad(t *testing.T) {
+	source := NewTestSQLite(t)
+	schema := NewSchemaBuilder(source)
+	err := schema.Create("accounts", func(table *Blueprint) {
+		table.Increments("id")
+		table.String("email").Unique()
+		table.Integer("age").Default(0)
+		table.Check("age >= 0")
+	})
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if err := schema.CreateView("adults", "SELECT id, email FROM accounts WHERE age >= 18"); err != nil {
+		t.Fatalf("Failed to create view: %v", err)
+	}
+
+	var dump strings.Builder
+	if err := schema.Dump(&dump); err != nil {
+		t.Fatalf("Dump failed: %v", err)
+	}
+	for _, want := range []string{"CREATE TABLE accounts", "CREATE UNIQUE INDEX accounts_email_unique", "CREATE VIEW adults"} {
+		if !strings.Contains(dump.String(), want) {
+			t.Errorf("Expected the dump to contain %q, got:\n%s", want, dump.String())
+		}
+	}
+
+	target := NewTestSQLite(t)
+	if err := NewSchemaBuilder(target).Load(strings.NewReader(dump.String())); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := target.Exec("INSERT INTO accounts (email, age) VALUES ('ada@example.com', 36)"); err != nil {
+		t.Fatalf("Failed to insert into the loaded table: %v", err)
+	}
+	if count, err := target.Table("adults").Count(); err != nil || count != 1 {
+		t.Errorf("Expected the loaded view to work, got %d, %v", count, err)
+	}
+	if _, err := target.Exec("INSERT INTO accounts (email, age) VALUES ('young@example.com', -1)"); err == nil {
+		t.Error("Expected the loaded check constraint to hold")
+	}
+
+	if err := NewSchemaBuilder(&Connection{Driver: "postgres"}).Dump(&dump); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for PostgreSQL, got %v", err)
+	}
+}
+
+func TestBlueprintOnlineChanges(t *testing.T) {
+	define := func(table *Blueprint) {
+		table.String("coupon").Nullable()
+		table.Index("coupon")
+		table.DropIndex("orders_legacy_index")
+		table.DropForeign("orders_customer_id_foreign")
+	}
+
+	blueprint := &Blueprint{table: "orders"}
+	define(blueprint.Online())
+	statements, _, err := blueprint.compile(&Connection{Driver: "mysql"})
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	want := []string{"ALTER TABLE orders DROP FOREIGN KEY orders_customer_id_foreign, DROP INDEX orders_legacy_index, " +
+		"ADD COLUMN coupon VARCHAR(255), ADD INDEX orders_coupon_index (coupon), ALGORITHM=INPLACE, LOCK=NONE"}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("Unexpected statements:\n%s", strings.Join(statements, "\n"))
+	}
+
+	blueprint = &Blueprint{table: "orders"}
+	define(blueprint.Online())
+	statements, _, err = blueprint.compile(&Connection{Driver: "postgres"})
+	if err != nil || len(statements) != 4 {
+		t.Errorf("Expected the hints to be ignored on PostgreSQL, got %v, %v", statements, err)
+	}
+
+	blueprint = &Blueprint{table: "orders"}
+	blueprint.Algorithm("fastest")
+	if _, _, err := blueprint.compile(&Connection{Driver: "mysql"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for an unknown algorithm, got %v", err)
+	}
+
+	var gotTable, gotAlter string
+	schema := NewSchemaBuilder(&Connection{Driver: "mysql"})
+	err = schema.Table("orders", func(table *Blueprint) {
+		table.Using(func(table, alter string) error {
+			gotTable, gotAlter = table, alter
+			return nil
+		}).Lock("none")
+		table.Integer("points").Default(0)
+		table.Unique("coupon")
+	})
+	if err != nil {
+		t.Fatalf("Table failed: %v", err)
+	}
+	if gotTable != "orders" || gotAlter != "ADD COLUMN points INTEGER NOT NULL DEFAULT 0, ADD UNIQUE INDEX orders_coupon_unique (coupon)" {
+		t.Errorf("Unexpected runner call: %q %q", gotTable, gotAlter)
+	}
+}
@@ -0,0 +1,135 @@
+package eloquent
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dumpSeparator ends each statement of a schema dump
+const dumpSeparator = ";\n\n"
+
+// Dump writes the connection's schema, its tables, indexes, views and triggers, as SQL
+// statements that Load runs to recreate it. Loading a dump is much faster than replaying a
+// long migration history, for example when setting up CI databases. SQLite and MySQL are
+// supported; use pg_dump --schema-only for PostgreSQL.
+func (sb *SchemaBuilder) Dump(w io.Writer) error {
+	if sb.connection == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+
+	var statements []string
+	var err error
+	switch sb.connection.Driver {
+	case "sqlite3":
+		statements, err = sb.sqliteSchema()
+	case "mysql":
+		statements, err = sb.mysqlSchema()
+	default:
+		return fmt.Errorf("%w: dumping %s schemas", errors.ErrUnsupported, sb.connection.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to dump schema: %w", err)
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "-- %s schema dump\n\n", sb.connection.Driver)
+	for _, statement := range statements {
+		out.WriteString(strings.TrimSpace(statement))
+		out.WriteString(dumpSeparator)
+	}
+	return out.Flush()
+}
+
+// Load runs the statements of a schema dump written by Dump
+func (sb *SchemaBuilder) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range strings.Split(string(data), dumpSeparator) {
+		statement = strings.TrimSpace(statement)
+		for strings.HasPrefix(statement, "--") {
+			_, statement, _ = strings.Cut(statement, "\n")
+			statement = strings.TrimSpace(statement)
+		}
+		if statement == "" {
+			continue
+		}
+		if err := sb.exec(statement); err != nil {
+			return fmt.Errorf("failed to load schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// sqliteSchema returns the statements creating a SQLite schema, tables first
+func (sb *SchemaBuilder) sqliteSchema() ([]string, error) {
+	var statements []string
+	err := sb.connection.DB.Select(&statements, `SELECT sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 WHEN 'view' THEN 2 ELSE 3 END, name`)
+	return statements, err
+}
+
+// mysqlSchema returns the statements creating a MySQL schema. Foreign key checks are off
+// while loading, so tables can be created in name order.
+func (sb *SchemaBuilder) mysqlSchema() ([]string, error) {
+	var tables []struct {
+		Name string `db:"name"`
+		Type string `db:"type"`
+	}
+	err := sb.connection.DB.Select(&tables, `SELECT table_name AS name, table_type AS type
+		FROM information_schema.tables WHERE table_schema = DATABASE()
+		ORDER BY table_type = 'VIEW', table_name`)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{"SET FOREIGN_KEY_CHECKS = 0"}
+	for _, table := range tables {
+		query := "SHOW CREATE TABLE `" + table.Name + "`"
+		if table.Type == "VIEW" {
+			query = "SHOW CREATE VIEW `" + table.Name + "`"
+		}
+		rows, err := sb.connection.DB.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		create, err := scanDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the definition of %s: %w", table.Name, err)
+		}
+		statements = append(statements, create)
+	}
+	return append(statements, "SET FOREIGN_KEY_CHECKS = 1"), nil
+}
+
+// scanDefinition reads the CREATE statement, the second column, of a SHOW CREATE result
+func scanDefinition(rows *sql.Rows) (string, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", sql.ErrNoRows
+	}
+
+	var create string
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+	values[1] = &create
+	err = rows.Scan(values...)
+	return create, err
+}
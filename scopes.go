@@ -3,6 +3,7 @@ package eloquent
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,8 +15,10 @@ type GlobalScope interface {
 	Apply(*QueryBuilder, Model)
 }
 
-// ScopeRegistry manages query scopes
+// ScopeRegistry manages query scopes.
+// It is safe for concurrent use.
 type ScopeRegistry struct {
+	mu     sync.RWMutex
 	scopes map[string]Scope
 	global []GlobalScope
 }
@@ -30,28 +33,48 @@ func NewScopeRegistry() *ScopeRegistry {
 
 // Register registers a named scope
 func (sr *ScopeRegistry) Register(name string, scope Scope) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
 	sr.scopes[name] = scope
 }
 
 // RegisterGlobal registers a global scope
 func (sr *ScopeRegistry) RegisterGlobal(scope GlobalScope) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
 	sr.global = append(sr.global, scope)
 }
 
 // Apply applies a named scope to a query builder
 func (sr *ScopeRegistry) Apply(name string, qb *QueryBuilder) error {
-	if scope, exists := sr.scopes[name]; exists {
+	sr.mu.RLock()
+	scope, exists := sr.scopes[name]
+	sr.mu.RUnlock()
+
+	if exists {
 		scope(qb)
 		return nil
 	}
 	return fmt.Errorf("scope '%s' not found", name)
 }
 
-// ApplyGlobal applies all global scopes to a query builder
-func (sr *ScopeRegistry) ApplyGlobal(qb *QueryBuilder, model Model) {
-	for _, scope := range sr.global {
-		scope.Apply(qb, model)
+// ApplyGlobal applies all global scopes to a query builder and returns it. An immutable
+// builder is left unchanged and the scoped copy is returned.
+func (sr *ScopeRegistry) ApplyGlobal(qb *QueryBuilder, model Model) *QueryBuilder {
+	sr.mu.RLock()
+	global := make([]GlobalScope, len(sr.global))
+	copy(global, sr.global)
+	sr.mu.RUnlock()
+
+	if len(global) == 0 {
+		return qb
 	}
+	return qb.apply(func(target *QueryBuilder) {
+		target.groupWheres(0)
+		for _, scope := range global {
+			scope.Apply(target, model)
+		}
+	})
 }
 
 // Common scopes
@@ -100,14 +123,21 @@ func SearchScope(query string, columns ...string) Scope {
 			columns = []string{"name", "title", "description"}
 		}
 
-		// Add OR conditions for each column
-		for i, column := range columns {
-			if i == 0 {
-				qb.Where(fmt.Sprintf("LOWER(%s)", column), "LIKE", searchTerm)
-			} else {
-				qb.OrWhere(fmt.Sprintf("LOWER(%s)", column), "LIKE", searchTerm)
+		// Match any column, grouped so the OR conditions don't escape other constraints
+		qb.WhereGroup(func(group *QueryBuilder) {
+			for _, column := range columns {
+				if err := validateColumn(column); err != nil {
+					group.fail(err)
+					return
+				}
+				group.wheres = append(group.wheres, WhereClause{
+					Column:  fmt.Sprintf("LOWER(%s) LIKE ?", column),
+					Boolean: "or",
+					Type:    "raw",
+					Values:  []interface{}{searchTerm},
+				})
 			}
-		}
+		})
 	}
 }
 
@@ -375,12 +405,16 @@ func CreateScope(name string, fn func(*QueryBuilder, ...interface{})) func(...in
 
 // ScopeExists checks if a scope exists in the registry
 func (sr *ScopeRegistry) ScopeExists(name string) bool {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
 	_, exists := sr.scopes[name]
 	return exists
 }
 
 // ListScopes returns all registered scope names
 func (sr *ScopeRegistry) ListScopes() []string {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
 	var names []string
 	for name := range sr.scopes {
 		names = append(names, name)
@@ -390,11 +424,15 @@ func (sr *ScopeRegistry) ListScopes() []string {
 
 // RemoveScope removes a scope from the registry
 func (sr *ScopeRegistry) RemoveScope(name string) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
 	delete(sr.scopes, name)
 }
 
 // ClearScopes removes all scopes from the registry
 func (sr *ScopeRegistry) ClearScopes() {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
 	sr.scopes = make(map[string]Scope)
 }
 
@@ -0,0 +1,64 @@
+package eloquent
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SecretResolver looks up secrets such as database passwords in an external store
+// (Vault, AWS Secrets Manager, ...). The reference format is up to the resolver.
+type SecretResolver interface {
+	Resolve(reference string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to the SecretResolver interface
+type SecretResolverFunc func(reference string) (string, error)
+
+// Resolve calls the underlying function
+func (f SecretResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+var (
+	secretResolver   SecretResolver
+	secretResolverMu sync.RWMutex
+)
+
+// SetSecretResolver sets the resolver consulted by AutoConnect for *_PASSWORD_SECRET references
+func SetSecretResolver(resolver SecretResolver) {
+	secretResolverMu.Lock()
+	defer secretResolverMu.Unlock()
+	secretResolver = resolver
+}
+
+// envPassword resolves the password for a connection prefix.
+// <prefix>PASSWORD_FILE is read first, then <prefix>PASSWORD_SECRET is passed to the
+// secret resolver, and finally the plain <prefix>PASSWORD value is used.
+func envPassword(prefix string) (string, error) {
+	if path := Env(prefix + "PASSWORD_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %sPASSWORD_FILE: %w", prefix, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	if reference := Env(prefix + "PASSWORD_SECRET"); reference != "" {
+		secretResolverMu.RLock()
+		secretResolver := secretResolver
+		secretResolverMu.RUnlock()
+
+		if secretResolver == nil {
+			return "", fmt.Errorf("%sPASSWORD_SECRET is set but no secret resolver is configured", prefix)
+		}
+		password, err := secretResolver.Resolve(reference)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %sPASSWORD_SECRET: %w", prefix, err)
+		}
+		return password, nil
+	}
+
+	return Env(prefix+"PASSWORD", ""), nil
+}
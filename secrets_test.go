@@ -0,0 +1,80 @@
+package eloquent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvPasswordFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+
+	t.Setenv("DB_SECRETS_PASSWORD", "plain")
+	t.Setenv("DB_SECRETS_PASSWORD_FILE", path)
+
+	password, err := envPassword("DB_SECRETS_")
+	if err != nil {
+		t.Fatalf("envPassword failed: %v", err)
+	}
+	if password != "from-file" {
+		t.Errorf("Expected password from file, got %q", password)
+	}
+}
+
+func TestEnvPasswordFromSecretResolver(t *testing.T) {
+	t.Setenv("DB_SECRETS_PASSWORD_SECRET", "vault:secret/db#password")
+
+	if _, err := envPassword("DB_SECRETS_"); err == nil {
+		t.Error("Expected error when no secret resolver is configured")
+	}
+
+	SetSecretResolver(SecretResolverFunc(func(reference string) (string, error) {
+		if reference != "vault:secret/db#password" {
+			return "", fmt.Errorf("unknown secret %s", reference)
+		}
+		return "from-vault", nil
+	}))
+	defer SetSecretResolver(nil)
+
+	password, err := envPassword("DB_SECRETS_")
+	if err != nil {
+		t.Fatalf("envPassword failed: %v", err)
+	}
+	if password != "from-vault" {
+		t.Errorf("Expected password from resolver, got %q", password)
+	}
+}
+
+func TestEnvPasswordPlain(t *testing.T) {
+	t.Setenv("DB_SECRETS_PASSWORD", "plain")
+
+	password, err := envPassword("DB_SECRETS_")
+	if err != nil {
+		t.Fatalf("envPassword failed: %v", err)
+	}
+	if password != "plain" {
+		t.Errorf("Expected plain password, got %q", password)
+	}
+}
+
+func TestEnvPasswordWithDatabaseURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write password file: %v", err)
+	}
+
+	t.Setenv("DB_SECRETS_URL", "postgres://reporter@reports.internal/reports")
+	t.Setenv("DB_SECRETS_PASSWORD_FILE", path)
+
+	config, err := envConnectionConfig("DB_SECRETS_")
+	if err != nil {
+		t.Fatalf("envConnectionConfig failed: %v", err)
+	}
+	if config.Password != "from-file" || config.Username != "reporter" {
+		t.Errorf("Expected the password file to apply to the URL config, got %+v", config)
+	}
+}
@@ -0,0 +1,378 @@
+package eloquent
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ShardResolver maps shard key values to connection names
+type ShardResolver interface {
+	// Resolve returns the connection name holding the given shard key value
+	Resolve(key interface{}) (string, error)
+	// Connections returns the connection names of every shard
+	Connections() []string
+}
+
+// HashShardResolver distributes shard keys across connections by hashing the key value
+type HashShardResolver struct {
+	connections []string
+}
+
+// NewHashShardResolver creates a hash-based resolver over the given connection names
+func NewHashShardResolver(connections ...string) *HashShardResolver {
+	return &HashShardResolver{
+		connections: connections,
+	}
+}
+
+// Resolve returns the connection name for a shard key value
+func (r *HashShardResolver) Resolve(key interface{}) (string, error) {
+	if len(r.connections) == 0 {
+		return "", fmt.Errorf("no shard connections configured")
+	}
+	if key == nil {
+		return "", fmt.Errorf("shard key value is nil")
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(fmt.Sprintf("%v", key)))
+	return r.connections[h.Sum32()%uint32(len(r.connections))], nil
+}
+
+// Connections returns the connection names of every shard
+func (r *HashShardResolver) Connections() []string {
+	return r.connections
+}
+
+var (
+	defaultShardResolver   ShardResolver
+	defaultShardResolverMu sync.RWMutex
+)
+
+// SetShardResolver sets the resolver used by sharded models that do not define their own
+func SetShardResolver(resolver ShardResolver) {
+	defaultShardResolverMu.Lock()
+	defer defaultShardResolverMu.Unlock()
+	defaultShardResolver = resolver
+}
+
+// ShardBy routes the model's queries and writes by the value of the given column
+func (m *BaseModel) ShardBy(column string, resolver ...ShardResolver) *BaseModel {
+	m.shardKey = column
+	if len(resolver) > 0 {
+		m.shardResolver = resolver[0]
+	}
+	return m
+}
+
+// GetShardKey returns the column used to route the model between shards
+func (m *BaseModel) GetShardKey() string {
+	return m.shardKey
+}
+
+// getShardResolver returns the model's resolver or the default one
+func (m *BaseModel) getShardResolver() ShardResolver {
+	if m.shardResolver != nil {
+		return m.shardResolver
+	}
+
+	defaultShardResolverMu.RLock()
+	defer defaultShardResolverMu.RUnlock()
+	return defaultShardResolver
+}
+
+// resolveShard returns the connection name for a shard key value
+func (m *BaseModel) resolveShard(key interface{}) (string, error) {
+	resolver := m.getShardResolver()
+	if resolver == nil {
+		return "", fmt.Errorf("model is sharded by '%s' but no shard resolver is configured", m.shardKey)
+	}
+
+	name, err := resolver.Resolve(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve shard for '%s': %w", m.shardKey, err)
+	}
+	return name, nil
+}
+
+// shardConnection returns the named shard connection of a manager without falling back
+// to the default
+func shardConnection(cm *ConnectionManager, name string) (*Connection, error) {
+	conn, ok := cm.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("shard connection '%s' not found", name)
+	}
+	return conn, nil
+}
+
+// shardedModel returns the sharding configuration of a builder's model
+func shardedModel(model Model) (*BaseModel, error) {
+	bm := baseModelOf(model)
+	if bm == nil || bm.shardKey == "" {
+		return nil, fmt.Errorf("model is not sharded")
+	}
+	return bm, nil
+}
+
+// OnShard routes the query to the shard holding the given shard key value.
+// If the shard cannot be resolved, running the query returns the error.
+func (mqb *ModelQueryBuilder) OnShard(key interface{}) *ModelQueryBuilder {
+	bm, err := shardedModel(mqb.model)
+	if err != nil {
+		return mqb.wrap(mqb.QueryBuilder.mutable().fail(err))
+	}
+	name, err := bm.resolveShard(key)
+	if err != nil {
+		return mqb.wrap(mqb.QueryBuilder.mutable().fail(err))
+	}
+	conn, err := shardConnection(bm.getManager(), name)
+	if err != nil {
+		return mqb.wrap(mqb.QueryBuilder.mutable().fail(err))
+	}
+	return mqb.wrap(mqb.QueryBuilder.apply(func(target *QueryBuilder) {
+		target.connection = conn
+	}))
+}
+
+// GetAcrossShards runs the query on every shard and merges the results. The merged rows are
+// ordered by the query's orders and then cut to its offset and limit, so the ordered columns
+// must be selected. Grouped, distinct and raw or field ordered queries return ErrInvalidQuery.
+func (mqb *ModelQueryBuilder) GetAcrossShards() ([]Model, error) {
+	bm, err := shardedModel(mqb.model)
+	if err != nil {
+		return nil, err
+	}
+	resolver := bm.getShardResolver()
+	if resolver == nil {
+		return nil, fmt.Errorf("no shard resolver is configured")
+	}
+	fanOut, err := mqb.QueryBuilder.fanOut()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []Model
+	for _, name := range resolver.Connections() {
+		conn, err := shardConnection(bm.getManager(), name)
+		if err != nil {
+			return nil, err
+		}
+
+		shardQB := &ModelQueryBuilder{
+			QueryBuilder: fanOut.clone(),
+			model:        mqb.model,
+		}
+		shardQB.connection = conn
+
+		results, err := shardQB.Get()
+		if err != nil {
+			return nil, fmt.Errorf("query on shard '%s' failed: %w", name, err)
+		}
+		models = append(models, results...)
+	}
+
+	return mergeShardRows(mqb.QueryBuilder, models), nil
+}
+
+// fanOut returns the query each shard runs for GetAcrossShards. Shards skip the offset and
+// return the first offset+limit rows, so the merged rows can be cut to the page again.
+func (qb *QueryBuilder) fanOut() (*QueryBuilder, error) {
+	if len(qb.groups) > 0 || len(qb.havings) > 0 || qb.distinct {
+		return nil, fmt.Errorf("%w: grouped and distinct queries cannot be merged across shards", ErrInvalidQuery)
+	}
+	for _, order := range qb.effectiveOrders() {
+		if order.Type == "raw" || order.Type == "field" {
+			return nil, fmt.Errorf("%w: %s orders cannot be merged across shards", ErrInvalidQuery, order.Type)
+		}
+	}
+
+	shardQB := qb.clone()
+	shardQB.offsetValue = nil
+	if qb.limitValue != nil && qb.offsetValue != nil {
+		limit := *qb.limitValue + *qb.offsetValue
+		shardQB.limitValue = &limit
+	}
+	return shardQB, nil
+}
+
+// mergeShardRows orders the rows of every shard by the query's orders, then applies its
+// offset and limit
+func mergeShardRows[T Model](qb *QueryBuilder, models []T) []T {
+	if orders := qb.effectiveOrders(); len(orders) > 0 {
+		sort.SliceStable(models, func(i, j int) bool {
+			for _, order := range orders {
+				column := unqualified(order.Column)
+				a, b := models[i].GetAttribute(column), models[j].GetAttribute(column)
+				if order.Nulls != "" && (a == nil) != (b == nil) {
+					return (a == nil) == (order.Nulls == "first")
+				}
+				result := compareShardValues(a, b)
+				if order.Direction == "desc" {
+					result = -result
+				}
+				if result != 0 {
+					return result < 0
+				}
+			}
+			return false
+		})
+	}
+
+	if qb.offsetValue != nil {
+		if *qb.offsetValue >= len(models) {
+			return nil
+		}
+		models = models[*qb.offsetValue:]
+	}
+	if qb.limitValue != nil && *qb.limitValue < len(models) {
+		models = models[:*qb.limitValue]
+	}
+	return models
+}
+
+// compareShardValues compares two attribute values the way the database orders them, with
+// nil first
+func compareShardValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if x, ok := shardNumber(a); ok {
+		if y, ok := shardNumber(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case x:
+				return 1
+			default:
+				return -1
+			}
+		}
+	case []byte:
+		a = string(x)
+	}
+	if y, ok := b.([]byte); ok {
+		b = string(y)
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// shardNumber returns a numeric attribute value as a float64
+func shardNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// OnShard routes the query to the shard holding the given shard key value
+func (tmqb *TypedModelQueryBuilder[T]) OnShard(key interface{}) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().OnShard(key).QueryBuilder)
+}
+
+// GetAcrossShards runs the query on every shard and merges the typed results like
+// ModelQueryBuilder.GetAcrossShards
+func (tmqb *TypedModelQueryBuilder[T]) GetAcrossShards() ([]T, error) {
+	bm, err := shardedModel(tmqb.model)
+	if err != nil {
+		return nil, err
+	}
+	resolver := bm.getShardResolver()
+	if resolver == nil {
+		return nil, fmt.Errorf("no shard resolver is configured")
+	}
+	fanOut, err := tmqb.QueryBuilder.fanOut()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []T
+	for _, name := range resolver.Connections() {
+		conn, err := shardConnection(bm.getManager(), name)
+		if err != nil {
+			return nil, err
+		}
+
+		shardQB := &TypedModelQueryBuilder[T]{
+			QueryBuilder: fanOut.clone(),
+			model:        tmqb.model,
+			modelFactory: tmqb.modelFactory,
+		}
+		shardQB.connection = conn
+
+		results, err := shardQB.Get()
+		if err != nil {
+			return nil, fmt.Errorf("query on shard '%s' failed: %w", name, err)
+		}
+		models = append(models, results...)
+	}
+
+	return mergeShardRows(tmqb.QueryBuilder, models), nil
+}
+
+// OnShard creates a new query routed to the shard holding the given key (static-like)
+func (ms *ModelStatic[T]) OnShard(key interface{}) *TypedModelQueryBuilder[T] {
+	model := ms.modelFactory()
+	qb := NewModelQueryBuilder(model).OnShard(key)
+	return &TypedModelQueryBuilder[T]{
+		QueryBuilder: qb.QueryBuilder,
+		model:        model,
+		modelFactory: ms.modelFactory,
+	}
+}
+
+// AllAcrossShards gets all records from every shard (static-like)
+func (ms *ModelStatic[T]) AllAcrossShards() ([]T, error) {
+	model := ms.modelFactory()
+	tmqb := &TypedModelQueryBuilder[T]{
+		QueryBuilder: NewModelQueryBuilder(model).QueryBuilder,
+		model:        model,
+		modelFactory: ms.modelFactory,
+	}
+	return tmqb.GetAcrossShards()
+}
@@ -0,0 +1,267 @@
+package eloquent
+
+import (
+	"errors"
+	"testing"
+)
+
+type shardedOrder struct {
+	*BaseModel
+	ID        string `db:"id"`
+	CompanyID string `db:"company_id"`
+	Total     int64  `db:"total"`
+}
+
+func newShardedOrder(resolver ShardResolver) *shardedOrder {
+	order := &shardedOrder{BaseModel: NewBaseModel()}
+	order.Table("orders").WithoutTimestamps().ShardBy("company_id", resolver)
+	order.SetParentModel(order)
+	return order
+}
+
+func setupShardTestDB(t *testing.T) *HashShardResolver {
+	if err := SQLite(":memory:"); err != nil {
+		t.Fatalf("Failed to set up default connection: %v", err)
+	}
+
+	shards := []string{"shard_a", "shard_b"}
+	for _, name := range shards {
+		err := GetManager().AddConnection(name, ConnectionConfig{Driver: "sqlite3", Database: ":memory:"})
+		if err != nil {
+			t.Fatalf("Failed to add shard %s: %v", name, err)
+		}
+		_, err = DB(name).Exec("CREATE TABLE orders (id TEXT PRIMARY KEY, company_id TEXT, total INTEGER)")
+		if err != nil {
+			t.Fatalf("Failed to create orders table on %s: %v", name, err)
+		}
+	}
+
+	return NewHashShardResolver(shards...)
+}
+
+func TestHashShardResolver(t *testing.T) {
+	resolver := NewHashShardResolver("shard_a", "shard_b")
+
+	first, err := resolver.Resolve("company-1")
+	if err != nil {
+		t.Fatalf("Failed to resolve shard: %v", err)
+	}
+	second, _ := resolver.Resolve("company-1")
+	if first != second {
+		t.Errorf("Expected stable shard resolution, got %s and %s", first, second)
+	}
+
+	if _, err := resolver.Resolve(nil); err == nil {
+		t.Error("Expected error when resolving nil shard key")
+	}
+	if _, err := NewHashShardResolver().Resolve("x"); err == nil {
+		t.Error("Expected error when no shards are configured")
+	}
+}
+
+func TestShardedModelRouting(t *testing.T) {
+	resolver := setupShardTestDB(t)
+	defer func() { _ = GetManager().CloseAll() }()
+
+	companies := []string{"acme", "globex", "initech", "umbrella", "hooli"}
+	for i, company := range companies {
+		order := newShardedOrder(resolver)
+		order.Fill(map[string]interface{}{"company_id": company, "total": int64(i)})
+		if err := order.Save(); err != nil {
+			t.Fatalf("Failed to save order for %s: %v", company, err)
+		}
+	}
+
+	for _, company := range companies {
+		shard, _ := resolver.Resolve(company)
+		count, err := NewQueryBuilder(DB(shard)).Table("orders").Where("company_id", company).Count()
+		if err != nil {
+			t.Fatalf("Failed to count orders on %s: %v", shard, err)
+		}
+		if count != 1 {
+			t.Errorf("Expected order for %s on %s, got %d", company, shard, count)
+		}
+
+		orders, err := NewModelQueryBuilder(newShardedOrder(resolver)).OnShard(company).Where("company_id", company).Get()
+		if err != nil {
+			t.Fatalf("Failed to query shard for %s: %v", company, err)
+		}
+		if len(orders) != 1 {
+			t.Errorf("Expected 1 order for %s via OnShard, got %d", company, len(orders))
+		}
+	}
+
+	// OnShard on an immutable base routes a copy and leaves the shared base alone
+	base := NewModelQueryBuilder(newShardedOrder(resolver)).Immutable()
+	typedBase := NewModelStatic(func() *shardedOrder { return newShardedOrder(resolver) }).Query().Immutable()
+	for _, company := range companies {
+		shard, _ := resolver.Resolve(company)
+		if routed := base.OnShard(company); routed.connection != DB(shard) || base.connection != nil {
+			t.Errorf("Expected only the copy to be routed to %s", shard)
+		}
+		orders, err := typedBase.OnShard(company).Where("company_id", company).Get()
+		if err != nil || len(orders) != 1 {
+			t.Errorf("Expected 1 order for %s via the typed OnShard, got %d, %v", company, len(orders), err)
+		}
+	}
+	if typedBase.connection != nil {
+		t.Error("Expected the typed base to stay unrouted")
+	}
+
+	all, err := NewModelQueryBuilder(newShardedOrder(resolver)).GetAcrossShards()
+	if err != nil {
+		t.Fatalf("Failed to fan out query: %v", err)
+	}
+	if len(all) != len(companies) {
+		t.Errorf("Expected %d orders across shards, got %d", len(companies), len(all))
+	}
+}
+
+func TestGetAcrossShardsMergesOrderedPages(t *testing.T) {
+	resolver := setupShardTestDB(t)
+	defer func() { _ = GetManager().CloseAll() }()
+
+	for i, company := range []string{"acme", "globex", "initech", "umbrella", "hooli"} {
+		order := newShardedOrder(resolver)
+		order.Fill(map[string]interface{}{"company_id": company, "total": int64(i)})
+		if err := order.Save(); err != nil {
+			t.Fatalf("Failed to save order for %s: %v", company, err)
+		}
+	}
+
+	page, err := NewModelQueryBuilder(newShardedOrder(resolver)).OrderBy("total", "desc").Offset(1).Limit(2).GetAcrossShards()
+	if err != nil {
+		t.Fatalf("Failed to fan out query: %v", err)
+	}
+	var totals []interface{}
+	for _, order := range page {
+		totals = append(totals, order.GetAttribute("total"))
+	}
+	if len(totals) != 2 || totals[0] != int64(3) || totals[1] != int64(2) {
+		t.Errorf("Expected totals [3 2] from the merged page, got %v", totals)
+	}
+
+	orders := NewModelStatic(func() *shardedOrder { return newShardedOrder(resolver) })
+	typed, err := orders.Query().OrderBy("total", "asc").Limit(3).GetAcrossShards()
+	if err != nil {
+		t.Fatalf("Failed to fan out typed query: %v", err)
+	}
+	if len(typed) != 3 || typed[0].Total != 0 || typed[2].Total != 2 {
+		t.Errorf("Expected the three lowest totals in order, got %+v", typed)
+	}
+
+	grouped := NewModelQueryBuilder(newShardedOrder(resolver))
+	grouped.GroupBy("company_id")
+	if _, err := grouped.GetAcrossShards(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected grouped fan out to fail with ErrInvalidQuery, got %v", err)
+	}
+	if _, err := NewModelQueryBuilder(newShardedOrder(resolver)).OrderByRaw("total * 2").GetAcrossShards(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected raw ordered fan out to fail with ErrInvalidQuery, got %v", err)
+	}
+}
+
+func TestShardedModelWithoutResolver(t *testing.T) {
+	order := NewBaseModel()
+	order.Table("orders").ShardBy("company_id")
+	order.Fill(map[string]interface{}{"company_id": "acme"})
+
+	if err := order.Save(); err == nil {
+		t.Error("Expected error when saving sharded model without a resolver")
+	}
+}
+
+func TestShardedQueryErrors(t *testing.T) {
+	resolver := setupShardTestDB(t)
+	defer func() { _ = GetManager().CloseAll() }()
+
+	// Unsharded models report the error instead of panicking
+	plain := NewBaseModel()
+	plain.Table("orders")
+	if _, err := NewModelQueryBuilder(plain).OnShard("acme").Get(); err == nil {
+		t.Error("Expected error when routing an unsharded model to a shard")
+	}
+	if _, err := NewModelQueryBuilder(plain).GetAcrossShards(); err == nil {
+		t.Error("Expected error when fanning out an unsharded model")
+	}
+
+	// Unknown shard connections are reported when the query runs
+	missing := newShardedOrder(NewHashShardResolver("shard_missing"))
+	if _, err := NewModelQueryBuilder(missing).OnShard("acme").Get(); err == nil {
+		t.Error("Expected error for missing shard connection")
+	}
+
+	if _, err := NewModelQueryBuilder(newShardedOrder(resolver)).OnShard("acme").Get(); err != nil {
+		t.Errorf("Expected valid shard query to succeed, got %v", err)
+	}
+
+	// Reads without a shard key fail instead of running on the default connection
+	if _, err := DB().Exec("CREATE TABLE orders (id TEXT PRIMARY KEY, company_id TEXT, total INTEGER)"); err != nil {
+		t.Fatalf("Failed to create orders table on the default connection: %v", err)
+	}
+	if _, err := NewModelQueryBuilder(newShardedOrder(resolver)).Where("company_id", "acme").Get(); err == nil {
+		t.Error("Expected error when reading a sharded model without OnShard")
+	}
+	if _, err := NewModelQueryBuilder(newShardedOrder(resolver)).Count(); err == nil {
+		t.Error("Expected error when counting a sharded model without OnShard")
+	}
+
+	// A model holding its shard key reads from its own shard
+	order := newShardedOrder(resolver)
+	order.Fill(map[string]interface{}{"company_id": "acme", "total": 100})
+	if err := order.Save(); err != nil {
+		t.Fatalf("Failed to save order: %v", err)
+	}
+	if count, err := NewModelQueryBuilder(order).Count(); err != nil || count != 1 {
+		t.Errorf("Expected the model's own shard to be read, got %d (%v)", count, err)
+	}
+}
+
+func TestShardedSaveToUnknownShard(t *testing.T) {
+	setupShardTestDB(t)
+	defer func() { _ = GetManager().CloseAll() }()
+	if _, err := DB().Exec("CREATE TABLE orders (id TEXT PRIMARY KEY, company_id TEXT, total INTEGER)"); err != nil {
+		t.Fatalf("Failed to create orders table on the default connection: %v", err)
+	}
+
+	order := newShardedOrder(NewHashShardResolver("shard_missing"))
+	order.Fill(map[string]interface{}{"company_id": "acme", "total": 100})
+	if err := order.Save(); err == nil {
+		t.Fatal("Expected saving to an unregistered shard to fail")
+	}
+
+	count, err := DB().Table("orders").Count()
+	if err != nil || count != 0 {
+		t.Errorf("Expected nothing written to the default connection, got %d rows (%v)", count, err)
+	}
+}
+
+func TestShardedModelBoundToManager(t *testing.T) {
+	cm := NewConnectionManager()
+	if err := cm.AddConnection("tenant_shard", ConnectionConfig{Driver: "sqlite3", Database: ":memory:"}); err != nil {
+		t.Fatalf("Failed to add shard: %v", err)
+	}
+	defer func() { _ = cm.CloseAll() }()
+	shard := cm.GetConnection("tenant_shard")
+	shard.DB.SetMaxOpenConns(1)
+	if _, err := shard.Exec("CREATE TABLE orders (id TEXT PRIMARY KEY, company_id TEXT, total INTEGER)"); err != nil {
+		t.Fatalf("Failed to create orders table: %v", err)
+	}
+
+	order := newShardedOrder(NewHashShardResolver("tenant_shard"))
+	order.Manager(cm)
+	order.Fill(map[string]interface{}{"company_id": "acme", "total": 100})
+	if err := order.Save(); err != nil {
+		t.Fatalf("Expected the shard to resolve through the bound manager, got %v", err)
+	}
+
+	results, err := NewModelQueryBuilder(order).OnShard("acme").Get()
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected to read the order from the bound manager's shard, got %d (%v)", len(results), err)
+	}
+	if count, err := NewModelQueryBuilder(order).Connection("tenant_shard").Count(); err != nil || count != 1 {
+		t.Errorf("Expected Connection to resolve through the bound manager, got %d (%v)", count, err)
+	}
+	if count, err := cm.Table("orders").Connection("tenant_shard").Count(); err != nil || count != 1 {
+		t.Errorf("Expected Connection on a manager's builder to resolve through it, got %d (%v)", count, err)
+	}
+}
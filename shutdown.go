@@ -0,0 +1,102 @@
+package eloquent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrShutdown is returned for queries and transactions started on a connection after
+// ConnectionManager.Shutdown has begun
+var ErrShutdown = errors.New("connection is shutting down")
+
+// inFlight counts the queries and transactions running on a connection so Shutdown can
+// wait for them
+type inFlight struct {
+	mu      sync.Mutex
+	active  int
+	closing bool
+	drained chan struct{}
+}
+
+// begin records a query starting, refusing it once the connection is shutting down
+func (f *inFlight) begin() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closing {
+		return ErrShutdown
+	}
+	f.active++
+	return nil
+}
+
+// end records a query finishing
+func (f *inFlight) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.active--
+	if f.closing && f.active == 0 {
+		close(f.drained)
+	}
+}
+
+// shuttingDown reports whether drain has been called
+func (f *inFlight) shuttingDown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closing
+}
+
+// drain refuses new queries and returns a channel closed once those running have finished
+func (f *inFlight) drain() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closing {
+		f.closing = true
+		f.drained = make(chan struct{})
+		if f.active == 0 {
+			close(f.drained)
+		}
+	}
+	return f.drained
+}
+
+// Shutdown stops the managed connections accepting new queries and transactions, which
+// fail with ErrShutdown, waits for those in flight to finish and then closes the pools.
+// When ctx is done first the pools are closed anyway, letting running queries finish on
+// their connections, and the error names the connections that were still busy.
+// Transactions started with Begin rather than Transaction are not waited for.
+func (cm *ConnectionManager) Shutdown(ctx context.Context) error {
+	connections := cm.connectionsSnapshot()
+
+	drained := make(map[string]<-chan struct{}, len(connections))
+	for name, conn := range connections {
+		drained[name] = conn.inFlight.drain()
+	}
+
+	var busy []string
+	for name, done := range drained {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			select {
+			case <-done:
+			default:
+				busy = append(busy, name)
+			}
+		}
+	}
+
+	var errs []error
+	if len(busy) > 0 {
+		sort.Strings(busy)
+		errs = append(errs, fmt.Errorf("queries still running on %s: %w", strings.Join(busy, ", "), ctx.Err()))
+	}
+	if err := cm.CloseAll(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+package eloquent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func newShutdownManager(t *testing.T) (*ConnectionManager, *Connection) {
+	cm := NewConnectionManager()
+	if err := cm.AddConnection("main", ConnectionConfig{Driver: "sqlite3", Database: ":memory:"}); err != nil {
+		t.Fatalf("Failed to add connection: %v", err)
+	}
+	return cm, cm.GetConnection("main")
+}
+
+// blockTransaction runs a transaction on conn that waits for release to be closed
+func blockTransaction(conn *Connection) (release chan struct{}, result chan error) {
+	started, release, result := make(chan struct{}), make(chan struct{}), make(chan error, 1)
+	go func() {
+		result <- conn.Transaction(func(tx *sqlx.Tx) error {
+			close(started)
+			<-release
+			_, err := tx.Exec("SELECT 1")
+			return err
+		})
+	}()
+	<-started
+	return release, result
+}
+
+func TestShutdownWaitsForInFlightQueries(t *testing.T) {
+	cm, conn := newShutdownManager(t)
+	release, result := blockTransaction(conn)
+
+	done := make(chan error, 1)
+	go func() { done <- cm.Shutdown(context.Background()) }()
+	for !conn.inFlight.shuttingDown() {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := conn.Select("SELECT 1"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected new queries to be refused, got %v", err)
+	}
+	if err := conn.Transaction(func(*sqlx.Tx) error { return nil }); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Expected new transactions to be refused, got %v", err)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected Shutdown to wait for the running transaction, returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("Expected the running transaction to commit, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if err := conn.DB.Ping(); err == nil {
+		t.Error("Expected the pool to be closed")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	cm, conn := newShutdownManager(t)
+	release, result := blockTransaction(conn)
+	defer func() {
+		close(release)
+		<-result
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := cm.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "main") {
+		t.Errorf("Expected a deadline error naming the busy connection, got %v", err)
+	}
+}
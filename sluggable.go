@@ -0,0 +1,121 @@
+package eloquent
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Sluggable generates a URL slug for each new record in column from the from column, such
+// as "hello-world" from "Hello, World!". Slugs are unique within the table: a taken slug
+// gets the first free numeric suffix, as in "hello-world-2". Slugs set explicitly are kept.
+// Concurrent inserts can still race, so back the column with a unique index.
+func (m *BaseModel) Sluggable(column, from string) *BaseModel {
+	m.slugColumn = column
+	m.slugFrom = from
+	return m
+}
+
+// RegenerateSlugs makes a Sluggable model regenerate its slug whenever an update changes
+// the source column, unless the update sets the slug itself
+func (m *BaseModel) RegenerateSlugs() *BaseModel {
+	m.regenerateSlugs = true
+	return m
+}
+
+// GetSlugColumn returns the column holding the model's slug, or "" if it is not Sluggable
+func (m *BaseModel) GetSlugColumn() string {
+	return m.slugColumn
+}
+
+// Slugify converts text to a lowercase URL slug, joining runs of letters and digits with
+// hyphens
+func Slugify(text string) string {
+	var slug strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pending = slug.Len() > 0
+			continue
+		}
+		if pending {
+			slug.WriteByte('-')
+			pending = false
+		}
+		slug.WriteRune(r)
+	}
+	return slug.String()
+}
+
+// fillSlug sets the slug of a model about to be inserted or updated. reserved holds the
+// slugs given to other models of the same batch and receives the new one; it may be nil.
+func (m *BaseModel) fillSlug(db *Connection, inserting bool, reserved map[string]bool) error {
+	if m.slugColumn == "" {
+		return nil
+	}
+	if inserting {
+		if slug := m.GetAttribute(m.slugColumn); slug != nil && slug != "" {
+			return nil
+		}
+	} else {
+		if !m.regenerateSlugs {
+			return nil
+		}
+		dirty := m.GetDirty()
+		if _, changed := dirty[m.slugFrom]; !changed {
+			return nil
+		}
+		if _, set := dirty[m.slugColumn]; set {
+			return nil
+		}
+	}
+
+	source := m.GetAttribute(m.slugFrom)
+	if source == nil {
+		return nil
+	}
+	base := Slugify(fmt.Sprint(source))
+	if base == "" {
+		return nil
+	}
+
+	slug, err := m.uniqueSlug(db, base, inserting, reserved)
+	if err != nil {
+		return fmt.Errorf("failed to generate slug: %w", err)
+	}
+	m.SetAttribute(m.slugColumn, slug)
+	return nil
+}
+
+// uniqueSlug returns base, or base with the first numeric suffix that no other record and
+// no reserved slug uses
+func (m *BaseModel) uniqueSlug(db *Connection, base string, inserting bool, reserved map[string]bool) (string, error) {
+	query := NewQueryBuilder(db).Table(m.qualifiedTable()).Select(m.slugColumn).Where(m.slugColumn, "like", base+"%")
+	if !inserting {
+		query = query.Where(m.primaryKey, "!=", m.GetAttribute(m.primaryKey))
+	}
+	rows, err := query.Get()
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		switch value := row[m.slugColumn].(type) {
+		case string:
+			taken[value] = true
+		case []byte:
+			taken[string(value)] = true
+		}
+	}
+
+	prefix := m.qualifiedTable() + "\x00"
+	slug := base
+	for n := 2; taken[slug] || reserved[prefix+slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	if reserved != nil {
+		reserved[prefix+slug] = true
+	}
+	return slug, nil
+}
@@ -0,0 +1,86 @@
+package eloquent
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Hello, World!":        "hello-world",
+		"  Go -- Eloquent  ":   "go-eloquent",
+		"Crème brûlée 2024":    "crème-brûlée-2024",
+		"already-a-slug":       "already-a-slug",
+		"!!!":                  "",
+		"Version 1.2 Released": "version-1-2-released",
+	}
+	for text, want := range tests {
+		if got := Slugify(text); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestSluggable(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, slug TEXT UNIQUE, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	newCustomer := func(name string) *CustomerModel {
+		customer := newEventCustomer(conn, map[string]interface{}{"name": name})
+		customer.Sluggable("slug", "name")
+		return customer
+	}
+
+	first := newCustomer("Ada Lovelace")
+	if err := first.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+	if slug := first.GetAttribute("slug"); slug != "ada-lovelace" {
+		t.Errorf("Expected ada-lovelace, got %v", slug)
+	}
+
+	// Collisions get the first free suffix, in single and batched inserts alike
+	second := newCustomer("Ada  Lovelace!")
+	if err := second.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+	third, fourth := newCustomer("ada lovelace"), newCustomer("Ada Lovelace")
+	if err := SaveAll([]Model{third, fourth}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+	for customer, want := range map[*CustomerModel]string{second: "ada-lovelace-2", third: "ada-lovelace-3", fourth: "ada-lovelace-4"} {
+		if slug := customer.GetAttribute("slug"); slug != want {
+			t.Errorf("Expected %s, got %v", want, slug)
+		}
+	}
+
+	// Explicit slugs are kept
+	explicit := newCustomer("Grace Hopper")
+	explicit.SetAttribute("slug", "amazing-grace")
+	if err := explicit.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+	if slug := explicit.GetAttribute("slug"); slug != "amazing-grace" {
+		t.Errorf("Expected the explicit slug to be kept, got %v", slug)
+	}
+
+	// Slugs only follow the source when regeneration is enabled
+	if err := first.Update(map[string]interface{}{"name": "Countess Lovelace"}); err != nil {
+		t.Fatalf("Failed to update customer: %v", err)
+	}
+	if slug := first.GetAttribute("slug"); slug != "ada-lovelace" {
+		t.Errorf("Expected the slug to be kept, got %v", slug)
+	}
+	first.RegenerateSlugs()
+	if err := first.Update(map[string]interface{}{"name": "Ada Lovelace"}); err != nil {
+		t.Fatalf("Failed to update customer: %v", err)
+	}
+	if slug := first.GetAttribute("slug"); slug != "ada-lovelace" {
+		t.Errorf("Expected the record's own slug to be reused, got %v", slug)
+	}
+	if err := first.Update(map[string]interface{}{"name": "Grace Hopper"}); err != nil {
+		t.Fatalf("Failed to update customer: %v", err)
+	}
+	if slug := first.GetAttribute("slug"); slug != "grace-hopper" {
+		t.Errorf("Expected the slug to follow the name, got %v", slug)
+	}
+}
@@ -0,0 +1,68 @@
+package eloquent
+
+import "context"
+
+// WithDeletedBy records who soft-deleted each record, as returned by the actor resolver,
+// in the given column, deleted_by by default. It also enables soft deletes.
+func (m *BaseModel) WithDeletedBy(column ...string) *BaseModel {
+	m.deletedBy = "deleted_by"
+	if len(column) > 0 {
+		m.deletedBy = column[0]
+	}
+	if !m.usesSoftDeletes() {
+		m.WithSoftDeletes()
+	}
+	return m
+}
+
+// WithDeleteReason records the reason given to DeleteWithReason in the given column,
+// delete_reason by default. It also enables soft deletes.
+func (m *BaseModel) WithDeleteReason(column ...string) *BaseModel {
+	m.deleteReason = "delete_reason"
+	if len(column) > 0 {
+		m.deleteReason = column[0]
+	}
+	if !m.usesSoftDeletes() {
+		m.WithSoftDeletes()
+	}
+	return m
+}
+
+// GetDeletedByColumn returns the column recording who deleted a record, or ""
+func (m *BaseModel) GetDeletedByColumn() string {
+	return m.deletedBy
+}
+
+// GetDeleteReasonColumn returns the column recording why a record was deleted, or ""
+func (m *BaseModel) GetDeleteReasonColumn() string {
+	return m.deleteReason
+}
+
+// deleteMetadata returns the metadata columns to set when soft-deleting; an empty reason
+// is stored as NULL
+func (m *BaseModel) deleteMetadata(ctx context.Context, reason string) map[string]interface{} {
+	values := make(map[string]interface{}, 3)
+	if m.deletedBy != "" {
+		values[m.deletedBy] = resolveActor(ctx)
+	}
+	if m.deleteReason != "" {
+		if reason != "" {
+			values[m.deleteReason] = reason
+		} else {
+			values[m.deleteReason] = nil
+		}
+	}
+	return values
+}
+
+// restoreMetadata returns the metadata columns cleared when restoring
+func (m *BaseModel) restoreMetadata() map[string]interface{} {
+	values := make(map[string]interface{}, 3)
+	if m.deletedBy != "" {
+		values[m.deletedBy] = nil
+	}
+	if m.deleteReason != "" {
+		values[m.deleteReason] = nil
+	}
+	return values
+}
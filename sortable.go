@@ -0,0 +1,239 @@
+package eloquent
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Sortable keeps the model's records in a user-defined order in the given integer column.
+// New records without a position are appended to the end of their list, and MoveBefore,
+// MoveAfter, MoveToTop and MoveToBottom reorder a record, shifting the records in between
+// in one transaction. Group columns, such as a list or parent id, split the table into
+// separately ordered lists.
+func (m *BaseModel) Sortable(column string, group ...string) *BaseModel {
+	m.sortColumn = column
+	m.sortGroup = group
+	return m
+}
+
+// GetSortColumn returns the column holding the model's position, or "" if it is not Sortable
+func (m *BaseModel) GetSortColumn() string {
+	return m.sortColumn
+}
+
+// MoveBefore moves the record right before other, which must be in the same list
+func (m *BaseModel) MoveBefore(other Model) error {
+	return m.moveNextTo(other, false)
+}
+
+// MoveAfter moves the record right after other, which must be in the same list
+func (m *BaseModel) MoveAfter(other Model) error {
+	return m.moveNextTo(other, true)
+}
+
+// MoveToTop moves the record to the start of its list
+func (m *BaseModel) MoveToTop() error {
+	return m.move(func(tx *sqlx.Tx, current int64) (int64, error) {
+		return m.boundaryPosition(tx, "MIN", current)
+	})
+}
+
+// MoveToBottom moves the record to the end of its list
+func (m *BaseModel) MoveToBottom() error {
+	return m.move(func(tx *sqlx.Tx, current int64) (int64, error) {
+		return m.boundaryPosition(tx, "MAX", current)
+	})
+}
+
+// moveNextTo moves the record before or after other
+func (m *BaseModel) moveNextTo(other Model, after bool) error {
+	o := baseModelOf(other)
+	if o == nil {
+		return fmt.Errorf("%w: cannot move next to %T", ErrInvalidQuery, other)
+	}
+	if o.GetTable() != m.GetTable() || m.sortKey() != o.sortKey() {
+		return fmt.Errorf("%w: cannot move a record next to one in another list", ErrInvalidQuery)
+	}
+
+	return m.move(func(tx *sqlx.Tx, current int64) (int64, error) {
+		if o == m {
+			return current, nil
+		}
+		target, err := o.storedPosition(tx)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case after && target < current:
+			return target + 1, nil
+		case !after && target > current:
+			return target - 1, nil
+		default:
+			return target, nil
+		}
+	})
+}
+
+// move moves the record to the position returned by target, shifting the records between
+// its current and new positions by one
+func (m *BaseModel) move(target func(tx *sqlx.Tx, current int64) (int64, error)) error {
+	if m.sortColumn == "" {
+		return fmt.Errorf("%w: %s is not sortable", ErrInvalidQuery, m.GetTable())
+	}
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
+	if !m.exists {
+		return fmt.Errorf("%w: cannot move a record that was never saved", ErrInvalidQuery)
+	}
+
+	db, err := m.resolveConnection()
+	if err != nil {
+		return err
+	}
+	table := db.prefixTable(m.qualifiedTable())
+	scope, scopeArgs := m.sortScope()
+
+	var position int64
+	err = db.Transaction(func(tx *sqlx.Tx) error {
+		exec := func(query string, args ...interface{}) error {
+			start := time.Now()
+			_, err := tx.Exec(tx.Rebind(query), args...)
+			db.observe(query, args, start, err)
+			return err
+		}
+
+		current, err := m.storedPosition(tx)
+		if err != nil {
+			return err
+		}
+		position, err = target(tx, current)
+		if err != nil || position == current {
+			return err
+		}
+
+		column := m.sortColumn
+		if position < current {
+			err = exec(fmt.Sprintf("UPDATE %s SET %s = %s + 1 WHERE %s AND %s >= ? AND %s < ?",
+				table, column, column, scope, column, column), append(scopeArgs, position, current)...)
+		} else {
+			err = exec(fmt.Sprintf("UPDATE %s SET %s = %s - 1 WHERE %s AND %s > ? AND %s <= ?",
+				table, column, column, scope, column, column), append(scopeArgs, current, position)...)
+		}
+		if err != nil {
+			return err
+		}
+		return exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", table, column, m.primaryKey),
+			position, m.GetAttribute(m.primaryKey))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move record: %w", err)
+	}
+
+	m.SetAttribute(m.sortColumn, position)
+	m.original[m.sortColumn] = position
+	m.syncAttributesToFields()
+	return nil
+}
+
+// storedPosition reads the record's current position in tx
+func (m *BaseModel) storedPosition(tx *sqlx.Tx) (int64, error) {
+	db, err := m.resolveConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	var position sql.NullInt64
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", m.sortColumn, db.prefixTable(m.qualifiedTable()), m.primaryKey)
+	err = tx.QueryRowx(tx.Rebind(query), m.GetAttribute(m.primaryKey)).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !position.Valid {
+		return 0, fmt.Errorf("%w: record has no %s", ErrInvalidQuery, m.sortColumn)
+	}
+	return position.Int64, nil
+}
+
+// boundaryPosition returns the smallest or largest position of the record's list
+func (m *BaseModel) boundaryPosition(tx *sqlx.Tx, aggregate string, current int64) (int64, error) {
+	db, err := m.resolveConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	scope, args := m.sortScope()
+	var position sql.NullInt64
+	query := fmt.Sprintf("SELECT %s(%s) FROM %s WHERE %s", aggregate, m.sortColumn, db.prefixTable(m.qualifiedTable()), scope)
+	if err := tx.QueryRowx(tx.Rebind(query), args...).Scan(&position); err != nil {
+		return 0, err
+	}
+	if !position.Valid {
+		return current, nil
+	}
+	return position.Int64, nil
+}
+
+// fillPosition appends a new record to the end of its list unless it has a position.
+// reserved holds the last positions given in the same batch and receives the new one;
+// it may be nil.
+func (m *BaseModel) fillPosition(db *Connection, reserved map[string]int64) error {
+	if m.sortColumn == "" || m.GetAttribute(m.sortColumn) != nil {
+		return nil
+	}
+
+	scope, args := m.sortScope()
+	var last sql.NullInt64
+	query := fmt.Sprintf("SELECT MAX(%s) FROM %s WHERE %s", m.sortColumn, db.prefixTable(m.qualifiedTable()), scope)
+	if err := db.DB.QueryRowx(db.DB.Rebind(query), args...).Scan(&last); err != nil {
+		return fmt.Errorf("failed to assign position: %w", err)
+	}
+
+	position := last.Int64 + 1
+	key := m.GetTable() + "\x00" + m.sortKey()
+	if reserved != nil {
+		if taken, ok := reserved[key]; ok && taken >= position {
+			position = taken + 1
+		}
+		reserved[key] = position
+	}
+	m.SetAttribute(m.sortColumn, position)
+	return nil
+}
+
+// sortScope returns the condition selecting the record's list, with ? placeholders
+func (m *BaseModel) sortScope() (string, []interface{}) {
+	if len(m.sortGroup) == 0 {
+		return "1 = 1", nil
+	}
+
+	conditions := make([]string, len(m.sortGroup))
+	var args []interface{}
+	for i, column := range m.sortGroup {
+		value := m.GetAttribute(column)
+		if value == nil {
+			conditions[i] = column + " IS NULL"
+			continue
+		}
+		conditions[i] = column + " = ?"
+		args = append(args, value)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// sortKey identifies the record's list among the lists of its table
+func (m *BaseModel) sortKey() string {
+	values := make([]interface{}, len(m.sortGroup))
+	for i, column := range m.sortGroup {
+		values[i] = m.GetAttribute(column)
+	}
+	return fmt.Sprintf("%#v", values)
+}
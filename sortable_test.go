@@ -0,0 +1,81 @@
+package eloquent
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSortable(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, list TEXT, position INTEGER, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	newCustomer := func(name, list string) *CustomerModel {
+		customer := newEventCustomer(conn, map[string]interface{}{"name": name})
+		customer.Sortable("position", "list")
+		customer.SetAttribute("list", list)
+		return customer
+	}
+	order := func(list string) []interface{} {
+		rows, err := NewQueryBuilder(conn).Table("customers").Where("list", list).OrderBy("position", "asc").Get()
+		if err != nil {
+			t.Fatalf("Failed to read order: %v", err)
+		}
+		names := make([]interface{}, len(rows))
+		for i, row := range rows {
+			names[i] = row["name"]
+		}
+		return names
+	}
+	expectOrder := func(list string, names ...interface{}) {
+		t.Helper()
+		if got := order(list); !reflect.DeepEqual(got, names) {
+			t.Errorf("Expected order %v, got %v", names, got)
+		}
+	}
+
+	a, b := newCustomer("a", "one"), newCustomer("b", "one")
+	for _, customer := range []*CustomerModel{a, b} {
+		if err := customer.Save(); err != nil {
+			t.Fatalf("Failed to save customer: %v", err)
+		}
+	}
+	c, d, other := newCustomer("c", "one"), newCustomer("d", "one"), newCustomer("x", "two")
+	if err := SaveAll([]Model{c, d, other}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+	if d.GetAttribute("position") != int64(4) || other.GetAttribute("position") != int64(1) {
+		t.Errorf("Expected positions to be appended per list, got %v and %v", d.GetAttribute("position"), other.GetAttribute("position"))
+	}
+	expectOrder("one", "a", "b", "c", "d")
+
+	if err := d.MoveBefore(b); err != nil {
+		t.Fatalf("MoveBefore failed: %v", err)
+	}
+	expectOrder("one", "a", "d", "b", "c")
+	if err := a.MoveAfter(b); err != nil {
+		t.Fatalf("MoveAfter failed: %v", err)
+	}
+	expectOrder("one", "d", "b", "a", "c")
+	if err := c.MoveToTop(); err != nil {
+		t.Fatalf("MoveToTop failed: %v", err)
+	}
+	expectOrder("one", "c", "d", "b", "a")
+	if err := c.MoveToBottom(); err != nil {
+		t.Fatalf("MoveToBottom failed: %v", err)
+	}
+	expectOrder("one", "d", "b", "a", "c")
+	if c.GetAttribute("position") != int64(4) || c.IsDirty() {
+		t.Errorf("Expected the moved model to hold its stored position, got %v", c.GetAttribute("position"))
+	}
+	expectOrder("two", "x")
+
+	if err := other.MoveBefore(a); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery moving between lists, got %v", err)
+	}
+	if err := newCustomer("new", "one").MoveToTop(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery moving an unsaved model, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package eloquent
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTransition is returned when a state machine does not allow a transition, or
+// when the record's state changed since the model was loaded
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+// StateMachine maps each state of a column to the states it may move to. The empty state
+// stands for NULL, so StateMachine{"": {"pending"}} lets records without a state start.
+type StateMachine map[string][]string
+
+// Allows reports whether the machine allows moving from one state to another
+func (sm StateMachine) Allows(from, to string) bool {
+	for _, state := range sm[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WithStateMachine restricts the transitions of a status column made with TransitionTo:
+//
+//	customer.WithStateMachine("status", eloquent.StateMachine{
+//		"pending":   {"active"},
+//		"active":    {"suspended"},
+//		"suspended": {"active"},
+//	})
+func (m *BaseModel) WithStateMachine(column string, machine StateMachine) *BaseModel {
+	m.detachDefinition()
+	if m.stateMachines == nil {
+		m.stateMachines = make(map[string]StateMachine)
+	}
+	if _, ok := m.stateMachines[column]; !ok {
+		m.stateColumns = append(m.stateColumns, column)
+	}
+	m.stateMachines[column] = machine
+	return m
+}
+
+// CanTransitionTo reports whether the record may move to state. The column defaults to
+// the first one given to WithStateMachine.
+func (m *BaseModel) CanTransitionTo(state string, column ...string) bool {
+	name, machine, err := m.stateMachineFor(column)
+	return err == nil && machine.Allows(m.storedState(name), state)
+}
+
+// TransitionTo moves the record to state after checking the state machine, running the
+// model's Transitioning and Transitioned hooks around the write. Only the state column and
+// updated_at are written, and only if the record is still in the state it was loaded in;
+// otherwise it fails with ErrInvalidTransition. The column defaults to the first one given
+// to WithStateMachine.
+func (m *BaseModel) TransitionTo(state string, column ...string) error {
+	name, machine, err := m.stateMachineFor(column)
+	if err != nil {
+		return err
+	}
+	if m.IsReadOnly() {
+		return ErrReadOnly
+	}
+	if !m.exists {
+		return fmt.Errorf("%w: cannot transition a record that was never saved", ErrInvalidQuery)
+	}
+
+	from := m.storedState(name)
+	if !machine.Allows(from, state) {
+		return fmt.Errorf("%w: %s cannot move from %q to %q", ErrInvalidTransition, name, from, state)
+	}
+	if hook, ok := m.outerModel().(TransitioningHook); ok {
+		if err := hook.Transitioning(name, from, state); err != nil {
+			return err
+		}
+	}
+
+	db, err := m.resolveConnection()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	values := map[string]interface{}{name: state}
+	if m.timestamps {
+		values[m.updatedAt] = now
+	}
+	m.blameValues(m.Context(), values, false)
+
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	setParts := make([]string, len(columns))
+	args := make([]interface{}, 0, len(columns)+2)
+	for i, column := range columns {
+		setParts[i] = column + " = ?"
+		args = append(args, values[column])
+	}
+
+	m.syncPrimaryKeyToAttributes()
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ? AND ", db.prefixTable(m.qualifiedTable()), strings.Join(setParts, ", "), m.primaryKey)
+	args = append(args, m.GetAttribute(m.primaryKey))
+	if previous := m.original[name]; previous == nil {
+		query += name + " IS NULL"
+	} else {
+		query += name + " = ?"
+		args = append(args, previous)
+	}
+
+	result, err := m.execWrite(db, db.DB.Rebind(query), args, now)
+	if err != nil {
+		return fmt.Errorf("failed to transition record: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return fmt.Errorf("%w: %s is no longer %q", ErrInvalidTransition, name, from)
+	}
+
+	m.changes = values
+	m.previous = make(map[string]interface{}, len(values))
+	for column, value := range values {
+		m.previous[column] = m.original[column]
+		m.SetAttribute(column, value)
+		m.original[column] = m.attributes[column]
+	}
+	m.syncAttributesToFields()
+
+	if hook, ok := m.outerModel().(TransitionedHook); ok {
+		hook.Transitioned(name, from, state)
+	}
+	m.publishEvent(EventUpdated)
+	return nil
+}
+
+// stateMachineFor returns the state machine of the given column, or of the first one
+func (m *BaseModel) stateMachineFor(column []string) (string, StateMachine, error) {
+	name := ""
+	if len(column) > 0 {
+		name = column[0]
+	} else if len(m.stateColumns) > 0 {
+		name = m.stateColumns[0]
+	}
+
+	machine, ok := m.stateMachines[name]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s has no state machine for %q", ErrInvalidQuery, m.GetTable(), name)
+	}
+	return name, machine, nil
+}
+
+// storedState returns the column's state as last loaded or saved, "" for NULL
+func (m *BaseModel) storedState(column string) string {
+	switch state := m.original[column].(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(state)
+	default:
+		return fmt.Sprint(state)
+	}
+}
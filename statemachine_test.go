@@ -0,0 +1,101 @@
+package eloquent
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// stateCustomer records its transition hooks
+type stateCustomer struct {
+	*BaseModel
+	transitions []string
+	veto        bool
+}
+
+func (c *stateCustomer) Transitioning(column, from, to string) error {
+	if c.veto {
+		return errors.New("vetoed")
+	}
+	return nil
+}
+
+func (c *stateCustomer) Transitioned(column, from, to string) {
+	c.transitions = append(c.transitions, fmt.Sprintf("%s:%s->%s", column, from, to))
+}
+
+func TestStateMachine(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, status TEXT, created_at DATETIME, updated_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	newCustomer := func() *stateCustomer {
+		customer := &stateCustomer{BaseModel: NewBaseModel()}
+		customer.Table("customers").Fillable("name", "status").Connection(conn.Name)
+		customer.SetParentModel(customer)
+		customer.WithStateMachine("status", StateMachine{
+			"pending":   {"active"},
+			"active":    {"suspended"},
+			"suspended": {"active"},
+		})
+		return customer
+	}
+	storedStatus := func(customer *stateCustomer) interface{} {
+		row, err := NewQueryBuilder(conn).Table("customers").Where("id", customer.GetAttribute("id")).First()
+		if err != nil {
+			t.Fatalf("Failed to read customer: %v", err)
+		}
+		return row["status"]
+	}
+
+	customer := newCustomer()
+	customer.Fill(map[string]interface{}{"name": "Ada", "status": "pending"})
+	if err := customer.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+
+	if customer.CanTransitionTo("suspended") {
+		t.Error("Expected pending customers not to be suspendable")
+	}
+	if err := customer.TransitionTo("suspended"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+
+	// Only the state is written; other pending changes stay dirty
+	customer.SetAttribute("name", "Ada Lovelace")
+	if err := customer.TransitionTo("active"); err != nil {
+		t.Fatalf("TransitionTo failed: %v", err)
+	}
+	if storedStatus(customer) != "active" || customer.GetAttribute("status") != "active" {
+		t.Errorf("Expected the customer to be active, got %v", storedStatus(customer))
+	}
+	if !customer.IsDirty("name") || customer.IsDirty("status") {
+		t.Errorf("Expected only the name to be dirty, got %v", customer.GetDirty())
+	}
+	if customer.GetChanges()["status"] != "active" || customer.GetPrevious()["status"] != "pending" {
+		t.Errorf("Expected the transition to be recorded as a change, got %v", customer.GetChanges())
+	}
+	if want := []string{"status:pending->active"}; !reflect.DeepEqual(customer.transitions, want) {
+		t.Errorf("Expected hooks %v, got %v", want, customer.transitions)
+	}
+
+	customer.veto = true
+	if err := customer.TransitionTo("suspended"); err == nil || storedStatus(customer) != "active" {
+		t.Errorf("Expected the Transitioning hook to abort, got %v", err)
+	}
+	customer.veto = false
+
+	// A state changed behind the model's back is not overwritten
+	if _, err := conn.Exec("UPDATE customers SET status = 'suspended'"); err != nil {
+		t.Fatalf("Failed to change status: %v", err)
+	}
+	if err := customer.TransitionTo("suspended"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition for a stale state, got %v", err)
+	}
+
+	if err := newCustomer().TransitionTo("active", "kind"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for a column without a state machine, got %v", err)
+	}
+}
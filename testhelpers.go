@@ -0,0 +1,76 @@
+package eloquent
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+)
+
+// TestingT is the subset of testing.TB used by the test helpers, so the package
+// does not have to import the testing package
+type TestingT interface {
+	Helper()
+	Name() string
+	Cleanup(func())
+	Fatalf(format string, args ...interface{})
+}
+
+var memoryDatabaseCounter atomic.Int64
+
+var unsafeDatabaseNameChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// MemorySQLiteDSN returns the DSN of a named shared-cache in-memory SQLite database.
+// Every pooled connection using the DSN sees the same database, unlike ":memory:".
+func MemorySQLiteDSN(name string) string {
+	return fmt.Sprintf("file:%s?mode=memory&cache=shared", name)
+}
+
+// UniqueMemorySQLiteDSN returns the DSN of a new uniquely named in-memory SQLite database
+func UniqueMemorySQLiteDSN(prefix string) string {
+	name := unsafeDatabaseNameChars.ReplaceAllString(prefix, "_")
+	return MemorySQLiteDSN(fmt.Sprintf("%s_%d", name, memoryDatabaseCounter.Add(1)))
+}
+
+// NewTestSQLite adds a connection to a private in-memory SQLite database named after
+// the test, so parallel tests don't share state. The connection is closed when the test ends.
+func NewTestSQLite(t TestingT, options ...SQLiteOptions) *Connection {
+	t.Helper()
+
+	dsn := UniqueMemorySQLiteDSN(t.Name())
+	config := ConnectionConfig{
+		Driver:   "sqlite3",
+		Database: dsn,
+	}
+	if len(options) > 0 {
+		config.Options = options[0].toDSNOptions()
+	}
+
+	if err := GetManager().AddConnection(dsn, config); err != nil {
+		t.Fatalf("failed to create test SQLite database: %v", err)
+	}
+
+	conn := GetManager().GetConnection(dsn)
+	t.Cleanup(func() {
+		_ = GetManager().RemoveConnection(dsn)
+	})
+
+	return conn
+}
+
+// WithTestConnection makes conn the default connection for the rest of the test and
+// restores the previous default connection when the test ends, so tests don't replace
+// the global manager's connection for the tests that follow them
+func WithTestConnection(t TestingT, conn *Connection) {
+	t.Helper()
+	runPendingBoot()
+
+	cm := GetManager()
+	cm.mu.RLock()
+	name := cm.default_
+	cm.mu.RUnlock()
+
+	previous := cm.setConnection(name, conn)
+	t.Cleanup(func() {
+		cm.setConnection(name, previous)
+	})
+}
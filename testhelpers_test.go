@@ -0,0 +1,42 @@
+package eloquent
+
+import (
+	"testing"
+)
+
+func TestUniqueMemorySQLiteDSN(t *testing.T) {
+	first := UniqueMemorySQLiteDSN("TestSomething/sub case")
+	second := UniqueMemorySQLiteDSN("TestSomething/sub case")
+
+	if first == second {
+		t.Errorf("Expected unique DSNs, got %s twice", first)
+	}
+	if MemorySQLiteDSN("db") != "file:db?mode=memory&cache=shared" {
+		t.Errorf("Unexpected memory DSN: %s", MemorySQLiteDSN("db"))
+	}
+}
+
+func TestNewTestSQLiteIsolation(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := NewTestSQLite(t)
+			if _, err := conn.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+				t.Fatalf("Failed to create table: %v", err)
+			}
+			if _, err := conn.Exec("INSERT INTO items (name) VALUES (?)", name); err != nil {
+				t.Fatalf("Failed to insert row: %v", err)
+			}
+
+			rows, err := conn.Select("SELECT name FROM items")
+			if err != nil {
+				t.Fatalf("Failed to select rows: %v", err)
+			}
+			if len(rows) != 1 || rows[0]["name"] != name {
+				t.Errorf("Expected only this test's row, got %v", rows)
+			}
+		})
+	}
+}
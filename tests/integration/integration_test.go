@@ -10,20 +10,12 @@ import (
 )
 
 func setupIntegrationDB(t *testing.T) {
-	// Set up in-memory SQLite database for integration testing
-	err := eloquent.SQLite(":memory:")
-	if err != nil {
-		t.Fatalf("Failed to set up integration test database: %v", err)
-	}
-
-	// Create test tables
-	conn := eloquent.DB()
-	if conn == nil {
-		t.Fatal("Failed to get database connection")
-	}
+	// Use a private in-memory SQLite database as the default connection
+	conn := eloquent.NewTestSQLite(t)
+	eloquent.WithTestConnection(t, conn)
 
 	// Create users table with proper SQLite syntax
-	_, err = conn.Exec(`
+	_, err := conn.Exec(`
 		CREATE TABLE users (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
@@ -75,13 +67,8 @@ func setupIntegrationDB(t *testing.T) {
 	}
 }
 
-func teardownIntegrationDB() {
-	_ = eloquent.GetManager().CloseAll()
-}
-
 func TestIntegrationFullCRUDWorkflow(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Test Create
 	user, err := models.User.Create(map[string]interface{}{
@@ -191,7 +178,6 @@ func TestIntegrationFullCRUDWorkflow(t *testing.T) {
 
 func TestIntegrationRelationshipWorkflow(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Create a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -282,7 +268,6 @@ func TestIntegrationRelationshipWorkflow(t *testing.T) {
 
 func TestIntegrationComplexQueries(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Create multiple users with different attributes
 	users := []map[string]interface{}{
@@ -392,7 +377,6 @@ func TestIntegrationComplexQueries(t *testing.T) {
 
 func TestIntegrationBatchOperations(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Create multiple users
 	userCount := 10
@@ -464,7 +448,6 @@ func TestIntegrationBatchOperations(t *testing.T) {
 
 func TestIntegrationTransactionSimulation(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Simulate a transaction-like operation
 	// Create user and profile together
@@ -532,7 +515,6 @@ func TestIntegrationTransactionSimulation(t *testing.T) {
 
 func TestIntegrationTimestamps(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Create a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -594,7 +576,6 @@ func TestIntegrationTimestamps(t *testing.T) {
 
 func TestIntegrationErrorHandling(t *testing.T) {
 	setupIntegrationDB(t)
-	defer teardownIntegrationDB()
 
 	// Test duplicate email constraint
 	user1, err := models.User.Create(map[string]interface{}{
@@ -1,28 +1,26 @@
 package tests
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/crashana/go-eloquent"
+	"github.com/crashana/go-eloquent/eloquenttest"
 	"github.com/crashana/go-eloquent/tests/models"
 )
 
 func setupTestDB(t *testing.T) {
-	// Set up in-memory SQLite database for testing
-	err := eloquent.SQLite(":memory:")
-	if err != nil {
-		t.Fatalf("Failed to set up test database: %v", err)
-	}
-
-	// Create test tables
-	conn := eloquent.DB()
-	if conn == nil {
-		t.Fatal("Failed to get database connection")
-	}
+	// Use a private in-memory SQLite database as the default connection
+	conn := eloquent.NewTestSQLite(t)
+	eloquent.WithTestConnection(t, conn)
 
 	// Create users table
-	_, err = conn.Exec(`
+	_, err := conn.Exec(`
 		CREATE TABLE users (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
@@ -74,13 +72,8 @@ func setupTestDB(t *testing.T) {
 	}
 }
 
-func teardownTestDB() {
-	_ = eloquent.GetManager().CloseAll()
-}
-
 func TestModelCreate(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Test creating a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -124,7 +117,6 @@ func TestModelCreate(t *testing.T) {
 
 func TestModelFind(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user first
 	user, err := models.User.Create(map[string]interface{}{
@@ -156,7 +148,6 @@ func TestModelFind(t *testing.T) {
 
 func TestModelFirst(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create multiple users
 	_, err := models.User.Create(map[string]interface{}{
@@ -190,7 +181,6 @@ func TestModelFirst(t *testing.T) {
 
 func TestModelAll(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create multiple users
 	_, err := models.User.Create(map[string]interface{}{
@@ -232,7 +222,6 @@ func TestModelAll(t *testing.T) {
 
 func TestModelWhere(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create users with different statuses
 	_, err := models.User.Create(map[string]interface{}{
@@ -282,7 +271,6 @@ func TestModelWhere(t *testing.T) {
 
 func TestModelUpdate(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -338,7 +326,6 @@ func TestModelUpdate(t *testing.T) {
 
 func TestModelSave(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -385,7 +372,6 @@ func TestModelSave(t *testing.T) {
 
 func TestModelDelete(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -424,7 +410,6 @@ func TestModelDelete(t *testing.T) {
 
 func TestModelFillable(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user with fillable attributes
 	user, err := models.User.Create(map[string]interface{}{
@@ -455,7 +440,6 @@ func TestModelFillable(t *testing.T) {
 
 func TestModelCasts(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user with boolean cast
 	user, err := models.User.Create(map[string]interface{}{
@@ -481,7 +465,6 @@ func TestModelCasts(t *testing.T) {
 
 func TestModelRelationships(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create a user
 	user, err := models.User.Create(map[string]interface{}{
@@ -536,7 +519,6 @@ func TestModelRelationships(t *testing.T) {
 
 func TestModelChainedQueries(t *testing.T) {
 	setupTestDB(t)
-	defer teardownTestDB()
 
 	// Create users with different attributes
 	_, err := models.User.Create(map[string]interface{}{
@@ -596,3 +578,1356 @@ func TestModelChainedQueries(t *testing.T) {
 		t.Errorf("Expected regular user name 'Regular User', got %s", regularUser.Name)
 	}
 }
+
+func TestModelReadOnly(t *testing.T) {
+	setupTestDB(t)
+
+	user, err := models.User.Create(map[string]interface{}{
+		"name":     "Read Only",
+		"email":    "readonly@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	user.ReadOnly()
+	if !user.IsReadOnly() {
+		t.Fatal("Expected user to be read-only")
+	}
+
+	if err := user.Update(map[string]interface{}{"name": "Changed"}); err != eloquent.ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly from Update, got %v", err)
+	}
+	if err := user.Save(); err != eloquent.ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly from Save, got %v", err)
+	}
+	if err := user.Delete(); err != eloquent.ErrReadOnly {
+		t.Errorf("Expected ErrReadOnly from Delete, got %v", err)
+	}
+
+	found, err := models.User.Find(user.ID)
+	if err != nil {
+		t.Fatalf("Expected user to still exist: %v", err)
+	}
+	if found.Name != "Read Only" {
+		t.Errorf("Expected name to be unchanged, got %s", found.Name)
+	}
+}
+
+func TestModelWithIsolatedManager(t *testing.T) {
+	setupTestDB(t)
+
+	cm := eloquent.NewConnectionManager()
+	err := cm.AddConnection("default", eloquent.ConnectionConfig{
+		Driver:   "sqlite3",
+		Database: eloquent.UniqueMemorySQLiteDSN(t.Name()),
+	})
+	if err != nil {
+		t.Fatalf("Failed to add isolated connection: %v", err)
+	}
+	defer func() { _ = cm.CloseAll() }()
+
+	_, err = cm.GetConnection().Exec(`
+		CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			email TEXT UNIQUE NOT NULL,
+			password TEXT NOT NULL,
+			is_admin BOOLEAN DEFAULT FALSE,
+			status TEXT DEFAULT 'active',
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create users table: %v", err)
+	}
+
+	isolatedUsers := models.User.WithManager(cm)
+	user, err := isolatedUsers.Create(map[string]interface{}{
+		"name":     "Isolated",
+		"email":    "isolated@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user on isolated manager: %v", err)
+	}
+
+	found, err := isolatedUsers.Find(user.ID)
+	if err != nil {
+		t.Fatalf("Failed to find user on isolated manager: %v", err)
+	}
+	if found.Name != "Isolated" {
+		t.Errorf("Expected name 'Isolated', got %s", found.Name)
+	}
+
+	count, err := cm.Table("users").Count()
+	if err != nil {
+		t.Fatalf("Failed to count isolated users: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 user on isolated manager, got %d", count)
+	}
+
+	globalUsers, err := models.User.All()
+	if err != nil {
+		t.Fatalf("Failed to get global users: %v", err)
+	}
+	if len(globalUsers) != 0 {
+		t.Errorf("Expected global connection to be untouched, got %d users", len(globalUsers))
+	}
+}
+
+func TestModelSaveAll(t *testing.T) {
+	setupTestDB(t)
+
+	var batch eloquent.Collection
+	for _, name := range []string{"Ann", "Ben", "Cid"} {
+		user := models.NewUser()
+		user.Fill(map[string]interface{}{
+			"name":     name,
+			"email":    name + "@example.com",
+			"password": "password123",
+		})
+		batch = append(batch, user)
+	}
+
+	queries := eloquent.DB().Metrics().Queries
+	if err := batch.Save(); err != nil {
+		t.Fatalf("Failed to save new users: %v", err)
+	}
+	if executed := eloquent.DB().Metrics().Queries - queries; executed != 1 {
+		t.Errorf("Expected 1 batched insert, got %d statements", executed)
+	}
+
+	users, err := models.User.All()
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+	if len(users) != 3 {
+		t.Fatalf("Expected 3 users, got %d", len(users))
+	}
+	if first := batch[0].(*models.UserModel); first.ID == "" || first.IsDirty() {
+		t.Errorf("Expected saved user to have an ID and be clean, got %q", first.ID)
+	}
+
+	// All users share the status change; only one also changes its name
+	toSave := make([]eloquent.Model, len(users))
+	for i, user := range users {
+		user.Status = "synced"
+		if user.Name == "Ben" {
+			user.Name = "Benjamin"
+		}
+		toSave[i] = user
+	}
+
+	queries = eloquent.DB().Metrics().Queries
+	if err := eloquent.SaveAll(toSave); err != nil {
+		t.Fatalf("Failed to save updated users: %v", err)
+	}
+	if executed := eloquent.DB().Metrics().Queries - queries; executed != 2 {
+		t.Errorf("Expected 2 grouped updates, got %d statements", executed)
+	}
+
+	synced, err := models.User.Where("status", "synced").Get()
+	if err != nil {
+		t.Fatalf("Failed to get synced users: %v", err)
+	}
+	if len(synced) != 3 {
+		t.Errorf("Expected 3 synced users, got %d", len(synced))
+	}
+	if _, err := models.User.Where("name", "Benjamin").First(); err != nil {
+		t.Errorf("Expected renamed user to be persisted: %v", err)
+	}
+
+	// Clean models are skipped
+	queries = eloquent.DB().Metrics().Queries
+	if err := eloquent.SaveAll(toSave); err != nil {
+		t.Fatalf("Failed to save clean users: %v", err)
+	}
+	if executed := eloquent.DB().Metrics().Queries - queries; executed != 0 {
+		t.Errorf("Expected no statements for clean models, got %d", executed)
+	}
+}
+
+func TestModelSaveAllRollsBack(t *testing.T) {
+	setupTestDB(t)
+
+	valid := models.NewUser()
+	valid.Fill(map[string]interface{}{"name": "Valid", "email": "dup@example.com", "password": "secret"})
+	duplicate := models.NewUser()
+	duplicate.Fill(map[string]interface{}{"name": "Duplicate", "email": "dup@example.com", "password": "secret"})
+
+	if err := eloquent.SaveAll([]eloquent.Model{valid, duplicate}); err == nil {
+		t.Fatal("Expected unique constraint violation")
+	}
+
+	users, err := models.User.All()
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+	if len(users) != 0 {
+		t.Errorf("Expected batch to roll back, got %d users", len(users))
+	}
+}
+
+func TestModelSaveAllReportsMissingRows(t *testing.T) {
+	setupTestDB(t)
+
+	var batch eloquent.Collection
+	for _, name := range []string{"Ann", "Ben"} {
+		user := models.NewUser()
+		user.Fill(map[string]interface{}{"name": name, "email": name + "@example.com", "password": "secret"})
+		batch = append(batch, user)
+	}
+	if err := batch.Save(); err != nil {
+		t.Fatalf("Failed to save users: %v", err)
+	}
+
+	// Ben's row disappears before the batch updates both users
+	ann, ben := batch[0].(*models.UserModel), batch[1].(*models.UserModel)
+	if _, err := eloquent.DB().Table("users").Where("id", ben.ID).Delete(); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	ann.SetAttribute("status", "synced")
+	ben.SetAttribute("status", "synced")
+
+	err := batch.Save()
+	if err == nil || !strings.Contains(err.Error(), ben.ID) || strings.Contains(err.Error(), ann.ID) {
+		t.Fatalf("Expected an error naming only the missing user, got %v", err)
+	}
+	if synced, _ := eloquent.DB().Table("users").Where("status", "synced").Count(); synced != 0 {
+		t.Errorf("Expected the batch to roll back, got %d synced users", synced)
+	}
+}
+
+func setupForeignKeyDB(t *testing.T) {
+	eloquent.WithTestConnection(t, eloquent.NewTestSQLite(t, eloquent.SQLiteOptions{ForeignKeys: true}))
+	schema := []string{
+		"CREATE TABLE users (id TEXT PRIMARY KEY, name TEXT NOT NULL, email TEXT UNIQUE NOT NULL, password TEXT NOT NULL, status TEXT, created_at DATETIME, updated_at DATETIME)",
+		"CREATE TABLE posts (id TEXT PRIMARY KEY, title TEXT NOT NULL, user_id TEXT REFERENCES users(id), created_at DATETIME, updated_at DATETIME)",
+	}
+	for _, statement := range schema {
+		if _, err := eloquent.DB().Exec(statement); err != nil {
+			t.Fatalf("Failed to set up schema: %v", err)
+		}
+	}
+}
+
+func TestUnitOfWork(t *testing.T) {
+	setupForeignKeyDB(t)
+
+	user := models.NewUser()
+	user.Fill(map[string]interface{}{"name": "Ann", "email": "ann@example.com", "password": "secret"})
+	user.SetAttribute("id", "u1")
+	post := models.NewPost()
+	post.Fill(map[string]interface{}{"title": "Hello", "user_id": "u1"})
+
+	// The post is registered first but must be inserted after the user it belongs to
+	uow := eloquent.NewUnitOfWork().Register(post, user)
+	if count, _ := eloquent.DB().Table("users").Count(); count != 0 {
+		t.Fatalf("Expected no writes before Commit, got %d users", count)
+	}
+	if err := uow.Commit(); err != nil {
+		t.Fatalf("Failed to commit unit of work: %v", err)
+	}
+	if !user.Exists() || !post.Exists() || post.ID == "" {
+		t.Errorf("Expected committed models to exist, got user %v and post %v", user.Exists(), post.Exists())
+	}
+
+	// Updates and deletes are flushed together, deleting the post before its user
+	post.Title = "Goodbye"
+	uow.Register(post).RegisterDeleted(user, post)
+	if err := uow.Commit(); err != nil {
+		t.Fatalf("Failed to commit deletes: %v", err)
+	}
+	if user.Exists() || post.Exists() {
+		t.Error("Expected deleted models to no longer exist")
+	}
+	eloquenttest.AssertDatabaseCount(t, "posts", 0)
+}
+
+func TestUnitOfWorkCommitTx(t *testing.T) {
+	setupForeignKeyDB(t)
+
+	tx, err := eloquent.DB().Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	orphan := models.NewPost()
+	orphan.Fill(map[string]interface{}{"title": "Orphan", "user_id": "missing"})
+	failed := eloquent.NewUnitOfWork().Register(orphan)
+	if err := failed.CommitTx(tx); err == nil {
+		t.Fatal("Expected a foreign key violation")
+	}
+
+	// The failed unit was rolled back to its savepoint, so the transaction is still usable
+	user := models.NewUser()
+	user.Fill(map[string]interface{}{"name": "Ben", "email": "ben@example.com", "password": "secret"})
+	if err := eloquent.NewUnitOfWork().Register(user).CommitTx(tx); err != nil {
+		t.Fatalf("Failed to commit unit of work in transaction: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+
+	// Only the second unit was committed
+	eloquenttest.AssertDatabaseHas(t, "users", map[string]interface{}{"email": "ben@example.com"})
+	eloquenttest.AssertDatabaseCount(t, "posts", 0)
+}
+
+// auditedUser records its Updating and Updated hooks in updatingCalls and updatedCalls
+type auditedUser struct {
+	*eloquent.BaseModel
+
+	ID     string `db:"id"`
+	Name   string `db:"name"`
+	Email  string `db:"email"`
+	Status string `db:"status"`
+}
+
+var updatingCalls, updatedCalls int
+
+func (u *auditedUser) Updating() error {
+	updatingCalls++
+	if u.Name == "Locked" {
+		return fmt.Errorf("user %s is locked", u.ID)
+	}
+	return nil
+}
+
+func (u *auditedUser) Updated() {
+	updatedCalls++
+}
+
+var auditedUsers = eloquent.NewModelStatic(func() *auditedUser {
+	user := &auditedUser{BaseModel: eloquent.NewBaseModel()}
+	user.Table("users").PrimaryKey("id").Fillable("name", "status").WithoutTimestamps()
+	user.SetParentModel(user)
+	return user
+})
+
+func TestModelUpdateModels(t *testing.T) {
+	setupTestDB(t)
+
+	for _, name := range []string{"Ann", "Ben", "Cid"} {
+		status := "inactive"
+		if name == "Cid" {
+			status = "active"
+		}
+		_, err := models.User.Create(map[string]interface{}{
+			"name":     name,
+			"email":    name + "@example.com",
+			"password": "password123",
+			"status":   status,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	updatingCalls, updatedCalls = 0, 0
+	updated, err := auditedUsers.Where("status", "inactive").UpdateModels(map[string]interface{}{
+		"status": "active",
+		"email":  "guarded@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Failed to update models: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("Expected 2 updated models, got %d", updated)
+	}
+	if updatingCalls != 2 || updatedCalls != 2 {
+		t.Errorf("Expected 2 updating and 2 updated hooks, got %d and %d", updatingCalls, updatedCalls)
+	}
+
+	active, err := models.User.Where("status", "active").Get()
+	if err != nil {
+		t.Fatalf("Failed to get active users: %v", err)
+	}
+	if len(active) != 3 {
+		t.Errorf("Expected 3 active users, got %d", len(active))
+	}
+	for _, user := range active {
+		if user.Email == "guarded@example.com" {
+			t.Errorf("Expected email of %s to be guarded by mass assignment", user.Name)
+		}
+	}
+
+	// An Updating hook error stops the update
+	updated, err = auditedUsers.Where("name", "Ann").UpdateModels(map[string]interface{}{"name": "Locked"})
+	if err == nil {
+		t.Fatal("Expected Updating hook to abort the update")
+	}
+	if updated != 0 {
+		t.Errorf("Expected no updated models, got %d", updated)
+	}
+	if _, err := models.User.Where("name", "Ann").First(); err != nil {
+		t.Errorf("Expected Ann to be unchanged: %v", err)
+	}
+}
+
+func TestModelUpdateModelsInChunks(t *testing.T) {
+	setupTestDB(t)
+
+	// More users than fit in one chunk, so the keys are read over several queries
+	for i := 0; i < 250; i++ {
+		_, err := eloquent.DB().Exec("INSERT INTO users (id, name, email, password, status) VALUES (?, ?, ?, 'secret', 'inactive')",
+			fmt.Sprintf("u%03d", i), fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@example.com", i))
+		if err != nil {
+			t.Fatalf("Failed to insert user: %v", err)
+		}
+	}
+
+	updatingCalls, updatedCalls = 0, 0
+	updated, err := auditedUsers.Where("status", "inactive").OrWhere("status", "pending").UpdateModels(map[string]interface{}{"status": "active"})
+	if err != nil {
+		t.Fatalf("Failed to update models: %v", err)
+	}
+	if updated != 250 || updatingCalls != 250 || updatedCalls != 250 {
+		t.Errorf("Expected 250 updated models and hooks, got %d, %d and %d", updated, updatingCalls, updatedCalls)
+	}
+	eloquenttest.AssertDatabaseMissing(t, "users", map[string]interface{}{"status": "inactive"})
+
+	// A chunk whose hook fails is rolled back as a whole, after the chunks before it committed
+	if _, err := eloquent.DB().Exec("UPDATE users SET name = 'Locked' WHERE id = 'u150'"); err != nil {
+		t.Fatalf("Failed to lock user: %v", err)
+	}
+	updated, err = auditedUsers.Where("status", "active").UpdateModels(map[string]interface{}{"status": "archived"})
+	if err == nil {
+		t.Fatal("Expected the locked user to stop the update")
+	}
+	if updated != 100 {
+		t.Errorf("Expected only the first chunk to be updated, got %d", updated)
+	}
+	if count, _ := eloquent.DB().Table("users").Where("status", "archived").Count(); count != 100 {
+		t.Errorf("Expected 100 archived users, got %d", count)
+	}
+}
+
+// softDeletingUsers is the users model with soft deletes enabled
+var softDeletingUsers = eloquent.NewModelStatic(func() *models.UserModel {
+	user := models.NewUser()
+	user.WithSoftDeletes()
+	return user
+})
+
+func TestModelDeleteByQuery(t *testing.T) {
+	setupTestDB(t)
+
+	for _, status := range []string{"banned", "banned", "active", "active"} {
+		_, err := models.User.Create(map[string]interface{}{
+			"name":     status + " user",
+			"email":    fmt.Sprintf("%s%d@example.com", status, time.Now().UnixNano()),
+			"password": "password123",
+			"status":   status,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	// Soft delete sets deleted_at and skips records that are already trashed
+	deleted, err := softDeletingUsers.Where("status", "banned").Delete()
+	if err != nil {
+		t.Fatalf("Failed to soft delete users: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 soft deleted users, got %d", deleted)
+	}
+	trashed, err := softDeletingUsers.Where("status", "banned").WhereNotNull("deleted_at").Get()
+	if err != nil {
+		t.Fatalf("Failed to get trashed users: %v", err)
+	}
+	if len(trashed) != 2 {
+		t.Errorf("Expected 2 trashed users, got %d", len(trashed))
+	}
+	deleted, err = softDeletingUsers.Where("status", "banned").Delete()
+	if err != nil {
+		t.Fatalf("Failed to repeat soft delete: %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("Expected already trashed users to be skipped, got %d", deleted)
+	}
+
+	// ForceDelete removes the rows even though the model uses soft deletes
+	deleted, err = softDeletingUsers.Where("status", "banned").ForceDelete()
+	if err != nil {
+		t.Fatalf("Failed to force delete users: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 force deleted users, got %d", deleted)
+	}
+
+	// Models without soft deletes are deleted outright
+	deleted, err = models.User.Where("status", "active").Delete()
+	if err != nil {
+		t.Fatalf("Failed to delete users: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 deleted users, got %d", deleted)
+	}
+
+	count, err := eloquent.DB().Table("users").Count()
+	if err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no users left, got %d", count)
+	}
+}
+
+func TestModelRestoreAndPurgeTrashed(t *testing.T) {
+	setupTestDB(t)
+
+	for i, status := range []string{"banned", "banned", "closed", "active"} {
+		_, err := models.User.Create(map[string]interface{}{
+			"name":     status + " user",
+			"email":    fmt.Sprintf("%s%d@example.com", status, i),
+			"password": "password123",
+			"status":   status,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	if _, err := softDeletingUsers.Where("status", "!=", "active").Delete(); err != nil {
+		t.Fatalf("Failed to soft delete users: %v", err)
+	}
+
+	trashed, err := softDeletingUsers.OnlyTrashed().Get()
+	if err != nil {
+		t.Fatalf("Failed to get trashed users: %v", err)
+	}
+	if len(trashed) != 3 {
+		t.Errorf("Expected 3 trashed users, got %d", len(trashed))
+	}
+
+	restored, err := softDeletingUsers.OnlyTrashed().Where("status", "banned").Restore()
+	if err != nil {
+		t.Fatalf("Failed to restore users: %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("Expected 2 restored users, got %d", restored)
+	}
+
+	purged, err := softDeletingUsers.OnlyTrashed().ForceDelete()
+	if err != nil {
+		t.Fatalf("Failed to purge trashed users: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 purged user, got %d", purged)
+	}
+
+	remaining, err := softDeletingUsers.Where("status", "!=", "closed").WhereNull("deleted_at").Get()
+	if err != nil {
+		t.Fatalf("Failed to get remaining users: %v", err)
+	}
+	if len(remaining) != 3 {
+		t.Errorf("Expected 3 remaining users, got %d", len(remaining))
+	}
+
+	if _, err := models.User.OnlyTrashed().Restore(); err == nil {
+		t.Error("Expected Restore to fail for a model without soft deletes")
+	}
+}
+
+// actorKey is the context key holding the acting user in tests
+type actorKey struct{}
+
+func TestModelDeleteMetadata(t *testing.T) {
+	setupTestDB(t)
+
+	for _, column := range []string{"deleted_by TEXT", "delete_reason TEXT"} {
+		if _, err := eloquent.DB().Exec("ALTER TABLE users ADD COLUMN " + column); err != nil {
+			t.Fatalf("Failed to add column: %v", err)
+		}
+	}
+	eloquent.SetActorResolver(func(ctx context.Context) interface{} {
+		return ctx.Value(actorKey{})
+	})
+	defer eloquent.SetActorResolver(nil)
+
+	auditedUsers := eloquent.NewModelStatic(func() *models.UserModel {
+		user := models.NewUser()
+		user.WithDeletedBy().WithDeleteReason()
+		return user
+	})
+	metadata := func(email string) (interface{}, interface{}) {
+		row, err := eloquent.DB().Table("users").Where("email", email).First()
+		if err != nil {
+			t.Fatalf("Failed to read user: %v", err)
+		}
+		return row["deleted_by"], row["delete_reason"]
+	}
+
+	user, err := auditedUsers.Create(map[string]interface{}{
+		"name":     "Spammer",
+		"email":    "spammer@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), actorKey{}, "admin-1")
+	if err := user.DeleteWithReason(ctx, "spam"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if by, reason := metadata("spammer@example.com"); by != "admin-1" || reason != "spam" {
+		t.Errorf("Expected the actor and reason to be recorded, got %v and %v", by, reason)
+	}
+
+	if err := user.Restore(); err != nil {
+		t.Fatalf("Failed to restore user: %v", err)
+	}
+	if by, reason := metadata("spammer@example.com"); by != nil || reason != nil {
+		t.Errorf("Expected Restore to clear the metadata, got %v and %v", by, reason)
+	}
+
+	// Query deletes resolve the actor from the query's context
+	deleted, err := auditedUsers.Where("email", "spammer@example.com").WithContext(ctx).DeleteWithReason("duplicate")
+	if err != nil || deleted != 1 {
+		t.Fatalf("Failed to delete users: %d, %v", deleted, err)
+	}
+	if by, reason := metadata("spammer@example.com"); by != "admin-1" || reason != "duplicate" {
+		t.Errorf("Expected the query's actor and reason to be recorded, got %v and %v", by, reason)
+	}
+
+	if _, err := auditedUsers.OnlyTrashed().Restore(); err != nil {
+		t.Fatalf("Failed to restore users: %v", err)
+	}
+	if by, reason := metadata("spammer@example.com"); by != nil || reason != nil {
+		t.Errorf("Expected Restore to clear the metadata, got %v and %v", by, reason)
+	}
+}
+
+func TestModelBlameable(t *testing.T) {
+	setupTestDB(t)
+
+	for _, column := range []string{"created_by TEXT", "updated_by TEXT"} {
+		if _, err := eloquent.DB().Exec("ALTER TABLE users ADD COLUMN " + column); err != nil {
+			t.Fatalf("Failed to add column: %v", err)
+		}
+	}
+	eloquent.SetActorResolver(func(ctx context.Context) interface{} {
+		return ctx.Value(actorKey{})
+	})
+	defer eloquent.SetActorResolver(nil)
+
+	blame := func(email string) (interface{}, interface{}) {
+		row, err := eloquent.DB().Table("users").Where("email", email).First()
+		if err != nil {
+			t.Fatalf("Failed to read user: %v", err)
+		}
+		return row["created_by"], row["updated_by"]
+	}
+	newUser := func(email string) *models.UserModel {
+		user := models.NewUser()
+		user.Blameable()
+		user.Fill(map[string]interface{}{"name": "Blamed", "email": email, "password": "password123"})
+		return user
+	}
+
+	user := newUser("blamed@example.com")
+	user.WithContext(context.WithValue(context.Background(), actorKey{}, "alice"))
+	if err := user.Save(); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	if created, updated := blame("blamed@example.com"); created != "alice" || updated != "alice" {
+		t.Errorf("Expected alice to be blamed for the insert, got %v and %v", created, updated)
+	}
+
+	user.WithContext(context.WithValue(context.Background(), actorKey{}, "bob"))
+	if err := user.Update(map[string]interface{}{"name": "Renamed"}); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	if created, updated := blame("blamed@example.com"); created != "alice" || updated != "bob" {
+		t.Errorf("Expected bob to be blamed for the update, got %v and %v", created, updated)
+	}
+
+	// Writes without a known actor leave the columns alone
+	user.WithContext(context.Background())
+	if err := user.Update(map[string]interface{}{"name": "Anonymous"}); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	if _, updated := blame("blamed@example.com"); updated != "bob" {
+		t.Errorf("Expected updated_by to be kept, got %v", updated)
+	}
+
+	// Batched inserts are blamed as well
+	batched := newUser("batched@example.com")
+	batched.WithContext(context.WithValue(context.Background(), actorKey{}, "carol"))
+	if err := eloquent.SaveAll([]eloquent.Model{batched}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+	if created, _ := blame("batched@example.com"); created != "carol" {
+		t.Errorf("Expected carol to be blamed for the batched insert, got %v", created)
+	}
+}
+
+func TestModelLifecycleFlags(t *testing.T) {
+	setupTestDB(t)
+
+	if user := models.NewUser(); user.Exists() || user.WasRecentlyCreated() {
+		t.Error("Expected a new model to neither exist nor be recently created")
+	}
+
+	created, err := models.User.Create(map[string]interface{}{
+		"name":     "Lifecycle",
+		"email":    "lifecycle@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if !created.Exists() || !created.WasRecentlyCreated() {
+		t.Error("Expected a created model to exist and be recently created")
+	}
+
+	found, err := models.User.Where("email", "lifecycle@example.com").First()
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+	if !found.Exists() || found.WasRecentlyCreated() {
+		t.Error("Expected a loaded model to exist and not be recently created")
+	}
+
+	found.Name = "Lifecycle Updated"
+	if err := found.Save(); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	if found.WasRecentlyCreated() {
+		t.Error("Expected an updated model not to be recently created")
+	}
+
+	if err := found.ForceDelete(); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if found.Exists() {
+		t.Error("Expected a deleted model to no longer exist")
+	}
+}
+
+func TestModelChangeHistory(t *testing.T) {
+	setupTestDB(t)
+
+	created, err := models.User.Create(map[string]interface{}{
+		"name":     "Before",
+		"email":    "history@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	user, err := models.User.Find(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+	if len(user.GetChanges()) != 0 {
+		t.Errorf("Expected no changes on a loaded model, got %v", user.GetChanges())
+	}
+
+	user.Name = "After"
+	if err := user.Save(); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+
+	changes := user.GetChanges()
+	if changes["name"] != "After" {
+		t.Errorf("Expected name change to be recorded, got %v", changes)
+	}
+	if _, ok := changes["updated_at"]; !ok {
+		t.Error("Expected updated_at change to be recorded")
+	}
+	if _, ok := changes["email"]; ok {
+		t.Error("Expected unchanged email not to be recorded")
+	}
+	if previous := user.GetPrevious(); previous["name"] != "Before" {
+		t.Errorf("Expected previous name 'Before', got %v", previous["name"])
+	}
+
+	if user.GetRawOriginal("name") != "After" {
+		t.Errorf("Expected saved name to become the original, got %v", user.GetRawOriginal("name"))
+	}
+	if original := user.GetOriginalAll(); original["email"] != "history@example.com" {
+		t.Errorf("Expected original email in GetOriginalAll, got %v", original["email"])
+	}
+	if _, ok := user.GetOriginal("updated_at").(time.Time); !ok {
+		t.Errorf("Expected cast original updated_at, got %T", user.GetOriginal("updated_at"))
+	}
+
+	// SyncChanges can also be called directly to record pending edits
+	user.SetAttribute("status", "premium")
+	user.SyncChanges()
+	if changes := user.GetChanges(); len(changes) != 1 || changes["status"] != "premium" {
+		t.Errorf("Expected only the status change, got %v", changes)
+	}
+}
+
+// namedUser appends a computed display_name built from its name and status
+type namedUser struct {
+	*eloquent.BaseModel
+
+	ID     string `db:"id"`
+	Name   string `db:"name"`
+	Status string `db:"status"`
+}
+
+var namedUsers = eloquent.NewModelStatic(func() *namedUser {
+	user := &namedUser{BaseModel: eloquent.NewBaseModel()}
+	user.Table("users").
+		Hidden("password").
+		Appends("display_name").
+		Accessor("display_name", func(m eloquent.Model) interface{} {
+			u := m.(*namedUser)
+			return u.Name + " (" + u.Status + ")"
+		})
+	user.SetParentModel(user)
+	return user
+})
+
+func TestModelAppends(t *testing.T) {
+	setupTestDB(t)
+
+	_, err := models.User.Create(map[string]interface{}{
+		"name":     "Ada",
+		"email":    "ada@example.com",
+		"password": "password123",
+		"status":   "premium",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	user, err := namedUsers.Where("email", "ada@example.com").First()
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+
+	data := user.ToMap()
+	if data["display_name"] != "Ada (premium)" {
+		t.Errorf("Expected display_name 'Ada (premium)', got %v", data["display_name"])
+	}
+	if _, ok := data["password"]; ok {
+		t.Error("Expected password to stay hidden")
+	}
+
+	payload, err := user.ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to serialize user: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("Failed to decode user JSON: %v", err)
+	}
+	if decoded["display_name"] != "Ada (premium)" || decoded["email"] != "ada@example.com" {
+		t.Errorf("Unexpected JSON payload: %s", payload)
+	}
+}
+
+func TestModelMakeVisibleAndHidden(t *testing.T) {
+	setupTestDB(t)
+
+	_, err := models.User.Create(map[string]interface{}{
+		"name":     "Admin View",
+		"email":    "admin-view@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	admin, err := models.User.Where("email", "admin-view@example.com").First()
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+	public, err := models.User.Where("email", "admin-view@example.com").First()
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+
+	admin.MakeVisible("password")
+	public.MakeHidden("email")
+
+	adminData := admin.ToMap()
+	if _, ok := adminData["password"]; !ok {
+		t.Error("Expected MakeVisible to show password")
+	}
+	if _, ok := adminData["email"]; !ok {
+		t.Error("Expected email to remain visible on the admin instance")
+	}
+
+	publicData := public.ToMap()
+	if _, ok := publicData["password"]; ok {
+		t.Error("Expected password to stay hidden on other instances")
+	}
+	if _, ok := publicData["email"]; ok {
+		t.Error("Expected MakeHidden to hide email")
+	}
+
+	// Instance overrides do not leak into newly loaded models
+	fresh, err := models.User.Where("email", "admin-view@example.com").First()
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+	freshData := fresh.ToMap()
+	if _, ok := freshData["password"]; ok {
+		t.Error("Expected password to be hidden on a fresh instance")
+	}
+	if _, ok := freshData["email"]; !ok {
+		t.Error("Expected email to be visible on a fresh instance")
+	}
+
+	// Hidden attributes win over Visible
+	fresh.Visible("name", "email").MakeHidden("email")
+	if data := fresh.ToMap(); len(data) != 1 || data["name"] != "Admin View" {
+		t.Errorf("Expected only name to be visible, got %v", data)
+	}
+}
+
+// apiUser renames, drops and omits attributes through json tags
+type apiUser struct {
+	*eloquent.BaseModel
+
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"full_name" db:"name"`
+	Email     string    `json:"-" db:"email"`
+	Status    string    `json:"status,omitempty" db:"status"`
+	CreatedAt time.Time `json:"created" db:"created_at"`
+}
+
+var apiUsers = eloquent.NewModelStatic(func() *apiUser {
+	user := &apiUser{BaseModel: eloquent.NewBaseModel()}
+	user.Table("users").
+		Hidden("password").
+		Casts(map[string]string{"created_at": "datetime"}).
+		CastsFor("external", map[string]string{"created_at": "datetime:2006-01-02"}).
+		CastsFor("internal", map[string]string{"created_at": "timestamp"})
+	user.SetParentModel(user)
+	return user
+})
+
+func TestModelJSONTagsAndContextCasts(t *testing.T) {
+	setupTestDB(t)
+
+	created, err := models.User.Create(map[string]interface{}{
+		"name":     "Grace",
+		"email":    "grace@example.com",
+		"password": "password123",
+		"status":   "",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	user, err := apiUsers.Find(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+
+	decode := func(payload []byte, err error) map[string]interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Failed to serialize user: %v", err)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("Failed to decode user JSON: %v", err)
+		}
+		return decoded
+	}
+
+	data := decode(user.ToJSON())
+	if data["full_name"] != "Grace" {
+		t.Errorf("Expected renamed full_name key, got %v", data)
+	}
+	for _, key := range []string{"name", "email", "status", "password", "created_at"} {
+		if _, ok := data[key]; ok {
+			t.Errorf("Expected %s to be renamed, dropped or omitted, got %v", key, data[key])
+		}
+	}
+	if _, ok := data["updated_at"]; !ok {
+		t.Error("Expected untagged updated_at to keep its column name")
+	}
+
+	external := decode(user.ToJSONFor("external"))
+	if external["created"] != created.CreatedAt.Format("2006-01-02") {
+		t.Errorf("Expected date-only created for external context, got %v", external["created"])
+	}
+
+	internal := decode(user.ToJSONFor("internal"))
+	if internal["created"] != float64(created.CreatedAt.Unix()) {
+		t.Errorf("Expected unix created for internal context, got %v", internal["created"])
+	}
+
+	if _, ok := user.ToMap()["created_at"].(time.Time); !ok {
+		t.Errorf("Expected default casts outside a context, got %T", user.ToMap()["created_at"])
+	}
+}
+
+func TestModelQueryDefaultsFromContext(t *testing.T) {
+	setupTestDB(t)
+
+	for _, status := range []string{"active", "active", "banned"} {
+		_, err := models.User.Create(map[string]interface{}{
+			"name":     status + " user",
+			"email":    fmt.Sprintf("%s%d@example.com", status, time.Now().UnixNano()),
+			"password": "password123",
+			"status":   status,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	ctx := eloquent.WithQueryDefaults(context.Background(), func(q *eloquent.QueryBuilder) {
+		q.Where("status", "active")
+	})
+
+	users, err := models.User.WithContext(ctx).Get()
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+	if len(users) != 2 {
+		t.Errorf("Expected 2 active users, got %d", len(users))
+	}
+
+	banned, err := models.User.Where("status", "banned").WithContext(ctx).Get()
+	if err != nil {
+		t.Fatalf("Failed to get banned users: %v", err)
+	}
+	if len(banned) != 0 {
+		t.Errorf("Expected context defaults to exclude banned users, got %d", len(banned))
+	}
+}
+
+// fakeUserRepository is an in-memory Repository used in place of the database
+type fakeUserRepository struct {
+	eloquent.Repository[*models.UserModel]
+	users map[interface{}]*models.UserModel
+}
+
+func (f *fakeUserRepository) Find(id interface{}) (*models.UserModel, error) {
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("model not found")
+}
+
+// displayName stands in for a service that depends on a repository
+func displayName(users eloquent.Repository[*models.UserModel], id interface{}) (string, error) {
+	user, err := users.Find(id)
+	if err != nil {
+		return "", err
+	}
+	return user.Name + " <" + user.Email + ">", nil
+}
+
+func TestRepository(t *testing.T) {
+	setupTestDB(t)
+
+	users := eloquent.NewRepository(models.User)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		user, err := users.Create(map[string]interface{}{
+			"name":     fmt.Sprintf("User %d", i),
+			"email":    fmt.Sprintf("user%d@example.com", i),
+			"password": "password123",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	updated, err := users.Update(ids[0], map[string]interface{}{"name": "Renamed"})
+	if err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+	if updated.Name != "Renamed" {
+		t.Errorf("Expected name 'Renamed', got %s", updated.Name)
+	}
+
+	if err := users.Delete(ids[1]); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if _, err := users.Find(ids[1]); err == nil {
+		t.Error("Expected deleted user not to be found")
+	}
+
+	all, err := users.All()
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("Expected 4 users, got %d", len(all))
+	}
+
+	page, err := users.Paginate(2, 3)
+	if err != nil {
+		t.Fatalf("Failed to paginate users: %v", err)
+	}
+	if page.Total != 4 || page.LastPage != 2 || len(page.Data) != 1 || page.From != 4 || page.To != 4 {
+		t.Errorf("Unexpected page: total=%d last=%d len=%d from=%d to=%d",
+			page.Total, page.LastPage, len(page.Data), page.From, page.To)
+	}
+
+	name, err := displayName(users, ids[0])
+	if err != nil {
+		t.Fatalf("Failed to get display name: %v", err)
+	}
+	if name != "Renamed <user0@example.com>" {
+		t.Errorf("Unexpected display name %q", name)
+	}
+
+	// Services can be tested against a fake without a database
+	fake := &fakeUserRepository{users: map[interface{}]*models.UserModel{
+		"fake-id": {Name: "Fake", Email: "fake@example.com"},
+	}}
+	if name, err := displayName(fake, "fake-id"); err != nil || name != "Fake <fake@example.com>" {
+		t.Errorf("Unexpected display name from fake: %q, %v", name, err)
+	}
+}
+
+// emailKeyedUser uses the email column as its primary key
+type emailKeyedUser struct {
+	*eloquent.BaseModel
+
+	Email string `db:"email"`
+	Name  string `db:"name"`
+}
+
+var emailKeyedUsers = eloquent.NewModelStatic(func() *emailKeyedUser {
+	user := &emailKeyedUser{BaseModel: eloquent.NewBaseModel()}
+	user.Table("users").PrimaryKey("email")
+	user.SetParentModel(user)
+	return user
+})
+
+func TestModelFindManyAndWhereKey(t *testing.T) {
+	setupTestDB(t)
+
+	var ids []interface{}
+	for i := 0; i < 4; i++ {
+		user, err := models.User.Create(map[string]interface{}{
+			"name":     fmt.Sprintf("User %d", i),
+			"email":    fmt.Sprintf("user%d@example.com", i),
+			"password": "password123",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	users, err := models.User.FindMany(ids[:3])
+	if err != nil {
+		t.Fatalf("Failed to find users: %v", err)
+	}
+	if len(users) != 3 {
+		t.Errorf("Expected 3 users, got %d", len(users))
+	}
+
+	if users, err := models.User.FindMany(nil); err != nil || len(users) != 0 {
+		t.Errorf("Expected no users for empty keys, got %d (%v)", len(users), err)
+	}
+
+	others, err := models.User.Query().WhereKeyNot(ids[:3]).Get()
+	if err != nil {
+		t.Fatalf("Failed to query users: %v", err)
+	}
+	if len(others) != 1 || others[0].ID != ids[3] {
+		t.Errorf("Expected only the fourth user, got %d users", len(others))
+	}
+
+	// Custom primary keys are used instead of id
+	user, err := emailKeyedUsers.Find("user2@example.com")
+	if err != nil {
+		t.Fatalf("Failed to find user by email key: %v", err)
+	}
+	if user.Name != "User 2" {
+		t.Errorf("Expected User 2, got %s", user.Name)
+	}
+
+	byEmail, err := emailKeyedUsers.FindMany([]interface{}{"user0@example.com", "user3@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to find users by email keys: %v", err)
+	}
+	if len(byEmail) != 2 {
+		t.Errorf("Expected 2 users, got %d", len(byEmail))
+	}
+
+	count, err := emailKeyedUsers.WhereKey("user1@example.com").Count()
+	if err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 user, got %d", count)
+	}
+}
+
+func TestModelFindOrAndFirstOr(t *testing.T) {
+	setupTestDB(t)
+
+	created, err := models.User.Create(map[string]interface{}{
+		"name":     "Existing",
+		"email":    "existing@example.com",
+		"password": "password123",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	fallbackCalls := 0
+	guest := func() (*models.UserModel, error) {
+		fallbackCalls++
+		return models.User.Create(map[string]interface{}{
+			"name":     "Guest",
+			"email":    "guest@example.com",
+			"password": "password123",
+		})
+	}
+
+	user, err := models.User.FindOr(created.ID, guest)
+	if err != nil {
+		t.Fatalf("FindOr failed: %v", err)
+	}
+	if user.Name != "Existing" || fallbackCalls != 0 {
+		t.Errorf("Expected existing user without fallback, got %s after %d calls", user.Name, fallbackCalls)
+	}
+
+	user, err = models.User.FindOr("missing-id", guest)
+	if err != nil {
+		t.Fatalf("FindOr failed: %v", err)
+	}
+	if user.Name != "Guest" || fallbackCalls != 1 {
+		t.Errorf("Expected guest from fallback, got %s after %d calls", user.Name, fallbackCalls)
+	}
+
+	user, err = models.User.Where("email", "guest@example.com").FirstOr(guest)
+	if err != nil {
+		t.Fatalf("FirstOr failed: %v", err)
+	}
+	if user.ID == "" || fallbackCalls != 1 {
+		t.Errorf("Expected stored guest without fallback, got %q after %d calls", user.ID, fallbackCalls)
+	}
+
+	// Errors from the fallback are returned
+	_, err = models.User.Where("email", "nobody@example.com").FirstOr(func() (*models.UserModel, error) {
+		return nil, fmt.Errorf("no default user")
+	})
+	if err == nil || err.Error() != "no default user" {
+		t.Errorf("Expected fallback error, got %v", err)
+	}
+
+	// Query errors other than not found skip the fallback
+	_, err = models.User.Where("email", "===", "x").FirstOr(guest)
+	if !errors.Is(err, eloquent.ErrInvalidQuery) || fallbackCalls != 1 {
+		t.Errorf("Expected ErrInvalidQuery without fallback, got %v after %d calls", err, fallbackCalls)
+	}
+
+	if _, err := models.User.Find("missing-id"); !errors.Is(err, eloquent.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound from Find, got %v", err)
+	}
+}
+
+func TestModelCountAndExists(t *testing.T) {
+	setupTestDB(t)
+
+	if exists, err := models.User.Exists(); err != nil || exists {
+		t.Errorf("Expected no users, got exists=%v (%v)", exists, err)
+	}
+
+	for i, status := range []string{"active", "active", "banned"} {
+		_, err := models.User.Create(map[string]interface{}{
+			"name":     status + " user",
+			"email":    fmt.Sprintf("%s%d@example.com", status, i),
+			"password": "password123",
+			"status":   status,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	if count, err := models.User.Count(); err != nil || count != 3 {
+		t.Errorf("Expected 3 users, got %d (%v)", count, err)
+	}
+	if count, err := models.User.Where("status", "active").Count(); err != nil || count != 2 {
+		t.Errorf("Expected 2 active users, got %d (%v)", count, err)
+	}
+	if exists, err := models.User.Where("status", "banned").Exists(); err != nil || !exists {
+		t.Errorf("Expected banned user to exist, got %v (%v)", exists, err)
+	}
+	if missing, err := models.User.Where("status", "closed").DoesntExist(); err != nil || !missing {
+		t.Errorf("Expected no closed users, got %v (%v)", missing, err)
+	}
+
+	// Constraints added by the model builder are kept
+	if _, err := softDeletingUsers.Where("status", "banned").Delete(); err != nil {
+		t.Fatalf("Failed to soft delete users: %v", err)
+	}
+	if count, err := softDeletingUsers.OnlyTrashed().Count(); err != nil || count != 1 {
+		t.Errorf("Expected 1 trashed user, got %d (%v)", count, err)
+	}
+	if exists, err := softDeletingUsers.OnlyTrashed().Where("status", "active").Exists(); err != nil || exists {
+		t.Errorf("Expected no trashed active users, got %v (%v)", exists, err)
+	}
+}
+
+func TestModelAggregates(t *testing.T) {
+	setupTestDB(t)
+
+	if latest, err := models.User.Max("created_at"); err != nil || latest != nil {
+		t.Errorf("Expected nil max for empty table, got %v (%v)", latest, err)
+	}
+
+	var created []time.Time
+	for i := 0; i < 3; i++ {
+		user, err := models.User.Create(map[string]interface{}{
+			"name":     fmt.Sprintf("User %d", i),
+			"email":    fmt.Sprintf("user%d@example.com", i),
+			"password": "password123",
+			"is_admin": i == 0,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		created = append(created, user.CreatedAt)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// Casts are applied to the result
+	latest, err := models.User.Max("created_at")
+	if err != nil {
+		t.Fatalf("Failed to get latest user: %v", err)
+	}
+	latestTime, ok := latest.(time.Time)
+	if !ok {
+		t.Fatalf("Expected time.Time from datetime cast, got %T", latest)
+	}
+	if !latestTime.Equal(created[2]) {
+		t.Errorf("Expected latest %v, got %v", created[2], latestTime)
+	}
+
+	earliest, err := models.User.Where("is_admin", false).Min("created_at")
+	if err != nil {
+		t.Fatalf("Failed to get earliest user: %v", err)
+	}
+	if earliestTime, ok := earliest.(time.Time); !ok || !earliestTime.Equal(created[1]) {
+		t.Errorf("Expected earliest non-admin %v, got %v", created[1], earliest)
+	}
+
+	admins, err := models.User.Sum("is_admin")
+	if err != nil {
+		t.Fatalf("Failed to sum admins: %v", err)
+	}
+	if admins != 1 {
+		t.Errorf("Expected 1 admin, got %v", admins)
+	}
+}
@@ -1,3 +1,5 @@
+//go:generate go run github.com/crashana/go-eloquent/cmd/eloquent-gen
+
 package models
 
 import (
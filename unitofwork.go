@@ -0,0 +1,478 @@
+package eloquent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// savepointCounter numbers the savepoints created by CommitTx so nested units get distinct names
+var savepointCounter atomic.Uint64
+
+// UnitOfWork collects model writes and flushes them together on Commit, for aggregate-style
+// domain code that changes several models and wants them persisted all or nothing:
+//
+//	uow := eloquent.NewUnitOfWork()
+//	uow.Register(order, line1, line2)
+//	uow.RegisterDeleted(oldLine)
+//	err := uow.Commit()
+//
+// Nothing is written until Commit. Inserts and updates are batched like SaveAll and run in
+// dependency order, so a model is inserted after the models it belongs to, and deletes run
+// last, children first. Dependencies come from the BelongsTo, HasOne and HasMany
+// relationships the models declare.
+//
+// All or nothing holds per connection: each connection's writes run in their own
+// transaction, committed one after another, so a unit spanning several connections can
+// fail with the writes to the earlier connections already committed.
+type UnitOfWork struct {
+	mu      sync.Mutex
+	saves   []Model
+	deletes []Model
+
+	outbox *Outbox
+	events []pendingEvent
+
+	// committed holds the model events of the last CommitTx until PublishCommitted
+	committed          []ModelEvent
+	committedPublisher EventPublisher
+}
+
+// pendingEvent is an application event waiting for the unit to commit
+type pendingEvent struct {
+	event   string
+	payload interface{}
+}
+
+// NewUnitOfWork creates an empty unit of work
+func NewUnitOfWork() *UnitOfWork {
+	return &UnitOfWork{}
+}
+
+// Register schedules models to be inserted if they are new or updated if they are dirty
+func (u *UnitOfWork) Register(models ...Model) *UnitOfWork {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.saves = append(u.saves, models...)
+	return u
+}
+
+// RegisterDeleted schedules models to be deleted, softly if they use soft deletes.
+// New models that were never saved are just dropped from the unit.
+func (u *UnitOfWork) RegisterDeleted(models ...Model) *UnitOfWork {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.deletes = append(u.deletes, models...)
+	return u
+}
+
+// UseOutbox sets the outbox PublishOnCommit stores events in. By default it is the
+// event publisher, when that is an Outbox.
+func (u *UnitOfWork) UseOutbox(outbox *Outbox) *UnitOfWork {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.outbox = outbox
+	return u
+}
+
+// PublishOnCommit stores an event in the outbox in the transaction that commits the unit,
+// so it is delivered if and only if the writes are. The payload is encoded as JSON when
+// the unit commits. The outbox must use the same connection as the models.
+func (u *UnitOfWork) PublishOnCommit(event string, payload interface{}) *UnitOfWork {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.events = append(u.events, pendingEvent{event: event, payload: payload})
+	return u
+}
+
+// Commit flushes the registered writes in one transaction per connection and clears the
+// unit so it can be reused. If a write fails its transaction is rolled back, the models it
+// did not commit get their state from before Commit back and the registrations are kept;
+// transactions of connections before it stay committed.
+func (u *UnitOfWork) Commit() error {
+	batches, snapshot, err := u.prepare()
+	if err != nil {
+		return err
+	}
+
+	for i, batch := range batches {
+		if err := batch.exec(); err != nil {
+			snapshot.restore(batches[:i])
+			return err
+		}
+		batch.finish()
+	}
+	u.reset()
+	return nil
+}
+
+// CommitTx flushes the registered writes inside a transaction the caller already opened,
+// wrapped in a savepoint: if a write fails only the unit's writes are rolled back and the
+// transaction can continue. The models must all use the connection tx belongs to.
+// A TxEventPublisher stores the unit's model events in tx. Other publishers only receive
+// them from PublishCommitted, which the caller calls once tx has committed.
+func (u *UnitOfWork) CommitTx(tx *sqlx.Tx) error {
+	batches, snapshot, err := u.prepare()
+	if err != nil {
+		return err
+	}
+	if len(batches) > 1 {
+		snapshot.restore(nil)
+		return fmt.Errorf("unit of work spans %d connections, CommitTx needs a single one", len(batches))
+	}
+
+	var committed []ModelEvent
+	var publisher EventPublisher
+	for _, batch := range batches {
+		savepoint := fmt.Sprintf("eloquent_uow_%d", savepointCounter.Add(1))
+		if _, err := tx.Exec("SAVEPOINT " + savepoint); err != nil {
+			snapshot.restore(nil)
+			return fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		if err := batch.execIn(tx); err != nil {
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint)
+			snapshot.restore(nil)
+			return err
+		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT " + savepoint); err != nil {
+			snapshot.restore(nil)
+			return fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		batch.markSaved()
+		if events := batch.unpublished(); len(events) > 0 {
+			committed = append(committed, events...)
+			publisher = batch.publisher
+		}
+	}
+	u.reset()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.committed, u.committedPublisher = committed, publisher
+	return nil
+}
+
+// PublishCommitted publishes the model events held back by the last CommitTx, for
+// publishers that are not TxEventPublishers. Call it after committing the transaction
+// passed to CommitTx; if that transaction is rolled back, the events are dropped by the
+// unit's next CommitTx instead.
+func (u *UnitOfWork) PublishCommitted() {
+	u.mu.Lock()
+	events, publisher := u.committed, u.committedPublisher
+	u.committed, u.committedPublisher = nil, nil
+	u.mu.Unlock()
+
+	if publisher != nil {
+		publishEvents(publisher, events)
+	}
+}
+
+// reset forgets the registered models after a successful commit
+func (u *UnitOfWork) reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.saves, u.deletes, u.events = nil, nil, nil
+}
+
+// prepare orders the registered writes and groups them into batches by connection. The
+// state of the models before it is returned to be put back if the batches fail.
+func (u *UnitOfWork) prepare() (batches []*batchSave, _ unitSnapshot, err error) {
+	u.mu.Lock()
+	saves := append([]Model(nil), u.saves...)
+	deletes := append([]Model(nil), u.deletes...)
+	events := append([]pendingEvent(nil), u.events...)
+	outbox := u.outbox
+	u.mu.Unlock()
+
+	order := tableOrder(append(saves, deletes...))
+	now := time.Now()
+
+	// Preparing sets timestamps, keys, slugs and soft delete columns, and runs hooks
+	snapshot, err := snapshotModels(append(saves, deletes...))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			snapshot.restore(nil)
+		}
+	}()
+
+	// Soft deletes become updates; hard deletes are written after every other statement
+	removed := make(map[*BaseModel]bool)
+	var hardDeletes []*BaseModel
+	for _, model := range deletes {
+		m := baseModelOf(model)
+		if m == nil {
+			return nil, nil, fmt.Errorf("model %T does not support units of work", model)
+		}
+		if m.IsReadOnly() {
+			return nil, nil, ErrReadOnly
+		}
+		if !m.exists {
+			removed[m] = true
+			continue
+		}
+		if m.usesSoftDeletes() {
+			values := m.deleteMetadata(m.Context(), "")
+			values[m.deletedAt] = now
+			for column, value := range values {
+				m.SetAttribute(column, value)
+			}
+			saves = append(saves, model)
+			continue
+		}
+		if !removed[m] {
+			removed[m] = true
+			hardDeletes = append(hardDeletes, m)
+		}
+	}
+
+	var pending []*BaseModel
+	for _, model := range saves {
+		m := baseModelOf(model)
+		if m == nil {
+			return nil, nil, fmt.Errorf("model %T does not support units of work", model)
+		}
+		if !removed[m] {
+			pending = append(pending, m)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return order[pending[i].GetTable()] < order[pending[j].GetTable()]
+	})
+	sort.SliceStable(hardDeletes, func(i, j int) bool {
+		return order[hardDeletes[i].GetTable()] > order[hardDeletes[j].GetTable()]
+	})
+
+	models := make([]Model, len(pending))
+	for i, m := range pending {
+		models[i] = m
+	}
+	if batches, err = prepareBatches(models); err != nil {
+		return nil, nil, err
+	}
+	if batches, err = addDeletes(batches, hardDeletes); err != nil {
+		return nil, nil, err
+	}
+	if batches, err = addOutboxEvents(batches, outbox, events); err != nil {
+		return nil, nil, err
+	}
+	return batches, snapshot, nil
+}
+
+// modelSnapshot is the state of a model before a unit of work prepared its write
+type modelSnapshot struct {
+	model              *BaseModel
+	attributes         map[string]interface{}
+	original           map[string]interface{}
+	changes            map[string]interface{}
+	previous           map[string]interface{}
+	exists             bool
+	wasRecentlyCreated bool
+}
+
+// unitSnapshot holds the state of every model of a unit
+type unitSnapshot []modelSnapshot
+
+// snapshotModels records the state of the models, each once
+func snapshotModels(models []Model) (unitSnapshot, error) {
+	snapshot := make(unitSnapshot, 0, len(models))
+	seen := make(map[*BaseModel]bool, len(models))
+	for _, model := range models {
+		m := baseModelOf(model)
+		if m == nil {
+			return nil, fmt.Errorf("model %T does not support units of work", model)
+		}
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		snapshot = append(snapshot, modelSnapshot{
+			model:              m,
+			attributes:         copyAttributes(m.attributes),
+			original:           copyAttributes(m.original),
+			changes:            copyAttributes(m.changes),
+			previous:           copyAttributes(m.previous),
+			exists:             m.exists,
+			wasRecentlyCreated: m.wasRecentlyCreated,
+		})
+	}
+	return snapshot, nil
+}
+
+// restore puts back the state of every model outside the committed batches
+func (s unitSnapshot) restore(committed []*batchSave) {
+	saved := make(map[*BaseModel]bool)
+	for _, batch := range committed {
+		for _, m := range append(append(batch.inserted, batch.updated...), batch.deleted...) {
+			saved[m] = true
+		}
+	}
+	for _, snapshot := range s {
+		if saved[snapshot.model] {
+			continue
+		}
+		m := snapshot.model
+		m.attributes = snapshot.attributes
+		m.original = snapshot.original
+		m.changes = snapshot.changes
+		m.previous = snapshot.previous
+		m.exists = snapshot.exists
+		m.wasRecentlyCreated = snapshot.wasRecentlyCreated
+	}
+}
+
+// copyAttributes returns a shallow copy of an attribute map, nil for nil
+func copyAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(attributes))
+	for key, value := range attributes {
+		copied[key] = value
+	}
+	return copied
+}
+
+// addOutboxEvents appends the statement storing the unit's events to the batch of the
+// outbox's connection
+func addOutboxEvents(batches []*batchSave, outbox *Outbox, events []pendingEvent) ([]*batchSave, error) {
+	if len(events) == 0 {
+		return batches, nil
+	}
+	if outbox == nil {
+		outbox, _ = GetEventPublisher().(*Outbox)
+	}
+	if outbox == nil {
+		return nil, fmt.Errorf("PublishOnCommit needs an outbox, set one with UseOutbox")
+	}
+
+	messages := make([]OutboxMessage, len(events))
+	for i, pending := range events {
+		message, err := newOutboxMessage(pending.event, pending.payload)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = message
+	}
+
+	batches, batch := batchFor(batches, outbox.conn)
+	query, args := outbox.insert(messages)
+	batch.statements = append(batch.statements, batchStatement{query: query, args: args})
+	return batches, nil
+}
+
+// batchFor returns the batch for a connection, adding an empty one if there is none
+func batchFor(batches []*batchSave, conn *Connection) ([]*batchSave, *batchSave) {
+	for _, batch := range batches {
+		if batch.conn == conn {
+			return batches, batch
+		}
+	}
+	batch := &batchSave{conn: conn}
+	return append(batches, batch), batch
+}
+
+// addDeletes appends DELETE statements for the models to the batches of their connections,
+// one statement per table for consecutive models of the same table, preceded by the
+// history copies of versioned models
+func addDeletes(batches []*batchSave, models []*BaseModel) ([]*batchSave, error) {
+	now := time.Now()
+	conns := make([]*Connection, len(models))
+	for i, m := range models {
+		db, err := m.resolveConnection()
+		if err != nil {
+			return nil, err
+		}
+		conns[i] = db
+	}
+
+	for start := 0; start < len(models); {
+		first, db := models[start], conns[start]
+		end := start + 1
+		for end < len(models) && end-start < maxBatchParams &&
+			models[end].GetTable() == first.GetTable() && conns[end] == db {
+			end++
+		}
+
+		var batch *batchSave
+		batches, batch = batchFor(batches, db)
+
+		keys := make([]interface{}, 0, end-start)
+		for _, m := range models[start:end] {
+			m.syncPrimaryKeyToAttributes()
+			keys = append(keys, m.GetAttribute(m.primaryKey))
+			batch.deleted = append(batch.deleted, m)
+			if m.historyTable != "" {
+				query, args := m.historyStatement(db, now)
+				batch.statements = append(batch.statements, batchStatement{query: query, args: args})
+			}
+		}
+		batch.statements = append(batch.statements, batchStatement{
+			query: fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)",
+				db.prefixTable(first.qualifiedTable()),
+				first.primaryKey,
+				strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")),
+			args:       keys,
+			keys:       keys,
+			table:      db.prefixTable(first.qualifiedTable()),
+			primaryKey: first.primaryKey,
+			delete:     true,
+		})
+		start = end
+	}
+	return batches, nil
+}
+
+// tableOrder ranks the tables of the models so every table comes after the tables it
+// depends on: those it belongs to and those declaring HasOne or HasMany relationships to
+// it. Tables in a cycle keep the order in which their models were registered.
+func tableOrder(models []Model) map[string]int {
+	var tables []string
+	seen := make(map[string]bool)
+	dependsOn := make(map[string][]string)
+
+	for _, model := range models {
+		m := baseModelOf(model)
+		if m == nil || seen[m.GetTable()] {
+			continue
+		}
+		table := m.GetTable()
+		seen[table] = true
+		tables = append(tables, table)
+
+		for _, relation := range RelationsOf(m.outerModel()) {
+			related := relatedTable(relation.Related)
+			switch relation.Type {
+			case BelongsTo:
+				dependsOn[table] = append(dependsOn[table], related)
+			case HasOne, HasMany:
+				dependsOn[related] = append(dependsOn[related], table)
+			}
+		}
+	}
+
+	rank := make(map[string]int, len(tables))
+	visiting := make(map[string]bool)
+	var visit func(table string)
+	visit = func(table string) {
+		if _, done := rank[table]; done || visiting[table] {
+			return
+		}
+		visiting[table] = true
+		for _, dependency := range dependsOn[table] {
+			visit(dependency)
+		}
+		rank[table] = len(rank)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return rank
+}
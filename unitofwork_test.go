@@ -0,0 +1,54 @@
+package eloquent
+
+import "testing"
+
+func TestUnitOfWorkRestoresFailedModels(t *testing.T) {
+	conn := NewTestSQLite(t)
+	if _, err := conn.Exec("CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT NOT NULL, created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	newCustomer := func(name interface{}) *CustomerModel {
+		customer := &CustomerModel{BaseModel: NewBaseModel()}
+		customer.Fillable("name").WithSoftDeletes().Connection(conn.Name)
+		customer.SetParentModel(customer)
+		customer.SetAttribute("name", name)
+		return customer
+	}
+
+	ada := newCustomer("Ada")
+	grace := newCustomer("Grace")
+	for _, customer := range []*CustomerModel{ada, grace} {
+		if err := customer.Save(); err != nil {
+			t.Fatalf("Failed to save customer: %v", err)
+		}
+	}
+	updatedAt := grace.GetAttribute("updated_at")
+
+	// The nameless customer fails the batch, so none of the prepared changes may stick
+	nameless := newCustomer(nil)
+	grace.SetAttribute("name", "Grace Hopper")
+	uow := NewUnitOfWork().RegisterDeleted(ada).Register(grace, nameless)
+	if err := uow.Commit(); err == nil {
+		t.Fatal("Expected the unit to fail")
+	}
+	if _, ok := ada.attributes["deleted_at"]; ok || !ada.Exists() {
+		t.Errorf("Expected the failed soft delete to be undone, got %v", ada.attributes)
+	}
+	if grace.GetAttribute("updated_at") != updatedAt || !grace.IsDirty("name") || grace.GetOriginal("name") != "Grace" {
+		t.Errorf("Expected the failed update to leave the model as it was, got %v", grace.attributes)
+	}
+	if nameless.Exists() || nameless.GetAttribute("id") != nil || nameless.GetAttribute("created_at") != nil {
+		t.Errorf("Expected the failed insert to leave the model new, got %v", nameless.attributes)
+	}
+
+	if err := NewUnitOfWork().RegisterDeleted(ada).Commit(); err != nil {
+		t.Fatalf("Failed to commit the soft delete: %v", err)
+	}
+	if ada.GetAttribute("deleted_at") == nil {
+		t.Error("Expected the soft delete to set deleted_at")
+	}
+	if count, _ := NewQueryBuilder(conn).Table("customers").WhereNotNull("deleted_at").Count(); count != 1 {
+		t.Errorf("Expected one soft deleted row, got %d", count)
+	}
+}
@@ -0,0 +1,208 @@
+package eloquent
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Columns bounding the period a history row was the current version of its record
+const (
+	validFromColumn = "valid_from"
+	validToColumn   = "valid_to"
+)
+
+// temporalTable is the source of a query reading a versioned table as of a point in time
+type temporalTable struct {
+	history   string
+	key       string
+	createdAt string
+	at        time.Time
+}
+
+// Versioned keeps the history of the model's records: before a record is updated or
+// deleted, its current row is copied into the history table, <table>_history by default,
+// in the same transaction. The history table has the model table's columns followed by
+// valid_from and valid_to, which bound when the copy was the current version; valid_from
+// is the row's updated_at or created_at and stays NULL for models without timestamps.
+// Query past states with AsOf. Call it once the table is known, after Table or
+// SetParentModel.
+func (m *BaseModel) Versioned(historyTable ...string) *BaseModel {
+	m.historyTable = m.qualifiedTable() + "_history"
+	if len(historyTable) > 0 {
+		m.historyTable = historyTable[0]
+	}
+	return m
+}
+
+// GetHistoryTable returns the history table of a versioned model, or "" if it is not versioned
+func (m *BaseModel) GetHistoryTable() string {
+	return m.historyTable
+}
+
+// historyStatement builds the INSERT copying the record as it was last loaded or saved
+// into the history table, with ? placeholders
+func (m *BaseModel) historyStatement(db *Connection, validTo time.Time) (string, []interface{}) {
+	columns := make([]string, 0, len(m.original)+2)
+	for column := range m.original {
+		if column != validFromColumn && column != validToColumn {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	args := make([]interface{}, 0, len(columns)+2)
+	for _, column := range columns {
+		args = append(args, m.original[column])
+	}
+	args = append(args, m.validFrom(), validTo)
+	columns = append(columns, validFromColumn, validToColumn)
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		db.prefixTable(m.historyTable),
+		strings.Join(columns, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+	return query, args
+}
+
+// validFrom returns when the record's stored version became current, or nil if unknown
+func (m *BaseModel) validFrom() interface{} {
+	if !m.timestamps {
+		return nil
+	}
+	for _, column := range []string{m.updatedAt, m.createdAt} {
+		if value, ok := m.original[column]; ok && value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// errNoRowsWritten rolls back a history copy when its record no longer exists
+var errNoRowsWritten = errors.New("no rows written")
+
+// execWrite runs the UPDATE or DELETE of a single record. Versioned models copy the
+// record into their history table first, in the same transaction.
+func (m *BaseModel) execWrite(db *Connection, query string, args []interface{}, validTo time.Time) (sql.Result, error) {
+	if m.historyTable == "" {
+		return db.Exec(query, args...)
+	}
+
+	var result sql.Result
+	err := db.Transaction(func(tx *sqlx.Tx) error {
+		historyQuery, historyArgs := m.historyStatement(db, validTo)
+		start := time.Now()
+		_, err := tx.Exec(tx.Rebind(historyQuery), historyArgs...)
+		db.observe(historyQuery, historyArgs, start, err)
+		if err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+
+		start = time.Now()
+		result, err = tx.Exec(query, args...)
+		db.observe(query, args, start, err)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+			return errNoRowsWritten
+		}
+		return nil
+	})
+	if errors.Is(err, errNoRowsWritten) {
+		return result, nil
+	}
+	return result, err
+}
+
+// AsOf reads the model's records as they were at the given time, combining the current
+// rows with the copies in the history table of a Versioned model. Where clauses and
+// ordering apply to the records as they were then. The query cannot update or delete.
+func (mqb *ModelQueryBuilder) AsOf(at time.Time) *ModelQueryBuilder {
+	m := baseModelOf(mqb.model)
+	if m == nil || m.historyTable == "" {
+		return mqb.wrap(mqb.QueryBuilder.mutable().fail(fmt.Errorf("%w: AsOf needs a versioned model", ErrInvalidQuery)))
+	}
+
+	createdAt := ""
+	if m.timestamps {
+		createdAt = m.createdAt
+	}
+	return mqb.wrap(mqb.QueryBuilder.asOfTable(&temporalTable{
+		history:   m.historyTable,
+		key:       m.primaryKey,
+		createdAt: createdAt,
+		at:        at,
+	}))
+}
+
+// AsOf reads the records as they were at the given time, see ModelQueryBuilder.AsOf
+func (tmqb *TypedModelQueryBuilder[T]) AsOf(at time.Time) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.modelQuery().AsOf(at).QueryBuilder)
+}
+
+// AsOf starts a query reading the records as they were at the given time
+func (ms *ModelStatic[T]) AsOf(at time.Time) *TypedModelQueryBuilder[T] {
+	return ms.Query().AsOf(at)
+}
+
+// asOfTable makes the query read from a temporal table
+func (qb *QueryBuilder) asOfTable(source *temporalTable) *QueryBuilder {
+	qb = qb.mutable()
+	qb.asOf = source
+	return qb
+}
+
+// writeAsOf writes the derived table holding the rows valid at qb.asOf.at: history rows
+// whose period contains it, and current rows created by then and not changed since
+func (qb *QueryBuilder) writeAsOf(sql *strings.Builder, getPlaceholder func() string) []interface{} {
+	source := qb.asOf
+	table := qb.connection.prefixTable(qb.table)
+	history := qb.connection.prefixTable(source.history)
+	args := []interface{}{source.at, source.at, source.at}
+
+	sql.WriteString("(SELECT * FROM ")
+	sql.WriteString(history)
+	sql.WriteString(" WHERE (" + validFromColumn + " IS NULL OR " + validFromColumn + " <= ")
+	sql.WriteString(getPlaceholder())
+	sql.WriteString(") AND " + validToColumn + " > ")
+	sql.WriteString(getPlaceholder())
+
+	sql.WriteString(" UNION ALL SELECT ")
+	sql.WriteString(table)
+	sql.WriteString(".*, NULL AS " + validFromColumn + ", NULL AS " + validToColumn + " FROM ")
+	sql.WriteString(table)
+	sql.WriteString(" WHERE NOT EXISTS (SELECT 1 FROM ")
+	sql.WriteString(history)
+	sql.WriteString(" WHERE " + history + "." + source.key + " = " + table + "." + source.key)
+	sql.WriteString(" AND " + history + "." + validToColumn + " > ")
+	sql.WriteString(getPlaceholder())
+	sql.WriteString(")")
+	if source.createdAt != "" {
+		sql.WriteString(" AND " + table + "." + source.createdAt + " <= ")
+		sql.WriteString(getPlaceholder())
+		args = append(args, source.at)
+	}
+
+	sql.WriteString(") AS ")
+	if qb.alias != "" {
+		sql.WriteString(qb.alias)
+	} else {
+		sql.WriteString(qb.table)
+	}
+	return args
+}
+
+// addHistory queues the history copy written before a versioned model is updated in a batch
+func (b *batchSave) addHistory(m *BaseModel, validTo time.Time) {
+	if m.historyTable == "" {
+		return
+	}
+	query, args := m.historyStatement(b.conn, validTo)
+	b.history = append(b.history, batchStatement{query: query, args: args})
+}
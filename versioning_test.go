@@ -0,0 +1,104 @@
+package eloquent
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVersionedModel(t *testing.T) {
+	conn := NewTestSQLite(t)
+	for _, schema := range []string{
+		"CREATE TABLE customers (id TEXT PRIMARY KEY, name TEXT, created_at DATETIME, updated_at DATETIME)",
+		"CREATE TABLE customers_history (id TEXT, name TEXT, created_at DATETIME, updated_at DATETIME, valid_from DATETIME, valid_to DATETIME)",
+	} {
+		if _, err := conn.Exec(schema); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+
+	newCustomer := func(name string) *CustomerModel {
+		customer := newEventCustomer(conn, map[string]interface{}{"name": name})
+		customer.Versioned()
+		return customer
+	}
+	tick := func() time.Time {
+		time.Sleep(5 * time.Millisecond)
+		at := time.Now()
+		time.Sleep(5 * time.Millisecond)
+		return at
+	}
+
+	customer := newCustomer("Ada")
+	if customer.GetHistoryTable() != "customers_history" {
+		t.Fatalf("Expected the default history table, got %q", customer.GetHistoryTable())
+	}
+	beforeCreate := tick()
+	if err := customer.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+	afterCreate := tick()
+	if err := customer.Update(map[string]interface{}{"name": "Ada Lovelace"}); err != nil {
+		t.Fatalf("Failed to update customer: %v", err)
+	}
+	afterUpdate := tick()
+
+	// Batched updates are versioned as well
+	customer.SetAttribute("name", "Countess Lovelace")
+	if err := SaveAll([]Model{customer}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+	afterBatch := tick()
+	if err := customer.Delete(); err != nil {
+		t.Fatalf("Failed to delete customer: %v", err)
+	}
+
+	history, err := NewQueryBuilder(conn).Table("customers_history").OrderBy("valid_to", "asc").Get()
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	if len(history) != 3 || history[0]["name"] != "Ada" || history[1]["name"] != "Ada Lovelace" ||
+		history[2]["name"] != "Countess Lovelace" || history[0]["valid_from"] == nil {
+		t.Fatalf("Expected a history row per version, got %v", history)
+	}
+
+	customers := NewModelStatic(func() *CustomerModel { return newCustomer("") })
+	nameAt := func(at time.Time) interface{} {
+		rows, err := customers.AsOf(at).Get()
+		if err != nil {
+			t.Fatalf("AsOf query failed: %v", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if len(rows) > 1 {
+			t.Fatalf("Expected at most one version at %v, got %d", at, len(rows))
+		}
+		return rows[0].GetAttribute("name")
+	}
+	for at, want := range map[time.Time]interface{}{
+		beforeCreate: nil,
+		afterCreate:  "Ada",
+		afterUpdate:  "Ada Lovelace",
+		afterBatch:   "Countess Lovelace",
+		time.Now():   nil,
+	} {
+		if got := nameAt(at); got != want {
+			t.Errorf("Expected %v as of %v, got %v", want, at, got)
+		}
+	}
+
+	// Current rows that never changed are read from the model table
+	current := newCustomer("Grace")
+	if err := current.Save(); err != nil {
+		t.Fatalf("Failed to save customer: %v", err)
+	}
+	if got := nameAt(tick()); got != "Grace" {
+		t.Errorf("Expected the unchanged customer, got %v", got)
+	}
+
+	plain := NewModelStatic(func() *CustomerModel { return newEventCustomer(conn, nil) })
+	if _, err := plain.AsOf(time.Now()).Get(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery for a model without history, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package eloquent
+
+// WhereGroup adds the where clauses callback adds to a fresh builder as one parenthesized
+// group, so OR conditions inside it don't escape the conditions around it:
+//
+//	q.Where("active", true).WhereGroup(func(g *eloquent.QueryBuilder) {
+//		g.Where("role", "admin").OrWhere("role", "owner")
+//	})
+//	// WHERE active = ? AND (role = ? OR role = ?)
+//
+// Only the callback's where clauses are used; groups can be nested.
+func (qb *QueryBuilder) WhereGroup(callback func(*QueryBuilder)) *QueryBuilder {
+	return qb.addWhereGroup(callback, "and")
+}
+
+// OrWhereGroup adds a parenthesized group of where clauses joined with OR
+func (qb *QueryBuilder) OrWhereGroup(callback func(*QueryBuilder)) *QueryBuilder {
+	return qb.addWhereGroup(callback, "or")
+}
+
+// addWhereGroup adds the where clauses callback adds to a fresh builder as a nested clause
+func (qb *QueryBuilder) addWhereGroup(callback func(*QueryBuilder), boolean string) *QueryBuilder {
+	qb = qb.mutable()
+
+	group := NewQueryBuilder(qb.connection)
+	group.table, group.alias = qb.table, qb.alias
+	callback(group)
+	if group.err != nil {
+		return qb.fail(group.err)
+	}
+	if len(group.wheres) == 0 {
+		return qb
+	}
+
+	qb.wheres = append(qb.wheres, WhereClause{Type: "nested", Boolean: boolean, Wheres: group.wheres})
+	return qb
+}
+
+// groupWheres wraps the where clauses from start on in a group when any of them after the
+// first is joined with OR. Constraints the package adds after a caller's conditions, such
+// as query defaults, scopes and soft delete filters, then apply to every row the
+// conditions match instead of only to their last OR branch.
+func (qb *QueryBuilder) groupWheres(start int) *QueryBuilder {
+	if start >= len(qb.wheres) {
+		return qb
+	}
+	for _, where := range qb.wheres[start+1:] {
+		if where.Boolean == "or" {
+			group := append([]WhereClause(nil), qb.wheres[start:]...)
+			group[0].Boolean = "and"
+			qb.wheres = append(qb.wheres[:start:start], WhereClause{Type: "nested", Boolean: qb.wheres[start].Boolean, Wheres: group})
+			qb.compiled.Store(nil)
+			return qb
+		}
+	}
+	return qb
+}
+
+// applyGrouped runs callback on the builder, grouping the where clauses it adds when they
+// contain OR so they can't escape the conditions already on the builder
+func (qb *QueryBuilder) applyGrouped(callback func(*QueryBuilder)) {
+	start := len(qb.wheres)
+	callback(qb)
+	qb.groupWheres(start)
+}
+
+// WhereGroup adds a parenthesized group of where clauses, see QueryBuilder.WhereGroup
+func (mqb *ModelQueryBuilder) WhereGroup(callback func(*QueryBuilder)) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.WhereGroup(callback))
+}
+
+// OrWhereGroup adds a parenthesized group of where clauses joined with OR
+func (mqb *ModelQueryBuilder) OrWhereGroup(callback func(*QueryBuilder)) *ModelQueryBuilder {
+	return mqb.wrap(mqb.QueryBuilder.OrWhereGroup(callback))
+}
+
+// WhereGroup adds a parenthesized group of where clauses, see QueryBuilder.WhereGroup
+func (tmqb *TypedModelQueryBuilder[T]) WhereGroup(callback func(*QueryBuilder)) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.WhereGroup(callback))
+}
+
+// OrWhereGroup adds a parenthesized group of where clauses joined with OR
+func (tmqb *TypedModelQueryBuilder[T]) OrWhereGroup(callback func(*QueryBuilder)) *TypedModelQueryBuilder[T] {
+	return tmqb.wrap(tmqb.QueryBuilder.OrWhereGroup(callback))
+}
+
+// WhereGroup starts a query with a parenthesized group of where clauses
+func (ms *ModelStatic[T]) WhereGroup(callback func(*QueryBuilder)) *TypedModelQueryBuilder[T] {
+	return ms.Query().WhereGroup(callback)
+}